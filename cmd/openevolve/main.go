@@ -0,0 +1,152 @@
+// Command openevolve runs single steps of an evolution run from the command
+// line, so evaluators and prompts can be debugged without writing Go:
+//
+//	openevolve evaluate [--config config.yaml] program.go evaluator.go
+//
+// evaluates one program and prints the full result as JSON, and
+//
+//	openevolve generate [--config config.yaml] parent.go [inspiration.go...]
+//
+// runs one generation step on a parent and prints the prompt, the raw LLM
+// response and the child code as JSON.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/ishanwen-byte/openevolve-go"
+)
+
+const usage = `usage:
+  openevolve evaluate [--config config.yaml] program evaluator
+  openevolve generate [--config config.yaml] [--score s] [--iteration n] parent [inspiration...]`
+
+// errUsage reports a command line that could not be understood
+var errUsage = errors.New(usage)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command named by the first argument, writing its result
+// to out
+func run(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "evaluate":
+		return evaluate(ctx, args[1:], out)
+	case "generate":
+		return generate(ctx, args[1:], out)
+	default:
+		return fmt.Errorf("unknown command %q\n%w", args[0], errUsage)
+	}
+}
+
+// evaluate evaluates one program and prints the result
+func evaluate(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	configPath := flags.String("config", "", "YAML configuration file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errUsage
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	code, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read program: %w", err)
+	}
+
+	result, err := openevolve.Evaluate(ctx, openevolve.EvaluateOptions{
+		Program:   string(code),
+		Evaluator: flags.Arg(1),
+		Config:    config,
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(out, result)
+}
+
+// generate runs one generation step and prints the prompt, response and
+// child. When no child can be extracted, the prompt and response are still
+// printed before the error is returned.
+func generate(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	configPath := flags.String("config", "", "YAML configuration file")
+	score := flags.Float64("score", 0, "score of the parent shown in the prompt")
+	iteration := flags.Int("iteration", 0, "iteration the prompt and LLM seed are built for")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		return errUsage
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	programs := make([]*openevolve.Program, flags.NArg())
+	for i, path := range flags.Args() {
+		code, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read program: %w", err)
+		}
+		programs[i] = &openevolve.Program{ID: filepath.Base(path), Code: string(code)}
+	}
+	programs[0].Score = *score
+
+	generation, err := openevolve.Generate(ctx, openevolve.GenerateOptions{
+		Parent:       programs[0],
+		Inspirations: programs[1:],
+		Iteration:    *iteration,
+		Config:       config,
+	})
+	if generation != nil {
+		if err := printJSON(out, generation); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// loadConfig reads the configuration at path, or returns nil for the
+// defaults when path is empty
+func loadConfig(path string) (*openevolve.Config, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return openevolve.LoadConfig(path)
+}
+
+// printJSON writes value to out as indented JSON
+func printJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to print result: %w", err)
+	}
+	return nil
+}
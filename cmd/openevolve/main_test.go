@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+func TestEvaluatePrintsResultAsJSON(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	// The evaluator is compiled together with the program it scores, so it
+	// must sit in the directory programs are written to
+	evaluatorFile, err := os.CreateTemp("", "evaluator-*.go")
+	require.NoError(t, err)
+	evaluatorPath := evaluatorFile.Name()
+	defer os.Remove(evaluatorPath)
+	_, err = evaluatorFile.WriteString(`package main
+
+import "fmt"
+
+func main() {
+	fmt.Printf("{\"score\": %g, \"success\": true, \"metrics\": {\"error\": 0.5}}", score())
+}
+`)
+	require.NoError(t, err)
+	require.NoError(t, evaluatorFile.Close())
+
+	programPath := filepath.Join(t.TempDir(), "program.go")
+	require.NoError(t, os.WriteFile(programPath, []byte("package main\n\nfunc score() float64 { return 0.75 }\n"), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, run(context.Background(), []string{"evaluate", programPath, evaluatorPath}, &out))
+
+	var result types.EvaluationResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, 0.75, result.Score)
+	assert.Equal(t, map[string]float64{"error": 0.5}, result.Metrics)
+}
+
+func TestGeneratePrintsPromptResponseAndChild(t *testing.T) {
+	const child = "package main\n\nfunc score() float64 { return 0.9 }\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := json.Marshal("Here you go:\n```go\n" + child + "```")
+		w.Write([]byte(`{"model": "fake", "choices": [{"message": {"role": "assistant", "content": ` + string(content) + `}}]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`llm:
+  api_base: `+server.URL+`
+  api_key: sk-test
+  models:
+    - name: fake
+      weight: 1
+`), 0644))
+	parentPath := filepath.Join(dir, "parent.go")
+	require.NoError(t, os.WriteFile(parentPath, []byte("package main\n\nfunc score() float64 { return 0.1 }\n"), 0644))
+	inspirationPath := filepath.Join(dir, "inspiration.go")
+	require.NoError(t, os.WriteFile(inspirationPath, []byte("package main // inspiration\n"), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, run(context.Background(), []string{"generate", "--config", configPath, "--score", "0.1", parentPath, inspirationPath}, &out))
+
+	var generation struct {
+		Prompt struct {
+			User string `json:"user"`
+		} `json:"prompt"`
+		LLMResponse string `json:"llm_response"`
+		ChildCode   string `json:"child_code"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &generation))
+	assert.Contains(t, generation.Prompt.User, "return 0.1")
+	assert.Contains(t, generation.Prompt.User, "// inspiration")
+	assert.Contains(t, generation.LLMResponse, "Here you go")
+	assert.Equal(t, strings.TrimSpace(child), strings.TrimSpace(generation.ChildCode))
+}
+
+func TestRunRejectsBadCommandLines(t *testing.T) {
+	var out bytes.Buffer
+	assert.ErrorIs(t, run(context.Background(), nil, &out), errUsage)
+	assert.ErrorIs(t, run(context.Background(), []string{"evolve"}, &out), errUsage)
+	assert.ErrorIs(t, run(context.Background(), []string{"evaluate", "program.go"}, &out), errUsage)
+	assert.ErrorIs(t, run(context.Background(), []string{"generate"}, &out), errUsage)
+	assert.Empty(t, out.String())
+}
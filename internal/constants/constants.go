@@ -14,6 +14,7 @@ const (
 	DefaultTimeout          = 60 // seconds
 	DefaultRetries          = 3
 	DefaultRetryDelay       = 5 // seconds
+	DefaultMaxRetryDelay    = 60 // seconds, cap on exponential backoff
 	DefaultTemperature      = 0.7
 	DefaultTopP             = 0.95
 	DefaultMaxTokens        = 4096
@@ -25,9 +26,101 @@ const (
 	DefaultMaxProgramsPerCell = 1
 	DefaultCheckpointInterval = 100
 
+	// Share of parent picks made uniformly under a non-uniform selection
+	DefaultExplorationRatio = 0.2
+
+	// Power-law exponent of rank-based parent selection
+	DefaultRankExponent = 1.0
+
+	// Programs drawn per tournament under tournament selection
+	DefaultTournamentSize = 3
+
+	// Boltzmann selection temperatures; scores usually lie in [0, 1]
+	DefaultBoltzmannTemperature    = 0.1
+	DefaultBoltzmannMinTemperature = 0.01
+
+	// Novelty defaults
+	DefaultNoveltyNeighbors   = 5
+	DefaultNoveltyArchiveSize = 1000
+
+	// Iterations an island may go without improving before model routing
+	// escalates it to the strong pool
+	DefaultStagnationIterations = 10
+
+	// Adaptive binning defaults
+	DefaultAdaptiveBinInterval = 5
+
+	// Generations between recomputations of dynamic grid bounds
+	DefaultDynamicBoundsInterval = 10
+
+	// CVT archive defaults
+	DefaultCVTCells      = 100
+	DefaultCVTSamples    = 10000
+	DefaultCVTIterations = 20
+
+	// Adaptive migration defaults
+	DefaultMigrationWindow      = 10
+	DefaultMinMigrationRate     = 0.0
+	DefaultMaxMigrationRate     = 0.5
+	DefaultMigrationTargetSlope = 0.01
+
+	// Generations without improvement before an island goes extinct
+	DefaultExtinctionPatience = 50
+
+	// Recency decay defaults: the largest share of a score an unvalidated
+	// elite loses, and seconds until it has lost half of that
+	DefaultRecencyMaxDiscount = 0.05
+	DefaultRecencyHalfLife    = 3600
+
+	// Fitness sharing defaults: the distance within which programs share
+	// fitness and the shape of the falloff
+	DefaultSharingRadius = 0.2
+	DefaultSharingAlpha  = 1.0
+
+	// Repetition escalation defaults
+	DefaultRepetitionWindow          = 5
+	DefaultRepetitionThreshold       = 2
+	DefaultEscalationTemperatureStep = 0.3
+	MaxEscalationTemperature         = 2.0
+
+	// Prompt shrinkage defaults
+	DefaultShrinkWindow      = 20
+	DefaultShrinkFailureRate = 0.5
+	DefaultShrinkMaxLevel    = 3
+	DefaultShrinkTokenFactor = 0.75
+
+	// Memory watchdog defaults
+	DefaultMemoryWarnFraction  = 0.8
+	DefaultMemoryPruneFraction = 0.25
+	DefaultMemoryInterval      = 30 // seconds
+
+	// Champion promotion defaults
+	DefaultPromotionValidations = 1
+	DefaultPromotionTimeout     = 60 // seconds
+
+	// Iterations of an island that dynamic budget allocation looks back on
+	DefaultIslandBudgetWindow = 20
+
 	// Artifact defaults
 	DefaultArtifactMaxSize = 10 * 1024 // 10KB
 
+	// Re-evaluations an improving child must survive; 0 disables confirmation
+	DefaultAcceptanceWindow = 0
+
+	// Share of the LLM judges' mean score added to a program's score
+	DefaultLLMFeedbackWeight = 0.1
+
+	// Command programs run as shell scripts unless configured otherwise
+	DefaultCommandInterpreter = "sh"
+	DefaultCommandExtension   = ".sh"
+
+	// Adaptive timeout defaults
+	DefaultTimeoutPercentile = 0.95
+	DefaultTimeoutMultiplier = 2.0
+	DefaultTimeoutMinSamples = 10
+	DefaultTimeoutWindow     = 100
+	DefaultTimeoutFloor      = 5 // seconds
+
 	// File extensions
 	PythonExt = ".py"
 	GoExt     = ".go"
@@ -42,13 +135,54 @@ const (
 	ArtifactsDir  = "artifacts"
 	LogsDir       = "logs"
 
+	// Audit log file name inside LogsDir
+	AuditLogFile = "audit.jsonl"
+
+	// Changelog of the best program's lineage, written to OutputDir at run
+	// end and to CheckpointDir per checkpoint
+	ChangelogFile          = "changelog.md"
+	ChangelogLineageLength = 10
+
+	// Run manifest and best program, written to OutputDir
+	RunManifestFile = "run.json"
+	BestProgramFile = "best_program.go"
+
+	// Task a leaderboard entry is filed under when neither the
+	// configuration nor an evaluator file names one
+	DefaultLeaderboardTask = "default"
+
+	// Snapshot of the global best, rewritten in OutputDir/BestDir whenever
+	// it changes
+	BestDir      = "best"
+	ChampionFile = "champion.json"
+
+	// Programs that left the archive, appended in OutputDir
+	ColdStorageFile = "cold_storage.jsonl"
+
 	// Prompt defaults
 	DefaultSystemMessage = "You are an expert programmer helping to evolve and improve code."
 	DefaultEvolutionPrompt = "Please improve the following code:"
 	DefaultMutationPrompt = "Please apply a mutation to the following code:"
+	DefaultChangesInstruction = "Begin your answer with a single line of the form \"Changes: <summary>\" briefly explaining what you changed and why, then give the code."
 	DefaultStochasticity = 0.1
 	DefaultHistoryLength = 5
 
+	// Protected region markers
+	DefaultProtectedStartMarker = "// PROTECTED-REGION-START"
+	DefaultProtectedEndMarker   = "// PROTECTED-REGION-END"
+
+	// Evolve block markers delimit the code the LLM may change
+	EvolveBlockStartMarker    = "EVOLVE-BLOCK-START"
+	EvolveBlockEndMarker      = "EVOLVE-BLOCK-END"
+	DefaultEvolveBlockRetries = 2
+
+	// Inspirations shown per prompt and how far each must be from the parent,
+	// as a fraction of changed lines
+	DefaultNumInspirations        = 3
+	DefaultInspirationMinDistance = 0.05
+	// InspirationExcerptLength caps the code shown per inspiration
+	InspirationExcerptLength      = 1000
+
 	// OpenAI API
 	DefaultOpenAIBase = "https://api.openai.com/v1"
 
@@ -76,9 +210,173 @@ const (
 	ReasoningEffortHigh   = "high"
 )
 
+// Edit modes an iteration can ask the LLM for; each may have its own model pool
+const (
+	EditModeDiff    = "diff"
+	EditModeRewrite = "rewrite"
+)
+
+// Categories of failed iterations, counted in the evolution statistics
+const (
+	FailureLLMError     = "llm_error"
+	FailureNoCodeBlock  = "no_code_block"
+	FailureDiffMismatch = "diff_mismatch"
+	FailureEvolveBlock  = "evolve_block_violation"
+	FailureOverLength   = "over_length"
+	FailureCompileError = "compile_error"
+	FailureEvalTimeout  = "eval_timeout"
+	FailureEvalError    = "eval_error"
+	FailureOther        = "other"
+)
+
+// Model pools of budget-aware routing, and why an iteration was escalated
+const (
+	ModelPoolCheap  = "cheap"
+	ModelPoolStrong = "strong"
+
+	RouteReasonChampion   = "champion"
+	RouteReasonStagnation = "stagnation"
+)
+
+// Strategies for sampling several programs at once
+const (
+	SampleStrategyPerIsland    = "per_island"
+	SampleStrategyEliteBiased  = "elite_biased"
+	SampleStrategyDiverseCells = "diverse_cells"
+)
+
+// Parent selection strategies for grid sampling
+const (
+	ParentSelectionUniform             = "uniform"
+	ParentSelectionEpsilonGreedy       = "epsilon_greedy"
+	ParentSelectionScoreProportional   = "score_proportional"
+	ParentSelectionFitnessProportional = "fitness_proportional"
+	ParentSelectionRank                = "rank"
+	ParentSelectionTournament          = "tournament"
+	ParentSelectionBoltzmann           = "boltzmann"
+)
+
+// Checkpoint encodings
+const (
+	CheckpointFormatJSON = "json"
+	CheckpointFormatGob  = "gob"
+)
+
+// Methods for scaling a feature dimension onto the grid
+const (
+	FeatureScalingMinMax   = "minmax"
+	FeatureScalingZScore   = "zscore"
+	FeatureScalingQuantile = "quantile"
+	FeatureScalingLog      = "log"
+)
+
+// Strategies for splitting a grid dimension into bins
+const (
+	GridScalingLinear   = "linear"
+	GridScalingLog      = "log"
+	GridScalingQuantile = "quantile"
+)
+
+// Archive layouts
+const (
+	ArchiveGrid = "grid"
+	ArchiveCVT  = "cvt"
+)
+
+// Distances fitness sharing measures crowding by
+const (
+	SharingDistanceFeatures = "features"
+	SharingDistanceCode     = "code"
+)
+
+// Policies for evicting a member from a full grid cell
+const (
+	CellReplacementWorstOut  = "worst_out"
+	CellReplacementOldestOut = "oldest_out"
+)
+
+// Policies for evicting programs from an island over its population cap
+const (
+	EvictionLowestFitness = "lowest_fitness"
+	EvictionOldest        = "oldest"
+	EvictionNonEliteFirst = "non_elite_first"
+)
+
+// Experiment trackers a run can report to
+const (
+	TrackingBackendMLflow = "mlflow"
+	TrackingBackendWandB  = "wandb"
+
+	DefaultTrackingExperiment = "openevolve"
+	DefaultTrackingTimeout    = 30
+	DefaultWandBBaseURL       = "https://api.wandb.ai"
+)
+
+// Why a program went to cold storage
+const (
+	ColdReasonEvicted   = "evicted"
+	ColdReasonReplaced  = "replaced"
+	ColdReasonExtinct   = "extinct"
+	ColdReasonCompacted = "compacted"
+)
+
+// Kinds of program evolution can target
+const (
+	ProgramTypeGo      = "go"
+	ProgramTypeCommand = "command"
+)
+
+// Directions an evaluator's score is optimized in
+const (
+	ScoreDirectionMaximize = "maximize"
+	ScoreDirectionMinimize = "minimize"
+)
+
+// Network access policies for evaluated programs
+const (
+	NetworkDeny  = "deny"
+	NetworkAllow = "allow"
+)
+
+// Phases a run reports progress in
+const (
+	ProgressPhaseSeeding    = "seeding"
+	ProgressPhaseEvolving   = "evolving"
+	ProgressPhaseCheckpoint = "checkpoint"
+	ProgressPhaseFinished   = "finished"
+)
+
+// Metadata keys recorded on evolved programs
+const (
+	MetadataModel = "model"
+	MetadataPool  = "pool"
+	MetadataPhase = "phase"
+)
+
+// Why the run entered one of its configured phases
+const (
+	PhaseReasonStart       = "start"
+	PhaseReasonResume      = "resume"
+	PhaseReasonIterations  = "iterations"
+	PhaseReasonStagnation  = "stagnation"
+	PhaseReasonTargetScore = "target_score"
+)
+
+// Novelty weight decay schedules
+const (
+	NoveltyDecayNone        = "none"
+	NoveltyDecayLinear      = "linear"
+	NoveltyDecayExponential = "exponential"
+)
+
 // Evaluation stages
 const (
 	EvalStageValidation = "validation"
 	EvalStageBasic      = "basic"
 	EvalStageComprehensive = "comprehensive"
-)
\ No newline at end of file
+)
+// Languages an evaluator scaffold is generated in
+const (
+	ScaffoldLanguageGo     = "go"
+	ScaffoldLanguagePython = "python"
+)
@@ -0,0 +1,30 @@
+// Package goimport holds helpers for reasoning about Go import paths
+package goimport
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Name returns the package name an import path is assumed to declare, the
+// way goimports guesses it: the last path element, skipping a trailing
+// major version such as /v2, without a go- prefix and cut at the first
+// character that cannot start a name, so gopkg.in/yaml.v3 is yaml and
+// github.com/x/go-foo is foo
+func Name(importPath string) string {
+	name := path.Base(importPath)
+	if strings.HasPrefix(name, "v") {
+		if _, err := strconv.Atoi(name[1:]); err == nil && path.Dir(importPath) != "." {
+			name = path.Base(path.Dir(importPath))
+		}
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if end := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
@@ -0,0 +1,22 @@
+package goimport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestName(t *testing.T) {
+	for importPath, name := range map[string]string{
+		"math":                   "math",
+		"path/filepath":          "filepath",
+		"math/rand/v2":           "rand",
+		"gopkg.in/yaml.v3":       "yaml",
+		"github.com/x/go-foo":    "foo",
+		"github.com/x/foo-go":    "foo",
+		"github.com/x/v2":        "x",
+		"github.com/google/uuid": "uuid",
+	} {
+		assert.Equal(t, name, Name(importPath), importPath)
+	}
+}
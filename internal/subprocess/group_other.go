@@ -0,0 +1,9 @@
+//go:build !unix
+
+package subprocess
+
+import "os/exec"
+
+// configureProcessGroup is a no-op on platforms without process groups;
+// exec.CommandContext still kills the direct child on cancellation
+func configureProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package subprocess
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessGroup starts the command in its own process group and
+// kills the whole group on cancellation, so binaries spawned by `go run`
+// do not outlive the context that started them
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		if cmd.Process == nil {
+			return nil
+		}
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
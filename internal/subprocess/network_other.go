@@ -0,0 +1,13 @@
+//go:build !linux
+
+package subprocess
+
+import "os/exec"
+
+// NetworkIsolationSupported reports whether isolateNetwork cuts commands
+// off from the network on this platform
+const NetworkIsolationSupported = false
+
+// isolateNetwork is a no-op where network namespaces are unavailable;
+// connection attempts are still detected from the command's output
+func isolateNetwork(cmd *exec.Cmd) {}
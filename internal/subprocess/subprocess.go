@@ -0,0 +1,107 @@
+// Package subprocess runs external commands the way every part of
+// OpenEvolve must: recorded in the audit log, killed together with their
+// children on cancellation and, on request, cut off from the network.
+package subprocess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
+)
+
+// waitDelay bounds how long we wait for output pipes to close after a
+// cancelled command has been killed
+const waitDelay = 2 * time.Second
+
+// Options control how Run starts a command
+type Options struct {
+	// Auditor, if set, records the command in the audit log
+	Auditor *audit.Logger
+	// DenyNetwork runs the command without network access where the
+	// platform supports it
+	DenyNetwork bool
+	// Dir is the working directory; empty inherits ours
+	Dir string
+	// Env is added to our environment for the command
+	Env []string
+}
+
+// Run executes an external command and records it in the audit log.
+// Cancelling ctx kills the command together with any processes it spawned.
+func Run(ctx context.Context, opts Options, name string, args ...string) ([]byte, error) {
+	auditor, denyNetwork := opts.Auditor, opts.DenyNetwork
+
+	var inputHash string
+	if auditor != nil {
+		inputHash = hashFileArgs(args)
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.Dir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+	configureProcessGroup(cmd)
+	if denyNetwork {
+		isolateNetwork(cmd)
+	}
+	cmd.WaitDelay = waitDelay
+	startTime := time.Now()
+	output, err := cmd.CombinedOutput()
+
+	// A command that never started may lack permission for isolation
+	var exitErr *exec.ExitError
+	if err != nil && denyNetwork && NetworkIsolationSupported && cmd.ProcessState == nil && !errors.As(err, &exitErr) {
+		err = fmt.Errorf("failed to start %s without network access (set evaluator network to allow to run unisolated): %w", name, err)
+	}
+
+	if auditor != nil {
+		entry := audit.Entry{
+			Kind:       audit.KindExec,
+			Name:       name,
+			Args:       args,
+			InputHash:  inputHash,
+			OutputHash: audit.Hash(output),
+			Duration:   time.Since(startTime),
+			ExitStatus: -1,
+		}
+		if cmd.ProcessState != nil {
+			entry.ExitStatus = cmd.ProcessState.ExitCode()
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		auditor.Record(entry)
+	}
+
+	return output, err
+}
+
+// hashFileArgs hashes the contents of every argument that names a regular file,
+// so the audit log identifies exactly which program and evaluator were run
+func hashFileArgs(args []string) string {
+	var input []byte
+	found := false
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			continue
+		}
+		input = append(input, arg...)
+		input = append(input, data...)
+		found = true
+	}
+	if !found {
+		return ""
+	}
+	return audit.Hash(input)
+}
@@ -0,0 +1,36 @@
+package subprocess
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHonorsCancellation(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The grandchild sleep must be killed along with the shell
+	start := time.Now()
+	_, err := Run(ctx, Options{}, "sh", "-c", "sleep 10 & wait")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRunAddsEnvironment(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	output, err := Run(context.Background(), Options{Env: []string{"OPENEVOLVE_TEST=set"}}, "sh", "-c", "echo $OPENEVOLVE_TEST")
+	require.NoError(t, err)
+	assert.Equal(t, "set\n", string(output))
+}
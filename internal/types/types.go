@@ -10,10 +10,37 @@ type Program struct {
 	Code        string            `json:"code"`
 	Features    []float64         `json:"features"`
 	Score       float64           `json:"score"`
+	// Metrics reported by the evaluator, the objectives of multi-objective mode
+	Metrics     map[string]float64 `json:"metrics,omitempty"`
 	Fitness     float64           `json:"fitness"`
+	// NoveltyBlended marks Fitness as including a novelty bonus
+	NoveltyBlended bool           `json:"novelty_blended,omitempty"`
 	Generation  int               `json:"generation"`
 	IslandID    int               `json:"island_id"`
+	ParentID    string            `json:"parent_id,omitempty"`
+	// InspirationIDs are the programs shown to the LLM alongside the parent
+	InspirationIDs []string       `json:"inspiration_ids,omitempty"`
+	// Changes summarizes how the program differs from its parent
+	Changes     string            `json:"changes,omitempty"`
+	// Stale marks a score produced by an evaluator program that has since
+	// changed
+	Stale       bool              `json:"stale,omitempty"`
+	// Failed marks a program whose evaluation did not succeed; it ranks
+	// below every program that did, whatever its score
+	Failed      bool              `json:"failed,omitempty"`
+	// Origin names the run a merged program came from; empty for programs
+	// evolved in this run
+	Origin      string            `json:"origin,omitempty"`
+	// Behavior is the descriptor the evaluator reported for novelty search
+	Behavior    []float64         `json:"behavior,omitempty"`
+	Children    int               `json:"children"`
+	CellGeneration int            `json:"cell_generation"`
 	Artifacts   map[string]string `json:"artifacts"`
+	// Tags label the program, e.g. with the experiment that produced it
+	Tags        []string          `json:"tags,omitempty"`
+	// Metadata holds free-form details about the program, such as the
+	// model that wrote it
+	Metadata    map[string]any    `json:"metadata,omitempty"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 }
@@ -35,6 +62,8 @@ type MAPGrid struct {
 	Resolution  map[string]int    `json:"resolution"`
 	Bounds      map[string][2]float64 `json:"bounds"`
 	Cells       map[string]*Program `json:"cells"`
+	Members     map[string][]*Program `json:"members,omitempty"`
+	Edges       map[string][]float64 `json:"edges,omitempty"`
 	TotalCells  int               `json:"total_cells"`
 	FilledCells int               `json:"filled_cells"`
 }
@@ -46,9 +75,40 @@ type EvaluationResult struct {
 	Fitness  float64           `json:"fitness"`
 	Features []float64         `json:"features"`
 	Success  bool              `json:"success"`
+	Metrics  map[string]float64 `json:"metrics,omitempty"`
+	// Behavior describes what the program did, for novelty search
+	Behavior []float64         `json:"behavior,omitempty"`
+	// NamedFeatures are feature values the evaluator reported by grid
+	// dimension, such as memory_mb
+	NamedFeatures map[string]float64 `json:"named_features,omitempty"`
 	Artifacts map[string]string `json:"artifacts"`
 	Error    string            `json:"error,omitempty"`
 	Duration time.Duration     `json:"duration"`
+	// Timeout the evaluation ran under
+	Timeout  time.Duration     `json:"timeout,omitempty"`
+	// Environment the evaluation ran in
+	Environment *Environment   `json:"environment,omitempty"`
+}
+
+// CompileError is one error the Go toolchain reported while building a
+// program
+type CompileError struct {
+	// File is the base name of the file the error is in
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	// Column is 0 when the toolchain reported none
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Environment identifies where scores were produced, so scores from other
+// machines or another version of the evaluator are not silently mixed
+type Environment struct {
+	GoVersion     string `json:"go_version"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	// EvaluatorHash is the SHA-256 of the evaluator program
+	EvaluatorHash string `json:"evaluator_hash"`
 }
 
 // LLMRequest represents a request to an LLM
@@ -59,6 +119,7 @@ type LLMRequest struct {
 	TopP        float64           `json:"top_p"`
 	MaxTokens   int               `json:"max_tokens"`
 	Timeout     time.Duration     `json:"timeout"`
+	Seed        int               `json:"seed,omitempty"`
 }
 
 // LLMMessage represents a message in an LLM conversation
@@ -71,6 +132,7 @@ type LLMMessage struct {
 type LLMResponse struct {
 	Content   string        `json:"content"`
 	Model     string        `json:"model"`
+	Member    string        `json:"member,omitempty"`
 	Usage     TokenUsage    `json:"usage"`
 	Duration  time.Duration `json:"duration"`
 	Error     error         `json:"error,omitempty"`
@@ -104,6 +166,11 @@ type Checkpoint struct {
 	GlobalBest   *Program            `json:"global_best"`
 	Config       map[string]interface{} `json:"config"`
 	Stats        EvolutionStats      `json:"stats"`
+	Environment  *Environment        `json:"environment,omitempty"`
+	// Migration events, oldest first
+	Migrations   []MigrationEvent    `json:"migrations,omitempty"`
+	// Behavior descriptors of the novelty archive, oldest first
+	NoveltyArchive [][]float64       `json:"novelty_archive,omitempty"`
 }
 
 // EvolutionStats tracks statistics about the evolution process
@@ -117,6 +184,84 @@ type EvolutionStats struct {
 	Duration         time.Duration `json:"duration"`
 	StartTime        time.Time     `json:"start_time"`
 	LastUpdate       time.Time     `json:"last_update"`
+	// Migration decisions per island when adaptive migration is enabled
+	Migration        []IslandMigration `json:"migration,omitempty"`
+	// Quality-diversity of each island after the programs added to it, in
+	// the order they were added
+	QDHistory        []QDSample `json:"qd_history,omitempty"`
+	// Each island's state at the end of every generation, in the order
+	// the generations ended
+	History          []HistorySample `json:"history,omitempty"`
+	// Programs whose scores predate the latest evaluator change
+	StalePrograms    int        `json:"stale_programs,omitempty"`
+	EvaluatorChanges []EvaluatorChange `json:"evaluator_changes,omitempty"`
+	// Failed iterations per category, such as llm_error or compile_error
+	Failures         map[string]int64 `json:"failures,omitempty"`
+	// Programs evicted to keep islands within their population size
+	Evicted          int64            `json:"evicted,omitempty"`
+	// Programs removed by compaction for trailing their cell elite
+	Compacted        int64            `json:"compacted,omitempty"`
+	// Islands wiped and reseeded after stagnating
+	Extinctions      int64            `json:"extinctions,omitempty"`
+	// Run phase the controller last entered, so a resumed run continues in it
+	Phase            string           `json:"phase,omitempty"`
+}
+
+// EvaluatorChange records the evaluator program changing during a run
+type EvaluatorChange struct {
+	Iteration int       `json:"iteration"`
+	// Hash of the new evaluator program
+	Hash      string    `json:"hash"`
+	// Programs whose scores it made stale
+	StalePrograms int   `json:"stale_programs"`
+	Time      time.Time `json:"time"`
+}
+
+// QDSample records an island's quality-diversity at an iteration
+type QDSample struct {
+	Iteration int `json:"iteration"`
+	IslandID  int `json:"island_id"`
+	// QDScore sums the scores of the island's cell elites
+	QDScore   float64 `json:"qd_score"`
+	// Coverage is the share of grid cells holding an elite
+	Coverage  float64 `json:"coverage"`
+}
+
+// HistorySample records an island's state at the end of a generation
+type HistorySample struct {
+	IslandID   int       `json:"island_id"`
+	Generation int       `json:"generation"`
+	// BestScore is 0 while the island holds no program that succeeded
+	BestScore  float64   `json:"best_score"`
+	// AvgScore averages the island's programs that did not fail
+	AvgScore   float64   `json:"avg_score"`
+	Programs   int       `json:"programs"`
+	// Occupancy is the share of grid cells holding an elite
+	Occupancy  float64   `json:"occupancy"`
+	Time       time.Time `json:"time"`
+}
+
+// IslandMigration explains the migration rate chosen for an island
+type IslandMigration struct {
+	IslandID   int     `json:"island_id"`
+	// Slope is the island's best-score gain per generation over the window
+	Slope      float64 `json:"slope"`
+	Rate       float64 `json:"rate"`
+	Stagnating bool    `json:"stagnating"`
+}
+
+// MigrationEvent records one program moving, or being copied, from one
+// island to another
+type MigrationEvent struct {
+	ProgramID string `json:"program_id"`
+	// ID of the copy that arrived, when migrants are copied
+	CopyID     string    `json:"copy_id,omitempty"`
+	From       int       `json:"from"`
+	To         int       `json:"to"`
+	// Generation of the source island when the program left
+	Generation int       `json:"generation"`
+	Score      float64   `json:"score"`
+	Time       time.Time `json:"time"`
 }
 
 // PromptTemplate represents a template for generating prompts
@@ -146,6 +291,8 @@ type Config struct {
 	Evaluator EvaluatorConfig `yaml:"evaluator" json:"evaluator"`
 	Prompt    PromptConfig    `yaml:"prompt" json:"prompt"`
 	Controller ControllerConfig `yaml:"controller" json:"controller"`
+	Audit     AuditConfig     `yaml:"audit" json:"audit"`
+	Tracking  TrackingConfig  `yaml:"tracking" json:"tracking"`
 }
 
 // LLMConfig represents LLM configuration
@@ -154,6 +301,12 @@ type LLMConfig struct {
 	APIKey           string                  `yaml:"api_key" json:"api_key"`
 	Models           []LLMModelConfig        `yaml:"models" json:"models"`
 	EvaluatorModels  []LLMModelConfig        `yaml:"evaluator_models" json:"evaluator_models"`
+	DiffModels       []LLMModelConfig        `yaml:"diff_models" json:"diff_models"`
+	RewriteModels    []LLMModelConfig        `yaml:"rewrite_models" json:"rewrite_models"`
+	// Inexpensive and strong model pools used by model routing
+	CheapModels      []LLMModelConfig        `yaml:"cheap_models" json:"cheap_models"`
+	StrongModels     []LLMModelConfig        `yaml:"strong_models" json:"strong_models"`
+	Routing          RoutingConfig           `yaml:"routing" json:"routing"`
 	SystemMessage    string                  `yaml:"system_message" json:"system_message"`
 	Temperature      float64                 `yaml:"temperature" json:"temperature"`
 	TopP             float64                 `yaml:"top_p" json:"top_p"`
@@ -165,6 +318,20 @@ type LLMConfig struct {
 	ReasoningEffort  *string                 `yaml:"reasoning_effort" json:"reasoning_effort"`
 }
 
+// RoutingConfig sends routine iterations to the cheap model pool and
+// escalates to the strong pool when an island stops improving or the
+// champion is being refined. Routing replaces the diff and rewrite pools.
+type RoutingConfig struct {
+	Enabled              bool `yaml:"enabled" json:"enabled"`
+	// StagnationIterations is how many of an island's iterations may pass
+	// without improving its best child before it is routed to the strong
+	// pool; 0 never escalates on stagnation
+	StagnationIterations int  `yaml:"stagnation_iterations" json:"stagnation_iterations"`
+	// RefineChampion routes iterations whose parent is the global best to
+	// the strong pool
+	RefineChampion       bool `yaml:"refine_champion" json:"refine_champion"`
+}
+
 // LLMModelConfig represents configuration for a single LLM model
 type LLMModelConfig struct {
 	Name             string  `yaml:"name" json:"name"`
@@ -188,11 +355,227 @@ type DatabaseConfig struct {
 	GridDimensions    []string          `yaml:"grid_dimensions" json:"grid_dimensions"`
 	GridResolution    map[string]int    `yaml:"grid_resolution" json:"grid_resolution"`
 	GridBounds        map[string][2]float64 `yaml:"grid_bounds" json:"grid_bounds"`
+	// Archive is grid, a rectangular grid over GridResolution, or cvt, whose
+	// cells are k-means centroids in the feature space and which scales to
+	// more dimensions
+	Archive           string            `yaml:"archive" json:"archive"`
+	CVT               CVTConfig         `yaml:"cvt" json:"cvt"`
+	// FeatureScaling picks how each dimension's raw values are mapped onto
+	// the grid: minmax (the default), zscore, quantile or log. Quantile and
+	// log suit heavy-tailed features such as execution time.
+	FeatureScaling    map[string]string `yaml:"feature_scaling" json:"feature_scaling"`
+	// GridScaling picks how each dimension of the grid archive is split
+	// into bins: linear (the default) over its bounds, log with bins
+	// doubling in width from the lower bound, or quantile with bins
+	// re-fitted to the population every adaptive bin interval
+	GridScaling       map[string]string `yaml:"grid_scaling" json:"grid_scaling"`
 	MigrationInterval int               `yaml:"migration_interval" json:"migration_interval"`
 	MigrationRate     float64           `yaml:"migration_rate" json:"migration_rate"`
+	// CopyMigrants sends copies of migrants under new IDs and keeps the
+	// originals on their island instead of moving them
+	CopyMigrants      bool              `yaml:"copy_migrants" json:"copy_migrants"`
+	// AsyncMigration runs migrations in the background instead of with
+	// every island paused
+	AsyncMigration    bool              `yaml:"async_migration" json:"async_migration"`
 	MaxProgramsPerCell int              `yaml:"max_programs_per_cell" json:"max_programs_per_cell"`
+	// CellReplacement picks the member a full cell evicts: worst_out or oldest_out
+	CellReplacement   string            `yaml:"cell_replacement" json:"cell_replacement"`
+	// PopulationSize caps the programs kept per island; 0 keeps them all.
+	// Eviction picks which go first: lowest_fitness, oldest, or
+	// non_elite_first, which evicts programs outside the grid before cell
+	// members. Grid elites and island and global bests are never evicted.
+	PopulationSize    int               `yaml:"population_size" json:"population_size"`
+	Eviction          string            `yaml:"eviction" json:"eviction"`
+	// ColdStorage appends programs evicted from an island or replaced in a
+	// grid cell to a JSONL file, ColdStoragePath or cold_storage.jsonl in
+	// OutputDir, so no program is lost for good
+	ColdStorage       bool              `yaml:"cold_storage" json:"cold_storage"`
+	ColdStoragePath   string            `yaml:"cold_storage_path" json:"cold_storage_path"`
+	// CheckpointInterval saves a checkpoint every this many iterations, in
+	// addition to the one saved when a run ends; 0 saves only that one
 	CheckpointInterval int              `yaml:"checkpoint_interval" json:"checkpoint_interval"`
+	// CheckpointFormat is json or the more compact binary gob; either may
+	// be gzip-compressed. Loading detects the format from the file.
+	CheckpointFormat  string            `yaml:"checkpoint_format" json:"checkpoint_format"`
+	CheckpointCompression bool          `yaml:"checkpoint_compression" json:"checkpoint_compression"`
 	OutputDir         string            `yaml:"output_dir" json:"output_dir"`
+	NoveltyWeight     float64           `yaml:"novelty_weight" json:"novelty_weight"`
+	NoveltyDecay      string            `yaml:"novelty_decay" json:"novelty_decay"`
+	NoveltyDecayIterations int          `yaml:"novelty_decay_iterations" json:"novelty_decay_iterations"`
+	// NoveltyArchive scores novelty against the behavior of past programs
+	// instead of the island's current population
+	NoveltyArchive    NoveltyArchiveConfig `yaml:"novelty_archive" json:"novelty_archive"`
+	SampleStrategy    string            `yaml:"sample_strategy" json:"sample_strategy"`
+	StalenessBias     float64           `yaml:"staleness_bias" json:"staleness_bias"`
+	// RandomSeed seeds the database's own random source, used when sampling
+	// without a caller-supplied one; 0 takes the controller's seed, and a
+	// time-based seed when that is 0 too
+	RandomSeed        int64             `yaml:"random_seed" json:"random_seed"`
+	// ParentSelection picks parents: uniform, epsilon_greedy (the best
+	// elite), score_proportional, fitness_proportional (weighted by
+	// novelty-blended fitness), rank, boltzmann or tournament. All but
+	// tournament pick among grid elites. Except under uniform,
+	// ExplorationRatio of picks are still made uniformly to keep exploring.
+	ParentSelection   string            `yaml:"parent_selection" json:"parent_selection"`
+	// IslandParentSelection overrides ParentSelection for individual islands
+	IslandParentSelection map[int]string `yaml:"island_parent_selection" json:"island_parent_selection"`
+	// IslandOverrides configure individual islands differently from the rest
+	IslandOverrides   map[int]IslandOverride `yaml:"island_overrides" json:"island_overrides"`
+	// TournamentSize is how many programs a tournament draws from the
+	// island's population
+	TournamentSize    int               `yaml:"tournament_size" json:"tournament_size"`
+	Boltzmann         BoltzmannConfig   `yaml:"boltzmann" json:"boltzmann"`
+	ExplorationRatio  float64           `yaml:"exploration_ratio" json:"exploration_ratio"`
+	// RankExponent weights the elite ranked r (1 is the best) by r^-exponent
+	// under rank selection; 0 is uniform and larger values favour elites
+	RankExponent      float64           `yaml:"rank_exponent" json:"rank_exponent"`
+	// AdaptiveBinning makes quantile the grid scaling of every dimension
+	// without one of its own
+	AdaptiveBinning   bool              `yaml:"adaptive_binning" json:"adaptive_binning"`
+	AdaptiveBinInterval int             `yaml:"adaptive_bin_interval" json:"adaptive_bin_interval"`
+	// DynamicBounds periodically resets grid bounds to the observed feature
+	// range and remaps the elites into the new grid
+	DynamicBounds     bool              `yaml:"dynamic_bounds" json:"dynamic_bounds"`
+	DynamicBoundsInterval int           `yaml:"dynamic_bounds_interval" json:"dynamic_bounds_interval"`
+	// Objectives switch parent selection to non-dominated sorting over these
+	// metrics; empty selects on Score alone
+	Objectives        []Objective       `yaml:"objectives" json:"objectives"`
+	AdaptiveMigration AdaptiveMigrationConfig `yaml:"adaptive_migration" json:"adaptive_migration"`
+	Extinction        ExtinctionConfig  `yaml:"extinction" json:"extinction"`
+	RecencyDecay      RecencyDecayConfig `yaml:"recency_decay" json:"recency_decay"`
+	Compaction        CompactionConfig  `yaml:"compaction" json:"compaction"`
+	FitnessSharing    FitnessSharingConfig `yaml:"fitness_sharing" json:"fitness_sharing"`
+	// Elitism pins every island's best as the global best always is: it is
+	// never evicted from its cell, migrated away, wiped by extinction or
+	// replaced under its ID, and every checkpoint writes it
+	Elitism           bool              `yaml:"elitism" json:"elitism"`
+	// Tags are attached to every program the run seeds or evolves, e.g. to
+	// label an experiment
+	Tags              []string          `yaml:"tags" json:"tags"`
+}
+
+// NoveltyArchiveConfig keeps the behavior descriptors of past programs:
+// the behavior vector an evaluator reports, such as an embedding, or else
+// the program's raw features. A program's novelty is its mean distance to
+// the nearest descriptors in the archive.
+type NoveltyArchiveConfig struct {
+	Enabled   bool    `yaml:"enabled" json:"enabled"`
+	// Neighbors is how many nearest descriptors novelty is measured
+	// against; 0 uses the default
+	Neighbors int     `yaml:"neighbors" json:"neighbors"`
+	// Size caps the archive, dropping the oldest descriptors; 0 uses the
+	// default
+	Size      int     `yaml:"size" json:"size"`
+	// Threshold is the novelty a descriptor needs to enter the archive
+	Threshold float64 `yaml:"threshold" json:"threshold"`
+}
+
+// ExtinctionConfig restarts islands that stopped improving: their programs
+// are wiped, the grid elites kept in cold storage, and the island is
+// reseeded with a copy of the global best for its iterations to mutate
+type ExtinctionConfig struct {
+	Enabled  bool `yaml:"enabled" json:"enabled"`
+	// Patience is how many generations an island may go without improving
+	// its best score; 0 uses the default
+	Patience int  `yaml:"patience" json:"patience"`
+}
+
+// RecencyDecayConfig discounts elites that have not been evaluated or
+// rescored recently when they compete for grid cells and the global best,
+// so champions scored by an outdated evaluator give way over time
+type RecencyDecayConfig struct {
+	Enabled     bool    `yaml:"enabled" json:"enabled"`
+	// MaxDiscount is the fraction of its score a program loses at most,
+	// approached as it ages; 0 uses the default
+	MaxDiscount float64 `yaml:"max_discount" json:"max_discount"`
+	// HalfLife is how many seconds after its last evaluation a program
+	// loses half of MaxDiscount; 0 uses the default
+	HalfLife    int     `yaml:"half_life" json:"half_life"`
+}
+
+// FitnessSharingConfig divides the fitness programs are picked as parents
+// by with how crowded their neighborhood is, so that one lineage cannot
+// dominate an island
+type FitnessSharingConfig struct {
+	Enabled  bool    `yaml:"enabled" json:"enabled"`
+	// Distance is "features" (scaled feature space) or "code" (line
+	// distance); empty uses features
+	Distance string  `yaml:"distance" json:"distance"`
+	// Radius is the distance within which programs share fitness; 0 uses
+	// the default
+	Radius   float64 `yaml:"radius" json:"radius"`
+	// Alpha shapes how sharing falls off with distance, 1 being linear;
+	// 0 uses the default
+	Alpha    float64 `yaml:"alpha" json:"alpha"`
+}
+
+// CompactionConfig removes programs that the elite of their grid cell
+// outperforms, keeping the archive small in long runs
+type CompactionConfig struct {
+	// Threshold is how much fitness the cell elite must lead a program by
+	// for the program to be removed; 0 removes every program it beats
+	Threshold   float64 `yaml:"threshold" json:"threshold"`
+	// MaxPrograms compacts the database whenever it holds more programs;
+	// 0 compacts only on request
+	MaxPrograms int     `yaml:"max_programs" json:"max_programs"`
+}
+
+// IslandOverride replaces parts of the configuration on one island. Fields
+// left empty keep the run-wide setting.
+type IslandOverride struct {
+	// Models the island's iterations use in place of the LLM models, edit
+	// mode pools and model routing; unset model settings are taken from the
+	// LLM configuration
+	Models          []LLMModelConfig `yaml:"models" json:"models"`
+	Temperature     float64          `yaml:"temperature" json:"temperature"`
+	SystemMessage   string           `yaml:"system_message" json:"system_message"`
+	EvolutionPrompt string           `yaml:"evolution_prompt" json:"evolution_prompt"`
+	// ParentSelection takes precedence over IslandParentSelection
+	ParentSelection string           `yaml:"parent_selection" json:"parent_selection"`
+}
+
+// AdaptiveMigrationConfig sets each island's migration rate from how fast
+// its best score improves: stagnating islands take in more migrants,
+// improving ones are left isolated
+type AdaptiveMigrationConfig struct {
+	Enabled     bool    `yaml:"enabled" json:"enabled"`
+	// Window is how many generations the best-score slope is measured over
+	Window      int     `yaml:"window" json:"window"`
+	// Rates used for an improving and a stagnating island
+	MinRate     float64 `yaml:"min_rate" json:"min_rate"`
+	MaxRate     float64 `yaml:"max_rate" json:"max_rate"`
+	// TargetSlope is the best-score gain per generation at which an island
+	// counts as improving; slower islands get proportionally more migrants
+	TargetSlope float64 `yaml:"target_slope" json:"target_slope"`
+}
+
+// BoltzmannConfig sets the temperature of boltzmann parent selection, which
+// picks an elite with weight exp(score/temperature). High temperatures
+// explore broadly; annealing lowers it so later iterations exploit.
+type BoltzmannConfig struct {
+	// Temperature at an island's first generation
+	Temperature    float64 `yaml:"temperature" json:"temperature"`
+	// MinTemperature is the floor annealing stops at
+	MinTemperature float64 `yaml:"min_temperature" json:"min_temperature"`
+	// HalfLife is how many generations of an island halve its temperature;
+	// 0 keeps it fixed
+	HalfLife       int     `yaml:"half_life" json:"half_life"`
+}
+
+// CVTConfig shapes the cells of a CVT archive, computed by k-means over
+// random points in the feature space normalized by GridBounds. Zero values
+// use the defaults.
+type CVTConfig struct {
+	Cells      int `yaml:"cells" json:"cells"`
+	// Samples is how many random points are clustered into cells
+	Samples    int `yaml:"samples" json:"samples"`
+	Iterations int `yaml:"iterations" json:"iterations"`
+}
+
+// Objective is an evaluator metric optimized in multi-objective mode
+type Objective struct {
+	Metric   string `yaml:"metric" json:"metric"`
+	Minimize bool   `yaml:"minimize" json:"minimize"`
 }
 
 // EvaluatorConfig represents evaluator configuration
@@ -202,6 +585,68 @@ type EvaluatorConfig struct {
 	Timeout           int               `yaml:"timeout" json:"timeout"`
 	CollectArtifacts  bool              `yaml:"collect_artifacts" json:"collect_artifacts"`
 	ArtifactMaxSize   int               `yaml:"artifact_max_size" json:"artifact_max_size"`
+	// AcceptanceWindow is how many times an improving child and its parent
+	// are re-evaluated before the child is accepted, which requires the child
+	// to beat the parent on every paired re-run; 0 (the default) disables it
+	AcceptanceWindow  int               `yaml:"acceptance_window" json:"acceptance_window"`
+	AdaptiveTimeout   AdaptiveTimeoutConfig `yaml:"adaptive_timeout" json:"adaptive_timeout"`
+	// Network is deny (the default) or allow. Denied programs run without
+	// network access where the platform supports it, and connection
+	// attempts are reported in the network_attempts artifact.
+	Network           string            `yaml:"network" json:"network"`
+	// ProgramType is go (the default) or command, for evolving scripts run
+	// by an interpreter
+	ProgramType       string            `yaml:"program_type" json:"program_type"`
+	Command           CommandProgramConfig `yaml:"command" json:"command"`
+	// ReevaluateOnChange re-evaluates the grid elites when the evaluator
+	// program changes during a run; otherwise their scores are only
+	// marked stale
+	ReevaluateOnChange bool             `yaml:"reevaluate_on_change" json:"reevaluate_on_change"`
+	// ChunkSize splits Go programs larger than this many bytes into a
+	// temporary module of files of about this size, with a generated
+	// go.mod; the evaluator is then given the module directory in place of
+	// the program file. 0 (the default) never splits.
+	ChunkSize         int               `yaml:"chunk_size" json:"chunk_size"`
+	// ScoreDirection is maximize (the default) or minimize. Minimized
+	// scores are negated as they leave the evaluator, so higher is better
+	// everywhere else; target_score stays in the evaluator's units.
+	ScoreDirection    string            `yaml:"score_direction" json:"score_direction"`
+	// UseLLMFeedback has the LLM's evaluator models judge every program that
+	// evaluates successfully; their scores are recorded as llm_ metrics
+	UseLLMFeedback    bool              `yaml:"use_llm_feedback" json:"use_llm_feedback"`
+	// LLMFeedbackWeight of the judges' mean score is added to the program's
+	// score; 0 only records the metrics
+	LLMFeedbackWeight float64           `yaml:"llm_feedback_weight" json:"llm_feedback_weight"`
+}
+
+// CommandProgramConfig describes how command programs are run. Each script
+// runs in a fresh directory holding a copy of the fixtures; the evaluator
+// is then run there with the script path, the path of a file holding the
+// script's combined output and its exit code as arguments.
+type CommandProgramConfig struct {
+	// Interpreter and its arguments; the script path is appended
+	Interpreter []string `yaml:"interpreter" json:"interpreter"`
+	// Extension of the script file, such as .sh
+	Extension   string   `yaml:"extension" json:"extension"`
+	// Fixtures is a directory copied into each script's working directory
+	Fixtures    string   `yaml:"fixtures" json:"fixtures"`
+}
+
+// AdaptiveTimeoutConfig derives evaluation timeouts from the durations of
+// recent successful evaluations instead of using the fixed timeout
+type AdaptiveTimeoutConfig struct {
+	Enabled    bool    `yaml:"enabled" json:"enabled"`
+	// Percentile of observed durations, in (0, 1], scaled by Multiplier
+	Percentile float64 `yaml:"percentile" json:"percentile"`
+	Multiplier float64 `yaml:"multiplier" json:"multiplier"`
+	// MinSamples successful evaluations are needed before adapting
+	MinSamples int     `yaml:"min_samples" json:"min_samples"`
+	// Window is how many recent durations are kept
+	Window     int     `yaml:"window" json:"window"`
+	// Floor and ceiling in seconds; a zero ceiling keeps the fixed timeout
+	// as the upper bound
+	MinTimeout int     `yaml:"min_timeout" json:"min_timeout"`
+	MaxTimeout int     `yaml:"max_timeout" json:"max_timeout"`
 }
 
 // CascadeStage represents a stage in cascade evaluation
@@ -210,6 +655,7 @@ type CascadeStage struct {
 	Threshold    float64 `yaml:"threshold" json:"threshold"`
 	Timeout      int     `yaml:"timeout" json:"timeout"`
 	Critical     bool    `yaml:"critical" json:"critical"`
+	Independent  bool    `yaml:"independent" json:"independent"`
 }
 
 // PromptConfig represents prompt configuration
@@ -221,6 +667,54 @@ type PromptConfig struct {
 	Stochasticity    float64            `yaml:"stochasticity" json:"stochasticity"`
 	IncludeHistory   bool               `yaml:"include_history" json:"include_history"`
 	HistoryLength    int                `yaml:"history_length" json:"history_length"`
+	ProtectedRegions ProtectedRegionsConfig `yaml:"protected_regions" json:"protected_regions"`
+	EvolveBlockRetries int              `yaml:"evolve_block_retries" json:"evolve_block_retries"`
+	Repetition       RepetitionConfig   `yaml:"repetition" json:"repetition"`
+	Shrink           ShrinkConfig       `yaml:"shrink" json:"shrink"`
+	// InspirationMinDistance is the fraction of lines an inspiration must
+	// differ from the parent by; identical programs are always dropped
+	InspirationMinDistance float64      `yaml:"inspiration_min_distance" json:"inspiration_min_distance"`
+	// InspirationDistinctCells also drops inspirations in the parent's grid cell
+	InspirationDistinctCells bool       `yaml:"inspiration_distinct_cells" json:"inspiration_distinct_cells"`
+	// SummarizeInspirations shows each inspiration as an LLM-written summary
+	// of its approach plus its metrics instead of its truncated code, so
+	// more of them fit in the context window
+	SummarizeInspirations bool          `yaml:"summarize_inspirations" json:"summarize_inspirations"`
+	// ChangesInstruction asks the LLM to open its answer with a
+	// "Changes:" line, which becomes the child's change summary; empty
+	// leaves it out of the prompt
+	ChangesInstruction string           `yaml:"changes_instruction" json:"changes_instruction"`
+}
+
+// RepetitionConfig controls how repeated LLM outputs for the same parent
+// are detected and escalated
+type RepetitionConfig struct {
+	Window          int     `yaml:"window" json:"window"`
+	Threshold       int     `yaml:"threshold" json:"threshold"`
+	TemperatureStep float64 `yaml:"temperature_step" json:"temperature_step"`
+}
+
+// ShrinkConfig simplifies the prompt while too many recent iterations fail
+// with over-length or malformed output. Each level halves the inspirations
+// and the code shown for each, and scales max tokens by TokenFactor; the
+// prompt grows back a level once failures fall below half the rate.
+type ShrinkConfig struct {
+	Enabled     bool    `yaml:"enabled" json:"enabled"`
+	// Window is how many recent iterations the failure rate covers
+	Window      int     `yaml:"window" json:"window"`
+	FailureRate float64 `yaml:"failure_rate" json:"failure_rate"`
+	MaxLevel    int     `yaml:"max_level" json:"max_level"`
+	TokenFactor float64 `yaml:"token_factor" json:"token_factor"`
+}
+
+// ProtectedRegionsConfig represents code regions that are hidden from the LLM
+// and re-attached to every child verbatim
+type ProtectedRegionsConfig struct {
+	Enabled     bool   `yaml:"enabled" json:"enabled"`
+	Header      bool   `yaml:"header" json:"header"`
+	Imports     bool   `yaml:"imports" json:"imports"`
+	StartMarker string `yaml:"start_marker" json:"start_marker"`
+	EndMarker   string `yaml:"end_marker" json:"end_marker"`
 }
 
 // ControllerConfig represents controller configuration
@@ -228,9 +722,138 @@ type ControllerConfig struct {
 	MaxIterations    int               `yaml:"max_iterations" json:"max_iterations"`
 	MaxGenerations   int               `yaml:"max_generations" json:"max_generations"`
 	TargetScore      *float64          `yaml:"target_score" json:"target_score"`
+	TargetCheck      TargetCheckConfig `yaml:"target_check" json:"target_check"`
 	ParallelWorkers  int               `yaml:"parallel_workers" json:"parallel_workers"`
 	CheckpointDir    string            `yaml:"checkpoint_dir" json:"checkpoint_dir"`
+	// ResumeFrom is a checkpoint file, or a checkpoint directory to resume
+	// from its latest intact checkpoint
 	ResumeFrom       string            `yaml:"resume_from" json:"resume_from"`
 	Seed             int               `yaml:"seed" json:"seed"`
 	Verbose          bool              `yaml:"verbose" json:"verbose"`
-}
\ No newline at end of file
+	Changelog        bool              `yaml:"changelog" json:"changelog"`
+	// Caps iteration starts per minute across all islands; 0 disables pacing
+	MaxIterationsPerMinute float64 `yaml:"max_iterations_per_minute" json:"max_iterations_per_minute"`
+	Memory           MemoryConfig      `yaml:"memory" json:"memory"`
+	Promotion        PromotionConfig   `yaml:"promotion" json:"promotion"`
+	IslandBudget     IslandBudgetConfig `yaml:"island_budget" json:"island_budget"`
+	Leaderboard      LeaderboardConfig `yaml:"leaderboard" json:"leaderboard"`
+	// Phases schedule the run as a sequence of stages, such as bootstrap,
+	// explore, exploit, refine and finalize; empty runs without phases
+	Phases           []PhaseConfig     `yaml:"phases" json:"phases"`
+}
+
+// PhaseConfig is one stage of a run. A phase ends once any of its
+// transition conditions holds and the run moves on to the next; the last
+// phase lasts until the run ends. Overrides left empty keep the run-wide
+// setting, and island overrides take precedence over them.
+type PhaseConfig struct {
+	Name        string   `yaml:"name" json:"name"`
+	// Iterations ends the phase once this many of its iterations finished
+	Iterations  int      `yaml:"iterations" json:"iterations"`
+	// Stagnation ends the phase after this many iterations in a row
+	// without a new global best
+	Stagnation  int      `yaml:"stagnation" json:"stagnation"`
+	// TargetScore, in the evaluator's units, ends the phase once the best
+	// program reaches it
+	TargetScore *float64 `yaml:"target_score" json:"target_score"`
+	Temperature float64  `yaml:"temperature" json:"temperature"`
+	ParentSelection string `yaml:"parent_selection" json:"parent_selection"`
+	// Model forces the named LLM model, bypassing edit mode pools and
+	// model routing
+	Model       string   `yaml:"model" json:"model"`
+}
+
+// LeaderboardConfig records the best result of every run on a task in a
+// shared leaderboard file when the run ends
+type LeaderboardConfig struct {
+	// Dir holds one leaderboard file per task; empty disables the
+	// leaderboard
+	Dir  string `yaml:"dir" json:"dir"`
+	// Task names the benchmark runs compete on; empty uses the name of
+	// the directory holding the evaluator
+	Task string `yaml:"task" json:"task"`
+}
+
+// TargetCheckConfig re-checks a champion that reaches the target score
+// before the run stops, so evaluator noise cannot end a run early. With
+// neither evaluations nor a hold-out evaluator the run stops at once.
+type TargetCheckConfig struct {
+	// Evaluations re-evaluates the champion this many times; every run must
+	// succeed and their mean must still reach the target
+	Evaluations      int    `yaml:"evaluations" json:"evaluations"`
+	// HoldoutEvaluator is an evaluator program the champion must also
+	// reach the target on
+	HoldoutEvaluator string `yaml:"holdout_evaluator" json:"holdout_evaluator"`
+}
+
+// IslandBudgetConfig divides the run's iterations between islands. Without
+// shares or dynamic allocation, islands take iterations as fast as they
+// finish them.
+type IslandBudgetConfig struct {
+	// Shares weighs each island's part of the iterations, by island index;
+	// islands past the end of the list get 1
+	Shares  []float64 `yaml:"shares" json:"shares"`
+	// Dynamic also weighs each island by one plus the improvements to its
+	// best among its last Window iterations
+	Dynamic bool      `yaml:"dynamic" json:"dynamic"`
+	Window  int       `yaml:"window" json:"window"`
+}
+
+// PromotionConfig deploys every new champion that passes validation, so a
+// long run keeps a production function up to date
+type PromotionConfig struct {
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	// Validations re-evaluates the champion this many times; every run must
+	// succeed and their mean must still beat the last promoted score
+	Validations int      `yaml:"validations" json:"validations"`
+	// MinScore, in the evaluator's units, a champion must reach
+	MinScore    *float64 `yaml:"min_score" json:"min_score"`
+	// CopyTo is replaced atomically with the champion's code
+	CopyTo      string   `yaml:"copy_to" json:"copy_to"`
+	// Command runs with the path of the champion's code appended
+	Command     []string `yaml:"command" json:"command"`
+	// Webhook receives the champion as a JSON POST
+	Webhook     string   `yaml:"webhook" json:"webhook"`
+	// Timeout in seconds for the command and the webhook
+	Timeout     int      `yaml:"timeout" json:"timeout"`
+}
+
+// MemoryConfig guards long runs against being killed for running out of
+// memory. Once the process's resident memory crosses the limit, the islands
+// are paused, their populations pruned and a checkpoint saved.
+type MemoryConfig struct {
+	// LimitMB is the resident memory in MiB that triggers pruning; 0
+	// disables the watchdog
+	LimitMB       int     `yaml:"limit_mb" json:"limit_mb"`
+	// WarnFraction of the limit logs a warning ahead of pruning
+	WarnFraction  float64 `yaml:"warn_fraction" json:"warn_fraction"`
+	// PruneFraction of each island's evictable programs is evicted
+	PruneFraction float64 `yaml:"prune_fraction" json:"prune_fraction"`
+	// Interval between memory checks in seconds
+	Interval      int     `yaml:"interval" json:"interval"`
+}
+
+// AuditConfig represents configuration for the audit log of external calls
+type AuditConfig struct {
+	Enabled        bool     `yaml:"enabled" json:"enabled"`
+	Path           string   `yaml:"path" json:"path"`
+	RedactPatterns []string `yaml:"redact_patterns" json:"redact_patterns"`
+}
+
+// TrackingConfig reports a run's configuration, per-iteration metrics and
+// final artifacts to an external experiment tracker
+type TrackingConfig struct {
+	// Backend is mlflow or wandb; empty disables tracking
+	Backend    string `yaml:"backend" json:"backend"`
+	// URI is the MLflow tracking server, or the W&B API base URL
+	URI        string `yaml:"uri" json:"uri"`
+	// Experiment is the MLflow experiment or W&B project
+	Experiment string `yaml:"experiment" json:"experiment"`
+	// Entity is the W&B user or team; empty uses the key's default
+	Entity     string `yaml:"entity" json:"entity"`
+	RunName    string `yaml:"run_name" json:"run_name"`
+	// APIKey authenticates with W&B, or is sent to MLflow as a bearer token
+	APIKey     string `yaml:"api_key" json:"api_key"`
+	// Timeout of each request to the tracker in seconds
+	Timeout    int    `yaml:"timeout" json:"timeout"`
+}
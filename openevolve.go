@@ -0,0 +1,256 @@
+// Package openevolve embeds an evolution run in a Go application. Run wires
+// up the program database, evaluator, LLM ensemble and iteration workers
+// from a single configuration; the packages under pkg/ remain available
+// for finer control.
+package openevolve
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/config"
+	"github.com/ishanwen-byte/openevolve-go/pkg/controller"
+	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
+	"github.com/ishanwen-byte/openevolve-go/pkg/iteration"
+	"github.com/ishanwen-byte/openevolve-go/pkg/leaderboard"
+	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
+	"github.com/ishanwen-byte/openevolve-go/pkg/scaffold"
+)
+
+// Config configures a run
+type Config = types.Config
+
+// Program is an evolved program and its evaluation
+type Program = types.Program
+
+// EvolutionStats summarizes a run
+type EvolutionStats = types.EvolutionStats
+
+// Progress reports how far a run has come
+type Progress = controller.Progress
+
+// PhaseChange reports a run entering one of its configured phases
+type PhaseChange = controller.PhaseChange
+
+// EvaluationResult is the outcome of evaluating one program
+type EvaluationResult = types.EvaluationResult
+
+// Generation is the prompt, LLM response and child of one generation step
+type Generation = iteration.Generation
+
+// Options describes a run
+type Options struct {
+	// InitialProgram is the source code evolution starts from
+	InitialProgram string
+	// InitialPrograms, if set, replaces InitialProgram with several
+	// starting points: the islands are split into as many contiguous
+	// groups as there are programs, each seeded with its own
+	InitialPrograms []string
+	// Evaluator is the path of the evaluator program
+	Evaluator string
+	// Config configures the run; nil uses DefaultConfig
+	Config *Config
+	// OnProgress, if set, is called after seeding, after every iteration,
+	// at checkpoints and when the run finishes. It may be called
+	// concurrently; cancel ctx from it to stop the run early.
+	OnProgress func(Progress)
+	// OnPhase, if set, is called whenever the run enters one of the phases
+	// configured in Controller.Phases
+	OnPhase func(PhaseChange)
+}
+
+// ScaffoldOptions describes an evaluator generated by Scaffold
+type ScaffoldOptions = scaffold.Options
+
+// Scaffold writes a ready-to-edit evaluator for a new problem into dir and
+// returns the paths of the files written; pass the evaluator.go among them
+// as Options.Evaluator
+func Scaffold(dir string, opts ScaffoldOptions) ([]string, error) {
+	return scaffold.Write(dir, opts)
+}
+
+// LeaderboardEntry is one run's best result on a task's leaderboard
+type LeaderboardEntry = leaderboard.Entry
+
+// Leaderboard returns the runs recorded on task's leaderboard in dir, best
+// first. Runs record themselves when Controller.Leaderboard.Dir is set.
+func Leaderboard(dir, task string) ([]LeaderboardEntry, error) {
+	return leaderboard.Load(leaderboard.File(dir, task))
+}
+
+// Result is the outcome of a run
+type Result struct {
+	// BestProgram is the fittest program found, nil if none was evaluated
+	BestProgram *Program
+	Stats       EvolutionStats
+}
+
+// DefaultConfig returns the default configuration, to be adjusted before
+// passing it to Run
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// LoadConfig reads a YAML configuration file, applying environment
+// overrides
+func LoadConfig(path string) (*Config, error) {
+	manager := config.NewManager()
+	if err := manager.Load(path); err != nil {
+		return nil, err
+	}
+	return manager.GetConfig(), nil
+}
+
+// Run evolves the initial program until the iteration budget is spent, the
+// target score is reached or ctx is cancelled, and returns the best program
+// found. When the configuration names a checkpoint to resume from, the run
+// continues from it instead of the initial program.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Evaluator == "" {
+		return nil, fmt.Errorf("evaluator path is required")
+	}
+
+	runConfig := DefaultConfig()
+	if opts.Config != nil {
+		copied := *opts.Config
+		runConfig = &copied
+	}
+	if err := config.Validate(runConfig); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	c, err := controller.NewFromConfig(*runConfig, opts.Evaluator)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	c.OnProgress = opts.OnProgress
+	c.OnPhase = opts.OnPhase
+
+	startIteration := 0
+	if resumeFrom := runConfig.Controller.ResumeFrom; resumeFrom != "" {
+		if info, err := os.Stat(resumeFrom); err == nil && info.IsDir() {
+			startIteration, err = c.Database().LoadLatest(resumeFrom)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resume: %w", err)
+			}
+		} else {
+			if err := c.Database().LoadCheckpoint(resumeFrom); err != nil {
+				return nil, fmt.Errorf("failed to resume: %w", err)
+			}
+			startIteration = c.Database().LastIteration()
+		}
+	} else {
+		initialPrograms := opts.InitialPrograms
+		if len(initialPrograms) == 0 && opts.InitialProgram != "" {
+			initialPrograms = []string{opts.InitialProgram}
+		}
+		if len(initialPrograms) == 0 {
+			return nil, fmt.Errorf("initial program is required")
+		}
+		if err := c.SeedIslands(ctx, initialPrograms); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.Run(ctx, startIteration); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		BestProgram: c.Database().GetGlobalBest(),
+		Stats:       c.Database().GetStats(),
+	}, nil
+}
+
+// EvaluateOptions describes a single evaluation
+type EvaluateOptions struct {
+	// Program is the source code to evaluate
+	Program string
+	// Evaluator is the path of the evaluator program
+	Evaluator string
+	// Config configures the evaluator; nil uses DefaultConfig
+	Config *Config
+}
+
+// Evaluate runs only the evaluator pipeline, cascade stages included, on
+// one program and returns its full result, so an evaluator and its scoring
+// can be debugged without evolving anything. The score is in the
+// evaluator's own units, whatever the score direction.
+func Evaluate(ctx context.Context, opts EvaluateOptions) (*EvaluationResult, error) {
+	if opts.Evaluator == "" {
+		return nil, fmt.Errorf("evaluator path is required")
+	}
+
+	evalConfig := DefaultConfig().Evaluator
+	if opts.Config != nil {
+		evalConfig = opts.Config.Evaluator
+	}
+
+	eval, err := evaluator.New(evalConfig, opts.Evaluator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create evaluator: %w", err)
+	}
+	defer eval.Close()
+	if opts.Config != nil {
+		judge, err := llm.NewEvaluatorEnsemble(opts.Config.LLM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create evaluator LLM ensemble: %w", err)
+		}
+		if judge != nil {
+			eval.SetJudge(judge)
+		}
+	}
+
+	result, err := eval.Evaluate(ctx, opts.Program)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate program: %w", err)
+	}
+	if evalConfig.ScoreDirection == constants.ScoreDirectionMinimize {
+		result.Score = -result.Score
+	}
+	return result, nil
+}
+
+// GenerateOptions describes a single generation step
+type GenerateOptions struct {
+	// Parent is the program to improve; its score, metrics and artifacts
+	// appear in the prompt as they would during a run
+	Parent *Program
+	// Inspirations are shown to the LLM alongside the parent
+	Inspirations []*Program
+	// Iteration is the iteration number the prompt and LLM seed are built for
+	Iteration int
+	// Config configures the prompt and the LLMs; nil uses DefaultConfig
+	Config *Config
+}
+
+// Generate runs exactly one generation step, building the prompt, asking
+// the LLM and extracting the child, without a database or an evaluator, so
+// prompts and LLM output can be debugged on their own. When no child can be
+// extracted, the prompt and raw response are returned with the error.
+func Generate(ctx context.Context, opts GenerateOptions) (*Generation, error) {
+	if opts.Parent == nil {
+		return nil, fmt.Errorf("parent program is required")
+	}
+
+	genConfig := DefaultConfig()
+	if opts.Config != nil {
+		genConfig = opts.Config
+	}
+
+	ensemble, err := llm.NewEnsembleFromConfig(genConfig.LLM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM ensemble: %w", err)
+	}
+	defer ensemble.Close()
+	if err := ensemble.AddIslandPools(genConfig.Database.IslandOverrides, genConfig.LLM); err != nil {
+		return nil, fmt.Errorf("failed to create LLM ensemble: %w", err)
+	}
+
+	worker := iteration.NewGenerationWorker(*genConfig, ensemble)
+	return worker.Generate(ctx, opts.Parent, opts.Inspirations, opts.Iteration)
+}
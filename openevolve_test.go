@@ -0,0 +1,145 @@
+package openevolve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+const improvedProgram = "package main\n\nfunc score() float64 { return 0.9 }\n"
+
+func TestRunEvolvesInitialProgram(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	// The LLM always rewrites the program into the improved one
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := json.Marshal("```go\n" + improvedProgram + "```")
+		w.Write([]byte(`{"model": "fake", "choices": [{"message": {"role": "assistant", "content": ` + string(content) + `}}]}`))
+	}))
+	defer server.Close()
+
+	// The evaluator is compiled together with the program it scores, so it
+	// must sit in the directory programs are written to
+	evaluatorFile, err := os.CreateTemp("", "evaluator-*.go")
+	require.NoError(t, err)
+	evaluatorPath := evaluatorFile.Name()
+	defer os.Remove(evaluatorPath)
+	_, err = evaluatorFile.WriteString("package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(score()) }\n")
+	require.NoError(t, err)
+	require.NoError(t, evaluatorFile.Close())
+
+	dir := t.TempDir()
+
+	config := DefaultConfig()
+	config.LLM.APIBase = server.URL
+	config.LLM.APIKey = "sk-test"
+	config.LLM.Models = []types.LLMModelConfig{{Name: "fake", Weight: 1}}
+	config.Database.NumIslands = 1
+	config.Database.OutputDir = dir
+	config.Controller.MaxIterations = 1
+	config.Controller.ParallelWorkers = 1
+	config.Controller.CheckpointDir = filepath.Join(dir, "checkpoints")
+	config.Evaluator.ParallelWorkers = 1
+
+	var phases []string
+	result, err := Run(context.Background(), Options{
+		InitialProgram: "package main\n\nfunc score() float64 { return 0.1 }\n",
+		Evaluator:      evaluatorPath,
+		Config:         config,
+		OnProgress:     func(progress Progress) { phases = append(phases, progress.Phase) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"seeding", "evolving", "finished"}, phases)
+	require.NotNil(t, result.BestProgram)
+	assert.Equal(t, 0.9, result.BestProgram.Score)
+	assert.Equal(t, strings.TrimSpace(improvedProgram), strings.TrimSpace(result.BestProgram.Code))
+	assert.Equal(t, int64(2), result.Stats.TotalEvaluations)
+
+	// Missing inputs are rejected before anything runs
+	_, err = Run(context.Background(), Options{InitialProgram: "package main", Config: config})
+	assert.Error(t, err)
+	_, err = Run(context.Background(), Options{Evaluator: evaluatorPath, Config: config})
+	assert.Error(t, err)
+}
+
+func TestEvaluateReportsFullResult(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	evaluatorFile, err := os.CreateTemp("", "evaluator-*.go")
+	require.NoError(t, err)
+	evaluatorPath := evaluatorFile.Name()
+	defer os.Remove(evaluatorPath)
+	_, err = evaluatorFile.WriteString(`package main
+
+import "fmt"
+
+func main() {
+	fmt.Printf("{\"score\": %g, \"success\": true, \"metrics\": {\"error\": 0.5}, \"artifacts\": {\"note\": \"checked\"}}", score())
+}
+`)
+	require.NoError(t, err)
+	require.NoError(t, evaluatorFile.Close())
+
+	config := DefaultConfig()
+	config.Evaluator.ParallelWorkers = 1
+	config.Evaluator.ScoreDirection = "minimize"
+
+	result, err := Evaluate(context.Background(), EvaluateOptions{
+		Program:   "package main\n\nfunc score() float64 { return 2.5 }\n",
+		Evaluator: evaluatorPath,
+		Config:    config,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2.5, result.Score, "scores are in the evaluator's units")
+	assert.Equal(t, map[string]float64{"error": 0.5}, result.Metrics)
+	assert.Equal(t, "checked", result.Artifacts["note"])
+
+	_, err = Evaluate(context.Background(), EvaluateOptions{Program: "package main"})
+	assert.Error(t, err)
+}
+
+func TestGenerateRunsOneStep(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		content, _ := json.Marshal("Here you go:\n```go\n" + improvedProgram + "```")
+		w.Write([]byte(`{"model": "fake", "choices": [{"message": {"role": "assistant", "content": ` + string(content) + `}}]}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.LLM.APIBase = server.URL
+	config.LLM.APIKey = "sk-test"
+	config.LLM.Models = []types.LLMModelConfig{{Name: "fake", Weight: 1}}
+
+	generation, err := Generate(context.Background(), GenerateOptions{
+		Parent:       &Program{Code: "package main\n\nfunc score() float64 { return 0.1 }\n", Score: 0.1},
+		Inspirations: []*Program{{ID: "other", Code: "package main // inspiration", Score: 0.2}},
+		Config:       config,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Contains(t, generation.Prompt.User, "return 0.1")
+	assert.Contains(t, generation.Prompt.User, "// inspiration")
+	assert.Contains(t, generation.LLMResponse, "Here you go")
+	assert.Equal(t, strings.TrimSpace(improvedProgram), strings.TrimSpace(generation.ChildCode))
+
+	_, err = Generate(context.Background(), GenerateOptions{Config: config})
+	assert.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Entry kinds
+const (
+	KindLLM     = "llm"
+	KindExec    = "exec"
+	KindWebhook = "webhook"
+)
+
+// redactedPlaceholder replaces any secret found in an audit entry
+const redactedPlaceholder = "[REDACTED]"
+
+// defaultRedactPatterns match common credential formats regardless of configuration
+var defaultRedactPatterns = []string{
+	`sk-[A-Za-z0-9_\-]{8,}`,
+	`(?i)bearer\s+[A-Za-z0-9._\-]+`,
+	`(?i)(api[_-]?key|token|secret|password)(\s*[=:]\s*)[^\s"',;]+`,
+}
+
+// Entry is a single record in the audit log
+type Entry struct {
+	Timestamp  time.Time     `json:"timestamp"`
+	Kind       string        `json:"kind"`
+	Name       string        `json:"name"`
+	Args       []string      `json:"args,omitempty"`
+	InputHash  string        `json:"input_hash,omitempty"`
+	OutputHash string        `json:"output_hash,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	ExitStatus int           `json:"exit_status"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Logger writes audit entries to an append-only JSONL file.
+// A nil *Logger is valid and discards all entries, so callers never
+// need to check whether auditing is enabled.
+type Logger struct {
+	mu       sync.Mutex
+	file     *os.File
+	path     string
+	secrets  []string
+	patterns []*regexp.Regexp
+	logger   *logrus.Logger
+}
+
+// New opens the audit log described by config. It returns a nil Logger
+// when auditing is disabled. Any secrets passed in are redacted verbatim
+// in addition to the built-in and configured patterns.
+func New(config types.AuditConfig, secrets ...string) (*Logger, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	path := config.Path
+	if path == "" {
+		path = filepath.Join(constants.OutputDir, constants.LogsDir, constants.AuditLogFile)
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(defaultRedactPatterns)+len(config.RedactPatterns))
+	for _, expr := range append(append([]string{}, defaultRedactPatterns...), config.RedactPatterns...) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", expr, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	l := &Logger{
+		file:     file,
+		path:     path,
+		patterns: patterns,
+		logger:   logrus.New(),
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			l.secrets = append(l.secrets, secret)
+		}
+	}
+
+	return l, nil
+}
+
+// Record appends an entry to the audit log after redacting secrets
+func (l *Logger) Record(entry Entry) {
+	if l == nil {
+		return
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.Name = l.Redact(entry.Name)
+	entry.Error = l.Redact(entry.Error)
+	if len(entry.Args) > 0 {
+		args := make([]string, len(entry.Args))
+		for i, arg := range entry.Args {
+			args[i] = l.Redact(arg)
+		}
+		entry.Args = args
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		l.logger.WithError(err).Warn("Failed to marshal audit entry")
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		l.logger.WithError(err).Warn("Failed to write audit entry")
+	}
+}
+
+// Redact replaces known secrets and credential-like substrings in s
+func (l *Logger) Redact(s string) string {
+	if l == nil || s == "" {
+		return s
+	}
+
+	for _, secret := range l.secrets {
+		s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+	}
+	for _, re := range l.patterns {
+		if re.NumSubexp() >= 2 {
+			// Keep the key name and separator, hide only the value
+			s = re.ReplaceAllString(s, "${1}${2}"+redactedPlaceholder)
+		} else {
+			s = re.ReplaceAllString(s, redactedPlaceholder)
+		}
+	}
+
+	return s
+}
+
+// Path returns the location of the audit log file
+func (l *Logger) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Close flushes and closes the audit log
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Hash returns the hex-encoded SHA-256 digest of data
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// LLMSecrets collects every API key present in an LLM configuration
+func LLMSecrets(config types.LLMConfig) []string {
+	secrets := []string{config.APIKey}
+	for _, model := range config.Models {
+		secrets = append(secrets, model.APIKey)
+	}
+	for _, model := range config.EvaluatorModels {
+		secrets = append(secrets, model.APIKey)
+	}
+	for _, model := range append(config.CheapModels, config.StrongModels...) {
+		secrets = append(secrets, model.APIKey)
+	}
+	return secrets
+}
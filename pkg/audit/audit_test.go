@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+func TestNewDisabled(t *testing.T) {
+	logger, err := New(types.AuditConfig{Enabled: false})
+	require.NoError(t, err)
+	assert.Nil(t, logger)
+
+	// A nil logger must be safe to use
+	logger.Record(Entry{Kind: KindExec, Name: "go"})
+	assert.Equal(t, "secret", logger.Redact("secret"))
+	assert.NoError(t, logger.Close())
+}
+
+func TestRecordAppendsRedactedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+
+	logger, err := New(types.AuditConfig{Enabled: true, Path: path}, "my-configured-key")
+	require.NoError(t, err)
+
+	logger.Record(Entry{
+		Kind:       KindLLM,
+		Name:       "gpt-4",
+		Args:       []string{"POST", "https://api.example.com/v1/chat/completions"},
+		InputHash:  Hash([]byte("prompt")),
+		Duration:   time.Second,
+		ExitStatus: 401,
+		Error:      "HTTP 401: invalid key my-configured-key",
+	})
+	logger.Record(Entry{
+		Kind:  KindExec,
+		Name:  "go",
+		Args:  []string{"run", "main.go", "--api-key=sk-abcdefghijklmnop"},
+		Error: "Authorization: Bearer abc.def",
+	})
+	require.NoError(t, logger.Close())
+
+	// Reopening appends instead of truncating
+	logger, err = New(types.AuditConfig{Enabled: true, Path: path})
+	require.NoError(t, err)
+	logger.Record(Entry{Kind: KindExec, Name: "go"})
+	require.NoError(t, logger.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, KindLLM, entries[0].Kind)
+	assert.Equal(t, 401, entries[0].ExitStatus)
+	assert.NotContains(t, entries[0].Error, "my-configured-key")
+	assert.Contains(t, entries[0].Error, redactedPlaceholder)
+	assert.False(t, entries[0].Timestamp.IsZero())
+
+	assert.NotContains(t, entries[1].Args[2], "sk-abcdefghijklmnop")
+	assert.NotContains(t, entries[1].Error, "abc.def")
+}
+
+func TestRedactKeyValuePairs(t *testing.T) {
+	logger, err := New(types.AuditConfig{
+		Enabled:        true,
+		Path:           filepath.Join(t.TempDir(), "audit.jsonl"),
+		RedactPatterns: []string{`internal-[0-9]+`},
+	})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	assert.Equal(t, "password=[REDACTED] ok", logger.Redact("password=hunter2 ok"))
+	assert.Equal(t, "host [REDACTED]", logger.Redact("host internal-42"))
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	_, err := New(types.AuditConfig{
+		Enabled:        true,
+		Path:           filepath.Join(t.TempDir(), "audit.jsonl"),
+		RedactPatterns: []string{"("},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid redact pattern")
+}
+
+func TestLLMSecrets(t *testing.T) {
+	secrets := LLMSecrets(types.LLMConfig{
+		APIKey:          "global",
+		Models:          []types.LLMModelConfig{{APIKey: "model"}},
+		EvaluatorModels: []types.LLMModelConfig{{APIKey: "judge"}},
+	})
+	assert.Equal(t, []string{"global", "model", "judge"}, secrets)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/ishanwen-byte/openevolve-go/internal/constants"
@@ -110,6 +111,12 @@ func (m *Manager) applyEnvOverrides(config *types.Config) error {
 	if outputDir := os.Getenv("OUTPUT_DIR"); outputDir != "" {
 		config.Database.OutputDir = outputDir
 	}
+	if interval := os.Getenv("CHECKPOINT_INTERVAL"); interval != "" {
+		var n int
+		if _, err := fmt.Sscanf(interval, "%d", &n); err == nil {
+			config.Database.CheckpointInterval = n
+		}
+	}
 
 	// Controller configuration overrides
 	if maxIter := os.Getenv("MAX_ITERATIONS"); maxIter != "" {
@@ -124,13 +131,51 @@ func (m *Manager) applyEnvOverrides(config *types.Config) error {
 			config.Controller.Seed = n
 		}
 	}
+	if perMinute := os.Getenv("MAX_ITERATIONS_PER_MINUTE"); perMinute != "" {
+		var n float64
+		if _, err := fmt.Sscanf(perMinute, "%g", &n); err == nil {
+			config.Controller.MaxIterationsPerMinute = n
+		}
+	}
+	if limit := os.Getenv("MEMORY_LIMIT_MB"); limit != "" {
+		var n int
+		if _, err := fmt.Sscanf(limit, "%d", &n); err == nil {
+			config.Controller.Memory.LimitMB = n
+		}
+	}
 	if verbose := os.Getenv("VERBOSE"); verbose != "" {
 		config.Controller.Verbose = strings.ToLower(verbose) == "true"
 	}
 
+	// Experiment tracker overrides, named as the trackers' own clients name them
+	switch config.Tracking.Backend {
+	case constants.TrackingBackendMLflow:
+		if uri := os.Getenv("MLFLOW_TRACKING_URI"); uri != "" {
+			config.Tracking.URI = uri
+		}
+		if token := os.Getenv("MLFLOW_TRACKING_TOKEN"); token != "" {
+			config.Tracking.APIKey = token
+		}
+	case constants.TrackingBackendWandB:
+		if apiKey := os.Getenv("WANDB_API_KEY"); apiKey != "" {
+			config.Tracking.APIKey = apiKey
+		}
+	}
+
 	return nil
 }
 
+// Validate checks a configuration built in code, filling in default paths
+// the same way Load does
+func Validate(config *types.Config) error {
+	return NewManager().validate(config)
+}
+
+// Default returns a copy of the default configuration
+func Default() *types.Config {
+	return getDefaultConfig()
+}
+
 // validate validates the configuration
 func (m *Manager) validate(config *types.Config) error {
 	// Validate LLM configuration
@@ -149,6 +194,12 @@ func (m *Manager) validate(config *types.Config) error {
 	if len(config.LLM.Models) > 0 && totalWeight <= 0 {
 		return fmt.Errorf("sum of model weights must be positive")
 	}
+	if config.LLM.Routing.Enabled && len(config.LLM.StrongModels) == 0 {
+		return fmt.Errorf("model routing needs strong models")
+	}
+	if config.LLM.Routing.StagnationIterations < 0 {
+		return fmt.Errorf("routing stagnation iterations must not be negative")
+	}
 
 	// Validate database configuration
 	if config.Database.NumIslands <= 0 {
@@ -157,8 +208,186 @@ func (m *Manager) validate(config *types.Config) error {
 	if len(config.Database.GridDimensions) == 0 {
 		return fmt.Errorf("grid dimensions are required")
 	}
-	if len(config.Database.GridResolution) != len(config.Database.GridDimensions) {
-		return fmt.Errorf("grid resolution must match dimensions")
+	switch config.Database.Archive {
+	case "", constants.ArchiveGrid:
+		if len(config.Database.GridResolution) != len(config.Database.GridDimensions) {
+			return fmt.Errorf("grid resolution must match dimensions")
+		}
+	case constants.ArchiveCVT:
+		if cvt := config.Database.CVT; cvt.Cells < 0 || cvt.Samples < 0 || cvt.Iterations < 0 {
+			return fmt.Errorf("cvt cells, samples and iterations must not be negative")
+		} else if cvt.Samples > 0 && cvt.Samples < cvt.Cells {
+			return fmt.Errorf("cvt archive needs at least as many samples as cells")
+		}
+		if config.Database.AdaptiveBinning {
+			return fmt.Errorf("adaptive binning requires the grid archive")
+		}
+	default:
+		return fmt.Errorf("unknown archive: %s", config.Database.Archive)
+	}
+	for dim, method := range config.Database.FeatureScaling {
+		if !slices.Contains(config.Database.GridDimensions, dim) {
+			return fmt.Errorf("feature scaling set for unknown dimension: %s", dim)
+		}
+		switch method {
+		case constants.FeatureScalingMinMax, constants.FeatureScalingZScore, constants.FeatureScalingQuantile, constants.FeatureScalingLog:
+		default:
+			return fmt.Errorf("unknown feature scaling for %s: %s", dim, method)
+		}
+	}
+	quantileBins := config.Database.AdaptiveBinning
+	for dim, strategy := range config.Database.GridScaling {
+		if !slices.Contains(config.Database.GridDimensions, dim) {
+			return fmt.Errorf("grid scaling set for unknown dimension: %s", dim)
+		}
+		switch strategy {
+		case constants.GridScalingLinear, constants.GridScalingLog:
+		case constants.GridScalingQuantile:
+			quantileBins = true
+		default:
+			return fmt.Errorf("unknown grid scaling for %s: %s", dim, strategy)
+		}
+		if config.Database.Archive == constants.ArchiveCVT {
+			return fmt.Errorf("grid scaling requires the grid archive")
+		}
+	}
+	if quantileBins && config.Database.AdaptiveBinInterval <= 0 {
+		return fmt.Errorf("adaptive bin interval must be positive")
+	}
+	if config.Database.CheckpointInterval < 0 {
+		return fmt.Errorf("checkpoint interval must not be negative")
+	}
+	switch config.Database.CheckpointFormat {
+	case "", constants.CheckpointFormatJSON, constants.CheckpointFormatGob:
+	default:
+		return fmt.Errorf("unknown checkpoint format: %s", config.Database.CheckpointFormat)
+	}
+	if config.Database.MaxProgramsPerCell <= 0 {
+		return fmt.Errorf("max programs per cell must be positive")
+	}
+	switch config.Database.CellReplacement {
+	case "", constants.CellReplacementWorstOut, constants.CellReplacementOldestOut:
+	default:
+		return fmt.Errorf("unknown cell replacement policy: %s", config.Database.CellReplacement)
+	}
+	if config.Database.PopulationSize < 0 {
+		return fmt.Errorf("population size must not be negative")
+	}
+	switch config.Database.Eviction {
+	case "", constants.EvictionLowestFitness, constants.EvictionOldest, constants.EvictionNonEliteFirst:
+	default:
+		return fmt.Errorf("unknown eviction policy: %s", config.Database.Eviction)
+	}
+	if config.Database.NoveltyWeight < 0 || config.Database.NoveltyWeight > 1 {
+		return fmt.Errorf("novelty weight must be between 0 and 1")
+	}
+	switch config.Database.NoveltyDecay {
+	case "", constants.NoveltyDecayNone, constants.NoveltyDecayLinear, constants.NoveltyDecayExponential:
+	default:
+		return fmt.Errorf("unknown novelty decay schedule: %s", config.Database.NoveltyDecay)
+	}
+	if archive := config.Database.NoveltyArchive; archive.Neighbors < 0 || archive.Size < 0 || archive.Threshold < 0 {
+		return fmt.Errorf("novelty archive neighbors, size and threshold must not be negative")
+	}
+
+	if !validParentSelection(config.Database.ParentSelection) {
+		return fmt.Errorf("unknown parent selection: %s", config.Database.ParentSelection)
+	}
+	for island, selection := range config.Database.IslandParentSelection {
+		if island < 0 || island >= config.Database.NumIslands {
+			return fmt.Errorf("parent selection set for unknown island %d", island)
+		}
+		if !validParentSelection(selection) {
+			return fmt.Errorf("unknown parent selection for island %d: %s", island, selection)
+		}
+	}
+	for island, override := range config.Database.IslandOverrides {
+		if island < 0 || island >= config.Database.NumIslands {
+			return fmt.Errorf("overrides set for unknown island %d", island)
+		}
+		if override.ParentSelection != "" && !validParentSelection(override.ParentSelection) {
+			return fmt.Errorf("unknown parent selection for island %d: %s", island, override.ParentSelection)
+		}
+		if override.Temperature < 0 {
+			return fmt.Errorf("temperature for island %d must not be negative", island)
+		}
+		for _, model := range override.Models {
+			if model.Weight < 0 {
+				return fmt.Errorf("model weights for island %d must not be negative", island)
+			}
+		}
+	}
+	if config.Database.TournamentSize < 0 {
+		return fmt.Errorf("tournament size must not be negative")
+	}
+	if boltzmann := config.Database.Boltzmann; boltzmann.Temperature < 0 || boltzmann.MinTemperature < 0 || boltzmann.HalfLife < 0 {
+		return fmt.Errorf("boltzmann temperatures and half-life must not be negative")
+	}
+	if config.Database.ExplorationRatio < 0 || config.Database.ExplorationRatio > 1 {
+		return fmt.Errorf("exploration ratio must be between 0 and 1")
+	}
+	if config.Database.RankExponent < 0 {
+		return fmt.Errorf("rank exponent must not be negative")
+	}
+	if config.Database.StalenessBias < 0 {
+		return fmt.Errorf("staleness bias must not be negative")
+	}
+	switch config.Database.SampleStrategy {
+	case "", constants.SampleStrategyPerIsland, constants.SampleStrategyEliteBiased, constants.SampleStrategyDiverseCells:
+	default:
+		return fmt.Errorf("unknown sample strategy: %s", config.Database.SampleStrategy)
+	}
+	if config.Database.DynamicBounds {
+		if config.Database.DynamicBoundsInterval <= 0 {
+			return fmt.Errorf("dynamic bounds interval must be positive")
+		}
+		if config.Database.AdaptiveBinning {
+			return fmt.Errorf("dynamic bounds and adaptive binning cannot be combined")
+		}
+	}
+	if adaptive := config.Database.AdaptiveMigration; adaptive.Enabled {
+		if adaptive.Window < 2 {
+			return fmt.Errorf("adaptive migration window must be at least 2 generations")
+		}
+		if adaptive.MinRate < 0 || adaptive.MaxRate > 1 || adaptive.MinRate > adaptive.MaxRate {
+			return fmt.Errorf("adaptive migration rates must satisfy 0 <= min <= max <= 1")
+		}
+		if adaptive.TargetSlope <= 0 {
+			return fmt.Errorf("adaptive migration target slope must be positive")
+		}
+	}
+	if config.Database.Extinction.Patience < 0 {
+		return fmt.Errorf("extinction patience must not be negative")
+	}
+	if decay := config.Database.RecencyDecay; decay.MaxDiscount < 0 || decay.MaxDiscount > 1 {
+		return fmt.Errorf("recency decay max discount must be between 0 and 1")
+	}
+	if config.Database.RecencyDecay.HalfLife < 0 {
+		return fmt.Errorf("recency decay half life must not be negative")
+	}
+	if config.Database.Compaction.Threshold < 0 {
+		return fmt.Errorf("compaction threshold must not be negative")
+	}
+	if config.Database.Compaction.MaxPrograms < 0 {
+		return fmt.Errorf("compaction max programs must not be negative")
+	}
+	if sharing := config.Database.FitnessSharing; sharing.Radius < 0 || sharing.Alpha < 0 {
+		return fmt.Errorf("fitness sharing radius and alpha must not be negative")
+	}
+	switch config.Database.FitnessSharing.Distance {
+	case "", constants.SharingDistanceFeatures, constants.SharingDistanceCode:
+	default:
+		return fmt.Errorf("invalid fitness sharing distance: %s", config.Database.FitnessSharing.Distance)
+	}
+	metrics := make(map[string]bool, len(config.Database.Objectives))
+	for _, objective := range config.Database.Objectives {
+		if objective.Metric == "" {
+			return fmt.Errorf("objective metric is required")
+		}
+		if metrics[objective.Metric] {
+			return fmt.Errorf("duplicate objective: %s", objective.Metric)
+		}
+		metrics[objective.Metric] = true
 	}
 
 	// Validate evaluator configuration
@@ -168,6 +397,69 @@ func (m *Manager) validate(config *types.Config) error {
 	if len(config.Evaluator.CascadeStages) == 0 {
 		return fmt.Errorf("at least one cascade stage is required")
 	}
+	switch config.Evaluator.Network {
+	case "", constants.NetworkDeny, constants.NetworkAllow:
+	default:
+		return fmt.Errorf("unknown network policy: %s", config.Evaluator.Network)
+	}
+	switch config.Evaluator.ScoreDirection {
+	case "", constants.ScoreDirectionMaximize, constants.ScoreDirectionMinimize:
+	default:
+		return fmt.Errorf("unknown score direction: %s", config.Evaluator.ScoreDirection)
+	}
+	switch config.Evaluator.ProgramType {
+	case "", constants.ProgramTypeGo:
+	case constants.ProgramTypeCommand:
+		if len(config.Evaluator.Command.Interpreter) == 0 {
+			return fmt.Errorf("command programs need an interpreter")
+		}
+	default:
+		return fmt.Errorf("unknown program type: %s", config.Evaluator.ProgramType)
+	}
+	if weight := config.Evaluator.LLMFeedbackWeight; weight < 0 || weight > 1 {
+		return fmt.Errorf("llm feedback weight must be between 0 and 1")
+	}
+	if config.Evaluator.UseLLMFeedback && len(config.LLM.EvaluatorModels) == 0 {
+		return fmt.Errorf("llm feedback requires evaluator models")
+	}
+	if config.Evaluator.AcceptanceWindow < 0 {
+		return fmt.Errorf("acceptance window must not be negative")
+	}
+	if config.Evaluator.ChunkSize < 0 {
+		return fmt.Errorf("chunk size must not be negative")
+	}
+	if adaptive := config.Evaluator.AdaptiveTimeout; adaptive.Enabled {
+		if adaptive.Percentile <= 0 || adaptive.Percentile > 1 {
+			return fmt.Errorf("adaptive timeout percentile must be in (0, 1]")
+		}
+		if adaptive.Multiplier <= 0 {
+			return fmt.Errorf("adaptive timeout multiplier must be positive")
+		}
+		if adaptive.Window <= 0 {
+			return fmt.Errorf("adaptive timeout window must be positive")
+		}
+		if adaptive.MinTimeout < 0 || adaptive.MaxTimeout < 0 {
+			return fmt.Errorf("adaptive timeout bounds must not be negative")
+		}
+		if adaptive.MaxTimeout > 0 && adaptive.MinTimeout > adaptive.MaxTimeout {
+			return fmt.Errorf("adaptive timeout floor must not exceed its ceiling")
+		}
+	}
+
+	if config.Prompt.InspirationMinDistance < 0 || config.Prompt.InspirationMinDistance > 1 {
+		return fmt.Errorf("inspiration min distance must be between 0 and 1")
+	}
+	if shrink := config.Prompt.Shrink; shrink.Enabled {
+		if shrink.Window < 0 || shrink.MaxLevel < 0 {
+			return fmt.Errorf("prompt shrink window and max level must not be negative")
+		}
+		if shrink.FailureRate < 0 || shrink.FailureRate > 1 {
+			return fmt.Errorf("prompt shrink failure rate must be between 0 and 1")
+		}
+		if shrink.TokenFactor < 0 || shrink.TokenFactor > 1 {
+			return fmt.Errorf("prompt shrink token factor must be between 0 and 1")
+		}
+	}
 
 	// Validate controller configuration
 	if config.Controller.MaxIterations <= 0 {
@@ -176,6 +468,64 @@ func (m *Manager) validate(config *types.Config) error {
 	if config.Controller.ParallelWorkers <= 0 {
 		return fmt.Errorf("parallel workers must be positive")
 	}
+	if config.Controller.MaxIterationsPerMinute < 0 {
+		return fmt.Errorf("max iterations per minute must not be negative")
+	}
+	memory := config.Controller.Memory
+	if memory.LimitMB < 0 || memory.Interval < 0 {
+		return fmt.Errorf("memory limit and check interval must not be negative")
+	}
+	if memory.WarnFraction < 0 || memory.WarnFraction > 1 {
+		return fmt.Errorf("memory warn fraction must be between 0 and 1")
+	}
+	if memory.PruneFraction < 0 || memory.PruneFraction > 1 {
+		return fmt.Errorf("memory prune fraction must be between 0 and 1")
+	}
+	if config.Controller.TargetCheck.Evaluations < 0 {
+		return fmt.Errorf("target check evaluations must not be negative")
+	}
+	promotion := config.Controller.Promotion
+	if promotion.Validations < 0 || promotion.Timeout < 0 {
+		return fmt.Errorf("promotion validations and timeout must not be negative")
+	}
+	if promotion.Enabled && promotion.CopyTo == "" && len(promotion.Command) == 0 && promotion.Webhook == "" {
+		return fmt.Errorf("promotion needs a copy target, command or webhook")
+	}
+	budget := config.Controller.IslandBudget
+	if len(budget.Shares) > config.Database.NumIslands {
+		return fmt.Errorf("island budget has %d shares for %d islands", len(budget.Shares), config.Database.NumIslands)
+	}
+	for _, share := range budget.Shares {
+		if share <= 0 {
+			return fmt.Errorf("island budget shares must be positive")
+		}
+	}
+	if budget.Window < 0 {
+		return fmt.Errorf("island budget window must not be negative")
+	}
+	if task := config.Controller.Leaderboard.Task; task != "" && filepath.Base(task) != task {
+		return fmt.Errorf("leaderboard task must be a plain name: %s", task)
+	}
+	if err := validatePhases(config); err != nil {
+		return err
+	}
+
+	switch config.Tracking.Backend {
+	case "":
+	case constants.TrackingBackendMLflow:
+		if config.Tracking.URI == "" {
+			return fmt.Errorf("mlflow tracking needs a tracking URI")
+		}
+	case constants.TrackingBackendWandB:
+		if config.Tracking.APIKey == "" {
+			return fmt.Errorf("wandb tracking needs an API key")
+		}
+	default:
+		return fmt.Errorf("unknown tracking backend: %s", config.Tracking.Backend)
+	}
+	if config.Tracking.Timeout < 0 {
+		return fmt.Errorf("tracking timeout must not be negative")
+	}
 
 	// Validate paths
 	if config.Database.OutputDir == "" {
@@ -184,10 +534,53 @@ func (m *Manager) validate(config *types.Config) error {
 	if config.Controller.CheckpointDir == "" {
 		config.Controller.CheckpointDir = filepath.Join(config.Database.OutputDir, constants.CheckpointDir)
 	}
+	if config.Audit.Path == "" {
+		config.Audit.Path = filepath.Join(config.Database.OutputDir, constants.LogsDir, constants.AuditLogFile)
+	}
 
 	return nil
 }
 
+// validParentSelection reports whether selection names a parent selection
+// strategy; empty selects the default
+func validParentSelection(selection string) bool {
+	switch selection {
+	case "", constants.ParentSelectionUniform, constants.ParentSelectionEpsilonGreedy,
+		constants.ParentSelectionScoreProportional, constants.ParentSelectionRank, constants.ParentSelectionTournament,
+		constants.ParentSelectionBoltzmann, constants.ParentSelectionFitnessProportional:
+		return true
+	}
+	return false
+}
+
+// validatePhases checks the run's phase schedule
+func validatePhases(config *types.Config) error {
+	models := make(map[string]bool, len(config.LLM.Models))
+	for _, model := range config.LLM.Models {
+		models[model.Name] = true
+	}
+	names := make(map[string]bool, len(config.Controller.Phases))
+	for _, phase := range config.Controller.Phases {
+		if phase.Name == "" {
+			return fmt.Errorf("phase name is required")
+		}
+		if names[phase.Name] {
+			return fmt.Errorf("duplicate phase: %s", phase.Name)
+		}
+		names[phase.Name] = true
+		if phase.Iterations < 0 || phase.Stagnation < 0 || phase.Temperature < 0 {
+			return fmt.Errorf("phase %s iterations, stagnation and temperature must not be negative", phase.Name)
+		}
+		if !validParentSelection(phase.ParentSelection) {
+			return fmt.Errorf("unknown parent selection for phase %s: %s", phase.Name, phase.ParentSelection)
+		}
+		if phase.Model != "" && !models[phase.Model] {
+			return fmt.Errorf("phase %s uses unknown model: %s", phase.Name, phase.Model)
+		}
+	}
+	return nil
+}
+
 // getDefaultConfig returns the default configuration
 func getDefaultConfig() *types.Config {
 	return &types.Config{
@@ -203,6 +596,15 @@ func getDefaultConfig() *types.Config {
 				},
 			},
 			EvaluatorModels: []types.LLMModelConfig{},
+			DiffModels:      []types.LLMModelConfig{},
+			RewriteModels:   []types.LLMModelConfig{},
+			CheapModels:     []types.LLMModelConfig{},
+			StrongModels:    []types.LLMModelConfig{},
+			Routing: types.RoutingConfig{
+				Enabled:              false,
+				StagnationIterations: constants.DefaultStagnationIterations,
+				RefineChampion:       true,
+			},
 			SystemMessage:   constants.DefaultSystemMessage,
 			Temperature:     constants.DefaultTemperature,
 			TopP:            constants.DefaultTopP,
@@ -217,11 +619,79 @@ func getDefaultConfig() *types.Config {
 			GridDimensions:    []string{"complexity", "novelty"},
 			GridResolution:    map[string]int{"complexity": 10, "novelty": 10},
 			GridBounds:        map[string][2]float64{"complexity": {0, 1}, "novelty": {0, 1}},
+			Archive:           constants.ArchiveGrid,
+			CVT: types.CVTConfig{
+				Cells:      constants.DefaultCVTCells,
+				Samples:    constants.DefaultCVTSamples,
+				Iterations: constants.DefaultCVTIterations,
+			},
+			FeatureScaling:    map[string]string{},
+			GridScaling:       map[string]string{},
 			MigrationInterval: constants.DefaultMigrationInterval,
 			MigrationRate:     constants.DefaultMigrationRate,
+			CopyMigrants:      false,
+			AsyncMigration:    false,
 			MaxProgramsPerCell: constants.DefaultMaxProgramsPerCell,
+			CellReplacement:   constants.CellReplacementWorstOut,
+			PopulationSize:    0,
+			Eviction:          constants.EvictionLowestFitness,
+			ColdStorage:       false,
 			CheckpointInterval: constants.DefaultCheckpointInterval,
+			CheckpointFormat:  constants.CheckpointFormatJSON,
 			OutputDir:         constants.OutputDir,
+			NoveltyWeight:     0,
+			NoveltyDecay:      constants.NoveltyDecayNone,
+			NoveltyArchive: types.NoveltyArchiveConfig{
+				Enabled:   false,
+				Neighbors: constants.DefaultNoveltyNeighbors,
+				Size:      constants.DefaultNoveltyArchiveSize,
+			},
+			SampleStrategy:    constants.SampleStrategyPerIsland,
+			StalenessBias:     0,
+			ParentSelection:   constants.ParentSelectionUniform,
+			ExplorationRatio:  constants.DefaultExplorationRatio,
+			RankExponent:      constants.DefaultRankExponent,
+			IslandParentSelection: map[int]string{},
+			IslandOverrides:       map[int]types.IslandOverride{},
+			TournamentSize:    constants.DefaultTournamentSize,
+			Boltzmann: types.BoltzmannConfig{
+				Temperature:    constants.DefaultBoltzmannTemperature,
+				MinTemperature: constants.DefaultBoltzmannMinTemperature,
+				HalfLife:       0,
+			},
+			AdaptiveBinning:   false,
+			AdaptiveBinInterval: constants.DefaultAdaptiveBinInterval,
+			DynamicBounds:     false,
+			DynamicBoundsInterval: constants.DefaultDynamicBoundsInterval,
+			Objectives:        []types.Objective{},
+			AdaptiveMigration: types.AdaptiveMigrationConfig{
+				Enabled:     false,
+				Window:      constants.DefaultMigrationWindow,
+				MinRate:     constants.DefaultMinMigrationRate,
+				MaxRate:     constants.DefaultMaxMigrationRate,
+				TargetSlope: constants.DefaultMigrationTargetSlope,
+			},
+			Extinction: types.ExtinctionConfig{
+				Enabled:  false,
+				Patience: constants.DefaultExtinctionPatience,
+			},
+			RecencyDecay: types.RecencyDecayConfig{
+				Enabled:     false,
+				MaxDiscount: constants.DefaultRecencyMaxDiscount,
+				HalfLife:    constants.DefaultRecencyHalfLife,
+			},
+			Compaction: types.CompactionConfig{
+				Threshold:   0,
+				MaxPrograms: 0,
+			},
+			FitnessSharing: types.FitnessSharingConfig{
+				Enabled:  false,
+				Distance: constants.SharingDistanceFeatures,
+				Radius:   constants.DefaultSharingRadius,
+				Alpha:    constants.DefaultSharingAlpha,
+			},
+			Elitism: false,
+			Tags: []string{},
 		},
 		Evaluator: types.EvaluatorConfig{
 			CascadeStages: []types.CascadeStage{
@@ -248,15 +718,58 @@ func getDefaultConfig() *types.Config {
 			Timeout:           constants.DefaultTimeout,
 			CollectArtifacts:  true,
 			ArtifactMaxSize:   constants.DefaultArtifactMaxSize,
+			AcceptanceWindow:  constants.DefaultAcceptanceWindow,
+			AdaptiveTimeout: types.AdaptiveTimeoutConfig{
+				Enabled:    false,
+				Percentile: constants.DefaultTimeoutPercentile,
+				Multiplier: constants.DefaultTimeoutMultiplier,
+				MinSamples: constants.DefaultTimeoutMinSamples,
+				Window:     constants.DefaultTimeoutWindow,
+				MinTimeout: constants.DefaultTimeoutFloor,
+			},
+			Network: constants.NetworkDeny,
+			ProgramType: constants.ProgramTypeGo,
+			ScoreDirection: constants.ScoreDirectionMaximize,
+			Command: types.CommandProgramConfig{
+				Interpreter: []string{constants.DefaultCommandInterpreter},
+				Extension:   constants.DefaultCommandExtension,
+			},
+			ReevaluateOnChange: false,
+			ChunkSize:        0,
+			UseLLMFeedback:    false,
+			LLMFeedbackWeight: constants.DefaultLLMFeedbackWeight,
 		},
 		Prompt: types.PromptConfig{
 			Templates:       []types.PromptTemplate{},
 			SystemMessage:   constants.DefaultSystemMessage,
 			EvolutionPrompt: constants.DefaultEvolutionPrompt,
 			MutationPrompt:  constants.DefaultMutationPrompt,
+			SummarizeInspirations: false,
+			ChangesInstruction: constants.DefaultChangesInstruction,
 			Stochasticity:   constants.DefaultStochasticity,
 			IncludeHistory:  true,
 			HistoryLength:   constants.DefaultHistoryLength,
+			ProtectedRegions: types.ProtectedRegionsConfig{
+				Enabled:     false,
+				Header:      true,
+				Imports:     false,
+				StartMarker: constants.DefaultProtectedStartMarker,
+				EndMarker:   constants.DefaultProtectedEndMarker,
+			},
+			EvolveBlockRetries: constants.DefaultEvolveBlockRetries,
+			Repetition: types.RepetitionConfig{
+				Window:          constants.DefaultRepetitionWindow,
+				Threshold:       constants.DefaultRepetitionThreshold,
+				TemperatureStep: constants.DefaultEscalationTemperatureStep,
+			},
+			Shrink: types.ShrinkConfig{
+				Enabled:     false,
+				Window:      constants.DefaultShrinkWindow,
+				FailureRate: constants.DefaultShrinkFailureRate,
+				MaxLevel:    constants.DefaultShrinkMaxLevel,
+				TokenFactor: constants.DefaultShrinkTokenFactor,
+			},
+			InspirationMinDistance: constants.DefaultInspirationMinDistance,
 		},
 		Controller: types.ControllerConfig{
 			MaxIterations:   constants.DefaultMaxIterations,
@@ -265,6 +778,43 @@ func getDefaultConfig() *types.Config {
 			CheckpointDir:   filepath.Join(constants.OutputDir, constants.CheckpointDir),
 			Seed:            42,
 			Verbose:         false,
+			Changelog:       false,
+			TargetCheck: types.TargetCheckConfig{
+				Evaluations:      0,
+				HoldoutEvaluator: "",
+			},
+			Memory: types.MemoryConfig{
+				LimitMB:       0,
+				WarnFraction:  constants.DefaultMemoryWarnFraction,
+				PruneFraction: constants.DefaultMemoryPruneFraction,
+				Interval:      constants.DefaultMemoryInterval,
+			},
+			Promotion: types.PromotionConfig{
+				Enabled:     false,
+				Validations: constants.DefaultPromotionValidations,
+				Command:     []string{},
+				Timeout:     constants.DefaultPromotionTimeout,
+			},
+			IslandBudget: types.IslandBudgetConfig{
+				Shares:  []float64{},
+				Dynamic: false,
+				Window:  constants.DefaultIslandBudgetWindow,
+			},
+			Leaderboard: types.LeaderboardConfig{
+				Dir:  "",
+				Task: "",
+			},
+			Phases: []types.PhaseConfig{},
+		},
+		Audit: types.AuditConfig{
+			Enabled:        false,
+			Path:           filepath.Join(constants.OutputDir, constants.LogsDir, constants.AuditLogFile),
+			RedactPatterns: []string{},
+		},
+		Tracking: types.TrackingConfig{
+			Backend:    "",
+			Experiment: constants.DefaultTrackingExperiment,
+			Timeout:    constants.DefaultTrackingTimeout,
 		},
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -121,6 +122,261 @@ func TestValidation(t *testing.T) {
 	// Restore valid config
 	config.Database.NumIslands = originalNumIslands
 
+	// Test scaling for a dimension that is not on the grid
+	config.Database.FeatureScaling = map[string]string{"runtime": "log"}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "feature scaling set for unknown dimension: runtime")
+
+	// Restore valid config
+	config.Database.FeatureScaling = map[string]string{"complexity": "quantile"}
+	assert.NoError(t, manager.validate(config))
+	config.Database.FeatureScaling = map[string]string{}
+
+	// Test negative checkpoint interval
+	config.Database.CheckpointInterval = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checkpoint interval must not be negative")
+
+	// Restore valid config
+	config.Database.CheckpointInterval = 100
+
+	// Test unknown cell replacement policy
+	config.Database.CellReplacement = "random_out"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown cell replacement policy")
+
+	// Restore valid config
+	config.Database.CellReplacement = "worst_out"
+
+	// Test negative population size
+	config.Database.PopulationSize = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "population size must not be negative")
+
+	// Test unknown eviction policy
+	config.Database.PopulationSize = 100
+	config.Database.Eviction = "random"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown eviction policy")
+
+	// Restore valid config
+	config.Database.Eviction = "non_elite_first"
+	assert.NoError(t, manager.validate(config))
+	config.Database.PopulationSize = 0
+	config.Database.Eviction = "lowest_fitness"
+
+	// Test unknown parent selection
+	config.Database.ParentSelection = "roulette"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown parent selection")
+
+	// Restore valid config
+	config.Database.ParentSelection = "uniform"
+
+	// Test parent selection for an island that does not exist
+	config.Database.IslandParentSelection = map[int]string{config.Database.NumIslands: "tournament"}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "parent selection set for unknown island")
+
+	// Restore valid config
+	config.Database.IslandParentSelection = map[int]string{0: "tournament"}
+	assert.NoError(t, manager.validate(config))
+	config.Database.IslandParentSelection = nil
+
+	// Island overrides must target an existing island
+	config.Database.IslandOverrides = map[int]types.IslandOverride{0: {ParentSelection: "rank", Temperature: 0.2}}
+	assert.NoError(t, manager.validate(config))
+	config.Database.IslandOverrides = map[int]types.IslandOverride{config.Database.NumIslands: {Temperature: 0.2}}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "overrides set for unknown island")
+	config.Database.IslandOverrides = map[int]types.IslandOverride{0: {ParentSelection: "lottery"}}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown parent selection for island 0")
+	config.Database.IslandOverrides = nil
+
+	// Test negative boltzmann half-life
+	config.Database.Boltzmann.HalfLife = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "boltzmann temperatures and half-life must not be negative")
+
+	// Restore valid config
+	config.Database.Boltzmann.HalfLife = 0
+
+	// A CVT archive ignores grid resolution but needs enough samples
+	config.Database.Archive = "cvt"
+	config.Database.GridResolution = map[string]int{}
+	assert.NoError(t, manager.validate(config))
+	config.Database.CVT.Samples = config.Database.CVT.Cells - 1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "at least as many samples as cells")
+	config.Database.Archive = "hexagonal"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown archive")
+
+	// Restore valid config
+	config.Database.Archive = "grid"
+	config.Database.CVT.Samples = 10000
+	config.Database.GridResolution = map[string]int{"complexity": 10, "novelty": 10}
+
+	// Model routing escalates to strong models, so it needs some
+	config.LLM.Routing.Enabled = true
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "model routing needs strong models")
+	config.LLM.StrongModels = []types.LLMModelConfig{{Name: "strong", Weight: 1}}
+	assert.NoError(t, manager.validate(config))
+
+	// Restore valid config
+	config.LLM.Routing.Enabled = false
+	config.LLM.StrongModels = []types.LLMModelConfig{}
+
+	// Dynamic bounds replace bin edges, so they exclude adaptive binning
+	config.Database.DynamicBounds = true
+	assert.NoError(t, manager.validate(config))
+	config.Database.AdaptiveBinning = true
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dynamic bounds and adaptive binning cannot be combined")
+
+	// Restore valid config
+	config.Database.DynamicBounds = false
+	config.Database.AdaptiveBinning = false
+
+	// Grid scaling must name a known dimension and strategy
+	config.Database.GridScaling = map[string]string{"complexity": "log", "novelty": "quantile"}
+	assert.NoError(t, manager.validate(config))
+	config.Database.GridScaling = map[string]string{"runtime": "log"}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "grid scaling set for unknown dimension")
+	config.Database.GridScaling = map[string]string{"complexity": "cubic"}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown grid scaling")
+
+	// Restore valid config
+	config.Database.GridScaling = map[string]string{}
+
+	// Test exploration ratio out of range
+	config.Database.ExplorationRatio = 1.5
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exploration ratio must be between 0 and 1")
+
+	// Restore valid config
+	config.Database.ExplorationRatio = 0.2
+
+	// Test negative rank exponent
+	config.Database.RankExponent = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "rank exponent must not be negative")
+
+	// Restore valid config
+	config.Database.RankExponent = 1
+
+	// Test adaptive migration with inverted rates
+	config.Database.AdaptiveMigration.Enabled = true
+	config.Database.AdaptiveMigration.MinRate = 0.6
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "adaptive migration rates")
+
+	// Restore valid config
+	config.Database.AdaptiveMigration.MinRate = 0
+	assert.NoError(t, manager.validate(config))
+	config.Database.AdaptiveMigration.Enabled = false
+
+	// Test negative extinction patience
+	config.Database.Extinction.Patience = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "extinction patience must not be negative")
+
+	// Restore valid config
+	config.Database.Extinction.Patience = 0
+
+	// Test a recency discount above the whole score
+	config.Database.RecencyDecay.MaxDiscount = 1.5
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "recency decay max discount")
+
+	// Restore valid config
+	config.Database.RecencyDecay.MaxDiscount = 0
+
+	// Test a negative compaction threshold
+	config.Database.Compaction.Threshold = -0.1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "compaction threshold must not be negative")
+
+	// Restore valid config
+	config.Database.Compaction.Threshold = 0
+
+	// Test an unknown fitness sharing distance
+	config.Database.FitnessSharing.Distance = "semantic"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid fitness sharing distance")
+
+	// Restore valid config
+	config.Database.FitnessSharing.Distance = ""
+
+	// Test LLM feedback without evaluator models to judge with
+	config.Evaluator.UseLLMFeedback = true
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "llm feedback requires evaluator models")
+
+	// Restore valid config
+	config.Evaluator.UseLLMFeedback = false
+
+	// Test a leaderboard task that is a path
+	config.Controller.Leaderboard.Task = "../task"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "leaderboard task must be a plain name")
+
+	// Restore valid config
+	config.Controller.Leaderboard.Task = ""
+
+	// Test phases with a repeated name and an unknown model
+	config.Controller.Phases = []types.PhaseConfig{{Name: "explore"}, {Name: "explore"}}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate phase")
+	config.Controller.Phases = []types.PhaseConfig{{Name: "refine", Model: "missing"}}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown model")
+	config.Controller.Phases = []types.PhaseConfig{{Name: "explore", Iterations: 10, ParentSelection: "uniform"}, {Name: "exploit", ParentSelection: "tournament"}}
+	assert.NoError(t, manager.validate(config))
+
+	// Restore valid config
+	config.Controller.Phases = nil
+
+	// Test a negative novelty archive size
+	config.Database.NoveltyArchive.Size = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "novelty archive")
+
+	// Restore valid config
+	config.Database.NoveltyArchive.Size = 0
+
 	// Test invalid evaluator config
 	originalWorkers := config.Evaluator.ParallelWorkers
 	config.Evaluator.ParallelWorkers = 0
@@ -131,6 +387,77 @@ func TestValidation(t *testing.T) {
 	// Restore valid config
 	config.Evaluator.ParallelWorkers = originalWorkers
 
+	// Test negative acceptance window
+	config.Evaluator.AcceptanceWindow = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "acceptance window must not be negative")
+
+	// Restore valid config
+	config.Evaluator.AcceptanceWindow = 0
+
+	// Test negative chunk size
+	config.Evaluator.ChunkSize = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "chunk size must not be negative")
+
+	// Restore valid config
+	config.Evaluator.ChunkSize = 0
+
+	// Test unknown network policy
+	config.Evaluator.Network = "proxy"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown network policy")
+
+	// Restore valid config
+	config.Evaluator.Network = "deny"
+
+	// Test unknown score direction
+	config.Evaluator.ScoreDirection = "ascending"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown score direction")
+
+	// Restore valid config
+	config.Evaluator.ScoreDirection = "minimize"
+
+	// Test command programs without an interpreter
+	config.Evaluator.ProgramType = "command"
+	config.Evaluator.Command.Interpreter = nil
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "command programs need an interpreter")
+
+	// Restore valid config
+	config.Evaluator.ProgramType = "go"
+
+	// Test adaptive timeout with a floor above its ceiling
+	config.Evaluator.AdaptiveTimeout.Enabled = true
+	config.Evaluator.AdaptiveTimeout.MinTimeout = 30
+	config.Evaluator.AdaptiveTimeout.MaxTimeout = 10
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "adaptive timeout floor must not exceed its ceiling")
+
+	// Restore valid config
+	config.Evaluator.AdaptiveTimeout.MaxTimeout = 0
+	assert.NoError(t, manager.validate(config))
+	config.Evaluator.AdaptiveTimeout.Enabled = false
+
+	// Test prompt shrinkage with a token factor above one
+	config.Prompt.Shrink.Enabled = true
+	config.Prompt.Shrink.TokenFactor = 1.5
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prompt shrink token factor must be between 0 and 1")
+
+	// Restore valid config
+	config.Prompt.Shrink.TokenFactor = 0.75
+	assert.NoError(t, manager.validate(config))
+	config.Prompt.Shrink.Enabled = false
+
 	// Test invalid controller config
 	originalMaxIter := config.Controller.MaxIterations
 	config.Controller.MaxIterations = 0
@@ -140,6 +467,78 @@ func TestValidation(t *testing.T) {
 
 	// Restore valid config
 	config.Controller.MaxIterations = originalMaxIter
+
+	// Test negative pacing
+	config.Controller.MaxIterationsPerMinute = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "max iterations per minute must not be negative")
+
+	// Restore valid config
+	config.Controller.MaxIterationsPerMinute = 0
+
+	// Test negative target check evaluations
+	config.Controller.TargetCheck.Evaluations = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "target check evaluations must not be negative")
+
+	// Restore valid config
+	config.Controller.TargetCheck.Evaluations = 0
+
+	// Test memory watchdog bounds
+	config.Controller.Memory.LimitMB = -1
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "memory limit and check interval must not be negative")
+	config.Controller.Memory.LimitMB = 1024
+	config.Controller.Memory.PruneFraction = 1.5
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "memory prune fraction must be between 0 and 1")
+
+	// Restore valid config
+	config.Controller.Memory.LimitMB = 0
+	config.Controller.Memory.PruneFraction = 0.25
+
+	// Test promotion without anywhere to deploy
+	config.Controller.Promotion.Enabled = true
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "promotion needs a copy target, command or webhook")
+	config.Controller.Promotion.Webhook = "http://localhost:8080/deploy"
+	assert.NoError(t, manager.validate(config))
+
+	// Restore valid config
+	config.Controller.Promotion = types.PromotionConfig{}
+
+	// Test island budget shares
+	config.Controller.IslandBudget.Shares = make([]float64, config.Database.NumIslands+1)
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "island budget has")
+	config.Controller.IslandBudget.Shares = []float64{2, 0}
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "island budget shares must be positive")
+
+	// Restore valid config
+	config.Controller.IslandBudget.Shares = nil
+
+	// Test tracking backends and their requirements
+	config.Tracking.Backend = "tensorboard"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown tracking backend")
+	config.Tracking.Backend = "mlflow"
+	err = manager.validate(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "mlflow tracking needs a tracking URI")
+
+	// Restore valid config
+	config.Tracking.URI = "http://localhost:5000"
+	assert.NoError(t, manager.validate(config))
+	config.Tracking.Backend = ""
 }
 
 func TestEnvOverrides(t *testing.T) {
@@ -152,8 +551,10 @@ func TestEnvOverrides(t *testing.T) {
 	os.Setenv("OPENAI_MODEL", "custom-model")
 	os.Setenv("NUM_ISLANDS", "20")
 	os.Setenv("OUTPUT_DIR", "custom-output")
+	os.Setenv("CHECKPOINT_INTERVAL", "25")
 	os.Setenv("MAX_ITERATIONS", "500")
 	os.Setenv("SEED", "123")
+	os.Setenv("MAX_ITERATIONS_PER_MINUTE", "7.5")
 	os.Setenv("VERBOSE", "true")
 	defer func() {
 		os.Unsetenv("OPENAI_API_BASE")
@@ -161,8 +562,10 @@ func TestEnvOverrides(t *testing.T) {
 		os.Unsetenv("OPENAI_MODEL")
 		os.Unsetenv("NUM_ISLANDS")
 		os.Unsetenv("OUTPUT_DIR")
+		os.Unsetenv("CHECKPOINT_INTERVAL")
 		os.Unsetenv("MAX_ITERATIONS")
 		os.Unsetenv("SEED")
+		os.Unsetenv("MAX_ITERATIONS_PER_MINUTE")
 		os.Unsetenv("VERBOSE")
 	}()
 
@@ -176,8 +579,10 @@ func TestEnvOverrides(t *testing.T) {
 	assert.Equal(t, "custom-model", config.LLM.Models[0].Name)
 	assert.Equal(t, 20, config.Database.NumIslands)
 	assert.Equal(t, "custom-output", config.Database.OutputDir)
+	assert.Equal(t, 25, config.Database.CheckpointInterval)
 	assert.Equal(t, 500, config.Controller.MaxIterations)
 	assert.Equal(t, 123, config.Controller.Seed)
+	assert.Equal(t, 7.5, config.Controller.MaxIterationsPerMinute)
 	assert.True(t, config.Controller.Verbose)
 }
 
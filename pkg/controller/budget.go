@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/iteration"
+)
+
+// islandBudget divides iterations between islands by weight. Every start
+// credits each running island with its weight's fraction of an iteration
+// and charges the starting island a whole one; an island may start while it
+// is less than one iteration in debt. Credits sum to zero, so some running
+// island can always start. A nil budget lets every island start at once.
+type islandBudget struct {
+	config types.IslandBudgetConfig
+
+	mu      sync.Mutex
+	islands []*islandAllowance
+	// Closed and replaced whenever credits change
+	changed chan struct{}
+}
+
+// islandAllowance is one island's standing in the budget
+type islandAllowance struct {
+	credit  float64
+	retired bool
+	best    float64
+	// Whether each of the island's last iterations improved its best,
+	// oldest first
+	recent []bool
+}
+
+// newIslandBudget returns a budget, or nil when islands are not budgeted
+func newIslandBudget(config types.IslandBudgetConfig, numIslands int) *islandBudget {
+	if len(config.Shares) == 0 && !config.Dynamic {
+		return nil
+	}
+	budget := &islandBudget{
+		config:  config,
+		islands: make([]*islandAllowance, numIslands),
+		changed: make(chan struct{}),
+	}
+	for i := range budget.islands {
+		budget.islands[i] = &islandAllowance{best: math.Inf(-1)}
+	}
+	return budget
+}
+
+// reset clears the credits and retirements of a previous run
+func (b *islandBudget) reset() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, island := range b.islands {
+		island.credit = 0
+		island.retired = false
+	}
+}
+
+// weight returns an island's current weight
+func (b *islandBudget) weight(islandID int) float64 {
+	weight := 1.0
+	if islandID < len(b.config.Shares) {
+		weight = b.config.Shares[islandID]
+	}
+	if b.config.Dynamic {
+		improvements := 0
+		for _, improved := range b.islands[islandID].recent {
+			if improved {
+				improvements++
+			}
+		}
+		weight *= float64(1 + improvements)
+	}
+	return weight
+}
+
+// acquire waits until the island may start an iteration and charges it for
+// one. It returns false if ctx is done first.
+func (b *islandBudget) acquire(ctx context.Context, islandID int) bool {
+	if b == nil {
+		return true
+	}
+	for {
+		b.mu.Lock()
+		if b.islands[islandID].credit > -1 {
+			b.charge(islandID)
+			b.mu.Unlock()
+			return true
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-changed:
+		}
+	}
+}
+
+// charge credits every running island its share of the iteration islandID
+// starts and charges islandID for it
+func (b *islandBudget) charge(islandID int) {
+	weights := make([]float64, len(b.islands))
+	total := 0.0
+	for i, island := range b.islands {
+		if !island.retired {
+			weights[i] = b.weight(i)
+			total += weights[i]
+		}
+	}
+	for i, island := range b.islands {
+		if !island.retired {
+			island.credit += weights[i] / total
+		}
+	}
+	b.islands[islandID].credit--
+	b.notify()
+}
+
+// retire removes an island that stopped from the budget, sharing its
+// credit or debt among the islands still running
+func (b *islandBudget) retire(islandID int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	retired := b.islands[islandID]
+	retired.retired = true
+	running := 0
+	for _, island := range b.islands {
+		if !island.retired {
+			running++
+		}
+	}
+	for _, island := range b.islands {
+		if !island.retired {
+			island.credit += retired.credit / float64(running)
+		}
+	}
+	retired.credit = 0
+	b.notify()
+}
+
+// observe records whether an iteration improved its island's best, for
+// dynamic allocation. A child the worker rolled back never entered the
+// database, so it counts as no improvement.
+func (b *islandBudget) observe(islandID int, result *iteration.IterationResult) {
+	if b == nil || !b.config.Dynamic {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	island := b.islands[islandID]
+	improved := false
+	if result != nil && !result.RolledBack && result.ChildProgram != nil && !result.ChildProgram.Failed &&
+		result.ChildProgram.Score > island.best {
+		island.best = result.ChildProgram.Score
+		improved = true
+	}
+
+	window := b.config.Window
+	if window <= 0 {
+		window = constants.DefaultIslandBudgetWindow
+	}
+	island.recent = append(island.recent, improved)
+	if len(island.recent) > window {
+		island.recent = island.recent[len(island.recent)-window:]
+	}
+}
+
+// notify wakes the islands waiting for credit
+func (b *islandBudget) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
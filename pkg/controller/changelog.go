@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
+)
+
+// maxChangelogCodeLength caps each lineage step's code in the changelog prompt
+const maxChangelogCodeLength = 2000
+
+// SetChangelogLLM sets the model used to summarize the best program's
+// lineage. Changelogs are only written when Controller.Changelog is enabled.
+func (c *Controller) SetChangelogLLM(client llm.Client) {
+	c.changelogLLM = client
+}
+
+// writeChangelog asks the LLM to describe how the best program evolved and
+// saves the answer to path. Failures are logged; they never stop the run.
+func (c *Controller) writeChangelog(ctx context.Context, path string) {
+	if !c.config.Controller.Changelog || c.changelogLLM == nil {
+		return
+	}
+
+	best := c.db.GetGlobalBest()
+	if best == nil {
+		return
+	}
+	lineage, err := c.db.GetLineage(best.ID)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to trace best lineage")
+		return
+	}
+	if len(lineage) > constants.ChangelogLineageLength {
+		lineage = lineage[len(lineage)-constants.ChangelogLineageLength:]
+	}
+
+	response, err := c.changelogLLM.Generate(ctx, changelogPrompt(lineage))
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to generate changelog")
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		c.logger.WithError(err).Warn("Failed to create changelog directory")
+		return
+	}
+	if err := os.WriteFile(path, []byte(response.Content), 0644); err != nil {
+		c.logger.WithError(err).Warn("Failed to write changelog")
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"file":    path,
+		"lineage": len(lineage),
+	}).Info("Wrote changelog")
+}
+
+// changelogPrompt builds the prompt describing the winning lineage
+func changelogPrompt(lineage []*types.Program) string {
+	var builder strings.Builder
+	builder.WriteString("Below is the lineage of the best program found by an evolutionary search, oldest first.\n")
+	builder.WriteString("Write a concise Markdown changelog for a human reader: one entry per step that explains the key algorithmic change and its effect on the score. ")
+	builder.WriteString("Finish with a short summary of how the final program differs from the first one.\n\n")
+
+	for i, program := range lineage {
+		code := program.Code
+		if len(code) > maxChangelogCodeLength {
+			code = code[:maxChangelogCodeLength] + "\n... (truncated)"
+		}
+		builder.WriteString(fmt.Sprintf("Step %d (Generation %d, Score: %.4f):\n", i+1, program.Generation, program.Score))
+		if program.Changes != "" {
+			builder.WriteString(fmt.Sprintf("Reported changes: %s\n", program.Changes))
+		}
+		builder.WriteString("```\n")
+		builder.WriteString(code)
+		builder.WriteString("\n```\n\n")
+	}
+
+	return builder.String()
+}
+
+// checkpointChangelogPath returns where the changelog for a checkpoint is saved
+func (c *Controller) checkpointChangelogPath(n int) string {
+	return filepath.Join(c.config.Controller.CheckpointDir, fmt.Sprintf("changelog_%d.md", n))
+}
+
+// finalChangelogPath returns where the end-of-run changelog is saved
+func (c *Controller) finalChangelogPath() string {
+	return filepath.Join(c.config.Database.OutputDir, constants.ChangelogFile)
+}
@@ -0,0 +1,602 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
+	"github.com/ishanwen-byte/openevolve-go/pkg/database"
+	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
+	"github.com/ishanwen-byte/openevolve-go/pkg/iteration"
+	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
+	"github.com/ishanwen-byte/openevolve-go/pkg/tracking"
+)
+
+// IslandRunner runs a single evolution iteration on an island
+type IslandRunner interface {
+	RunIslandIteration(ctx context.Context, islandID, iteration int) (*iteration.IterationResult, error)
+}
+
+// Controller drives an evolution run. Every island evolves in its own
+// goroutine; islands only synchronize for migration and checkpoints.
+type Controller struct {
+	config types.Config
+	db     *database.ProgramDatabase
+	runner IslandRunner
+	logger *logrus.Logger
+
+	// Iterations hold the read lock; migration and checkpoints take the
+	// write lock so they see a quiescent database
+	sync sync.RWMutex
+
+	// Bounds the number of iterations in flight across all islands
+	slots chan struct{}
+
+	// Spaces iteration starts to honor MaxIterationsPerMinute
+	pacer *pacer
+
+	// Prunes and checkpoints when memory use crosses the configured limit
+	memory *memoryWatchdog
+
+	// Validates and deploys new champions
+	promotion *promoter
+
+	// Divides iterations between islands when they are budgeted
+	budget *islandBudget
+
+	// Re-checks a champion that reaches the target score
+	targetCheck *targetCheck
+
+	// Moves the run through its configured phases
+	phases *phaseSchedule
+
+	// Last claimed iteration number and number of finished iterations
+	iteration atomic.Int64
+	finished  atomic.Int64
+
+	// Last iteration completed before the current run started
+	startIteration int
+
+	// Summarizes the best lineage into a changelog
+	changelogLLM llm.Client
+
+	// LLMs the controller built from config, for token accounting
+	ensemble *llm.Ensemble
+
+	// When the current run started
+	startedAt atomic.Pointer[time.Time]
+
+	// Components owned by the controller when it was built from config
+	evaluator *evaluator.Evaluator
+	holdout   *evaluator.Evaluator
+	auditor   *audit.Logger
+
+	// Experiment tracker the run reports to, if any
+	tracker tracking.Sink
+
+	// Checkpoint the run was forked from, if any
+	forkedFrom *ForkSource
+
+	// OnIteration, if set, is called after every successful iteration.
+	// It may be called concurrently from several islands.
+	OnIteration func(*iteration.IterationResult)
+
+	// OnProgress, if set, is called after seeding, after every iteration,
+	// successful or not, when a checkpoint is taken and when the run
+	// finishes. It may be called concurrently from several islands.
+	OnProgress func(Progress)
+
+	// OnPhase, if set, is called whenever the run enters one of its
+	// configured phases. Calls are never concurrent.
+	OnPhase func(PhaseChange)
+}
+
+// New creates a controller for the given database and iteration runner
+func New(config types.Config, db *database.ProgramDatabase, runner IslandRunner) *Controller {
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+	if config.Controller.Verbose {
+		logger.SetLevel(logrus.DebugLevel)
+	}
+
+	workers := config.Controller.ParallelWorkers
+	if workers <= 0 {
+		workers = config.Database.NumIslands
+	}
+
+	c := &Controller{
+		config:      config,
+		db:          db,
+		runner:      runner,
+		logger:      logger,
+		slots:       make(chan struct{}, workers),
+		pacer:       newPacer(config.Controller.MaxIterationsPerMinute),
+		memory:      newMemoryWatchdog(config.Controller.Memory),
+		promotion:   newPromoter(config.Controller.Promotion),
+		budget:      newIslandBudget(config.Controller.IslandBudget, config.Database.NumIslands),
+		targetCheck: newTargetCheck(config.Controller.TargetCheck),
+		phases:      newPhaseSchedule(config.Controller.Phases),
+	}
+	if c.promotion != nil {
+		db.OnNewGlobalBest(c.promotion.offer)
+	}
+	return c
+}
+
+// NewFromConfig builds a controller and everything it drives from config:
+// the audit log, the LLM ensemble and its model pools, the evaluator for the
+// program at evaluatorPath, the program database, the iteration worker and
+// the experiment tracker's run. Every external call is recorded in the audit
+// log when it is enabled.
+func NewFromConfig(config types.Config, evaluatorPath string) (*Controller, error) {
+	secrets := append(audit.LLMSecrets(config.LLM), config.Tracking.APIKey)
+	for _, override := range config.Database.IslandOverrides {
+		for _, model := range override.Models {
+			secrets = append(secrets, model.APIKey)
+		}
+	}
+	auditor, err := audit.New(config.Audit, secrets...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	ensemble, err := llm.NewEnsembleFromConfig(config.LLM)
+	if err == nil {
+		err = ensemble.AddIslandPools(config.Database.IslandOverrides, config.LLM)
+	}
+	if err != nil {
+		auditor.Close()
+		return nil, fmt.Errorf("failed to create LLM ensemble: %w", err)
+	}
+	ensemble.SetAuditLogger(auditor)
+
+	eval, err := evaluator.New(config.Evaluator, evaluatorPath)
+	if err != nil {
+		auditor.Close()
+		return nil, fmt.Errorf("failed to create evaluator: %w", err)
+	}
+	eval.SetAuditLogger(auditor)
+
+	judge, err := llm.NewEvaluatorEnsemble(config.LLM)
+	if err != nil {
+		eval.Close()
+		auditor.Close()
+		return nil, fmt.Errorf("failed to create evaluator LLM ensemble: %w", err)
+	}
+	if judge != nil {
+		judge.SetAuditLogger(auditor)
+		eval.SetJudge(judge)
+	}
+
+	holdout, err := newHoldoutEvaluator(config)
+	if err != nil {
+		eval.Close()
+		auditor.Close()
+		return nil, err
+	}
+	if holdout != nil {
+		holdout.SetAuditLogger(auditor)
+		if judge != nil {
+			holdout.SetJudge(judge)
+		}
+	}
+
+	tracker, err := tracking.New(context.Background(), config.Tracking)
+	if err != nil {
+		if holdout != nil {
+			holdout.Close()
+		}
+		eval.Close()
+		auditor.Close()
+		return nil, fmt.Errorf("failed to start experiment tracking: %w", err)
+	}
+
+	if config.Controller.Leaderboard.Task == "" {
+		config.Controller.Leaderboard.Task = evaluatorTask(evaluatorPath)
+	}
+
+	// Sampling is reproducible from the run seed unless the database has its own
+	if config.Database.RandomSeed == 0 {
+		config.Database.RandomSeed = int64(config.Controller.Seed)
+	}
+	db := database.New(config.Database, config.Controller.CheckpointDir)
+	db.SetEnvironment(eval.Environment())
+	worker := iteration.NewIterationWorker(config, db, eval, ensemble)
+
+	c := New(config, db, worker)
+	c.changelogLLM = ensemble
+	c.ensemble = ensemble
+	c.evaluator = eval
+	c.auditor = auditor
+	c.holdout = holdout
+	if c.promotion != nil {
+		c.promotion.evaluate = eval.Evaluate
+	}
+	if c.targetCheck != nil {
+		c.targetCheck.evaluate = eval.Evaluate
+		if holdout != nil {
+			c.targetCheck.holdout = holdout.Evaluate
+		}
+	}
+	c.tracker = tracker
+	return c, nil
+}
+
+// Database returns the program database the controller evolves
+func (c *Controller) Database() *database.ProgramDatabase {
+	return c.db
+}
+
+// Close releases the evaluator and audit log created by NewFromConfig and
+// finishes the experiment tracker's run
+func (c *Controller) Close() error {
+	if c.evaluator != nil {
+		c.evaluator.Close()
+	}
+	if c.holdout != nil {
+		c.holdout.Close()
+	}
+	if c.tracker != nil {
+		if err := c.tracker.Close(context.Background()); err != nil {
+			c.auditor.Close()
+			return fmt.Errorf("failed to finish experiment tracking run: %w", err)
+		}
+	}
+	if err := c.auditor.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	return nil
+}
+
+// Seed evaluates the initial program and places a copy of it on every
+// island, so each island starts with a parent. It needs the evaluator of a
+// controller built by NewFromConfig.
+func (c *Controller) Seed(ctx context.Context, code string) error {
+	return c.SeedIslands(ctx, []string{code})
+}
+
+// SeedIslands starts the islands from several initial programs so the run
+// compares different approaches. The islands are split into as many
+// contiguous groups as there are programs, each group seeded with its own
+// program: two programs on four islands seed islands 0 and 1 with the first
+// and islands 2 and 3 with the second.
+func (c *Controller) SeedIslands(ctx context.Context, codes []string) error {
+	if c.evaluator == nil {
+		return fmt.Errorf("controller has no evaluator to seed with")
+	}
+	numIslands := c.db.NumIslands()
+	if len(codes) == 0 {
+		return fmt.Errorf("at least one initial program is required")
+	}
+	if len(codes) > numIslands {
+		return fmt.Errorf("cannot seed %d islands with %d initial programs", numIslands, len(codes))
+	}
+
+	results := make([]*types.EvaluationResult, len(codes))
+	for idx, code := range codes {
+		result, err := c.evaluator.Evaluate(ctx, code)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate initial program %d: %w", idx, err)
+		}
+		if result.ID != "" {
+			c.evaluator.ClearArtifacts(result.ID)
+		}
+		if !result.Success {
+			c.logger.WithFields(logrus.Fields{
+				"program": idx,
+				"error":   result.Error,
+			}).Warn("Initial program failed evaluation")
+		}
+		results[idx] = result
+	}
+
+	now := time.Now()
+	for islandID := 0; islandID < numIslands; islandID++ {
+		idx := islandID * len(codes) / numIslands
+		result := results[idx]
+		program := &types.Program{
+			ID:        uuid.New().String(),
+			Code:      codes[idx],
+			Score:     result.Score,
+			Failed:    !result.Success,
+			Metrics:   result.Metrics,
+			Behavior:  result.Behavior,
+			Fitness:   result.Score,
+			Features:  c.db.GridFeatures(codes[idx], result.NamedFeatures, iteration.ExtractFeatures(result)),
+			IslandID:  islandID,
+			Tags:      append([]string(nil), c.config.Database.Tags...),
+			Artifacts: result.Artifacts,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := c.db.AddProgram(program, 0); err != nil {
+			return fmt.Errorf("failed to add initial program: %w", err)
+		}
+	}
+
+	scores := make([]float64, len(results))
+	for idx, result := range results {
+		scores[idx] = result.Score
+	}
+	c.logger.WithFields(logrus.Fields{
+		"scores":  scores,
+		"islands": numIslands,
+	}).Info("Seeded islands with initial programs")
+	c.reportProgress(constants.ProgressPhaseSeeding, 0)
+
+	return nil
+}
+
+// Run evolves all islands concurrently until the iteration budget is spent,
+// the target score is reached or ctx is cancelled. startIteration is the
+// last iteration already completed, e.g. when resuming from a checkpoint.
+func (c *Controller) Run(ctx context.Context, startIteration int) error {
+	if c.config.Database.NumIslands <= 0 {
+		return fmt.Errorf("number of islands must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c.iteration.Store(int64(startIteration))
+	c.finished.Store(0)
+	c.startIteration = startIteration
+	c.budget.reset()
+
+	c.logger.WithFields(logrus.Fields{
+		"islands":        c.config.Database.NumIslands,
+		"max_iterations": c.config.Controller.MaxIterations,
+		"workers":        cap(c.slots),
+		"max_per_minute": c.config.Controller.MaxIterationsPerMinute,
+	}).Info("Starting evolution")
+
+	startTime := time.Now()
+	c.startedAt.Store(&startTime)
+	c.writeManifest(startTime, startIteration, 0, false)
+	c.trackConfig(ctx)
+	c.startPhases(startIteration)
+	stopWatchdog := c.watchMemory(ctx)
+	stopPromotions := c.runPromotions(ctx)
+
+	var wg sync.WaitGroup
+	for islandID := 0; islandID < c.config.Database.NumIslands; islandID++ {
+		wg.Add(1)
+		go func(islandID int) {
+			defer wg.Done()
+			defer c.budget.retire(islandID)
+			if c.runIsland(ctx, islandID) {
+				cancel()
+			}
+		}(islandID)
+	}
+	wg.Wait()
+	c.db.WaitMigrations()
+	stopWatchdog()
+	stopPromotions()
+
+	// Always leave a checkpoint behind for resume
+	finished := int(c.finished.Load())
+	if err := c.checkpoint(startIteration + finished); err != nil {
+		return err
+	}
+
+	// The run context may already be cancelled; the summary should still be written
+	c.writeChangelog(context.WithoutCancel(ctx), c.finalChangelogPath())
+	c.writeManifest(startTime, startIteration, finished, true)
+	c.recordLeaderboard(startIteration + finished)
+	c.trackArtifacts(context.WithoutCancel(ctx))
+	c.reportProgress(constants.ProgressPhaseFinished, startIteration+finished)
+
+	c.logger.WithFields(logrus.Fields{
+		"iterations": finished,
+		"duration":   time.Since(startTime),
+		"failures":   c.db.GetStats().Failures,
+	}).Info("Evolution finished")
+
+	return nil
+}
+
+// runIsland runs the evolution loop of one island. It returns true when the
+// target score was reached and the whole run should stop.
+func (c *Controller) runIsland(ctx context.Context, islandID int) bool {
+	for ctx.Err() == nil {
+		if !c.budget.acquire(ctx, islandID) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case c.slots <- struct{}{}:
+		}
+
+		n := int(c.iteration.Add(1))
+		if n > c.config.Controller.MaxIterations {
+			<-c.slots
+			return false
+		}
+		if err := c.pacer.Wait(ctx); err != nil {
+			<-c.slots
+			return false
+		}
+
+		c.sync.RLock()
+		result, err := c.runner.RunIslandIteration(ctx, islandID, n)
+		c.sync.RUnlock()
+		<-c.slots
+
+		// Only the run context ends the loop; a request or evaluation that
+		// timed out on its own is just a failed iteration
+		if ctx.Err() != nil {
+			return false
+		}
+		c.finished.Add(1)
+
+		if err != nil {
+			category := iteration.FailureCategory(err)
+			c.db.RecordFailure(category)
+			c.logger.WithError(err).WithFields(logrus.Fields{
+				"island":    islandID,
+				"iteration": n,
+				"category":  category,
+			}).Warn("Iteration failed")
+		} else {
+			if result.Failure != "" {
+				c.db.RecordFailure(result.Failure)
+			}
+			if c.OnIteration != nil {
+				c.OnIteration(result)
+			}
+		}
+
+		c.budget.observe(islandID, result)
+		c.advancePhase(n)
+		c.trackIteration(ctx, n, result)
+		c.db.IncrementIslandGeneration(islandID)
+		c.checkEvaluator(ctx, n)
+		c.reportProgress(constants.ProgressPhaseEvolving, n)
+		if c.synchronize(n) {
+			c.writeChangelog(ctx, c.checkpointChangelogPath(n))
+			c.reportProgress(constants.ProgressPhaseCheckpoint, n)
+		}
+
+		if c.targetReached(ctx) {
+			c.logger.WithField("iteration", n).Info("Target score reached")
+			return true
+		}
+	}
+	return false
+}
+
+// synchronize performs migration, extinction and checkpoints that are due.
+// They run with every island paused between iterations. It reports whether
+// a checkpoint was started.
+func (c *Controller) synchronize(n int) bool {
+	// Background migrations take the database's write lock themselves, so
+	// the islands need not be paused for them
+	if c.config.Database.AsyncMigration {
+		c.db.MigrateIfDue()
+	}
+	migrate := !c.config.Database.AsyncMigration && c.db.ShouldMigrate()
+	extinct := len(c.db.StagnantIslands()) > 0
+	interval := c.config.Database.CheckpointInterval
+	checkpoint := interval > 0 && n%interval == 0
+	if !migrate && !extinct && !checkpoint {
+		return false
+	}
+
+	c.sync.Lock()
+	defer c.sync.Unlock()
+
+	if extinct {
+		c.db.ReseedStagnantIslands()
+	}
+
+	// Another island may have migrated while we waited for the lock
+	if migrate {
+		c.db.MigrateIfDue()
+	}
+	// Only the snapshot is taken with the islands paused; the checkpoint
+	// is written in the background
+	if checkpoint {
+		c.db.SaveCheckpointAsync(n, func(err error) {
+			if err != nil {
+				c.logger.WithError(err).WithField("iteration", n).Warn("Failed to save checkpoint")
+			}
+		})
+	}
+	return checkpoint
+}
+
+// checkEvaluator looks for a change to the evaluator program. Scores from
+// before a change are marked stale and, if configured, the elites are
+// re-evaluated, with every island paused.
+func (c *Controller) checkEvaluator(ctx context.Context, n int) {
+	if c.evaluator == nil {
+		return
+	}
+	changed, err := c.evaluator.CheckEvaluatorChange()
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to check evaluator program")
+		return
+	}
+	if !changed {
+		return
+	}
+
+	c.sync.Lock()
+	defer c.sync.Unlock()
+
+	environment := c.evaluator.Environment()
+	c.db.SetEnvironment(environment)
+	c.db.MarkScoresStale(n, environment.EvaluatorHash)
+	if !c.config.Evaluator.ReevaluateOnChange {
+		return
+	}
+
+	elites := c.db.StaleElites()
+	codes := make([]string, len(elites))
+	for i, program := range elites {
+		codes[i] = program.Code
+	}
+	results, err := c.evaluator.EvaluateBatch(ctx, codes)
+	if err != nil {
+		c.logger.WithError(err).Warn("Some elites failed re-evaluation")
+	}
+
+	rescored := 0
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		if result.ID != "" {
+			c.evaluator.ClearArtifacts(result.ID)
+		}
+		// An elite failing under the new evaluator keeps its stale score
+		if !result.Success {
+			continue
+		}
+		if err := c.db.Rescore(elites[i].ID, result.Score, result.Metrics); err != nil {
+			c.logger.WithError(err).Warn("Failed to rescore elite")
+			continue
+		}
+		rescored++
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"iteration": n,
+		"elites":    len(elites),
+		"rescored":  rescored,
+	}).Info("Re-evaluated elites under the changed evaluator")
+}
+
+// checkpoint saves a checkpoint with every island paused
+func (c *Controller) checkpoint(n int) error {
+	c.sync.Lock()
+	defer c.sync.Unlock()
+
+	if err := c.db.SaveCheckpoint(n); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// targetReached reports whether the best program meets the target score,
+// which is in the evaluator's units, and holds it when re-checked
+func (c *Controller) targetReached(ctx context.Context) bool {
+	target := c.config.Controller.TargetScore
+	if target == nil {
+		return false
+	}
+	best := c.db.GetGlobalBest()
+	if best == nil || best.Failed {
+		return false
+	}
+	return c.meetsScore(best.Score, *target) && c.targetConfirmed(ctx, best)
+}
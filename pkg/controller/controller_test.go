@@ -0,0 +1,924 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
+	"github.com/ishanwen-byte/openevolve-go/pkg/database"
+	"github.com/ishanwen-byte/openevolve-go/pkg/iteration"
+	"github.com/ishanwen-byte/openevolve-go/pkg/leaderboard"
+)
+
+// fakeRunner adds a program per iteration and tracks per-island concurrency
+type fakeRunner struct {
+	db        *database.ProgramDatabase
+	mu        sync.Mutex
+	perIsland map[int]int
+	active    map[int]*atomic.Int32
+	overlap   atomic.Bool
+	score     float64
+	// Iterations on this island fail with a request timeout
+	timeoutIsland int
+}
+
+func newFakeRunner(db *database.ProgramDatabase, islands int) *fakeRunner {
+	runner := &fakeRunner{
+		db:            db,
+		perIsland:     make(map[int]int),
+		active:        make(map[int]*atomic.Int32),
+		timeoutIsland: -1,
+	}
+	for i := 0; i < islands; i++ {
+		runner.active[i] = &atomic.Int32{}
+	}
+	return runner
+}
+
+func (r *fakeRunner) RunIslandIteration(ctx context.Context, islandID, n int) (*iteration.IterationResult, error) {
+	if r.active[islandID].Add(1) > 1 {
+		r.overlap.Store(true)
+	}
+	defer r.active[islandID].Add(-1)
+
+	// Give the other islands a chance to claim iterations
+	time.Sleep(time.Millisecond)
+
+	if islandID == r.timeoutIsland {
+		r.mu.Lock()
+		r.perIsland[islandID]++
+		r.mu.Unlock()
+		return nil, fmt.Errorf("generation failed: %w", context.DeadlineExceeded)
+	}
+
+	program := &types.Program{
+		ID:       fmt.Sprintf("island%d-iter%d", islandID, n),
+		Score:    r.score,
+		Features: []float64{0.5},
+		IslandID: islandID,
+	}
+	if err := r.db.AddProgram(program, n); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.perIsland[islandID]++
+	r.mu.Unlock()
+
+	return &iteration.IterationResult{Iteration: n, ChildProgram: program}, nil
+}
+
+func testConfig(dir string, islands, iterations int) types.Config {
+	return types.Config{
+		Database: types.DatabaseConfig{
+			NumIslands:         islands,
+			GridDimensions:     []string{"complexity"},
+			GridResolution:     map[string]int{"complexity": 5},
+			GridBounds:         map[string][2]float64{"complexity": {0, 1}},
+			MigrationInterval:  2,
+			MigrationRate:      0.5,
+			CheckpointInterval: 10,
+			OutputDir:          dir,
+		},
+		Controller: types.ControllerConfig{
+			MaxIterations:   iterations,
+			ParallelWorkers: islands,
+		},
+	}
+}
+
+func TestControllerRunsEveryIsland(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 3, 30)
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 3)
+
+	var results atomic.Int32
+	controller := New(config, db, runner)
+	controller.OnIteration = func(*iteration.IterationResult) { results.Add(1) }
+
+	require.NoError(t, controller.Run(context.Background(), 0))
+
+	assert.Equal(t, int32(30), results.Load())
+	assert.False(t, runner.overlap.Load(), "an island ran two iterations at once")
+	for islandID := 0; islandID < 3; islandID++ {
+		assert.Positive(t, runner.perIsland[islandID], "island %d never ran", islandID)
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "checkpoint_30.json"))
+	assert.NoError(t, err)
+}
+
+func TestControllerStopsAtTargetScore(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 1000)
+	target := 0.5
+	config.Controller.TargetScore = &target
+
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 2)
+	runner.score = 0.9
+
+	require.NoError(t, New(config, db, runner).Run(context.Background(), 0))
+
+	total := 0
+	for _, count := range runner.perIsland {
+		total += count
+	}
+	assert.Less(t, total, 1000)
+}
+
+func TestControllerRechecksTargetScore(t *testing.T) {
+	for _, holdoutScore := range []float64{0.4, 0.6} {
+		dir := t.TempDir()
+		config := testConfig(dir, 1, 20)
+		target := 0.5
+		config.Controller.TargetScore = &target
+		config.Controller.TargetCheck = types.TargetCheckConfig{Evaluations: 3, HoldoutEvaluator: "holdout.go"}
+
+		db := database.New(config.Database, dir)
+		runner := newFakeRunner(db, 1)
+		runner.score = 0.9
+		controller := New(config, db, runner)
+		require.NotNil(t, controller.targetCheck)
+
+		var evaluations, holdouts atomic.Int32
+		controller.targetCheck.evaluate = func(ctx context.Context, code string) (*types.EvaluationResult, error) {
+			evaluations.Add(1)
+			return &types.EvaluationResult{Score: 0.9, Success: true}, nil
+		}
+		controller.targetCheck.holdout = func(ctx context.Context, code string) (*types.EvaluationResult, error) {
+			holdouts.Add(1)
+			return &types.EvaluationResult{Score: holdoutScore, Success: true}, nil
+		}
+
+		require.NoError(t, controller.Run(context.Background(), 0))
+
+		// The champion never changes, so it is checked once
+		assert.Equal(t, int32(3), evaluations.Load())
+		assert.Equal(t, int32(1), holdouts.Load())
+		if holdoutScore < target {
+			// A champion that does not hold the target keeps the run going
+			assert.Equal(t, 20, runner.perIsland[0])
+		} else {
+			assert.Equal(t, 1, runner.perIsland[0])
+		}
+	}
+}
+
+func TestControllerSurvivesRequestTimeouts(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 20)
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 2)
+	runner.timeoutIsland = 1
+
+	require.NoError(t, New(config, db, runner).Run(context.Background(), 0))
+
+	// A timed out request is a failed iteration, not the end of the island
+	assert.Greater(t, runner.perIsland[1], 1)
+	assert.Equal(t, 20, runner.perIsland[0]+runner.perIsland[1])
+
+	// Untagged errors are counted as other failures
+	assert.Equal(t, map[string]int64{constants.FailureOther: int64(runner.perIsland[1])}, db.GetStats().Failures)
+}
+
+func TestControllerPacesIterations(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 3, 6)
+	config.Controller.MaxIterationsPerMinute = 1200 // one start every 50ms
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 3)
+
+	var mu sync.Mutex
+	var starts []time.Time
+	controller := New(config, db, runner)
+	controller.OnIteration = func(*iteration.IterationResult) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+	}
+
+	begin := time.Now()
+	require.NoError(t, controller.Run(context.Background(), 0))
+
+	// Six starts spaced 50ms apart, even with three islands racing
+	assert.Len(t, starts, 6)
+	assert.GreaterOrEqual(t, time.Since(begin), 250*time.Millisecond)
+}
+
+func TestPacerWaitHonorsCancellation(t *testing.T) {
+	p := newPacer(1) // one start a minute
+	require.NoError(t, p.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Wait(ctx), context.DeadlineExceeded)
+
+	// A nil pacer never waits
+	var unlimited *pacer
+	assert.NoError(t, unlimited.Wait(context.Background()))
+}
+
+func TestControllerRebalancesBins(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 1, 10)
+	config.Database.AdaptiveBinning = true
+	config.Database.AdaptiveBinInterval = 5
+	db := database.New(config.Database, dir)
+
+	require.NoError(t, New(config, db, newFakeRunner(db, 1)).Run(context.Background(), 0))
+
+	// Edges are only written once bins have been re-fitted
+	checkpoint, err := os.ReadFile(filepath.Join(dir, "checkpoint_10.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(checkpoint), `"edges"`)
+}
+
+func TestControllerWritesRunManifest(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 20)
+	config.Controller.Seed = 7
+	db := database.New(config.Database, dir)
+
+	require.NoError(t, New(config, db, newFakeRunner(db, 2)).Run(context.Background(), 0))
+
+	data, err := os.ReadFile(filepath.Join(dir, constants.RunManifestFile))
+	require.NoError(t, err)
+	var manifest RunManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	assert.NotEmpty(t, manifest.ConfigHash)
+	assert.Equal(t, 7, manifest.Seeds.Controller)
+	require.NotNil(t, manifest.FinishedAt)
+	assert.False(t, manifest.FinishedAt.Before(manifest.StartedAt))
+	assert.Equal(t, 20, manifest.Iterations)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "checkpoint_10.json"),
+		filepath.Join(dir, "checkpoint_20.json"),
+	}, manifest.Checkpoints)
+	assert.Equal(t, int64(20), manifest.Stats.TotalEvaluations)
+
+	best := db.GetGlobalBest()
+	require.NotNil(t, best)
+	assert.Equal(t, best.ID, manifest.BestProgramID)
+	assert.Equal(t, filepath.Join(dir, constants.BestProgramFile), manifest.BestProgram)
+}
+
+// phasedRunner records the phase each iteration ran in
+type phasedRunner struct {
+	*fakeRunner
+	phase  atomic.Pointer[types.PhaseConfig]
+	phases sync.Map
+}
+
+func (r *phasedRunner) SetPhase(phase types.PhaseConfig) {
+	r.phase.Store(&phase)
+}
+
+func (r *phasedRunner) RunIslandIteration(ctx context.Context, islandID, n int) (*iteration.IterationResult, error) {
+	r.phases.Store(n, r.phase.Load().Name)
+	return r.fakeRunner.RunIslandIteration(ctx, islandID, n)
+}
+
+func TestControllerRunsPhases(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 1, 12)
+	target := 0.5
+	config.Controller.Phases = []types.PhaseConfig{
+		{Name: "explore", Iterations: 4, ParentSelection: "uniform"},
+		{Name: "exploit", Stagnation: 3, ParentSelection: "tournament"},
+		{Name: "refine", TargetScore: &target},
+		{Name: "finalize"},
+	}
+	db := database.New(config.Database, dir)
+	runner := &phasedRunner{fakeRunner: newFakeRunner(db, 1)}
+	runner.score = 0.4
+
+	controller := New(config, db, runner)
+	var changes []PhaseChange
+	controller.OnPhase = func(change PhaseChange) {
+		changes = append(changes, change)
+	}
+	require.NoError(t, controller.Run(context.Background(), 0))
+
+	// The fake runner never improves on its first program, so exploit ends
+	// by stagnation and refine never reaches its target
+	require.Len(t, changes, 3)
+	assert.Equal(t, PhaseChange{To: "explore", Reason: constants.PhaseReasonStart}, changes[0])
+	assert.Equal(t, PhaseChange{From: "explore", To: "exploit", Iteration: 4, Reason: constants.PhaseReasonIterations}, changes[1])
+	assert.Equal(t, PhaseChange{From: "exploit", To: "refine", Iteration: 7, Reason: constants.PhaseReasonStagnation}, changes[2])
+	phase, _ := runner.phases.Load(5)
+	assert.Equal(t, "exploit", phase)
+	phase, _ = runner.phases.Load(12)
+	assert.Equal(t, "refine", phase)
+	assert.Equal(t, "refine", db.GetStats().Phase)
+
+	// A resumed run continues in the phase its checkpoint recorded
+	resumed := database.New(config.Database, dir)
+	require.NoError(t, resumed.LoadCheckpoint(filepath.Join(dir, "checkpoint_12.json")))
+	config.Controller.MaxIterations = 14
+	runner = &phasedRunner{fakeRunner: newFakeRunner(resumed, 1)}
+	runner.score = 0.6
+	controller = New(config, resumed, runner)
+	changes = nil
+	controller.OnPhase = func(change PhaseChange) {
+		changes = append(changes, change)
+	}
+	require.NoError(t, controller.Run(context.Background(), 12))
+	require.Len(t, changes, 2)
+	assert.Equal(t, PhaseChange{To: "refine", Iteration: 12, Reason: constants.PhaseReasonResume}, changes[0])
+	assert.Equal(t, PhaseChange{From: "refine", To: "finalize", Iteration: 13, Reason: constants.PhaseReasonTargetScore}, changes[1])
+}
+
+func TestControllerRecordsLeaderboard(t *testing.T) {
+	dir := t.TempDir()
+	boards := t.TempDir()
+	config := testConfig(dir, 2, 10)
+	config.Controller.Leaderboard = types.LeaderboardConfig{Dir: boards, Task: "bench"}
+	db := database.New(config.Database, dir)
+
+	require.NoError(t, New(config, db, newFakeRunner(db, 2)).Run(context.Background(), 0))
+
+	entries, err := leaderboard.Load(leaderboard.File(boards, "bench"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	best := db.GetGlobalBest()
+	require.NotNil(t, best)
+	assert.Equal(t, best.ID, entries[0].ProgramID)
+	assert.Equal(t, best.Score, entries[0].Score)
+	assert.Equal(t, audit.Hash([]byte(best.Code)), entries[0].ProgramHash)
+	assert.Equal(t, 10, entries[0].Iterations)
+	assert.Equal(t, 2, entries[0].Config.Islands)
+	assert.NotEmpty(t, entries[0].ConfigHash)
+
+	assert.Equal(t, "myproblem", evaluatorTask(filepath.Join("problems", "myproblem", "evaluator.go")))
+}
+
+func TestControllerForksFromCheckpoint(t *testing.T) {
+	sourceDir := t.TempDir()
+	sourceConfig := testConfig(sourceDir, 2, 10)
+	sourceDB := database.New(sourceConfig.Database, sourceDir)
+	runner := newFakeRunner(sourceDB, 2)
+	runner.score = 0.4
+	require.NoError(t, New(sourceConfig, sourceDB, runner).Run(context.Background(), 0))
+	checkpointPath := filepath.Join(sourceDir, "checkpoint_10.json")
+
+	// The fork adds a dimension an extractor derives and an island
+	forkDir := t.TempDir()
+	forkConfig := testConfig(forkDir, 3, 4)
+	forkConfig.Database.GridDimensions = []string{"score", "complexity"}
+	forkConfig.Database.GridResolution = map[string]int{"score": 4, "complexity": 5}
+	forkDB := database.New(forkConfig.Database, forkDir)
+	forked := New(forkConfig, forkDB, newFakeRunner(forkDB, 3))
+	require.NoError(t, forked.fork(checkpointPath))
+
+	for _, program := range sourceDB.GetIslandBest() {
+		imported, exists := forkDB.GetProgram(program.ID)
+		require.True(t, exists, "program %s was not imported", program.ID)
+		assert.Len(t, imported.Features, 2)
+	}
+	require.NoError(t, forked.Run(context.Background(), 0))
+
+	data, err := os.ReadFile(filepath.Join(forkDir, constants.RunManifestFile))
+	require.NoError(t, err)
+	var manifest RunManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.NotNil(t, manifest.ForkedFrom)
+	assert.Equal(t, checkpointPath, manifest.ForkedFrom.Checkpoint)
+	assert.Equal(t, filepath.Join(sourceDir, constants.RunManifestFile), manifest.ForkedFrom.Manifest)
+	assert.Equal(t, 10, manifest.ForkedFrom.Iteration)
+	assert.Equal(t, sourceDB.GetGlobalBest().ID, manifest.ForkedFrom.BestProgramID)
+
+	// A dimension neither in the checkpoint nor derivable cannot be mapped
+	badConfig := testConfig(t.TempDir(), 2, 4)
+	badConfig.Database.GridDimensions = []string{"complexity", "diversity"}
+	badDB := database.New(badConfig.Database, badConfig.Database.OutputDir)
+	err = New(badConfig, badDB, nil).fork(checkpointPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "diversity")
+
+	// Nor can a fork write over its source run
+	err = New(sourceConfig, database.New(sourceConfig.Database, sourceDir), nil).fork(checkpointPath)
+	assert.Error(t, err)
+}
+
+func TestControllerReportsProgress(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 1000)
+	config.Database.CheckpointInterval = 4
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 2)
+	runner.score = 0.3
+
+	// The caller stops the run once it has seen enough
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	phases := make(map[string]int)
+	var last Progress
+	controller := New(config, db, runner)
+	controller.OnProgress = func(progress Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		phases[progress.Phase]++
+		last = progress
+		if progress.Completed >= 10 {
+			cancel()
+		}
+	}
+
+	require.NoError(t, controller.Run(ctx, 0))
+
+	assert.GreaterOrEqual(t, phases[constants.ProgressPhaseEvolving], 10)
+	assert.Less(t, phases[constants.ProgressPhaseEvolving], 1000)
+	assert.Positive(t, phases[constants.ProgressPhaseCheckpoint])
+	assert.Equal(t, 1, phases[constants.ProgressPhaseFinished])
+	assert.Equal(t, constants.ProgressPhaseFinished, last.Phase)
+	assert.Equal(t, 0.3, last.BestScore)
+	assert.NotEmpty(t, last.BestProgramID)
+	assert.Equal(t, 1000, last.MaxIterations)
+	assert.Positive(t, last.Elapsed)
+}
+
+func TestControllerHonorsCancellation(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 1000)
+	db := database.New(config.Database, dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runner := newFakeRunner(db, 2)
+	require.NoError(t, New(config, db, runner).Run(ctx, 0))
+	assert.Empty(t, runner.perIsland)
+}
+
+// fakeLLM records the prompts it receives and answers with a fixed changelog
+type fakeLLM struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (f *fakeLLM) Generate(ctx context.Context, prompt string) (*types.LLMResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts = append(f.prompts, prompt)
+	return &types.LLMResponse{Content: "# Changelog\n"}, nil
+}
+
+func (f *fakeLLM) GenerateWithSystemMessage(ctx context.Context, systemMessage string, messages []types.LLMMessage) (*types.LLMResponse, error) {
+	return f.Generate(ctx, messages[len(messages)-1].Content)
+}
+
+func TestControllerWritesChangelog(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 10)
+	config.Controller.CheckpointDir = filepath.Join(dir, "checkpoints")
+	config.Controller.Changelog = true
+	config.Database.CheckpointInterval = 5
+
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 2)
+	runner.score = 0.4
+
+	summarizer := &fakeLLM{}
+	controller := New(config, db, runner)
+	controller.SetChangelogLLM(summarizer)
+	require.NoError(t, controller.Run(context.Background(), 0))
+
+	data, err := os.ReadFile(filepath.Join(dir, "changelog.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Changelog\n", string(data))
+
+	_, err = os.Stat(filepath.Join(dir, "checkpoints", "changelog_5.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, summarizer.prompts[0], "Step 1")
+}
+
+func TestChangelogPromptIncludesChanges(t *testing.T) {
+	prompt := changelogPrompt([]*types.Program{
+		{Generation: 0, Score: 0.1, Code: "v0"},
+		{Generation: 1, Score: 0.5, Code: "v1", Changes: "Switched to a heap"},
+	})
+	assert.Contains(t, prompt, "Reported changes: Switched to a heap")
+	assert.Equal(t, 1, strings.Count(prompt, "Reported changes"))
+}
+
+func TestNewFromConfigWritesAuditLog(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"model": "audited", "choices": [{"message": {"role": "assistant", "content": "ok"}}]}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	evaluatorPath := filepath.Join(dir, "evaluator.go")
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	auditPath := filepath.Join(dir, "audit.jsonl")
+	config := testConfig(dir, 1, 1)
+	config.LLM = types.LLMConfig{
+		APIBase: server.URL,
+		APIKey:  "sk-test-secret-value",
+		Models:  []types.LLMModelConfig{{Name: "audited", Weight: 1}},
+	}
+	config.Evaluator = types.EvaluatorConfig{ParallelWorkers: 1, Timeout: 60}
+	config.Audit = types.AuditConfig{Enabled: true, Path: auditPath}
+
+	controller, err := NewFromConfig(config, evaluatorPath)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = controller.changelogLLM.Generate(ctx, "prompt")
+	require.NoError(t, err)
+	_, err = controller.evaluator.Evaluate(ctx, "package main\n\nfunc main() {}\n")
+	require.NoError(t, err)
+	require.NoError(t, controller.Close())
+
+	data, err := os.ReadFile(auditPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-test-secret-value")
+
+	kinds := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry audit.Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		kinds[entry.Kind] = true
+	}
+	assert.True(t, kinds[audit.KindLLM], "LLM call was not audited")
+	assert.True(t, kinds[audit.KindExec], "evaluation was not audited")
+}
+
+func TestControllerSeedsIslandGroups(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	// The evaluator is compiled together with the program it scores, so it
+	// must sit in the directory programs are written to
+	evaluatorFile, err := os.CreateTemp("", "evaluator-*.go")
+	require.NoError(t, err)
+	defer os.Remove(evaluatorFile.Name())
+	_, err = evaluatorFile.WriteString("package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(score()) }\n")
+	require.NoError(t, err)
+	require.NoError(t, evaluatorFile.Close())
+
+	dir := t.TempDir()
+	config := testConfig(dir, 4, 0)
+	config.Database.GridDimensions = []string{"complexity", "diversity"}
+	config.Database.GridResolution = map[string]int{"complexity": 5, "diversity": 5}
+	config.Database.GridBounds = map[string][2]float64{"complexity": {0, 1}, "diversity": {0, 1}}
+	config.LLM.Models = []types.LLMModelConfig{{Name: "unused", Weight: 1}}
+	config.Evaluator = types.EvaluatorConfig{ParallelWorkers: 1, Timeout: 60}
+
+	controller, err := NewFromConfig(config, evaluatorFile.Name())
+	require.NoError(t, err)
+	defer controller.Close()
+
+	greedy := "package main\n\nfunc score() float64 { return 0.2 }\n"
+	dynamic := "package main\n\nfunc score() float64 { return 0.4 }\n"
+	require.NoError(t, controller.SeedIslands(context.Background(), []string{greedy, dynamic}))
+
+	best := controller.db.GetIslandBest()
+	require.Len(t, best, 4)
+	for islandID, want := range []string{greedy, greedy, dynamic, dynamic} {
+		assert.Equal(t, want, best[islandID].Code, "island %d", islandID)
+	}
+	assert.Equal(t, 0.4, controller.db.GetGlobalBest().Score)
+
+	assert.Error(t, controller.SeedIslands(context.Background(), []string{greedy, greedy, greedy, greedy, greedy}))
+	assert.Error(t, controller.SeedIslands(context.Background(), nil))
+}
+
+// fakeSink records what a run reports to an experiment tracker
+type fakeSink struct {
+	mu        sync.Mutex
+	config    *types.Config
+	steps     []int
+	metrics   []map[string]float64
+	artifacts map[string][]byte
+	closed    bool
+}
+
+func (s *fakeSink) LogConfig(ctx context.Context, config types.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = &config
+	return nil
+}
+
+func (s *fakeSink) LogMetrics(ctx context.Context, step int, metrics map[string]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, step)
+	s.metrics = append(s.metrics, metrics)
+	return nil
+}
+
+func (s *fakeSink) LogArtifact(ctx context.Context, name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.artifacts == nil {
+		s.artifacts = make(map[string][]byte)
+	}
+	s.artifacts[name] = data
+	return nil
+}
+
+func (s *fakeSink) Close(ctx context.Context) error {
+	s.closed = true
+	return nil
+}
+
+func TestControllerReportsToTracker(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 10)
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 2)
+	runner.score = 0.4
+	runner.timeoutIsland = 1
+
+	sink := &fakeSink{}
+	controller := New(config, db, runner)
+	controller.SetTracker(sink)
+	require.NoError(t, controller.Run(context.Background(), 0))
+	require.NoError(t, controller.Close())
+
+	require.NotNil(t, sink.config)
+	assert.Equal(t, 2, sink.config.Database.NumIslands)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, sink.steps)
+	for _, metrics := range sink.metrics {
+		if metrics["failed"] == 0 {
+			assert.Equal(t, 0.4, metrics["score"])
+			assert.Equal(t, 0.4, metrics["best_score"])
+		} else {
+			assert.NotContains(t, metrics, "score")
+		}
+	}
+	assert.Contains(t, sink.artifacts, constants.RunManifestFile)
+	assert.Equal(t, db.GetGlobalBest().Code, string(sink.artifacts[constants.BestProgramFile]))
+	assert.True(t, sink.closed)
+}
+
+func TestControllerRelievesMemoryPressure(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 1, 10)
+	config.Controller.Memory = types.MemoryConfig{LimitMB: 100, WarnFraction: 0.8, PruneFraction: 0.5}
+	db := database.New(config.Database, dir)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("program-%d", i),
+			Score:    float64(i),
+			Features: []float64{0.5},
+		}, i))
+	}
+
+	controller := New(config, db, newFakeRunner(db, 1))
+	require.NotNil(t, controller.memory)
+	var levels []int
+	for _, mb := range []uint64{50, 85, 90, 70, 85, 120, 130, 90, 120, 50, 120} {
+		controller.memory.rss = func() (uint64, error) { return mb << 20, nil }
+		_, level, err := controller.memory.check()
+		require.NoError(t, err)
+		levels = append(levels, level)
+	}
+	// Staying over the limit, or above the warning level, relieves nothing
+	// more until use falls below the warning level again
+	assert.Equal(t, []int{
+		memoryOK, memoryWarn, memoryOK, memoryOK, memoryWarn, memoryOver,
+		memoryOK, memoryOK, memoryOK, memoryOK, memoryOver,
+	}, levels)
+
+	// Four programs are evictable; half of them go and a checkpoint is left
+	controller.memory.rss = func() (uint64, error) { return 50 << 20, nil }
+	controller.checkMemory()
+	controller.memory.rss = func() (uint64, error) { return 120 << 20, nil }
+	controller.checkMemory()
+	controller.checkMemory()
+	assert.Equal(t, int64(2), db.GetStats().Evicted)
+	best, exists := db.GetProgram("program-4")
+	require.True(t, exists)
+	assert.Equal(t, 4.0, best.Score)
+	_, err := os.Stat(filepath.Join(dir, "checkpoint_0.json"))
+	assert.NoError(t, err)
+
+	assert.Nil(t, newMemoryWatchdog(types.MemoryConfig{}))
+}
+
+func TestControllerPromotesChampions(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	var hooked []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		hooked = append(hooked, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	target := filepath.Join(dir, "deploy", "champion.py")
+	config := testConfig(dir, 1, 5)
+	minScore := 2.0
+	config.Controller.Promotion = types.PromotionConfig{
+		Enabled:     true,
+		Validations: 2,
+		MinScore:    &minScore,
+		CopyTo:      target,
+		Command:     []string{"sh", "-c", `echo "$OPENEVOLVE_PROGRAM_ID $OPENEVOLVE_SCORE" >> "$0.log"`},
+		Webhook:     server.URL,
+	}
+	db := database.New(config.Database, dir)
+	controller := New(config, db, newFakeRunner(db, 1))
+	require.NotNil(t, controller.promotion)
+	auditPath := filepath.Join(dir, "audit.jsonl")
+	auditor, err := audit.New(types.AuditConfig{Enabled: true, Path: auditPath})
+	require.NoError(t, err)
+	controller.auditor = auditor
+
+	// Re-evaluations score each program by its code; "flaky" fails the second time
+	var evaluations atomic.Int32
+	controller.promotion.evaluate = func(ctx context.Context, code string) (*types.EvaluationResult, error) {
+		n := evaluations.Add(1)
+		scores := map[string]float64{"good": 3, "worse": 2.5, "low": 1, "flaky": 5, "better": 4}
+		return &types.EvaluationResult{Score: scores[code], Success: code != "flaky" || n%2 == 1}, nil
+	}
+
+	ctx := context.Background()
+	assert.True(t, controller.promote(ctx, &champion{ID: "good", Code: "good", Score: 9}))
+	assert.False(t, controller.promote(ctx, &champion{ID: "worse", Code: "worse", Score: 9}))
+	assert.False(t, controller.promote(ctx, &champion{ID: "low", Code: "low", Score: 9}))
+	assert.False(t, controller.promote(ctx, &champion{ID: "flaky", Code: "flaky", Score: 9}))
+	assert.True(t, controller.promote(ctx, &champion{ID: "better", Code: "better", Score: 9}))
+
+	code, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "better", string(code))
+	deployed, err := os.ReadFile(target + ".log")
+	require.NoError(t, err)
+	assert.Equal(t, "good 3\nbetter 4\n", string(deployed))
+	require.Len(t, hooked, 2)
+	assert.Equal(t, "better", hooked[1]["program_id"])
+	assert.Equal(t, 4.0, hooked[1]["score"])
+
+	// Both deployments ran the command and called the webhook on the record
+	require.NoError(t, auditor.Close())
+	data, err := os.ReadFile(auditPath)
+	require.NoError(t, err)
+	kinds := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry audit.Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		kinds[entry.Kind]++
+		if entry.Kind == audit.KindWebhook {
+			assert.Equal(t, http.StatusOK, entry.ExitStatus)
+			assert.Equal(t, server.URL, entry.Args[1])
+		}
+	}
+	assert.Equal(t, map[string]int{audit.KindExec: 2, audit.KindWebhook: 2}, kinds)
+	controller.auditor = nil
+
+	// A run promotes its champion through the database hook, even one
+	// found as the run ends
+	config.Controller.Promotion = types.PromotionConfig{Enabled: true, Webhook: server.URL}
+	db = database.New(config.Database, t.TempDir())
+	runner := newFakeRunner(db, 1)
+	runner.score = 0.9
+	require.NoError(t, New(config, db, runner).Run(ctx, 0))
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, hooked, 3)
+	assert.Equal(t, "island0-iter1", hooked[2]["program_id"])
+
+	assert.Nil(t, newPromoter(types.PromotionConfig{}))
+}
+
+func TestNewFromConfigSeedsDatabaseFromRun(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	evaluatorPath := filepath.Join(dir, "evaluator.go")
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	config := testConfig(dir, 1, 1)
+	config.LLM = types.LLMConfig{
+		APIBase: "http://localhost:1",
+		Models:  []types.LLMModelConfig{{Name: "unused", Weight: 1}},
+	}
+	config.Evaluator = types.EvaluatorConfig{ParallelWorkers: 1, Timeout: 60}
+	config.Database.GridResolution = map[string]int{"complexity": 20}
+	config.Controller.Seed = 7
+
+	// Two runs with the same seed sample the same parents
+	samples := func() []string {
+		controller, err := NewFromConfig(config, evaluatorPath)
+		require.NoError(t, err)
+		defer controller.Close()
+		assert.Equal(t, int64(7), controller.config.Database.RandomSeed)
+
+		db := controller.Database()
+		for i := 0; i < 20; i++ {
+			require.NoError(t, db.AddProgram(&types.Program{
+				ID:       fmt.Sprintf("program-%d", i),
+				Score:    float64(i) / 20,
+				Features: []float64{float64(i) / 20},
+			}, i))
+		}
+		var ids []string
+		for i := 0; i < 10; i++ {
+			program, err := db.SampleFromIsland(0)
+			require.NoError(t, err)
+			ids = append(ids, program.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, samples(), samples())
+}
+
+func TestControllerBudgetsIslands(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig(dir, 2, 40)
+	config.Controller.IslandBudget = types.IslandBudgetConfig{Shares: []float64{3}}
+	db := database.New(config.Database, dir)
+	runner := newFakeRunner(db, 2)
+
+	require.NoError(t, New(config, db, runner).Run(context.Background(), 0))
+
+	// Island 0 gets three iterations for every one of island 1, give or
+	// take the one each may be in debt
+	assert.InDelta(t, 30, runner.perIsland[0], 2)
+	assert.InDelta(t, 10, runner.perIsland[1], 2)
+
+	// Dynamic allocation favors the island that keeps improving
+	budget := newIslandBudget(types.IslandBudgetConfig{Dynamic: true, Window: 3}, 2)
+	for i, score := range []float64{1, 2, 3, 3} {
+		budget.observe(0, &iteration.IterationResult{ChildProgram: &types.Program{Score: score}})
+		budget.observe(1, &iteration.IterationResult{ChildProgram: &types.Program{Score: 1 - float64(i)}})
+	}
+	assert.Equal(t, 3.0, budget.weight(0), "two improvements in the last three iterations")
+	assert.Equal(t, 1.0, budget.weight(1), "its only improvement fell out of the window")
+
+	allowed := func(islandID int) bool {
+		budget.mu.Lock()
+		defer budget.mu.Unlock()
+		return budget.islands[islandID].credit > -1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	starts := map[int]int{}
+	for i := 0; i < 16; i++ {
+		islandID := 0
+		if !allowed(0) {
+			islandID = 1
+		}
+		require.True(t, budget.acquire(ctx, islandID))
+		starts[islandID]++
+	}
+	assert.InDelta(t, 12, starts[0], 1)
+	assert.InDelta(t, 4, starts[1], 1)
+
+	// An island in debt waits until another starts, or the run ends
+	for allowed(1) {
+		require.True(t, budget.acquire(ctx, 1))
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	assert.False(t, budget.acquire(ctx, 1))
+
+	assert.Nil(t, newIslandBudget(types.IslandBudgetConfig{Window: 20}, 2))
+}
+
+func TestIslandBudgetIgnoresRolledBackChildren(t *testing.T) {
+	budget := newIslandBudget(types.IslandBudgetConfig{Dynamic: true, Window: 3}, 1)
+	budget.observe(0, &iteration.IterationResult{ChildProgram: &types.Program{Score: 1}})
+	budget.observe(0, &iteration.IterationResult{ChildProgram: &types.Program{Score: 5}, RolledBack: true})
+	assert.Equal(t, 2.0, budget.weight(0), "the rolled-back child earns no credit")
+
+	// Nor does it raise the best a later child has to beat
+	budget.observe(0, &iteration.IterationResult{ChildProgram: &types.Program{Score: 2}})
+	assert.Equal(t, 3.0, budget.weight(0))
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/database"
+)
+
+// ForkSource links a forked run back to the checkpoint it branched from
+type ForkSource struct {
+	Checkpoint string `json:"checkpoint"`
+	// Manifest of the source run, when one is found near the checkpoint
+	Manifest      string    `json:"manifest,omitempty"`
+	Iteration     int       `json:"iteration"`
+	BestProgramID string    `json:"best_program_id,omitempty"`
+	ForkedAt      time.Time `json:"forked_at"`
+}
+
+// Fork branches a new run from the checkpoint at checkpointPath under
+// config, which may change models, prompts, islands or the grid. Every
+// grid dimension of config must be in the checkpoint or have a feature
+// extractor. The checkpoint's programs are placed afresh on the new grid
+// and islands, and the new run, which starts at iteration 0, records its
+// source in run.json. config must point at a new output directory.
+func Fork(checkpointPath string, config types.Config, evaluatorPath string) (*Controller, error) {
+	c, err := NewFromConfig(config, evaluatorPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.fork(checkpointPath); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// fork seeds the controller's empty database from a checkpoint of another
+// run
+func (c *Controller) fork(checkpointPath string) error {
+	checkpointPath, err := filepath.Abs(checkpointPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve checkpoint path: %w", err)
+	}
+	sourceDir := filepath.Dir(checkpointPath)
+	if sameDir(sourceDir, c.db.CheckpointDir()) {
+		return fmt.Errorf("fork must write its checkpoints outside %s", sourceDir)
+	}
+	manifest := filepath.Join(c.config.Database.OutputDir, constants.RunManifestFile)
+	if _, err := os.Stat(manifest); err == nil {
+		return fmt.Errorf("output directory %s already holds a run", c.config.Database.OutputDir)
+	}
+
+	checkpoint, err := database.ReadCheckpoint(checkpointPath)
+	if err != nil {
+		return err
+	}
+	if err := c.db.CheckDimensions(database.CheckpointDimensions(checkpoint)); err != nil {
+		return fmt.Errorf("checkpoint is incompatible with the new grid: %w", err)
+	}
+
+	// Scores from another toolchain, platform or evaluator are not
+	// comparable with new ones
+	if current := c.db.Environment(); current != nil && checkpoint.Environment != nil && *current != *checkpoint.Environment {
+		c.logger.WithFields(logrus.Fields{
+			"checkpoint": *checkpoint.Environment,
+			"current":    *current,
+		}).Warn("Forking a checkpoint evaluated in a different environment")
+	}
+
+	source := &ForkSource{
+		Checkpoint: checkpointPath,
+		Iteration:  checkpoint.Iteration,
+		ForkedAt:   time.Now(),
+	}
+	if checkpoint.GlobalBest != nil {
+		source.BestProgramID = checkpoint.GlobalBest.ID
+	}
+	// Checkpoints live in the output directory or a directory below it
+	for _, dir := range []string{sourceDir, filepath.Dir(sourceDir)} {
+		sourceManifest := filepath.Join(dir, constants.RunManifestFile)
+		if _, err := os.Stat(sourceManifest); err == nil {
+			source.Manifest = sourceManifest
+			break
+		}
+	}
+
+	if err := c.db.ImportCheckpoint(checkpoint); err != nil {
+		return fmt.Errorf("failed to import checkpoint: %w", err)
+	}
+	c.forkedFrom = source
+
+	c.logger.WithFields(logrus.Fields{
+		"checkpoint": checkpointPath,
+		"iteration":  checkpoint.Iteration,
+		"best":       source.BestProgramID,
+	}).Info("Forked run from checkpoint")
+
+	return nil
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
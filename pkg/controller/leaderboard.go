@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
+	"github.com/ishanwen-byte/openevolve-go/pkg/leaderboard"
+)
+
+// recordLeaderboard files the run's best program on its task's leaderboard
+// when the run ends. Failures are logged; they never stop the run.
+func (c *Controller) recordLeaderboard(iterations int) {
+	config := c.config.Controller.Leaderboard
+	if config.Dir == "" {
+		return
+	}
+	best := c.db.GetGlobalBest()
+	if best == nil {
+		return
+	}
+
+	task := config.Task
+	if task == "" {
+		task = constants.DefaultLeaderboardTask
+	}
+	runID, err := filepath.Abs(c.config.Database.OutputDir)
+	if err != nil {
+		runID = c.config.Database.OutputDir
+	}
+	entry := leaderboard.Entry{
+		RunID:       runID,
+		Task:        task,
+		FinishedAt:  time.Now(),
+		Score:       c.evaluatorScore(best.Score),
+		Minimize:    c.config.Evaluator.ScoreDirection == constants.ScoreDirectionMinimize,
+		ProgramID:   best.ID,
+		ProgramHash: audit.Hash([]byte(best.Code)),
+		Iterations:  iterations,
+		ConfigHash:  c.configHash(),
+		Config:      leaderboard.Summarize(c.config),
+	}
+
+	path := leaderboard.File(config.Dir, task)
+	if err := leaderboard.Record(path, entry); err != nil {
+		c.logger.WithError(err).Warn("Failed to record leaderboard entry")
+		return
+	}
+	c.logger.WithFields(logrus.Fields{
+		"file":  path,
+		"score": entry.Score,
+	}).Info("Recorded run on leaderboard")
+}
+
+// evaluatorTask names a task after the directory holding the evaluator,
+// as each problem keeps its evaluator in a directory of its own
+func evaluatorTask(evaluatorPath string) string {
+	dir, err := filepath.Abs(filepath.Dir(evaluatorPath))
+	if err != nil {
+		return ""
+	}
+	if name := filepath.Base(dir); name != string(filepath.Separator) {
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/database"
+)
+
+// RunManifest describes the outputs of a run so tools can find them
+// without knowing the output layout
+type RunManifest struct {
+	ConfigHash     string     `json:"config_hash"`
+	Seeds          RunSeeds   `json:"seeds"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	StartIteration int        `json:"start_iteration"`
+	Iterations     int        `json:"iterations"`
+	// Checkpoint files, oldest first
+	Checkpoints []string `json:"checkpoints"`
+	// Source of the best program, written when the run finishes
+	BestProgram   string               `json:"best_program,omitempty"`
+	BestProgramID string               `json:"best_program_id,omitempty"`
+	Stats         types.EvolutionStats `json:"stats"`
+	// Checkpoint a forked run branched from
+	ForkedFrom *ForkSource `json:"forked_from,omitempty"`
+}
+
+// RunSeeds are the seeds a run was configured with
+type RunSeeds struct {
+	Controller int   `json:"controller"`
+	LLM        int   `json:"llm"`
+	Database   int64 `json:"database"`
+}
+
+// writeManifest writes run.json to the output directory. It is written when
+// the run starts and rewritten when it finishes, so an interrupted run still
+// leaves one behind. Failures are logged; they never stop the run.
+func (c *Controller) writeManifest(startedAt time.Time, startIteration, iterations int, finished bool) {
+	manifest := RunManifest{
+		ConfigHash:     c.configHash(),
+		Seeds:          RunSeeds{Controller: c.config.Controller.Seed, LLM: c.config.LLM.RandomSeed, Database: c.config.Database.RandomSeed},
+		StartedAt:      startedAt,
+		StartIteration: startIteration,
+		Iterations:     iterations,
+		Checkpoints:    database.CheckpointFiles(c.db.CheckpointDir()),
+		Stats:          c.db.GetStats(),
+		ForkedFrom:     c.forkedFrom,
+	}
+	// JSON cannot encode the -Inf best score of an empty database
+	if math.IsInf(manifest.Stats.BestScore, 0) {
+		manifest.Stats.BestScore = 0
+	}
+
+	if finished {
+		now := time.Now()
+		manifest.FinishedAt = &now
+		if best := c.db.GetGlobalBest(); best != nil {
+			path := c.bestProgramPath()
+			if err := os.WriteFile(path, []byte(best.Code), 0644); err != nil {
+				c.logger.WithError(err).Warn("Failed to write best program")
+			} else {
+				manifest.BestProgram = path
+				manifest.BestProgramID = best.ID
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to marshal run manifest")
+		return
+	}
+	if err := os.MkdirAll(c.config.Database.OutputDir, 0755); err != nil {
+		c.logger.WithError(err).Warn("Failed to create output directory")
+		return
+	}
+	path := filepath.Join(c.config.Database.OutputDir, constants.RunManifestFile)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		c.logger.WithError(err).Warn("Failed to write run manifest")
+	}
+}
+
+// bestProgramPath returns where the best program is written at the end of
+// a run, with the extension of the evolved language
+func (c *Controller) bestProgramPath() string {
+	name := constants.BestProgramFile
+	if c.config.Evaluator.ProgramType == constants.ProgramTypeCommand {
+		name = strings.TrimSuffix(name, constants.GoExt) + c.config.Evaluator.Command.Extension
+	}
+	return filepath.Join(c.config.Database.OutputDir, name)
+}
+
+// configHash identifies the configuration a run used
+func (c *Controller) configHash() string {
+	data, err := json.Marshal(c.config)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Where memory use stands against the watchdog's limit
+const (
+	memoryOK = iota
+	memoryWarn
+	memoryOver
+)
+
+// memoryWatchdog compares the resident memory of the process with the
+// configured limit. A nil watchdog never checks.
+type memoryWatchdog struct {
+	config types.MemoryConfig
+	// rss reads the resident memory in bytes; replaced in tests
+	rss func() (uint64, error)
+	// Whether the warning for the current climb was logged
+	warned bool
+	// Whether memory was relieved for the current crossing
+	relieved bool
+}
+
+// newMemoryWatchdog returns a watchdog, or nil when no limit is set
+func newMemoryWatchdog(config types.MemoryConfig) *memoryWatchdog {
+	if config.LimitMB <= 0 {
+		return nil
+	}
+	return &memoryWatchdog{config: config, rss: residentMemory}
+}
+
+// check reads the resident memory and where it stands. The warning level
+// is reported once per climb past the warning threshold and over the limit
+// once per crossing; both re-arm when use falls below the warning level.
+func (w *memoryWatchdog) check() (uint64, int, error) {
+	rss, err := w.rss()
+	if err != nil {
+		return 0, memoryOK, err
+	}
+
+	warnFraction := w.config.WarnFraction
+	if warnFraction <= 0 {
+		warnFraction = constants.DefaultMemoryWarnFraction
+	}
+	limit := float64(w.config.LimitMB) * (1 << 20)
+	switch {
+	case float64(rss) >= limit:
+		if w.relieved {
+			return rss, memoryOK, nil
+		}
+		w.relieved = true
+		w.warned = true
+		return rss, memoryOver, nil
+	case float64(rss) >= warnFraction*limit:
+		if w.warned {
+			return rss, memoryOK, nil
+		}
+		w.warned = true
+		return rss, memoryWarn, nil
+	default:
+		w.warned = false
+		w.relieved = false
+		return rss, memoryOK, nil
+	}
+}
+
+// interval returns the time between checks
+func (w *memoryWatchdog) interval() time.Duration {
+	seconds := w.config.Interval
+	if seconds <= 0 {
+		seconds = constants.DefaultMemoryInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// watchMemory checks memory use in the background until ctx is done or the
+// returned function is called, which waits for the watchdog to stop
+func (c *Controller) watchMemory(ctx context.Context) func() {
+	if c.memory == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(c.memory.interval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				c.checkMemory()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// checkMemory warns as memory use nears the limit and frees memory once it
+// crosses it
+func (c *Controller) checkMemory() {
+	rss, level, err := c.memory.check()
+	if err != nil {
+		c.logger.WithError(err).Debug("Failed to read memory use")
+		return
+	}
+
+	fields := logrus.Fields{
+		"rss_mb":   rss >> 20,
+		"limit_mb": c.config.Controller.Memory.LimitMB,
+	}
+	switch level {
+	case memoryWarn:
+		c.logger.WithFields(fields).Warn("Memory use is nearing the limit")
+	case memoryOver:
+		c.relieveMemory(fields)
+	}
+}
+
+// relieveMemory prunes the island populations and saves a checkpoint with
+// every island paused, so a run killed for memory loses little, then
+// returns the freed memory to the system
+func (c *Controller) relieveMemory(fields logrus.Fields) {
+	fraction := c.config.Controller.Memory.PruneFraction
+	if fraction <= 0 {
+		fraction = constants.DefaultMemoryPruneFraction
+	}
+
+	c.sync.Lock()
+	defer c.sync.Unlock()
+
+	pruned := c.db.Prune(fraction)
+	n := c.startIteration + int(c.finished.Load())
+	if err := c.db.SaveCheckpoint(n); err != nil {
+		c.logger.WithError(err).WithField("iteration", n).Warn("Failed to save checkpoint")
+	}
+	debug.FreeOSMemory()
+
+	c.logger.WithFields(fields).WithFields(logrus.Fields{
+		"pruned":    pruned,
+		"iteration": n,
+	}).Warn("Memory limit crossed; pruned populations and saved a checkpoint")
+}
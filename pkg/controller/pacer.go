@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pacer spaces iteration starts evenly so a run stays under a fixed number
+// of iterations per minute. A nil pacer never waits.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newPacer returns a pacer for perMinute iterations a minute, or nil when
+// perMinute is not positive
+func newPacer(perMinute float64) *pacer {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &pacer{interval: time.Duration(float64(time.Minute) / perMinute)}
+}
+
+// Wait blocks until the caller may start its iteration or ctx is done.
+// Each call reserves the next free start time, so concurrent callers are
+// spread out rather than released in a burst.
+func (p *pacer) Wait(ctx context.Context) error {
+	if p == nil {
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	start := p.next
+	p.next = p.next.Add(p.interval)
+	p.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"math"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// PhaseChange reports the run entering one of its configured phases
+type PhaseChange struct {
+	// From is empty when the run enters its first phase
+	From      string `json:"from,omitempty"`
+	To        string `json:"to"`
+	Iteration int    `json:"iteration"`
+	// Reason is start or resume, or the condition that ended From:
+	// iterations, stagnation or target_score
+	Reason string `json:"reason"`
+}
+
+// phaseRunner is implemented by runners that apply a phase's overrides
+type phaseRunner interface {
+	SetPhase(phase types.PhaseConfig)
+}
+
+// phaseSchedule moves a run through its configured phases. A nil schedule
+// runs without phases.
+type phaseSchedule struct {
+	phases []types.PhaseConfig
+
+	// Serializes transitions so phases are entered in order
+	mu      sync.Mutex
+	current int
+	// Iterations finished in the current phase, and in a row without a new
+	// global best
+	iterations int
+	stagnant   int
+	best       float64
+}
+
+// newPhaseSchedule returns a schedule, or nil when no phases are configured
+func newPhaseSchedule(phases []types.PhaseConfig) *phaseSchedule {
+	if len(phases) == 0 {
+		return nil
+	}
+	return &phaseSchedule{phases: phases, best: math.Inf(-1)}
+}
+
+// name returns the name of the current phase, or "" without phases
+func (s *phaseSchedule) name() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.phases[s.current].Name
+}
+
+// startPhases enters the run's first phase or, when resuming, the phase its
+// checkpoint recorded
+func (c *Controller) startPhases(iteration int) {
+	s := c.phases
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current, s.iterations, s.stagnant = 0, 0, 0
+	reason := constants.PhaseReasonStart
+	recorded := c.db.GetStats().Phase
+	for i, phase := range s.phases {
+		if phase.Name == recorded {
+			s.current = i
+			reason = constants.PhaseReasonResume
+		}
+	}
+	s.best = math.Inf(-1)
+	if best := c.db.GetGlobalBest(); best != nil && !best.Failed {
+		s.best = best.Score
+	}
+	c.enterPhase(PhaseChange{To: s.phases[s.current].Name, Iteration: iteration, Reason: reason})
+}
+
+// advancePhase counts a finished iteration towards the current phase's
+// transition conditions and moves the run to the next phase once one holds
+func (c *Controller) advancePhase(n int) {
+	s := c.phases
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.iterations++
+	s.stagnant++
+	best := c.db.GetGlobalBest()
+	if best != nil && !best.Failed && best.Score > s.best {
+		s.best = best.Score
+		s.stagnant = 0
+	}
+	if s.current == len(s.phases)-1 {
+		return
+	}
+
+	phase := s.phases[s.current]
+	var reason string
+	switch {
+	case phase.TargetScore != nil && best != nil && !best.Failed && c.meetsScore(best.Score, *phase.TargetScore):
+		reason = constants.PhaseReasonTargetScore
+	case phase.Iterations > 0 && s.iterations >= phase.Iterations:
+		reason = constants.PhaseReasonIterations
+	case phase.Stagnation > 0 && s.stagnant >= phase.Stagnation:
+		reason = constants.PhaseReasonStagnation
+	default:
+		return
+	}
+	s.current++
+	s.iterations, s.stagnant = 0, 0
+	c.enterPhase(PhaseChange{From: phase.Name, To: s.phases[s.current].Name, Iteration: n, Reason: reason})
+}
+
+// enterPhase applies the current phase's overrides to the database and the
+// runner and announces the change, under the schedule's lock
+func (c *Controller) enterPhase(change PhaseChange) {
+	phase := c.phases.phases[c.phases.current]
+	c.db.EnterPhase(phase)
+	if runner, ok := c.runner.(phaseRunner); ok {
+		runner.SetPhase(phase)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"from":      change.From,
+		"to":        change.To,
+		"iteration": change.Iteration,
+		"reason":    change.Reason,
+	}).Info("Entering run phase")
+	if c.OnPhase != nil {
+		c.OnPhase(change)
+	}
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/iteration"
+)
+
+// Progress reports how far a run has come. A run's context can be cancelled
+// from OnProgress to stop it on conditions of the caller's choosing.
+type Progress struct {
+	// Phase is seeding, evolving, checkpoint or finished
+	Phase string `json:"phase"`
+	// RunPhase is the configured run phase the run is in, if any
+	RunPhase string `json:"run_phase,omitempty"`
+	// Iteration that just finished, or the last one when the phase has none
+	Iteration     int `json:"iteration"`
+	Completed     int `json:"completed"`
+	MaxIterations int `json:"max_iterations"`
+	// Best program so far; BestProgramID is empty while there is none
+	BestScore     float64 `json:"best_score"`
+	BestProgramID string  `json:"best_program_id,omitempty"`
+	// Tokens used by LLM calls so far, when the controller owns the LLMs
+	Tokens types.TokenUsage `json:"tokens"`
+	// Time LLM calls spent waiting out rate limits so far
+	Throttled time.Duration `json:"throttled,omitempty"`
+	// Spend per model pool when model routing is enabled
+	Routing map[string]iteration.PoolSpend `json:"routing,omitempty"`
+	Elapsed time.Duration                  `json:"elapsed"`
+}
+
+// routingReporter is implemented by runners that route between model pools
+type routingReporter interface {
+	RoutingSpend() map[string]iteration.PoolSpend
+}
+
+// reportProgress calls OnProgress, if set, with the run's current state
+func (c *Controller) reportProgress(phase string, iteration int) {
+	if c.OnProgress == nil {
+		return
+	}
+
+	progress := Progress{
+		Phase:         phase,
+		Iteration:     iteration,
+		Completed:     int(c.finished.Load()),
+		MaxIterations: c.config.Controller.MaxIterations,
+		RunPhase:      c.phases.name(),
+	}
+	if best := c.db.GetGlobalBest(); best != nil {
+		progress.BestScore = best.Score
+		progress.BestProgramID = best.ID
+	}
+	if c.ensemble != nil {
+		progress.Tokens = c.ensemble.Usage()
+		progress.Throttled = c.ensemble.Throttled()
+	}
+	if reporter, ok := c.runner.(routingReporter); ok {
+		progress.Routing = reporter.RoutingSpend()
+	}
+	if started := c.startedAt.Load(); started != nil {
+		progress.Elapsed = time.Since(*started)
+	}
+
+	c.OnProgress(progress)
+}
@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/atomicfile"
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/subprocess"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
+)
+
+// promoter validates new champions and deploys those that pass. Champions
+// found while one is being validated queue up, but only the latest is
+// kept. A nil promoter never promotes.
+type promoter struct {
+	config types.PromotionConfig
+
+	// evaluate re-runs the evaluator on a champion; set by NewFromConfig
+	evaluate func(ctx context.Context, code string) (*types.EvaluationResult, error)
+
+	mu sync.Mutex
+	// Latest champion waiting for validation
+	next *champion
+	// Signals that next was set
+	ready chan struct{}
+	// Score of the last promoted champion
+	promoted float64
+}
+
+// champion is a copy of a new global best, taken when it was added
+type champion struct {
+	ID         string             `json:"program_id"`
+	Code       string             `json:"code"`
+	Score      float64            `json:"score"`
+	Metrics    map[string]float64 `json:"metrics,omitempty"`
+	Generation int                `json:"generation"`
+	Iteration  int                `json:"iteration"`
+}
+
+// newPromoter returns a promoter, or nil when promotion is disabled
+func newPromoter(config types.PromotionConfig) *promoter {
+	if !config.Enabled {
+		return nil
+	}
+	return &promoter{
+		config:   config,
+		ready:    make(chan struct{}, 1),
+		promoted: math.Inf(-1),
+	}
+}
+
+// offer queues program for validation in place of any champion still
+// waiting
+func (p *promoter) offer(program *types.Program, iteration int) {
+	if program.Failed {
+		return
+	}
+	metrics := make(map[string]float64, len(program.Metrics))
+	for name, value := range program.Metrics {
+		if !math.IsNaN(value) && !math.IsInf(value, 0) {
+			metrics[name] = value
+		}
+	}
+
+	p.mu.Lock()
+	p.next = &champion{
+		ID:         program.ID,
+		Code:       program.Code,
+		Score:      program.Score,
+		Metrics:    metrics,
+		Generation: program.Generation,
+		Iteration:  iteration,
+	}
+	p.mu.Unlock()
+
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the champion waiting for validation, if any
+func (p *promoter) take() *champion {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	next := p.next
+	p.next = nil
+	return next
+}
+
+// timeout returns the time allowed to the command and the webhook
+func (p *promoter) timeout() time.Duration {
+	seconds := p.config.Timeout
+	if seconds <= 0 {
+		seconds = constants.DefaultPromotionTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// runPromotions promotes champions in the background until the returned
+// function is called, which promotes the champion still waiting, if any,
+// and waits for the promoter to stop. Promotions outlive a cancelled run,
+// so the champion that reached the target score is still deployed.
+func (c *Controller) runPromotions(ctx context.Context) func() {
+	if c.promotion == nil {
+		return func() {}
+	}
+
+	promoteCtx := context.WithoutCancel(ctx)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				if next := c.promotion.take(); next != nil {
+					c.promote(promoteCtx, next)
+				}
+				return
+			case <-c.promotion.ready:
+				if next := c.promotion.take(); next != nil {
+					c.promote(promoteCtx, next)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// promote validates a champion and, if it passes, deploys it. It reports
+// whether the champion was promoted; failures are logged.
+func (c *Controller) promote(ctx context.Context, next *champion) bool {
+	fields := logrus.Fields{"program_id": next.ID, "iteration": next.Iteration}
+
+	score, err := c.validateChampion(ctx, next)
+	if err != nil {
+		c.logger.WithFields(fields).WithError(err).Info("Champion failed validation")
+		return false
+	}
+	// Deployments see the score in the evaluator's units
+	deployed := *next
+	deployed.Score = c.evaluatorScore(score)
+	fields["score"] = deployed.Score
+
+	if err := c.deployChampion(ctx, &deployed); err != nil {
+		c.logger.WithFields(fields).WithError(err).Warn("Failed to promote champion")
+		return false
+	}
+
+	c.promotion.mu.Lock()
+	c.promotion.promoted = score
+	c.promotion.mu.Unlock()
+	c.logger.WithFields(fields).Info("Promoted champion")
+	return true
+}
+
+// validateChampion re-evaluates a champion and returns its mean score. It
+// fails unless every evaluation succeeds and the mean reaches the minimum
+// score and beats the last promoted champion.
+func (c *Controller) validateChampion(ctx context.Context, next *champion) (float64, error) {
+	score := next.Score
+	if runs := c.promotion.config.Validations; runs > 0 {
+		if c.promotion.evaluate == nil {
+			return 0, fmt.Errorf("controller has no evaluator to validate with")
+		}
+		total := 0.0
+		for i := 0; i < runs; i++ {
+			result, err := c.promotion.evaluate(ctx, next.Code)
+			if err != nil {
+				return 0, fmt.Errorf("failed to re-evaluate champion: %w", err)
+			}
+			if !result.Success {
+				return 0, fmt.Errorf("re-evaluation %d failed: %s", i+1, result.Error)
+			}
+			total += result.Score
+		}
+		score = total / float64(runs)
+	}
+
+	if min := c.promotion.config.MinScore; min != nil && !c.meetsScore(score, *min) {
+		return 0, fmt.Errorf("score %g does not reach the minimum promotion score %g", c.evaluatorScore(score), *min)
+	}
+	c.promotion.mu.Lock()
+	promoted := c.promotion.promoted
+	c.promotion.mu.Unlock()
+	if score <= promoted {
+		return 0, fmt.Errorf("score %g does not beat the last promoted score %g", c.evaluatorScore(score), c.evaluatorScore(promoted))
+	}
+	return score, nil
+}
+
+// deployChampion copies the champion's code to its target, runs the
+// deployment command and calls the webhook, in that order, stopping at the
+// first failure
+func (c *Controller) deployChampion(ctx context.Context, next *champion) error {
+	config := c.promotion.config
+
+	path := config.CopyTo
+	if path != "" {
+		if err := atomicfile.Write(path, []byte(next.Code)); err != nil {
+			return err
+		}
+	}
+
+	if len(config.Command) > 0 {
+		if path == "" {
+			file, err := os.CreateTemp("", "openevolve-champion-*")
+			if err != nil {
+				return fmt.Errorf("failed to write champion code: %w", err)
+			}
+			defer os.Remove(file.Name())
+			_, err = file.WriteString(next.Code)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write champion code: %w", err)
+			}
+			path = file.Name()
+		}
+
+		cmdCtx, cancel := context.WithTimeout(ctx, c.promotion.timeout())
+		defer cancel()
+		args := append(append([]string(nil), config.Command[1:]...), path)
+		opts := subprocess.Options{
+			Auditor: c.auditor,
+			Env: []string{
+				"OPENEVOLVE_PROGRAM_ID=" + next.ID,
+				"OPENEVOLVE_PROGRAM_PATH=" + path,
+				"OPENEVOLVE_SCORE=" + strconv.FormatFloat(next.Score, 'g', -1, 64),
+				"OPENEVOLVE_ITERATION=" + strconv.Itoa(next.Iteration),
+			},
+		}
+		if output, err := subprocess.Run(cmdCtx, opts, config.Command[0], args...); err != nil {
+			return fmt.Errorf("failed to run promotion command: %w: %s", err, output)
+		}
+	}
+
+	if config.Webhook != "" {
+		hookCtx, cancel := context.WithTimeout(ctx, c.promotion.timeout())
+		defer cancel()
+		if err := postWebhook(hookCtx, config.Webhook, next, c.auditor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postWebhook sends the champion to url as JSON and expects a 2xx reply.
+// The call is recorded in the audit log whatever its outcome.
+func postWebhook(ctx context.Context, url string, next *champion, auditor *audit.Logger) (err error) {
+	data, err := json.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("failed to marshal champion: %w", err)
+	}
+
+	var body []byte
+	var statusCode int
+	start := time.Now()
+	defer func() {
+		entry := audit.Entry{
+			Kind:       audit.KindWebhook,
+			Name:       next.ID,
+			Args:       []string{http.MethodPost, url},
+			InputHash:  audit.Hash(data),
+			Duration:   time.Since(start),
+			ExitStatus: statusCode,
+		}
+		if body != nil {
+			entry.OutputHash = audit.Hash(body)
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		auditor.Record(entry)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call promotion webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
+	body, _ = io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("promotion webhook returned status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// meetsScore reports whether score, in the database's units, reaches
+// threshold, which is in the evaluator's units
+func (c *Controller) meetsScore(score, threshold float64) bool {
+	if c.config.Evaluator.ScoreDirection == constants.ScoreDirectionMinimize {
+		return c.evaluatorScore(score) <= threshold
+	}
+	return score >= threshold
+}
+
+// evaluatorScore converts a score in the database's units, where higher is
+// always better, back to the evaluator's
+func (c *Controller) evaluatorScore(score float64) float64 {
+	if c.config.Evaluator.ScoreDirection == constants.ScoreDirectionMinimize {
+		return -score
+	}
+	return score
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package controller
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// residentMemory returns the resident set size of the process in bytes
+func residentMemory() (uint64, error) {
+	statm, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read memory statistics: %w", err)
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("unexpected memory statistics: %q", statm)
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse resident pages: %w", err)
+	}
+	return pages * uint64(os.Getpagesize()), nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package controller
+
+import "runtime"
+
+// residentMemory approximates the resident set size by the memory the Go
+// runtime has obtained from the system, where it cannot be read directly
+func residentMemory() (uint64, error) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.Sys, nil
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
+	"github.com/sirupsen/logrus"
+)
+
+// targetCheck re-checks a champion that reaches the target score before the
+// run stops. A nil check stops the run as soon as the target is reached.
+type targetCheck struct {
+	config types.TargetCheckConfig
+
+	// Re-evaluate the champion, and evaluate it on the hold-out evaluator;
+	// set when the controller is built from config
+	evaluate func(ctx context.Context, code string) (*types.EvaluationResult, error)
+	holdout  func(ctx context.Context, code string) (*types.EvaluationResult, error)
+
+	// Serializes checks; champions already checked are not checked again
+	mu        sync.Mutex
+	confirmed string
+	rejected  string
+}
+
+// newTargetCheck returns a check, or nil when the target is not re-checked
+func newTargetCheck(config types.TargetCheckConfig) *targetCheck {
+	if config.Evaluations <= 0 && config.HoldoutEvaluator == "" {
+		return nil
+	}
+	return &targetCheck{config: config}
+}
+
+// targetConfirmed reports whether the champion that reached the target
+// score still reaches it when re-checked
+func (c *Controller) targetConfirmed(ctx context.Context, best *types.Program) bool {
+	check := c.targetCheck
+	if check == nil {
+		return true
+	}
+	check.mu.Lock()
+	defer check.mu.Unlock()
+
+	switch best.ID {
+	case check.confirmed:
+		return true
+	case check.rejected:
+		return false
+	}
+
+	fields := logrus.Fields{
+		"program_id": best.ID,
+		"score":      c.evaluatorScore(best.Score),
+	}
+	if err := c.recheckChampion(ctx, best.Code); err != nil {
+		if ctx.Err() == nil {
+			check.rejected = best.ID
+			c.logger.WithFields(fields).WithError(err).Warn("Champion did not hold the target score")
+		}
+		return false
+	}
+	check.confirmed = best.ID
+	c.logger.WithFields(fields).Info("Champion held the target score")
+	return true
+}
+
+// recheckChampion re-evaluates a champion and evaluates it on the hold-out
+// evaluator, failing unless every evaluation succeeds, the mean of the
+// re-evaluations reaches the target and so does the hold-out score
+func (c *Controller) recheckChampion(ctx context.Context, code string) error {
+	check := c.targetCheck
+	target := *c.config.Controller.TargetScore
+
+	if runs := check.config.Evaluations; runs > 0 {
+		if check.evaluate == nil {
+			return fmt.Errorf("controller has no evaluator to re-check with")
+		}
+		total := 0.0
+		for i := 0; i < runs; i++ {
+			result, err := check.evaluate(ctx, code)
+			if err != nil {
+				return fmt.Errorf("failed to re-evaluate champion: %w", err)
+			}
+			if !result.Success {
+				return fmt.Errorf("re-evaluation %d failed: %s", i+1, result.Error)
+			}
+			total += result.Score
+		}
+		if mean := total / float64(runs); !c.meetsScore(mean, target) {
+			return fmt.Errorf("mean re-evaluated score %g does not reach the target %g", c.evaluatorScore(mean), target)
+		}
+	}
+
+	if check.config.HoldoutEvaluator != "" {
+		if check.holdout == nil {
+			return fmt.Errorf("controller has no hold-out evaluator")
+		}
+		result, err := check.holdout(ctx, code)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate champion on the hold-out evaluator: %w", err)
+		}
+		if !result.Success {
+			return fmt.Errorf("hold-out evaluation failed: %s", result.Error)
+		}
+		if !c.meetsScore(result.Score, target) {
+			return fmt.Errorf("hold-out score %g does not reach the target %g", c.evaluatorScore(result.Score), target)
+		}
+	}
+	return nil
+}
+
+// newHoldoutEvaluator creates the hold-out evaluator of a target check, or
+// returns nil when none is configured
+func newHoldoutEvaluator(config types.Config) (*evaluator.Evaluator, error) {
+	path := config.Controller.TargetCheck.HoldoutEvaluator
+	if path == "" {
+		return nil, nil
+	}
+	holdout, err := evaluator.New(config.Evaluator, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hold-out evaluator: %w", err)
+	}
+	return holdout, nil
+}
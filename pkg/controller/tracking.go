@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/pkg/iteration"
+	"github.com/ishanwen-byte/openevolve-go/pkg/tracking"
+)
+
+// SetTracker sets the experiment tracker the run reports its configuration,
+// iteration metrics and final artifacts to. Close finishes the tracker's run.
+func (c *Controller) SetTracker(sink tracking.Sink) {
+	c.tracker = sink
+}
+
+// trackConfig reports the run's configuration. Tracking failures are
+// logged; they never stop the run.
+func (c *Controller) trackConfig(ctx context.Context) {
+	if c.tracker == nil {
+		return
+	}
+	if err := c.tracker.LogConfig(ctx, c.config); err != nil {
+		c.logger.WithError(err).Warn("Failed to report config to experiment tracker")
+	}
+}
+
+// trackIteration reports the metrics of a finished iteration; result is
+// nil when the iteration failed
+func (c *Controller) trackIteration(ctx context.Context, n int, result *iteration.IterationResult) {
+	if c.tracker == nil {
+		return
+	}
+
+	stats := c.db.GetStats()
+	metrics := map[string]float64{
+		"completed": float64(c.finished.Load()),
+		"failed":    1,
+	}
+	var failures int64
+	for _, count := range stats.Failures {
+		failures += count
+	}
+	metrics["failures"] = float64(failures)
+	if result != nil {
+		metrics["failed"] = 0
+		if result.ChildProgram != nil {
+			metrics["score"] = result.ChildProgram.Score
+		}
+	}
+	if best := c.db.GetGlobalBest(); best != nil {
+		metrics["best_score"] = best.Score
+	}
+	if c.ensemble != nil {
+		metrics["total_tokens"] = float64(c.ensemble.Usage().TotalTokens)
+	}
+
+	if err := c.tracker.LogMetrics(ctx, n, metrics); err != nil {
+		c.logger.WithError(err).WithField("iteration", n).Warn("Failed to report metrics to experiment tracker")
+	}
+}
+
+// trackArtifacts uploads the run manifest, best program and changelog left
+// in the output directory at the end of the run
+func (c *Controller) trackArtifacts(ctx context.Context) {
+	if c.tracker == nil {
+		return
+	}
+
+	paths := []string{
+		filepath.Join(c.config.Database.OutputDir, constants.RunManifestFile),
+		c.bestProgramPath(),
+		c.finalChangelogPath(),
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := c.tracker.LogArtifact(ctx, filepath.Base(path), data); err != nil {
+			c.logger.WithError(err).WithField("file", path).Warn("Failed to upload artifact to experiment tracker")
+		}
+	}
+}
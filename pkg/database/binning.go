@@ -0,0 +1,73 @@
+package database
+
+import (
+	"math"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+)
+
+// logBinGrowth is how much wider each log-scale bin is than the one below it
+const logBinGrowth = 2.0
+
+// binning returns how a grid dimension is split into bins. Adaptive binning
+// makes quantile the default for dimensions without a strategy of their own.
+func (i *Island) binning(dim string) string {
+	if strategy := i.gridScaling[dim]; strategy != "" {
+		return strategy
+	}
+	if i.adaptiveBinning {
+		return constants.GridScalingQuantile
+	}
+	return constants.GridScalingLinear
+}
+
+// quantileBinning reports whether any dimension's bins are re-fitted to the
+// population's quantiles
+func (i *Island) quantileBinning() bool {
+	if len(i.Grid.Centroids) > 0 {
+		return false
+	}
+	for _, dim := range i.Grid.Dimensions {
+		if i.binning(dim) == constants.GridScalingQuantile {
+			return true
+		}
+	}
+	return false
+}
+
+// setLogEdges lays out the bins of log-scale dimensions over their bounds
+func (i *Island) setLogEdges() {
+	if len(i.Grid.Centroids) > 0 {
+		return
+	}
+	for _, dim := range i.Grid.Dimensions {
+		if i.binning(dim) != constants.GridScalingLog {
+			continue
+		}
+		if i.Grid.Edges == nil {
+			i.Grid.Edges = make(map[string][]float64)
+		}
+		resolution, ok := i.Grid.Resolution[dim]
+		if !ok {
+			resolution = 10 // Default resolution
+		}
+		bounds, ok := i.Grid.Bounds[dim]
+		if !ok {
+			bounds = [2]float64{0.0, 1.0}
+		}
+		i.Grid.Edges[dim] = logEdges(bounds, resolution)
+	}
+}
+
+// logEdges returns the resolution-1 interior edges of bins over bounds that
+// grow geometrically from the lower bound, so small values are told apart
+// finely while the long tail of a skewed feature shares a few wide bins
+func logEdges(bounds [2]float64, resolution int) []float64 {
+	edges := make([]float64, 0, resolution)
+	total := math.Pow(logBinGrowth, float64(resolution)) - 1
+	for b := 1; b < resolution; b++ {
+		fraction := (math.Pow(logBinGrowth, float64(b)) - 1) / total
+		edges = append(edges, bounds[0]+fraction*(bounds[1]-bounds[0]))
+	}
+	return edges
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// RecomputeBounds sets the grid bounds of every dimension to the range its
+// feature statistics have observed and remaps the population into the new
+// grid. It returns how many elites lost their cell in the remap, and false
+// when no dimension has observed a range to use.
+func (i *Island) RecomputeBounds() (int, bool) {
+	bounds := make(map[string][2]float64, len(i.Grid.Dimensions))
+	for dim, dimBounds := range i.Grid.Bounds {
+		bounds[dim] = dimBounds
+	}
+
+	changed := false
+	for _, dim := range i.Grid.Dimensions {
+		stats := i.FeatureStats[dim]
+		if stats.Count < 2 || stats.Max <= stats.Min {
+			continue
+		}
+		if current, ok := bounds[dim]; ok && current == [2]float64{stats.Min, stats.Max} {
+			continue
+		}
+		bounds[dim] = [2]float64{stats.Min, stats.Max}
+		changed = true
+	}
+	if !changed {
+		return 0, false
+	}
+
+	elites := make([]*types.Program, 0, len(i.Grid.Cells))
+	for _, elite := range i.Grid.Cells {
+		elites = append(elites, elite)
+	}
+
+	// The old map may be shared with other islands and the configuration
+	i.Grid.Bounds = bounds
+	i.setLogEdges()
+	i.rebin()
+
+	displaced := 0
+	for _, elite := range elites {
+		if i.Grid.Cells[i.calculateCellKey(elite.Features)] != elite {
+			displaced++
+		}
+	}
+	return displaced, true
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/atomicfile"
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// ChampionSnapshot is written to the best directory whenever a new global
+// best appears, so the best program survives a crash between checkpoints
+type ChampionSnapshot struct {
+	ProgramID string             `json:"program_id"`
+	Score     float64            `json:"score"`
+	Metrics   map[string]float64 `json:"metrics,omitempty"`
+	Iteration int                `json:"iteration"`
+	IslandID  int                `json:"island_id"`
+	Code      string             `json:"code"`
+	// Ancestors from the seed down to the champion, without their code
+	Lineage []LineageStep `json:"lineage"`
+	SavedAt time.Time     `json:"saved_at"`
+}
+
+// LineageStep describes one ancestor of a champion
+type LineageStep struct {
+	ID         string  `json:"id"`
+	ParentID   string  `json:"parent_id,omitempty"`
+	Generation int     `json:"generation"`
+	Score      float64 `json:"score"`
+	Changes    string  `json:"changes,omitempty"`
+}
+
+// ChampionPath returns where champion snapshots are written, or "" when the
+// database has no output directory
+func (db *ProgramDatabase) ChampionPath() string {
+	if db.config.OutputDir == "" {
+		return ""
+	}
+	return filepath.Join(db.config.OutputDir, constants.BestDir, constants.ChampionFile)
+}
+
+// pendingChampion is a snapshot of a new champion taken under the lock of
+// its island, written once the locks are released
+type pendingChampion struct {
+	champion *types.Program
+	snapshot ChampionSnapshot
+}
+
+// takeChampion snapshots a new champion for saveChampion, or returns nil
+// when the database has no output directory. The caller holds the lock of
+// the champion's island.
+func (db *ProgramDatabase) takeChampion(champion *types.Program, iteration int) *pendingChampion {
+	if db.ChampionPath() == "" {
+		return nil
+	}
+	return &pendingChampion{champion: champion, snapshot: db.championSnapshot(champion, iteration)}
+}
+
+// saveChampion writes a snapshot taken by takeChampion, unless another
+// program has since taken the champion's place. The caller holds no island
+// lock. Failures are logged, never returned.
+func (db *ProgramDatabase) saveChampion(pending *pendingChampion) {
+	if pending == nil {
+		return
+	}
+
+	db.bestMu.Lock()
+	defer db.bestMu.Unlock()
+	if db.globalBest.Load() != pending.champion {
+		return
+	}
+	if err := writeChampion(db.ChampionPath(), pending.snapshot); err != nil {
+		db.logger.WithError(err).Warn("Failed to save champion snapshot")
+	}
+}
+
+// championSnapshot describes a champion and its lineage
+func (db *ProgramDatabase) championSnapshot(best *types.Program, iteration int) ChampionSnapshot {
+	snapshot := ChampionSnapshot{
+		ProgramID: best.ID,
+		Score:     best.Score,
+		Metrics:   best.Metrics,
+		Iteration: iteration,
+		IslandID:  best.IslandID,
+		Code:      best.Code,
+		SavedAt:   time.Now(),
+	}
+	db.index.RLock()
+	lineage := db.lineage(best.ID)
+	db.index.RUnlock()
+	for _, program := range lineage {
+		snapshot.Lineage = append(snapshot.Lineage, LineageStep{
+			ID:         program.ID,
+			ParentID:   program.ParentID,
+			Generation: program.Generation,
+			Score:      program.Score,
+			Changes:    program.Changes,
+		})
+	}
+	return snapshot
+}
+
+// writeChampion replaces the snapshot at path atomically, so a crash while
+// writing leaves the previous champion in place
+func writeChampion(path string, snapshot ChampionSnapshot) error {
+	// JSON cannot encode non-finite metrics
+	metrics := make(map[string]float64, len(snapshot.Metrics))
+	for name, value := range snapshot.Metrics {
+		if !math.IsNaN(value) && !math.IsInf(value, 0) {
+			metrics[name] = value
+		}
+	}
+	snapshot.Metrics = metrics
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal champion snapshot: %w", err)
+	}
+	return atomicfile.Write(path, data)
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// SaveCheckpointAsync snapshots the database and writes the checkpoint in
+// the background, so callers only wait for the copy. Checkpoints are
+// written in the order they were requested. done, if not nil, is called
+// with the outcome once the write finishes.
+func (db *ProgramDatabase) SaveCheckpointAsync(iteration int, done func(error)) {
+	if db.checkpointDir == "" {
+		if done != nil {
+			done(nil)
+		}
+		return
+	}
+
+	db.queueCheckpoint(db.snapshot(iteration), func(err error) {
+		if err != nil {
+			db.writeMu.Lock()
+			if db.checkpointErr == nil {
+				db.checkpointErr = err
+			}
+			db.writeMu.Unlock()
+		}
+		if done != nil {
+			done(err)
+		}
+	})
+}
+
+// queueCheckpoint writes checkpoint in the background once every write
+// queued before it has finished, then reports the outcome to done
+func (db *ProgramDatabase) queueCheckpoint(checkpoint *types.Checkpoint, done func(error)) {
+	db.writeMu.Lock()
+	previous := db.written
+	written := make(chan struct{})
+	db.written = written
+	db.writeMu.Unlock()
+
+	go func() {
+		defer close(written)
+		if previous != nil {
+			<-previous
+		}
+		done(db.writeCheckpoint(checkpoint))
+	}()
+}
+
+// WaitCheckpoints blocks until every background checkpoint write has
+// finished and returns the first error among them since the last wait
+func (db *ProgramDatabase) WaitCheckpoints() error {
+	db.writeMu.Lock()
+	written := db.written
+	db.writeMu.Unlock()
+
+	if written != nil {
+		<-written
+	}
+
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
+	err := db.checkpointErr
+	db.checkpointErr = nil
+	return err
+}
+
+// cloneProgram copies a program and the slices and maps it owns. clones
+// maps originals to their copies, so a program referenced from several
+// places is copied once.
+func cloneProgram(program *types.Program, clones map[*types.Program]*types.Program) *types.Program {
+	if program == nil {
+		return nil
+	}
+	if clone, exists := clones[program]; exists {
+		return clone
+	}
+
+	clone := *program
+	clone.Features = append([]float64(nil), program.Features...)
+	clone.Behavior = append([]float64(nil), program.Behavior...)
+	clone.InspirationIDs = append([]string(nil), program.InspirationIDs...)
+	if program.Metrics != nil {
+		clone.Metrics = make(map[string]float64, len(program.Metrics))
+		for name, value := range program.Metrics {
+			clone.Metrics[name] = value
+		}
+	}
+	if program.Artifacts != nil {
+		clone.Artifacts = make(map[string]string, len(program.Artifacts))
+		for key, value := range program.Artifacts {
+			clone.Artifacts[key] = value
+		}
+	}
+	clone.Tags = append([]string(nil), program.Tags...)
+	if program.Metadata != nil {
+		clone.Metadata = copyMetadata(program.Metadata).(map[string]any)
+	}
+
+	clones[program] = &clone
+	return &clone
+}
+
+// copyProgram returns a copy of a stored program that callers may keep and
+// modify. The caller holds the lock of the program's island.
+func copyProgram(program *types.Program) *types.Program {
+	return cloneProgram(program, make(map[*types.Program]*types.Program, 1))
+}
+
+// copyPrograms copies each of programs as copyProgram does
+func copyPrograms(programs []*types.Program) []*types.Program {
+	copies := make([]*types.Program, len(programs))
+	for i, program := range programs {
+		copies[i] = copyProgram(program)
+	}
+	return copies
+}
+
+// copyStored copies a stored program under its island's read lock. The
+// caller holds the database read lock and no island lock.
+func (db *ProgramDatabase) copyStored(program *types.Program) *types.Program {
+	if program == nil {
+		return nil
+	}
+	if program.IslandID >= 0 && program.IslandID < len(db.islands) {
+		island := db.islands[program.IslandID]
+		island.mu.RLock()
+		defer island.mu.RUnlock()
+	}
+	return copyProgram(program)
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Grid dimensions derived from a program's code rather than reported by
+// its evaluation
+const (
+	FeatureLinesOfCode          = "lines_of_code"
+	FeatureASTNodes             = "ast_nodes"
+	FeatureCyclomaticComplexity = "cyclomatic_complexity"
+	FeatureFunctions            = "functions"
+)
+
+// codeFeatureExtractors measure the code itself. Code that does not parse
+// as Go is measured from its tokens instead.
+func codeFeatureExtractors() map[string]FeatureExtractor {
+	return map[string]FeatureExtractor{
+		FeatureLinesOfCode: func(program *types.Program) float64 {
+			return float64(linesOfCode(program.Code))
+		},
+		FeatureASTNodes: func(program *types.Program) float64 {
+			return float64(measureCode(program.Code).nodes)
+		},
+		FeatureCyclomaticComplexity: func(program *types.Program) float64 {
+			return float64(measureCode(program.Code).complexity)
+		},
+		FeatureFunctions: func(program *types.Program) float64 {
+			return float64(measureCode(program.Code).functions)
+		},
+	}
+}
+
+// isCodeFeature reports whether dimension is derived from code
+func isCodeFeature(dimension string) bool {
+	switch dimension {
+	case FeatureLinesOfCode, FeatureASTNodes, FeatureCyclomaticComplexity, FeatureFunctions:
+		return true
+	}
+	return false
+}
+
+// GridFeatures fits the features an evaluation reported to the grid.
+// Dimensions the evaluator reported a named value for take that value and
+// dimensions derived from code are measured from code; the other dimensions
+// take the positional features in order, and any left over are dropped.
+// Without named values or code dimensions the features are returned
+// unchanged.
+func (db *ProgramDatabase) GridFeatures(code string, named map[string]float64, features []float64) []float64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	derived := false
+	for _, dimension := range db.config.GridDimensions {
+		_, reported := named[dimension]
+		derived = derived || reported || isCodeFeature(dimension)
+	}
+	if !derived {
+		return features
+	}
+
+	// Dimensions past the reported features are left for completeFeatures
+	program := &types.Program{Code: code}
+	grid := make([]float64, 0, len(db.config.GridDimensions))
+	for _, dimension := range db.config.GridDimensions {
+		if value, ok := named[dimension]; ok {
+			grid = append(grid, value)
+			continue
+		}
+		if isCodeFeature(dimension) {
+			grid = append(grid, db.extractors[dimension](program))
+			continue
+		}
+		if len(features) == 0 {
+			break
+		}
+		grid = append(grid, features[0])
+		features = features[1:]
+	}
+	return grid
+}
+
+// linesOfCode counts the lines that are neither blank nor only a comment
+func linesOfCode(code string) int {
+	lines := 0
+	for _, line := range strings.Split(code, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "#") {
+			lines++
+		}
+	}
+	return lines
+}
+
+// codeMeasures are the structural measures of a program
+type codeMeasures struct {
+	nodes int
+	// Sum over functions of one plus their decision points, and at least 1
+	complexity int
+	// Declared functions and methods plus function literals
+	functions int
+}
+
+// measureCode walks the syntax tree of Go code, or scans the tokens of
+// code that does not parse
+func measureCode(code string) codeMeasures {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	if err != nil {
+		return scanCode(code)
+	}
+
+	var measures codeMeasures
+	decisions := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+		measures.nodes++
+		switch node := node.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			measures.functions++
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			decisions++
+		case *ast.CaseClause:
+			if node.List != nil {
+				decisions++
+			}
+		case *ast.CommClause:
+			if node.Comm != nil {
+				decisions++
+			}
+		case *ast.BinaryExpr:
+			if node.Op == token.LAND || node.Op == token.LOR {
+				decisions++
+			}
+		}
+		return true
+	})
+	measures.complexity = max(measures.functions+decisions, 1)
+	return measures
+}
+
+// scanCode estimates the measures of code from its tokens: every token
+// stands for a node and every func keyword for a function
+func scanCode(code string) codeMeasures {
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	src := []byte(code)
+	s.Init(fset.AddFile("", fset.Base(), len(src)), src, func(token.Position, string) {}, 0)
+
+	var measures codeMeasures
+	decisions := 0
+	for {
+		_, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON {
+			continue
+		}
+		measures.nodes++
+		switch tok {
+		case token.FUNC:
+			measures.functions++
+		case token.IF, token.FOR, token.CASE, token.LAND, token.LOR:
+			decisions++
+		}
+	}
+	measures.complexity = max(measures.functions+decisions, 1)
+	return measures
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// ColdRecord is one line of cold storage: a program that left the archive
+// and why
+type ColdRecord struct {
+	Reason     string         `json:"reason"`
+	IslandID   int            `json:"island_id"`
+	Generation int            `json:"generation"`
+	Time       time.Time      `json:"time"`
+	Program    *types.Program `json:"program"`
+}
+
+// ColdStoragePath returns where programs leaving the archive are appended,
+// or "" when cold storage is disabled or has nowhere to go
+func (db *ProgramDatabase) ColdStoragePath() string {
+	if !db.config.ColdStorage {
+		return ""
+	}
+	if db.config.ColdStoragePath != "" {
+		return db.config.ColdStoragePath
+	}
+	if db.config.OutputDir == "" {
+		return ""
+	}
+	return filepath.Join(db.config.OutputDir, constants.ColdStorageFile)
+}
+
+// archiveCold appends a program leaving an island to cold storage. It runs
+// under the lock of the island; failures are logged, never returned.
+func (db *ProgramDatabase) archiveCold(program *types.Program, island *Island, reason string) {
+	path := db.ColdStoragePath()
+	if path == "" {
+		return
+	}
+	record := ColdRecord{
+		Reason:     reason,
+		IslandID:   island.ID,
+		Generation: island.Generation,
+		Time:       time.Now(),
+		Program:    program,
+	}
+	db.coldMu.Lock()
+	defer db.coldMu.Unlock()
+	if err := appendColdRecord(path, record); err != nil {
+		db.logger.WithError(err).WithField("program", program.ID).Warn("Failed to archive program to cold storage")
+	}
+}
+
+// appendColdRecord writes a record to the end of a cold storage file
+func appendColdRecord(path string, record ColdRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cold storage record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cold storage directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open cold storage: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write cold storage: %w", err)
+	}
+	return file.Close()
+}
+
+// LoadColdStorage reads every record of a cold storage file, oldest first
+func LoadColdStorage(path string) ([]ColdRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cold storage: %w", err)
+	}
+	defer file.Close()
+
+	var records []ColdRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var record ColdRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse cold storage line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cold storage: %w", err)
+	}
+	return records, nil
+}
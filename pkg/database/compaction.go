@@ -0,0 +1,97 @@
+package database
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Compact removes every program whose grid cell elite leads it by more
+// than the compaction threshold, then rebuilds the program maps so the
+// memory they held is released. Removed programs go to cold storage if it
+// is enabled; cell elites, the island and global bests and the global
+// best's ancestors always stay.
+// It returns how many programs it removed.
+func (db *ProgramDatabase) Compact() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	threshold := db.config.Compaction.Threshold
+	keep := db.championLineage()
+
+	removed := 0
+	for _, island := range db.islands {
+		dominated := island.dominatedPrograms(threshold, keep)
+		for _, program := range dominated {
+			island.remove(program)
+			delete(db.programs, program.ID)
+			db.archiveCold(program, island, constants.ColdReasonCompacted)
+		}
+		if len(dominated) > 0 {
+			island.Programs = copyProgramMap(island.Programs)
+		}
+		removed += len(dominated)
+	}
+	if removed == 0 {
+		return 0
+	}
+	db.programs = copyProgramMap(db.programs)
+
+	db.stats.Compacted += int64(removed)
+	db.logger.WithFields(logrus.Fields{
+		"removed":  removed,
+		"programs": len(db.programs),
+	}).Info("Compacted program database")
+	return removed
+}
+
+// compactIfOversized compacts the database once it holds more programs
+// than the configured limit, unless a compaction is already running
+func (db *ProgramDatabase) compactIfOversized() {
+	limit := db.config.Compaction.MaxPrograms
+	if limit <= 0 {
+		return
+	}
+	db.index.RLock()
+	total := len(db.programs)
+	db.index.RUnlock()
+	if total <= limit || !db.compacting.CompareAndSwap(false, true) {
+		return
+	}
+	defer db.compacting.Store(false)
+	db.Compact()
+}
+
+// dominatedPrograms returns the programs of an island whose cell elite is
+// fitter by more than threshold. Elites, the island best and the programs
+// in keep are never returned.
+func (i *Island) dominatedPrograms(threshold float64, keep map[string]bool) []*types.Program {
+	if len(i.Grid.Dimensions) == 0 {
+		return nil
+	}
+	var dominated []*types.Program
+	for _, program := range sortedPrograms(i.Programs) {
+		if keep[program.ID] || program.ID == i.BestID || program.ID == i.championID() {
+			continue
+		}
+		elite, exists := i.Grid.Cells[i.calculateCellKey(program.Features)]
+		if !exists || elite == program {
+			continue
+		}
+		if i.cellFitness(elite) > i.cellFitness(program)+threshold {
+			dominated = append(dominated, program)
+		}
+	}
+	return dominated
+}
+
+// copyProgramMap returns a copy of programs sized to its contents, as Go
+// maps never shrink after deletes
+func copyProgramMap(programs map[string]*types.Program) map[string]*types.Program {
+	copied := make(map[string]*types.Program, len(programs))
+	for id, program := range programs {
+		copied[id] = program
+	}
+	return copied
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"math/rand"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// cvtSeed fixes the random points of a CVT archive, so every island and
+// every resumed run gets the same cells
+const cvtSeed = 1
+
+// cvtCentroids returns the cell centroids of a CVT archive in the unit
+// hypercube, found by Lloyd's k-means over uniformly random points, or nil
+// when config uses the grid archive
+func cvtCentroids(config types.DatabaseConfig) [][]float64 {
+	if config.Archive != constants.ArchiveCVT || len(config.GridDimensions) == 0 {
+		return nil
+	}
+
+	cells := config.CVT.Cells
+	if cells <= 0 {
+		cells = constants.DefaultCVTCells
+	}
+	samples := config.CVT.Samples
+	if samples <= 0 {
+		samples = constants.DefaultCVTSamples
+	}
+	if samples < cells {
+		samples = cells
+	}
+	iterations := config.CVT.Iterations
+	if iterations <= 0 {
+		iterations = constants.DefaultCVTIterations
+	}
+
+	rng := rand.New(rand.NewSource(cvtSeed))
+	points := make([][]float64, samples)
+	for idx := range points {
+		points[idx] = make([]float64, len(config.GridDimensions))
+		for dim := range points[idx] {
+			points[idx][dim] = rng.Float64()
+		}
+	}
+
+	// Start from the first points, which are as random as any
+	centroids := make([][]float64, cells)
+	for idx := range centroids {
+		centroids[idx] = append([]float64(nil), points[idx]...)
+	}
+
+	for iteration := 0; iteration < iterations; iteration++ {
+		sums := make([][]float64, cells)
+		counts := make([]int, cells)
+		for _, point := range points {
+			cell := nearestCentroid(centroids, point)
+			if sums[cell] == nil {
+				sums[cell] = make([]float64, len(point))
+			}
+			for dim, value := range point {
+				sums[cell][dim] += value
+			}
+			counts[cell]++
+		}
+
+		// A centroid that attracted no points stays where it is
+		for cell, sum := range sums {
+			if counts[cell] == 0 {
+				continue
+			}
+			for dim := range sum {
+				centroids[cell][dim] = sum[dim] / float64(counts[cell])
+			}
+		}
+	}
+
+	return centroids
+}
+
+// nearestCentroid returns the index of the centroid closest to point
+func nearestCentroid(centroids [][]float64, point []float64) int {
+	nearest, best := 0, -1.0
+	for idx, centroid := range centroids {
+		distance := 0.0
+		for dim, value := range point {
+			delta := value - centroid[dim]
+			distance += delta * delta
+		}
+		if best < 0 || distance < best {
+			nearest, best = idx, distance
+		}
+	}
+	return nearest
+}
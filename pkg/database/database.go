@@ -1,24 +1,34 @@
 package database
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
 )
 
 // ProgramDatabase implements the main database for OpenEvolve
 // It combines MAP-Elites algorithm with island-based evolution
+//
+// Work on a single island, such as adding or sampling a program, holds mu
+// for reading and that island's own lock, so islands proceed in parallel.
+// Work spanning islands, such as migration or loading a checkpoint, holds
+// mu for writing. State shared by the islands is guarded by mu together
+// with its own lock: index, stats, coldMu, or bestMu for the global best,
+// which is read without any lock. Island locks are taken in ID order and
+// before any of those.
 type ProgramDatabase struct {
 	// Configuration
 	config types.DatabaseConfig
@@ -28,25 +38,71 @@ type ProgramDatabase struct {
 
 	// All programs indexed by ID
 	programs map[string]*types.Program
+	index    sync.RWMutex
 
 	// Islands for parallel evolution
 	islands []*Island
 
-	// Global best program
-	globalBest *types.Program
-	globalBestScore float64
+	// Global best program; updates hold bestMu
+	globalBest atomic.Pointer[types.Program]
+	bestMu     sync.Mutex
 
-	// Evolution state
-	currentIsland int
+	// Extractors that fill in grid dimensions missing from a program's features
+	extractors map[string]FeatureExtractor
+
+	// Evolution state; programs without an island go to the island in
+	// turn, the number of programs added modulo the number of islands
+	turns         atomic.Int64
 	lastIteration int
 	lastMigrationGeneration int
 
+	// Every migration so far, oldest first
+	migrations []types.MigrationEvent
+
+	// Set while a scheduled migration runs; background ones are tracked
+	// by migrationsRunning
+	migrating         atomic.Bool
+	migrationsRunning sync.WaitGroup
+
+	// Set while an automatic compaction runs
+	compacting atomic.Bool
+
+	// Random source for sampling without a caller-supplied one
+	rng *rand.Rand
+
+	// Cell centroids shared by the islands' CVT archives
+	centroids [][]float64
+
 	// Statistics
-	stats types.EvolutionStats
+	stats   types.EvolutionStats
+	statsMu sync.Mutex
+
+	// Serializes appends to cold storage
+	coldMu sync.Mutex
 
 	// Checkpointing
 	checkpointDir string
 
+	// Background checkpoint writes: the latest one closes written when it
+	// is done, and the first error since the last wait is kept
+	writeMu sync.Mutex
+	written chan struct{}
+	checkpointErr error
+
+	// Environment scores are produced in, saved with checkpoints
+	environment *types.Environment
+
+	// Behavior descriptors of past programs for novelty search; nil when
+	// the novelty archive is disabled
+	behaviors *noveltyArchive
+
+	// Discounts elites not evaluated recently when they compete for the
+	// global best; nil when recency decay is disabled
+	decay *recencyDecay
+
+	// Functions subscribed to database milestones
+	hooks hooks
+
 	// Logger
 	logger *logrus.Logger
 }
@@ -65,28 +121,29 @@ func New(config types.DatabaseConfig, checkpointDir string) *ProgramDatabase {
 		config:      config,
 		programs:    make(map[string]*types.Program),
 		islands:     make([]*Island, config.NumIslands),
-		globalBestScore: math.Inf(-1),
-		currentIsland: 0,
+		extractors:  defaultFeatureExtractors(),
 		lastIteration: 0,
 		lastMigrationGeneration: 0,
 		checkpointDir: checkpointDir,
+		behaviors:   newNoveltyArchive(config.NoveltyArchive),
+		decay:       newRecencyDecay(config.RecencyDecay),
 		logger: logger,
 		stats: types.EvolutionStats{
 			StartTime: time.Now(),
 		},
 	}
 
-	// Initialize islands
-	for i := 0; i < config.NumIslands; i++ {
-		db.islands[i] = NewIsland(i, config)
+	seed := config.RandomSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
+	db.rng = newLockedRand(seed)
+	db.centroids = cvtCentroids(config)
+	logger.Debugf("Database: Set random seed to %d", seed)
 
-	// Set random seed if specified
-	if config.OutputDir != "" {
-		// Use a deterministic seed based on output dir for reproducibility
-		seed := time.Now().UnixNano()
-		rand.Seed(seed)
-		logger.Debugf("Database: Set random seed to %d", seed)
+	// Initialize islands
+	for i := 0; i < config.NumIslands; i++ {
+		db.islands[i] = db.newIsland(i)
 	}
 
 	logger.Info(fmt.Sprintf("Initialized program database with %d islands", config.NumIslands))
@@ -94,10 +151,58 @@ func New(config types.DatabaseConfig, checkpointDir string) *ProgramDatabase {
 	return db
 }
 
-// AddProgram adds a new program to the database
+// newIsland creates an island that shares the database's random source
+func (db *ProgramDatabase) newIsland(id int) *Island {
+	island := newIslandWithCells(id, db.config, db.centroids)
+	island.rng = db.rng
+	island.best = &db.globalBest
+	if db.ColdStoragePath() != "" {
+		island.onReplace = func(program *types.Program) {
+			db.archiveCold(program, island, constants.ColdReasonReplaced)
+		}
+	}
+	return island
+}
+
+// AddProgram adds a new program to the database. Programs added to
+// different islands are placed concurrently. The database keeps program,
+// so it must not be modified once added.
 func (db *ProgramDatabase) AddProgram(program *types.Program, iteration int) error {
-	db.mu.Lock()
-	defer db.mu.Unlock()
+	best, champion, err := db.addProgram(program, iteration, nil)
+	if err != nil {
+		return err
+	}
+	db.saveChampion(champion)
+	if best != nil {
+		db.notifyNewBest(best, iteration)
+	}
+	db.compactIfOversized()
+	return nil
+}
+
+// isPinned reports whether a stored program is pinned on its island. The
+// caller holds the read lock but no island lock.
+func (db *ProgramDatabase) isPinned(id string) bool {
+	db.index.RLock()
+	existing, exists := db.programs[id]
+	db.index.RUnlock()
+	if !exists || existing.IslandID < 0 || existing.IslandID >= len(db.islands) {
+		return false
+	}
+
+	island := db.islands[existing.IslandID]
+	island.mu.Lock()
+	defer island.mu.Unlock()
+	return island.pinned(id)
+}
+
+// addProgram places a program under the locks of its island and returns a
+// copy of it, with a champion snapshot to save, if it became the global best. Features of the dimensions
+// marked in prescaled are already on the grid's scale and are not scaled
+// again.
+func (db *ProgramDatabase) addProgram(program *types.Program, iteration int, prescaled []bool) (*types.Program, *pendingChampion, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
 	// Ensure program has ID
 	if program.ID == "" {
@@ -111,35 +216,61 @@ func (db *ProgramDatabase) AddProgram(program *types.Program, iteration int) err
 		program.UpdatedAt = now
 	}
 
-	// Add to global programs map
-	db.programs[program.ID] = program
+	// Reject programs that would not map to a grid cell
+	if err := db.completeFeatures(program); err != nil {
+		return nil, nil, fmt.Errorf("failed to add program: %w", err)
+	}
 
-	// Determine target island
-	targetIsland := db.currentIsland
+	// Pinned programs are never replaced under their IDs
+	if db.config.Elitism && db.isPinned(program.ID) {
+		return nil, nil, fmt.Errorf("failed to add program: %s is pinned by elitism", program.ID)
+	}
+
+	// Count offspring so sampling can avoid over-exploited parents
+	db.countChild(program)
+
+	// Determine target island, rotating through them
+	targetIsland := int((db.turns.Add(1) - 1) % int64(len(db.islands)))
 	if program.IslandID >= 0 && program.IslandID < len(db.islands) {
 		targetIsland = program.IslandID
 	}
 
-	// Add to island
 	island := db.islands[targetIsland]
+	island.mu.Lock()
+	defer island.mu.Unlock()
+
+	// Scale features for the MAP-Elites grid, archiving the behavior of
+	// programs that ran before features lose their units
+	program.IslandID = targetIsland
+	if !program.Failed {
+		db.behaviors.add(behaviorOf(program.Behavior, program.Features))
+	}
+	island.observeFeatures(program.Features, prescaled)
+	program.Features = island.scaleIncoming(program.Features, prescaled)
+
+	// Add to global programs map and island
+	db.index.Lock()
+	db.programs[program.ID] = program
+	db.index.Unlock()
 	island.Programs[program.ID] = program
 
-	// Scale features and add to MAP-Elites grid
-	scaledFeatures := island.ScaleFeatures(program.Features)
-	program.Features = scaledFeatures
+	// Pin a new champion before it competes for a cell so it cannot lose it
+	newBest := db.promote(program)
+
 	island.AddToGrid(program)
+	island.updateFront(program)
 
 	// Update island best
-	if program.Score > island.BestScore {
+	if rankScore(program) > island.BestScore {
 		island.BestProgram = program
 		island.BestScore = program.Score
 		island.BestID = program.ID
 	}
 
-	// Update global best
-	if program.Score > db.globalBestScore {
-		db.globalBest = program
-		db.globalBestScore = program.Score
+	var best *types.Program
+	var champion *pendingChampion
+	if newBest {
+		best = copyProgram(program)
 		programID := program.ID
 		if len(programID) > 8 {
 			programID = programID[:8]
@@ -150,119 +281,190 @@ func (db *ProgramDatabase) AddProgram(program *types.Program, iteration int) err
 			"island":   targetIsland,
 			"iteration": iteration,
 		}).Info("New global best program found")
+		champion = db.takeChampion(program, iteration)
 	}
 
 	// Update statistics
+	db.statsMu.Lock()
 	db.stats.TotalEvaluations++
-	if program.Score > 0 { // Assume positive score means success
+	if !program.Failed {
 		db.stats.SuccessfulEvals++
 	} else {
 		db.stats.FailedEvals++
 	}
 	db.stats.LastUpdate = time.Now()
+	db.statsMu.Unlock()
 
-	// Rotate to next island
-	db.currentIsland = (db.currentIsland + 1) % len(db.islands)
+	db.enforcePopulationCap(island)
+	db.recordQD(island, iteration)
 
-	return nil
+	return best, champion, nil
 }
 
-// GetProgram retrieves a program by ID
+// countChild counts a program as a child of its parent, under the lock of
+// the parent's island
+func (db *ProgramDatabase) countChild(program *types.Program) {
+	db.index.RLock()
+	parent, exists := db.programs[program.ParentID]
+	db.index.RUnlock()
+	if !exists || parent == program || parent.IslandID < 0 || parent.IslandID >= len(db.islands) {
+		return
+	}
+
+	island := db.islands[parent.IslandID]
+	island.mu.Lock()
+	parent.Children++
+	island.mu.Unlock()
+}
+
+// promote makes program the global best if it beats the current one
+func (db *ProgramDatabase) promote(program *types.Program) bool {
+	db.bestMu.Lock()
+	defer db.bestMu.Unlock()
+
+	if best := db.globalBest.Load(); best != nil && db.decay.apply(program, rankScore(program)) <= db.decay.apply(best, rankScore(best)) {
+		return false
+	}
+	db.globalBest.Store(program)
+	return true
+}
+
+// bestScore returns the score of the global best, -Inf before there is one
+func (db *ProgramDatabase) bestScore() float64 {
+	if best := db.globalBest.Load(); best != nil {
+		return best.Score
+	}
+	return math.Inf(-1)
+}
+
+// readIslands read-locks every island for a read spanning islands under
+// the read lock, and returns the function that unlocks them
+func (db *ProgramDatabase) readIslands() func() {
+	for _, island := range db.islands {
+		island.mu.RLock()
+	}
+	return func() {
+		for _, island := range db.islands {
+			island.mu.RUnlock()
+		}
+	}
+}
+
+// GetProgram retrieves a copy of a program by ID
 func (db *ProgramDatabase) GetProgram(id string) (*types.Program, bool) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
-
+	db.index.RLock()
 	program, exists := db.programs[id]
-	return program, exists
+	db.index.RUnlock()
+
+	if !exists {
+		return nil, false
+	}
+	return db.copyStored(program), true
+}
+
+// Novelty scores raw features against the population of an island,
+// returning a value in [0, 1] where 1 means nothing similar exists yet
+func (db *ProgramDatabase) Novelty(islandID int, features []float64) float64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if islandID < 0 || islandID >= len(db.islands) {
+		return 1.0
+	}
+
+	island := db.islands[islandID]
+	island.mu.RLock()
+	defer island.mu.RUnlock()
+	return island.Novelty(island.ScaleFeatures(features), constants.DefaultNoveltyNeighbors)
 }
 
-// SampleFromIsland samples a program from the specified island
+// SampleFromIsland samples a copy of a program from the specified island
 func (db *ProgramDatabase) SampleFromIsland(islandID int) (*types.Program, error) {
+	return db.SampleFromIslandWith(islandID, nil)
+}
+
+// SampleFromIslandWith samples a program from the specified island using
+// rng, so that a seeded rng reproduces the same pick for the same state. A
+// nil rng draws from the database's own seeded source.
+func (db *ProgramDatabase) SampleFromIslandWith(islandID int, rng *rand.Rand) (*types.Program, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
+	if rng == nil {
+		rng = db.rng
+	}
+
 	if islandID < 0 || islandID >= len(db.islands) {
 		return nil, fmt.Errorf("invalid island ID: %d", islandID)
 	}
 
 	island := db.islands[islandID]
+	island.mu.RLock()
+	defer island.mu.RUnlock()
+
+	// Multi-objective mode ranks parents by Pareto dominance, not Score
+	if len(db.config.Objectives) > 0 && len(island.Programs) > 0 {
+		return copyProgram(island.sampleNonDominated(rng)), nil
+	}
+
+	if island.runsTournament(rng) {
+		return copyProgram(island.sampleTournament(rng)), nil
+	}
 
 	// First try to sample from MAP-Elites grid
-	program := island.SampleFromGrid()
+	program := island.SampleFromGridWith(rng)
 	if program != nil {
-		return program, nil
+		return copyProgram(program), nil
 	}
 
 	// Fallback to sampling from island population
 	if len(island.Programs) > 0 {
 		// Convert to slice for random sampling
-		programs := make([]*types.Program, 0, len(island.Programs))
-		for _, p := range island.Programs {
-			programs = append(programs, p)
-		}
-
-		idx := rand.Intn(len(programs))
-		return programs[idx], nil
+		programs := sortedPrograms(island.Programs)
+		return copyProgram(programs[rng.Intn(len(programs))]), nil
 	}
 
 	return nil, fmt.Errorf("island %d is empty", islandID)
 }
 
-// SampleMultiple samples multiple programs, one from each island
-func (db *ProgramDatabase) SampleMultiple(count int) ([]*types.Program, error) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
-
-	if count <= 0 {
-		return nil, fmt.Errorf("invalid sample count: %d", count)
+// sortedPrograms returns the programs ordered by ID so that index-based
+// sampling does not depend on map iteration order
+func sortedPrograms(programs map[string]*types.Program) []*types.Program {
+	sorted := make([]*types.Program, 0, len(programs))
+	for _, program := range programs {
+		sorted = append(sorted, program)
 	}
-
-	programs := make([]*types.Program, 0, count)
-
-	// Sample from different islands
-	for i := 0; i < count && i < len(db.islands); i++ {
-		islandID := (db.currentIsland + i) % len(db.islands)
-		island := db.islands[islandID]
-
-		// Try MAP-Elites first
-		program := island.SampleFromGrid()
-		if program != nil {
-			programs = append(programs, program)
-			continue
-		}
-
-		// Fallback to best program
-		if island.BestProgram != nil {
-			programs = append(programs, island.BestProgram)
-		}
-	}
-
-	// If we still need more programs, sample globally
-	for len(programs) < count && len(db.programs) > 0 {
-		// Sample random program from global pool
-		idx := rand.Intn(len(db.programs))
-		for _, program := range db.programs {
-			if idx == 0 {
-				programs = append(programs, program)
-				break
-			}
-			idx--
-		}
-	}
-
-	return programs, nil
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a].ID < sorted[b].ID
+	})
+	return sorted
 }
 
 // MigratePrograms performs migration between islands
 func (db *ProgramDatabase) MigratePrograms() error {
+	db.notifyMigrations(db.migratePrograms())
+	return nil
+}
+
+// migratePrograms moves programs around the ring of islands and returns
+// the migrations made
+func (db *ProgramDatabase) migratePrograms() []types.MigrationEvent {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
+	return db.migrateLocked()
+}
+
+// migrateLocked is migratePrograms for callers holding the write lock
+func (db *ProgramDatabase) migrateLocked() []types.MigrationEvent {
 	if len(db.islands) < 2 {
 		return nil // No migration needed with single island
 	}
 
 	migrated := 0
+	first := len(db.migrations)
 
 	// Ring topology migration - each island migrates to next
 	for i, island := range db.islands {
@@ -271,86 +473,394 @@ func (db *ProgramDatabase) MigratePrograms() error {
 		// Select best programs for migration
 		candidates := make([]*types.Program, 0)
 		for _, program := range island.Programs {
-			if program.Score > island.BestScore*0.8 { // Migrate top 20%
+			if rankScore(program) > island.BestScore-0.2*math.Abs(island.BestScore) { // Migrate top 20%
 				candidates = append(candidates, program)
 			}
 		}
 
-		// Migrate subset of candidates
-		toMigrate := int(float64(len(candidates)) * db.config.MigrationRate)
-		if toMigrate < 1 && len(candidates) > 0 {
+		// Migrate subset of candidates, at the rate the receiving island
+		// calls for
+		decision := db.migration(targetIsland)
+		toMigrate := int(float64(len(candidates)) * decision.Rate)
+		if toMigrate < 1 && len(candidates) > 0 && decision.Rate > 0 {
 			toMigrate = 1
 		}
+		if db.config.AdaptiveMigration.Enabled {
+			db.logger.WithFields(logrus.Fields{
+				"island":     targetIsland.ID,
+				"slope":      decision.Slope,
+				"rate":       decision.Rate,
+				"stagnating": decision.Stagnating,
+			}).Debug("Chose adaptive migration rate")
+		}
+
+		// Copies skip code the target already holds, so programs do not
+		// multiply as they travel around the ring
+		targetCodes := make(map[string]bool, len(targetIsland.Programs))
+		if db.config.CopyMigrants {
+			for _, program := range targetIsland.Programs {
+				targetCodes[program.Code] = true
+			}
+		}
 
+		sent := 0
 		for j := 0; j < toMigrate && j < len(candidates); j++ {
 			program := candidates[j]
+			event := types.MigrationEvent{
+				ProgramID:  program.ID,
+				From:       island.ID,
+				To:         targetIsland.ID,
+				Generation: island.Generation,
+				Score:      program.Score,
+			}
 
-			// Move to target island
-			delete(island.Programs, program.ID)
-			program.IslandID = targetIsland.ID
+			if db.config.CopyMigrants {
+				// Send a copy and keep the original where it is
+				if targetCodes[program.Code] {
+					continue
+				}
+				program = migrantCopy(program, targetIsland.ID)
+				event.CopyID = program.ID
+				targetCodes[program.Code] = true
+				db.programs[program.ID] = program
+			} else {
+				// Pinned programs, the champion among them, stay put so
+				// their islands and cells stay valid
+				if island.pinned(program.ID) {
+					continue
+				}
+
+				// Move to target island
+				island.remove(program)
+				program.IslandID = targetIsland.ID
+			}
 			targetIsland.Programs[program.ID] = program
 			targetIsland.AddToGrid(program)
+			targetIsland.updateFront(program)
+			if rankScore(program) > targetIsland.BestScore {
+				targetIsland.BestProgram = program
+				targetIsland.BestScore = program.Score
+				targetIsland.BestID = program.ID
+			}
 
-			migrated++
+			event.Time = time.Now()
+			db.migrations = append(db.migrations, event)
+			sent++
 		}
 
-		island.Migrated += migrated
+		island.Migrated += sent
+		migrated += sent
+		db.enforcePopulationCap(targetIsland)
 	}
 
-	db.lastMigrationGeneration = db.islands[0].Generation
+	db.lastMigrationGeneration = db.minIslandGeneration()
 
 	db.logger.WithField("migrated", migrated).Info("Completed island migration")
 
-	return nil
+	return append([]types.MigrationEvent(nil), db.migrations[first:]...)
+}
+
+// MigrationHistory returns every migration so far, oldest first
+func (db *ProgramDatabase) MigrationHistory() []types.MigrationEvent {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return append([]types.MigrationEvent(nil), db.migrations...)
 }
 
-// GetGlobalBest returns the globally best program
+// migrantCopy clones a program for another island under a new ID. The copy
+// descends from the original, so lineage shows where it migrated from.
+func migrantCopy(program *types.Program, islandID int) *types.Program {
+	migrant := cloneProgram(program, make(map[*types.Program]*types.Program))
+	now := time.Now()
+	migrant.ID = uuid.New().String()
+	migrant.ParentID = program.ID
+	migrant.IslandID = islandID
+	migrant.Children = 0
+	migrant.CreatedAt = now
+	migrant.UpdatedAt = now
+	return migrant
+}
+
+// SameCell reports whether two programs fall into the same grid cell of
+// a's island
+func (db *ProgramDatabase) SameCell(a, b *types.Program) bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if a.IslandID < 0 || a.IslandID >= len(db.islands) {
+		return false
+	}
+	island := db.islands[a.IslandID]
+	island.mu.RLock()
+	defer island.mu.RUnlock()
+	if len(island.Grid.Dimensions) == 0 {
+		return false
+	}
+	key := island.calculateCellKey(a.Features)
+	return key != "" && key == island.calculateCellKey(b.Features)
+}
+
+// GetGlobalBest returns a copy of the globally best program
 func (db *ProgramDatabase) GetGlobalBest() *types.Program {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
+	return db.copyStored(db.globalBest.Load())
+}
+
+// CheckChampion verifies the global best program is stored, lives on its
+// island, holds its grid cell and has its full lineage in the database
+func (db *ProgramDatabase) CheckChampion() error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	return db.checkChampion()
+}
+
+func (db *ProgramDatabase) checkChampion() error {
+	champion := db.globalBest.Load()
+	if champion == nil {
+		return nil
+	}
+
+	if stored, exists := db.programs[champion.ID]; !exists || stored != champion {
+		return fmt.Errorf("champion %s is not stored in the database", champion.ID)
+	}
+	if champion.IslandID < 0 || champion.IslandID >= len(db.islands) {
+		return fmt.Errorf("champion %s is on unknown island %d", champion.ID, champion.IslandID)
+	}
+	island := db.islands[champion.IslandID]
+	if island.Programs[champion.ID] != champion {
+		return fmt.Errorf("champion %s is missing from island %d", champion.ID, island.ID)
+	}
+	if len(island.Grid.Dimensions) > 0 && island.GetFromGrid(champion.Features) != champion {
+		return fmt.Errorf("champion %s does not hold its grid cell", champion.ID)
+	}
+
+	seen := map[string]bool{champion.ID: true}
+	for id := champion.ParentID; id != "" && !seen[id]; {
+		ancestor, exists := db.programs[id]
+		if !exists {
+			return fmt.Errorf("champion %s is missing ancestor %s", champion.ID, id)
+		}
+		seen[id] = true
+		id = ancestor.ParentID
+	}
+
+	return nil
+}
 
-	return db.globalBest
+// adoptChampion stores a global best that is missing from every island on
+// its own island, or the first one if that island no longer exists
+func (db *ProgramDatabase) adoptChampion() {
+	champion := db.globalBest.Load()
+	if champion.IslandID < 0 || champion.IslandID >= len(db.islands) {
+		champion.IslandID = 0
+	}
+	island := db.islands[champion.IslandID]
+
+	db.programs[champion.ID] = champion
+	island.Programs[champion.ID] = champion
+	island.AddToGrid(champion)
+	island.updateFront(champion)
+	if rankScore(champion) > island.BestScore {
+		island.BestProgram = champion
+		island.BestScore = champion.Score
+		island.BestID = champion.ID
+	}
 }
 
-// GetIslandBest returns the best program from each island
+// GetIslandBest returns a copy of the best program from each island
 func (db *ProgramDatabase) GetIslandBest() []*types.Program {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
+	defer db.readIslands()()
 
 	best := make([]*types.Program, 0, len(db.islands))
 	for _, island := range db.islands {
 		if island.BestProgram != nil {
-			best = append(best, island.BestProgram)
+			best = append(best, copyProgram(island.BestProgram))
 		}
 	}
 
 	return best
 }
 
-// UpdateGeneration increments generation counter for all islands
+// UpdateGeneration increments generation counter for all islands. Migration
+// is left to the caller, see ShouldMigrate.
 func (db *ProgramDatabase) UpdateGeneration() {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
 	for _, island := range db.islands {
-		island.IncrementGeneration()
+		db.advanceIsland(island)
 	}
+}
+
+// IncrementIslandGeneration advances the generation counter of one island,
+// for controllers that evolve islands independently
+func (db *ProgramDatabase) IncrementIslandGeneration(islandID int) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	// Check if migration is needed
-	if db.islands[0].Generation-db.lastMigrationGeneration >= db.config.MigrationInterval {
-		go db.MigratePrograms() // Async migration
+	if islandID >= 0 && islandID < len(db.islands) {
+		island := db.islands[islandID]
+		island.mu.Lock()
+		defer island.mu.Unlock()
+		db.advanceIsland(island)
 	}
 }
 
-// SaveCheckpoint saves the database state to a checkpoint file
-func (db *ProgramDatabase) SaveCheckpoint(iteration int) error {
+// advanceIsland increments an island's generation and periodically re-fits
+// its bin edges or bounds to the observed feature distribution
+func (db *ProgramDatabase) advanceIsland(island *Island) {
+	island.IncrementGeneration()
+	db.recordHistory(island)
+	if db.config.AdaptiveMigration.Enabled {
+		island.recordBest(db.config.AdaptiveMigration.Window)
+	}
+	if db.config.Extinction.Enabled {
+		island.recordImprovement()
+	}
+
+	if interval := db.config.DynamicBoundsInterval; db.config.DynamicBounds && interval > 0 && island.Generation%interval == 0 {
+		if displaced, ok := island.RecomputeBounds(); ok {
+			db.logger.WithFields(logrus.Fields{
+				"island":       island.ID,
+				"bounds":       island.Grid.Bounds,
+				"displaced":    displaced,
+				"filled_cells": island.Grid.FilledCells,
+			}).Info("Recomputed grid bounds and remapped elites")
+		}
+	}
+
+	interval := db.config.AdaptiveBinInterval
+	if !island.quantileBinning() || interval <= 0 || island.Generation%interval != 0 {
+		return
+	}
+	if island.RebalanceBins() {
+		db.logger.WithFields(logrus.Fields{
+			"island":       island.ID,
+			"total_cells":  island.Grid.TotalCells,
+			"filled_cells": island.Grid.FilledCells,
+		}).Debug("Rebalanced grid bins")
+	}
+}
+
+// ShouldMigrate reports whether every island has advanced at least one
+// migration interval since the last migration
+func (db *ProgramDatabase) ShouldMigrate() bool {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
+	defer db.readIslands()()
+
+	return db.migrationDue()
+}
+
+// migrationDue is ShouldMigrate for callers holding the islands
+func (db *ProgramDatabase) migrationDue() bool {
+	return len(db.islands) > 1 && db.config.MigrationInterval > 0 &&
+		db.minIslandGeneration()-db.lastMigrationGeneration >= db.config.MigrationInterval
+}
+
+// minIslandGeneration returns the generation of the slowest island
+func (db *ProgramDatabase) minIslandGeneration() int {
+	generation := math.MaxInt
+	for _, island := range db.islands {
+		if island.Generation < generation {
+			generation = island.Generation
+		}
+	}
+	return generation
+}
+
+// relinkMembers points cell members at the canonical program instances,
+// dropping members that are no longer stored. Checkpoints written before
+// cells had members get each cell's elite as its only member.
+func (db *ProgramDatabase) relinkMembers(grid MAPGrid) map[string][]*types.Program {
+	members := make(map[string][]*types.Program, len(grid.Cells))
+	for key, elite := range grid.Cells {
+		if elite == nil {
+			continue
+		}
+		saved, ok := grid.Members[key]
+		if !ok {
+			saved = []*types.Program{elite}
+		}
+		for _, member := range saved {
+			if member == nil {
+				continue
+			}
+			if member.ID == elite.ID {
+				members[key] = append(members[key], elite)
+			} else if canonical, ok := db.programs[member.ID]; ok {
+				members[key] = append(members[key], canonical)
+			}
+		}
+	}
+	return members
+}
 
+// SetEnvironment records the environment scores are produced in; it is
+// saved with checkpoints and compared with theirs on load
+func (db *ProgramDatabase) SetEnvironment(environment *types.Environment) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.environment = environment
+}
+
+// Environment returns the environment scores are produced in
+func (db *ProgramDatabase) Environment() *types.Environment {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.environment
+}
+
+// CheckpointDir returns the directory checkpoints are saved to
+func (db *ProgramDatabase) CheckpointDir() string {
+	return db.checkpointDir
+}
+
+// LastIteration returns the iteration of the last loaded checkpoint
+func (db *ProgramDatabase) LastIteration() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.lastIteration
+}
+
+// SaveCheckpoint saves the database state to a checkpoint file, after any
+// checkpoints still being written in the background
+func (db *ProgramDatabase) SaveCheckpoint(iteration int) error {
 	if db.checkpointDir == "" {
 		return nil
 	}
 
+	result := make(chan error, 1)
+	db.queueCheckpoint(db.snapshot(iteration), func(err error) { result <- err })
+	return <-result
+}
+
+// snapshot copies the database state into a checkpoint under the read
+// lock. Programs are cloned, so the checkpoint can be encoded while
+// iterations keep changing the database.
+func (db *ProgramDatabase) snapshot(iteration int) *types.Checkpoint {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	clones := make(map[*types.Program]*types.Program, len(db.programs))
+	clone := func(program *types.Program) *types.Program {
+		return cloneProgram(program, clones)
+	}
+
 	// Create checkpoint
 	checkpoint := &types.Checkpoint{
 		Version:    "1.0",
@@ -358,37 +868,88 @@ func (db *ProgramDatabase) SaveCheckpoint(iteration int) error {
 		Iteration:  iteration,
 		Generation: db.islands[0].Generation,
 		Islands:    make(map[int]*types.Island),
-		GlobalBest: db.globalBest,
+		GlobalBest: clone(db.globalBest.Load()),
 		Stats:      db.stats,
+		Environment: db.environment,
+		Migrations: append([]types.MigrationEvent(nil), db.migrations...),
+		NoveltyArchive: db.behaviors.snapshot(),
 	}
+	// Failures keep being counted while an async checkpoint is written
+	checkpoint.Stats.Failures = copyFailures(db.stats.Failures)
 
 	// Convert islands to types.Island
 	for _, island := range db.islands {
+		cells := make(map[string]*types.Program, len(island.Grid.Cells))
+		for key, program := range island.Grid.Cells {
+			cells[key] = clone(program)
+		}
+		members := make(map[string][]*types.Program, len(island.Grid.Members))
+		for key, cellMembers := range island.Grid.Members {
+			members[key] = make([]*types.Program, len(cellMembers))
+			for i, program := range cellMembers {
+				members[key][i] = clone(program)
+			}
+		}
+		edges := make(map[string][]float64, len(island.Grid.Edges))
+		for dim, dimEdges := range island.Grid.Edges {
+			edges[dim] = append([]float64(nil), dimEdges...)
+		}
+		programs := make(map[string]*types.Program, len(island.Programs))
+		for id, program := range island.Programs {
+			programs[id] = clone(program)
+		}
+		// Pinned programs are written even if the population lost them
+		if db.config.Elitism {
+			pinned := []*types.Program{island.BestProgram}
+			if best := db.globalBest.Load(); best != nil && best.IslandID == island.ID {
+				pinned = append(pinned, best)
+			}
+			for _, program := range pinned {
+				if program != nil && programs[program.ID] == nil {
+					programs[program.ID] = clone(program)
+				}
+			}
+		}
+
 		// Convert MAPGrid
 		grid := types.MAPGrid{
 			Dimensions: island.Grid.Dimensions,
 			Resolution: island.Grid.Resolution,
 			Bounds:     island.Grid.Bounds,
-			Cells:      island.Grid.Cells,
+			Cells:      cells,
+			Members:    members,
+			Edges:      edges,
 			TotalCells: island.Grid.TotalCells,
 			FilledCells: island.Grid.FilledCells,
 		}
 
+		// JSON cannot encode the -Inf best score of an empty island
+		bestScore := island.BestScore
+		if math.IsInf(bestScore, 0) {
+			bestScore = 0
+		}
+
 		checkpoint.Islands[island.ID] = &types.Island{
 			ID:         island.ID,
-			Programs:   island.Programs,
+			Programs:   programs,
 			Grid:       grid,
-			BestScore:  island.BestScore,
+			BestScore:  bestScore,
 			BestID:     island.BestID,
 			Generation: island.Generation,
 			Migrated:   island.Migrated,
 		}
 	}
 
-	// Serialize to JSON
-	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	return checkpoint
+}
+
+// writeCheckpoint encodes a checkpoint and writes it to the checkpoint
+// directory, both under its iteration and as the latest checkpoint
+func (db *ProgramDatabase) writeCheckpoint(checkpoint *types.Checkpoint) error {
+	// Serialize in the configured format
+	data, err := db.encodeCheckpoint(checkpoint)
 	if err != nil {
-		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+		return err
 	}
 
 	// Create checkpoint directory
@@ -397,35 +958,53 @@ func (db *ProgramDatabase) SaveCheckpoint(iteration int) error {
 	}
 
 	// Write checkpoint file
-	checkpointFile := filepath.Join(db.checkpointDir, fmt.Sprintf("checkpoint_%d.json", iteration))
+	ext := db.checkpointExt()
+	checkpointFile := filepath.Join(db.checkpointDir, fmt.Sprintf("checkpoint_%d%s", checkpoint.Iteration, ext))
 	if err := ioutil.WriteFile(checkpointFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write checkpoint file: %w", err)
 	}
 
 	// Also write latest checkpoint
-	latestFile := filepath.Join(db.checkpointDir, "latest.json")
+	latestFile := filepath.Join(db.checkpointDir, "latest"+ext)
 	if err := ioutil.WriteFile(latestFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write latest checkpoint: %w", err)
 	}
 
 	db.logger.WithFields(logrus.Fields{
-		"iteration": iteration,
+		"iteration": checkpoint.Iteration,
 		"file":      checkpointFile,
 	}).Info("Saved checkpoint")
+	db.notifyCheckpoint(checkpoint.Iteration, checkpointFile)
 
 	return nil
 }
 
-// LoadCheckpoint loads database state from a checkpoint file
+// LoadCheckpoint loads database state from a checkpoint file in any
+// supported format, compressed or not
 func (db *ProgramDatabase) LoadCheckpoint(checkpointPath string) error {
-	data, err := ioutil.ReadFile(checkpointPath)
+	return db.LoadCheckpointWith(checkpointPath, LoadOptions{})
+}
+
+// LoadCheckpointWith loads the islands and programs of a checkpoint file
+// selected by opts
+func (db *ProgramDatabase) LoadCheckpointWith(checkpointPath string, opts LoadOptions) error {
+	checkpoint, err := ReadCheckpoint(checkpointPath)
 	if err != nil {
-		return fmt.Errorf("failed to read checkpoint file: %w", err)
+		return err
+	}
+	if err := verifyCheckpoint(checkpoint); err != nil {
+		return fmt.Errorf("checkpoint %s is corrupt: %w", checkpointPath, err)
 	}
+	return db.restoreCheckpoint(checkpoint, checkpointPath, opts)
+}
 
-	var checkpoint types.Checkpoint
-	if err := json.Unmarshal(data, &checkpoint); err != nil {
-		return fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+// restoreCheckpoint replaces the database's state with a verified
+// checkpoint read from checkpointPath
+func (db *ProgramDatabase) restoreCheckpoint(checkpoint *types.Checkpoint, checkpointPath string, opts LoadOptions) error {
+	if opts.partial() {
+		if err := selectIslands(checkpoint, opts, db.config.NumIslands); err != nil {
+			return fmt.Errorf("failed to select checkpoint islands: %w", err)
+		}
 	}
 
 	db.mu.Lock()
@@ -442,7 +1021,7 @@ func (db *ProgramDatabase) LoadCheckpoint(checkpointPath string) error {
 	// Restore islands
 	db.islands = make([]*Island, len(checkpoint.Islands))
 	for id, islandData := range checkpoint.Islands {
-		island := NewIsland(id, db.config)
+		island := db.newIsland(id)
 		island.Programs = islandData.Programs
 
 		// Convert types.MAPGrid to MAPGrid
@@ -451,11 +1030,17 @@ func (db *ProgramDatabase) LoadCheckpoint(checkpointPath string) error {
 			Resolution: islandData.Grid.Resolution,
 			Bounds:     islandData.Grid.Bounds,
 			Cells:      islandData.Grid.Cells,
+			Members:    islandData.Grid.Members,
+			Edges:      islandData.Grid.Edges,
+			Centroids:  island.Grid.Centroids,
 			TotalCells: islandData.Grid.TotalCells,
 			FilledCells: islandData.Grid.FilledCells,
 		}
 
 		island.BestScore = islandData.BestScore
+		if islandData.BestID == "" {
+			island.BestScore = math.Inf(-1)
+		}
 		island.BestID = islandData.BestID
 		island.Generation = islandData.Generation
 		island.Migrated = islandData.Migrated
@@ -468,19 +1053,74 @@ func (db *ProgramDatabase) LoadCheckpoint(checkpointPath string) error {
 		db.islands[id] = island
 	}
 
-	// Restore global best
-	db.globalBest = checkpoint.GlobalBest
-	if db.globalBest != nil {
-		db.globalBestScore = db.globalBest.Score
+	// Islands a partial load left empty start from scratch
+	if opts.partial() {
+		for id := len(db.islands); id < db.config.NumIslands; id++ {
+			db.islands = append(db.islands, db.newIsland(id))
+		}
+	}
+
+	// Re-link grid cells to the canonical program instances; JSON decoding
+	// gives every reference its own copy, which would split artifacts and
+	// other per-program state between the grid and the population
+	for _, island := range db.islands {
+		if island == nil {
+			continue
+		}
+		for key, program := range island.Grid.Cells {
+			if program == nil {
+				continue
+			}
+			if canonical, ok := db.programs[program.ID]; ok {
+				island.Grid.Cells[key] = canonical
+			}
+		}
+		island.Grid.Members = db.relinkMembers(island.Grid)
+		island.Grid.FilledCells = len(island.Grid.Cells)
+		island.rebuildFront()
+	}
+
+	// Restore global best, adopting it onto its island if the checkpoint
+	// lost track of it
+	best := checkpoint.GlobalBest
+	if best != nil {
+		if canonical, ok := db.programs[best.ID]; ok {
+			best = canonical
+		}
+	}
+	db.globalBest.Store(best)
+	if best != nil && db.programs[best.ID] != best {
+		db.adoptChampion()
+	}
+	// Loading only elites drops ancestors on purpose
+	if err := db.checkChampion(); err != nil && !opts.ElitesOnly {
+		db.logger.WithError(err).Warn("Checkpoint champion is incomplete")
+	}
+
+	// Scores from another toolchain, platform or evaluator are not
+	// comparable with new ones
+	if db.environment != nil && checkpoint.Environment != nil && *db.environment != *checkpoint.Environment {
+		db.logger.WithFields(logrus.Fields{
+			"checkpoint": *checkpoint.Environment,
+			"current":    *db.environment,
+		}).Warn("Checkpoint was evaluated in a different environment")
+	}
+	if db.environment == nil {
+		db.environment = checkpoint.Environment
 	}
 
 	// Restore statistics
+	db.statsMu.Lock()
 	db.stats = checkpoint.Stats
+	db.statsMu.Unlock()
 	db.lastIteration = checkpoint.Iteration
+	db.migrations = checkpoint.Migrations
+	db.behaviors.restore(checkpoint.NoveltyArchive)
 
 	db.logger.WithFields(logrus.Fields{
 		"iteration": checkpoint.Iteration,
 		"programs":  len(db.programs),
+		"islands":   len(db.islands),
 		"file":      checkpointPath,
 	}).Info("Loaded checkpoint")
 
@@ -491,15 +1131,23 @@ func (db *ProgramDatabase) LoadCheckpoint(checkpointPath string) error {
 func (db *ProgramDatabase) GetStats() types.EvolutionStats {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
 
 	stats := db.stats
 	stats.Duration = time.Since(db.stats.StartTime)
 
-	// Calculate average score
+	// Calculate average score of the programs that did not fail
 	if db.stats.TotalEvaluations > 0 {
 		totalScore := 0.0
 		count := 0
 		for _, program := range db.programs {
+			if program.Failed {
+				continue
+			}
 			totalScore += program.Score
 			count++
 		}
@@ -508,14 +1156,143 @@ func (db *ProgramDatabase) GetStats() types.EvolutionStats {
 		}
 	}
 
-	stats.BestScore = db.globalBestScore
+	stats.BestScore = db.bestScore()
+	stats.Migration = db.migrationStats()
+	stats.QDHistory = append([]types.QDSample(nil), db.stats.QDHistory...)
+	stats.History = append([]types.HistorySample(nil), db.stats.History...)
+	stats.EvaluatorChanges = append([]types.EvaluatorChange(nil), db.stats.EvaluatorChanges...)
+	stats.Failures = copyFailures(db.stats.Failures)
+	stats.StalePrograms = 0
+	for _, program := range db.programs {
+		if program.Stale {
+			stats.StalePrograms++
+		}
+	}
 
 	return stats
 }
 
+// RecordFailure counts a failed iteration under its category
+func (db *ProgramDatabase) RecordFailure(category string) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	if db.stats.Failures == nil {
+		db.stats.Failures = make(map[string]int64)
+	}
+	db.stats.Failures[category]++
+	db.stats.LastUpdate = time.Now()
+}
+
+// copyFailures copies failure counts, returning nil when there are none
+func copyFailures(failures map[string]int64) map[string]int64 {
+	if len(failures) == 0 {
+		return nil
+	}
+	copied := make(map[string]int64, len(failures))
+	for category, count := range failures {
+		copied[category] = count
+	}
+	return copied
+}
+
+// GetLineage returns copies of the ancestors of a program followed by the
+// program itself, oldest first. The walk stops at the first ancestor no
+// longer in the database.
+func (db *ProgramDatabase) GetLineage(programID string) ([]*types.Program, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	if _, exists := db.programs[programID]; !exists {
+		return nil, fmt.Errorf("program not found: %s", programID)
+	}
+	return copyPrograms(db.lineage(programID)), nil
+}
+
+func (db *ProgramDatabase) lineage(programID string) []*types.Program {
+	var lineage []*types.Program
+	seen := make(map[string]bool)
+	for id := programID; id != "" && !seen[id]; {
+		program, exists := db.programs[id]
+		if !exists {
+			break
+		}
+		seen[id] = true
+		lineage = append(lineage, program)
+		id = program.ParentID
+	}
+
+	// Reverse into chronological order
+	for i, j := 0, len(lineage)-1; i < j; i, j = i+1, j-1 {
+		lineage[i], lineage[j] = lineage[j], lineage[i]
+	}
+	return lineage
+}
+
+// GetDescendants returns copies of every program descended from a program,
+// ordered by generation and then ID
+func (db *ProgramDatabase) GetDescendants(programID string) ([]*types.Program, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	if _, exists := db.programs[programID]; !exists {
+		return nil, fmt.Errorf("program not found: %s", programID)
+	}
+
+	children := make(map[string][]*types.Program)
+	for _, program := range db.programs {
+		if program.ParentID != "" {
+			children[program.ParentID] = append(children[program.ParentID], program)
+		}
+	}
+
+	var descendants []*types.Program
+	seen := map[string]bool{programID: true}
+	queue := []string{programID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			descendants = append(descendants, child)
+			queue = append(queue, child.ID)
+		}
+	}
+
+	sort.Slice(descendants, func(a, b int) bool {
+		if descendants[a].Generation != descendants[b].Generation {
+			return descendants[a].Generation < descendants[b].Generation
+		}
+		return descendants[a].ID < descendants[b].ID
+	})
+	return copyPrograms(descendants), nil
+}
+
+// NumIslands returns the number of islands
+func (db *ProgramDatabase) NumIslands() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return len(db.islands)
+}
+
 // GetCurrentIsland returns the current island ID
 func (db *ProgramDatabase) GetCurrentIsland() int {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
-	return db.currentIsland
+	if len(db.islands) == 0 {
+		return 0
+	}
+	return int(db.turns.Load() % int64(len(db.islands)))
 }
\ No newline at end of file
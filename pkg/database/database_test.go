@@ -1,13 +1,23 @@
 package database
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
 )
 
@@ -70,6 +80,427 @@ func TestIslandAddToGrid(t *testing.T) {
 	assert.Equal(t, 0.9, stored.Score)
 }
 
+func TestIslandCellSubPopulationWorstOut(t *testing.T) {
+	island := NewIsland(0, types.DatabaseConfig{
+		GridDimensions:     []string{"complexity"},
+		GridResolution:     map[string]int{"complexity": 5},
+		MaxProgramsPerCell: 2,
+		CellReplacement:    constants.CellReplacementWorstOut,
+	})
+
+	features := []float64{0.3}
+	assert.True(t, island.AddToGrid(&types.Program{ID: "a", Score: 0.5, Features: features}))
+	assert.True(t, island.AddToGrid(&types.Program{ID: "b", Score: 0.7, Features: features}))
+	assert.False(t, island.AddToGrid(&types.Program{ID: "c", Score: 0.4, Features: features}), "worse than every member")
+	assert.True(t, island.AddToGrid(&types.Program{ID: "d", Score: 0.6, Features: features}))
+
+	cellKey := island.calculateCellKey(features)
+	assert.Equal(t, 1, island.Grid.FilledCells)
+	assert.Equal(t, []string{"b", "d"}, programIDs(island.Grid.Members[cellKey]))
+	assert.Equal(t, "b", island.Grid.Cells[cellKey].ID)
+	assert.Len(t, island.elites(), 2)
+}
+
+func TestIslandCellSubPopulationOldestOut(t *testing.T) {
+	island := NewIsland(0, types.DatabaseConfig{
+		GridDimensions:     []string{"complexity"},
+		GridResolution:     map[string]int{"complexity": 5},
+		MaxProgramsPerCell: 3,
+		CellReplacement:    constants.CellReplacementOldestOut,
+	})
+
+	features := []float64{0.3}
+	for _, program := range []*types.Program{
+		{ID: "elite", Score: 0.9, Features: features},
+		{ID: "old", Score: 0.5, Features: features},
+		{ID: "mid", Score: 0.6, Features: features},
+		{ID: "new", Score: 0.1, Features: features},
+	} {
+		assert.True(t, island.AddToGrid(program))
+	}
+
+	// The oldest non-elite goes, even though the newcomer is worse
+	cellKey := island.calculateCellKey(features)
+	assert.Equal(t, []string{"elite", "mid", "new"}, programIDs(island.Grid.Members[cellKey]))
+	assert.Equal(t, "elite", island.Grid.Cells[cellKey].ID)
+
+	// Removing the elite promotes the fittest remaining member
+	island.remove(island.Grid.Cells[cellKey])
+	assert.Equal(t, "mid", island.Grid.Cells[cellKey].ID)
+	assert.Equal(t, 1, island.Grid.FilledCells)
+}
+
+func TestProgramDatabase_CheckpointKeepsCellMembers(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:         1,
+		GridDimensions:     []string{"complexity"},
+		GridResolution:     map[string]int{"complexity": 5},
+		GridBounds:         map[string][2]float64{"complexity": {0, 1}},
+		MaxProgramsPerCell: 2,
+	}
+
+	db1 := New(config, tempDir)
+	for idx, id := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, db1.AddProgram(&types.Program{ID: id, Score: float64(idx) / 10, Features: []float64{0.5}}, idx))
+	}
+	require.NoError(t, db1.SaveCheckpoint(4))
+
+	db2 := New(config, tempDir)
+	require.NoError(t, db2.LoadCheckpoint(filepath.Join(tempDir, "checkpoint_4.json")))
+
+	saved, loaded := db1.islands[0].Grid.Members, db2.islands[0].Grid.Members
+	require.Len(t, loaded, len(saved))
+	shared := false
+	for key, members := range saved {
+		assert.Equal(t, programIDs(members), programIDs(loaded[key]))
+		shared = shared || len(members) > 1
+		for _, member := range loaded[key] {
+			assert.Same(t, db2.islands[0].Programs[member.ID], member)
+		}
+	}
+	assert.True(t, shared, "some cell should hold several programs")
+}
+
+// programIDs returns the IDs of programs in order
+func programIDs(programs []*types.Program) []string {
+	ids := make([]string, 0, len(programs))
+	for _, program := range programs {
+		ids = append(ids, program.ID)
+	}
+	return ids
+}
+
+func TestIslandNovelty(t *testing.T) {
+	island := NewIsland(0, types.DatabaseConfig{
+		GridDimensions: []string{"complexity", "diversity"},
+	})
+
+	// An empty island is maximally novel
+	assert.Equal(t, 1.0, island.Novelty([]float64{0.5, 0.5}, 3))
+
+	island.Programs["a"] = &types.Program{ID: "a", Features: []float64{0.5, 0.5}}
+	island.Programs["b"] = &types.Program{ID: "b", Features: []float64{0.6, 0.5}}
+
+	assert.Equal(t, 0.0, island.Novelty([]float64{0.5, 0.5}, 1))
+	assert.Greater(t, island.Novelty([]float64{1.0, 0.0}, 1), island.Novelty([]float64{0.55, 0.5}, 1))
+}
+
+func TestIslandAddToGridUsesFitness(t *testing.T) {
+	island := NewIsland(0, types.DatabaseConfig{
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		NoveltyWeight:  0.5,
+	})
+
+	incumbent := &types.Program{ID: "incumbent", Score: 0.9, Fitness: 0.5, NoveltyBlended: true, Features: []float64{0.3}}
+	novel := &types.Program{ID: "novel", Score: 0.8, Fitness: 0.7, NoveltyBlended: true, Features: []float64{0.3}}
+
+	assert.True(t, island.AddToGrid(incumbent))
+	assert.True(t, island.AddToGrid(novel))
+	assert.Equal(t, "novel", island.GetFromGrid([]float64{0.3}).ID)
+
+	// A blended fitness of zero is still a real fitness
+	zero := &types.Program{ID: "zero", Score: 0.95, Fitness: 0, NoveltyBlended: true, Features: []float64{0.3}}
+	assert.False(t, island.AddToGrid(zero))
+}
+
+func TestIslandAddToGridIgnoresFitnessWithoutNovelty(t *testing.T) {
+	island := NewIsland(0, types.DatabaseConfig{
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+	})
+
+	incumbent := &types.Program{ID: "incumbent", Score: 0.9, Fitness: 0.5, NoveltyBlended: true, Features: []float64{0.3}}
+	challenger := &types.Program{ID: "challenger", Score: 0.8, Fitness: 0.7, NoveltyBlended: true, Features: []float64{0.3}}
+
+	assert.True(t, island.AddToGrid(incumbent))
+	assert.False(t, island.AddToGrid(challenger))
+	assert.Equal(t, "incumbent", island.GetFromGrid([]float64{0.3}).ID)
+}
+
+func TestIslandSamplingAvoidsStaleElites(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+		StalenessBias:  1,
+	}
+
+	db := New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "stale", Score: 0.5, Features: []float64{0.1}}, 0))
+	for idx := 0; idx < 20; idx++ {
+		db.IncrementIslandGeneration(0)
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("child%d", idx),
+			ParentID: "stale",
+			Score:    0.1,
+			Features: []float64{0.1},
+		}, idx))
+	}
+	require.NoError(t, db.AddProgram(&types.Program{ID: "fresh", Score: 0.5, Features: []float64{0.9}}, 21))
+
+	stale, _ := db.GetProgram("stale")
+	assert.Equal(t, 20, stale.Children)
+	assert.Equal(t, float64(40), db.islands[0].Staleness(stale))
+
+	rng := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		program, err := db.SampleFromIslandWith(0, rng)
+		require.NoError(t, err)
+		counts[program.ID]++
+	}
+	assert.Greater(t, counts["fresh"], 5*counts["stale"])
+}
+
+func TestIslandParentSelectionStrategies(t *testing.T) {
+	sample := func(selection string, ratio float64) map[string]int {
+		config := types.DatabaseConfig{
+			NumIslands:       1,
+			GridDimensions:   []string{"complexity"},
+			GridResolution:   map[string]int{"complexity": 4},
+			GridBounds:       map[string][2]float64{"complexity": {0, 1}},
+			ParentSelection:  selection,
+			ExplorationRatio: ratio,
+			RankExponent:     2,
+		}
+		db := New(config, "")
+		require.NoError(t, db.AddProgram(&types.Program{ID: "best", Score: 0.9, Features: []float64{0.1}}, 0))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "mid", Score: 0.3, Features: []float64{0.4}}, 1))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "worst", Score: 0, Features: []float64{0.9}}, 2))
+
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			program, err := db.SampleFromIslandWith(0, rng)
+			require.NoError(t, err)
+			counts[program.ID]++
+		}
+		return counts
+	}
+
+	// Pure exploitation always picks the best elite
+	assert.Equal(t, map[string]int{"best": 1000}, sample("epsilon_greedy", 0))
+
+	// Exploration spreads a share of picks over every elite
+	greedy := sample("epsilon_greedy", 0.3)
+	assert.Greater(t, greedy["worst"], 50)
+	assert.Greater(t, greedy["best"], 700)
+
+	// Without exploration a zero score is never picked in proportion
+	proportional := sample("score_proportional", 0)
+	assert.Zero(t, proportional["worst"])
+	assert.Greater(t, proportional["best"], 2*proportional["mid"])
+
+	// Rank weights 1, 1/4, 1/9 still give the zero score a chance
+	ranked := sample("rank", 0)
+	assert.Greater(t, ranked["worst"], 40)
+	assert.Greater(t, ranked["mid"], ranked["worst"])
+	assert.Greater(t, ranked["best"], 3*ranked["mid"])
+
+	// Without novelty, fitness is the score
+	fitness := sample("fitness_proportional", 0)
+	assert.Zero(t, fitness["worst"])
+	assert.Greater(t, fitness["best"], 2*fitness["mid"])
+
+	uniform := sample("uniform", 0)
+	assert.Greater(t, uniform["worst"], 250)
+}
+
+func TestIslandProportionalSelectionWithNegativeScores(t *testing.T) {
+	for _, selection := range []string{"score_proportional", "fitness_proportional"} {
+		config := types.DatabaseConfig{
+			NumIslands:      1,
+			GridDimensions:  []string{"complexity"},
+			GridResolution:  map[string]int{"complexity": 4},
+			GridBounds:      map[string][2]float64{"complexity": {0, 1}},
+			ParentSelection: selection,
+		}
+		db := New(config, "")
+		// Losses under minimize: -1 is the best
+		require.NoError(t, db.AddProgram(&types.Program{ID: "best", Score: -1, Features: []float64{0.1}}, 0))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "mid", Score: -3, Features: []float64{0.4}}, 1))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "worst", Score: -5, Features: []float64{0.9}}, 2))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "failed", Score: 0, Failed: true, Features: []float64{0.6}}, 3))
+
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			program, err := db.SampleFromIslandWith(0, rng)
+			require.NoError(t, err)
+			counts[program.ID]++
+		}
+
+		// Weights 4, 2 and ~0 rather than a uniform pick
+		assert.Greater(t, counts["best"], 600, selection)
+		assert.Greater(t, counts["mid"], 250, selection)
+		assert.Zero(t, counts["worst"], selection)
+		assert.Zero(t, counts["failed"], selection)
+	}
+}
+
+func TestProgramDatabase_SeededSampling(t *testing.T) {
+	sample := func(seed int64) []string {
+		config := types.DatabaseConfig{
+			NumIslands:     1,
+			GridDimensions: []string{"complexity"},
+			GridResolution: map[string]int{"complexity": 10},
+			GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+			RandomSeed:     seed,
+		}
+		db := New(config, "")
+		for i := 0; i < 5; i++ {
+			program := &types.Program{ID: fmt.Sprintf("p%d", i), Score: 0.5, Features: []float64{float64(i) / 5}}
+			require.NoError(t, db.AddProgram(program, i))
+		}
+
+		ids := make([]string, 0, 50)
+		for i := 0; i < 50; i++ {
+			program, err := db.SampleFromIsland(0)
+			require.NoError(t, err)
+			ids = append(ids, program.ID)
+		}
+		return ids
+	}
+
+	// The database's own source makes unseeded calls reproducible
+	first := sample(7)
+	assert.Equal(t, first, sample(7))
+	assert.NotEqual(t, first, sample(8))
+
+	// Databases sampling side by side do not disturb each other
+	concurrent := make([][]string, 4)
+	var wg sync.WaitGroup
+	for i := range concurrent {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			concurrent[i] = sample(7)
+		}(i)
+	}
+	wg.Wait()
+	for _, ids := range concurrent {
+		assert.Equal(t, first, ids)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range first {
+		seen[id] = true
+	}
+	assert.Len(t, seen, 5)
+}
+
+func TestIslandTournamentSelection(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:            2,
+		GridDimensions:        []string{"complexity"},
+		GridResolution:        map[string]int{"complexity": 4},
+		GridBounds:            map[string][2]float64{"complexity": {0, 1}},
+		IslandParentSelection: map[int]string{1: "tournament"},
+		TournamentSize:        5,
+	}
+	db := New(config, "")
+
+	// Every program lands in one cell, so the grid only holds the best
+	for island := 0; island < 2; island++ {
+		for idx := 0; idx < 5; idx++ {
+			require.NoError(t, db.AddProgram(&types.Program{
+				ID:       fmt.Sprintf("island%d-p%d", island, idx),
+				Score:    0.1 * float64(idx+1),
+				Features: []float64{0.5},
+				IslandID: island,
+			}, idx))
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	grid := make(map[string]int)
+	tournament := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		program, err := db.SampleFromIslandWith(0, rng)
+		require.NoError(t, err)
+		grid[program.ID]++
+		program, err = db.SampleFromIslandWith(1, rng)
+		require.NoError(t, err)
+		tournament[program.ID]++
+	}
+
+	assert.Equal(t, map[string]int{"island0-p4": 1000}, grid)
+	// The best wins two thirds of five-way tournaments; the rest of the
+	// population still gets picked
+	assert.Greater(t, tournament["island1-p4"], 600)
+	assert.Less(t, tournament["island1-p4"], 750)
+	assert.Greater(t, tournament["island1-p3"], 150)
+	assert.Less(t, tournament["island1-p0"], 10)
+
+	// An island override takes precedence over the per-island selection
+	config.IslandOverrides = map[int]types.IslandOverride{1: {ParentSelection: "rank"}}
+	assert.Equal(t, "rank", NewIsland(1, config).parentSelection)
+}
+
+func TestIslandBoltzmannSelectionAnneals(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:      1,
+		GridDimensions:  []string{"complexity"},
+		GridResolution:  map[string]int{"complexity": 4},
+		GridBounds:      map[string][2]float64{"complexity": {0, 1}},
+		ParentSelection: "boltzmann",
+		Boltzmann:       types.BoltzmannConfig{Temperature: 1, MinTemperature: 0.05, HalfLife: 10},
+	}
+	db := New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "best", Score: 0.9, Features: []float64{0.1}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "mid", Score: 0.6, Features: []float64{0.4}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "worst", Score: 0.3, Features: []float64{0.9}}, 2))
+
+	sample := func() map[string]int {
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			program, err := db.SampleFromIslandWith(0, rng)
+			require.NoError(t, err)
+			counts[program.ID]++
+		}
+		return counts
+	}
+
+	// Hot: close to uniform
+	early := sample()
+	assert.Greater(t, early["worst"], 200)
+
+	// Fifty generations cool the island to its floor, where the best dominates
+	for generation := 0; generation < 50; generation++ {
+		db.IncrementIslandGeneration(0)
+	}
+	assert.InDelta(t, 0.05, db.islands[0].temperature(), 1e-9)
+	late := sample()
+	assert.Greater(t, late["best"], 950)
+	assert.Less(t, late["worst"], early["worst"])
+}
+
+func TestIslandBoltzmannSelectionSkipsFailedElites(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:      1,
+		GridDimensions:  []string{"complexity"},
+		GridResolution:  map[string]int{"complexity": 4},
+		GridBounds:      map[string][2]float64{"complexity": {0, 1}},
+		ParentSelection: "boltzmann",
+		Boltzmann:       types.BoltzmannConfig{Temperature: 0.1},
+	}
+	db := New(config, "")
+	// Under minimize the broken program's raw score is the largest
+	require.NoError(t, db.AddProgram(&types.Program{ID: "broken", Score: 0, Failed: true, Features: []float64{0.1}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "working", Score: -2, Features: []float64{0.9}}, 1))
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		program, err := db.SampleFromIslandWith(0, rng)
+		require.NoError(t, err)
+		assert.Equal(t, "working", program.ID)
+	}
+}
+
 func TestIslandGetBestProgram(t *testing.T) {
 	island := NewIsland(0, types.DatabaseConfig{})
 
@@ -102,7 +533,7 @@ func TestProgramDatabase_New(t *testing.T) {
 
 	assert.NotNil(t, db)
 	assert.Equal(t, 3, len(db.islands))
-	assert.Equal(t, 0, db.currentIsland)
+	assert.Equal(t, 0, db.GetCurrentIsland())
 	assert.NotNil(t, db.programs)
 }
 
@@ -133,6 +564,55 @@ func TestProgramDatabase_AddProgram(t *testing.T) {
 	assert.Equal(t, program, best)
 }
 
+func TestProgramDatabase_AddProgramDerivesMissingFeatures(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"diversity", "score"},
+		GridResolution: map[string]int{"diversity": 10, "score": 10},
+		GridBounds:     map[string][2]float64{"diversity": {0, 1}, "score": {0, 1}},
+	}
+	db := New(config, "")
+
+	program := &types.Program{ID: "short", Score: 0.7, Features: []float64{0.2}}
+	require.NoError(t, db.AddProgram(program, 1))
+	assert.Equal(t, []float64{0.2, 0.7}, program.Features)
+
+	// A fresh database has no feature statistics, so features are stored unscaled
+	db = New(config, "")
+	db.RegisterFeatureExtractor("diversity", func(*types.Program) float64 { return 0.4 })
+	bare := &types.Program{ID: "bare", Score: 0.1}
+	require.NoError(t, db.AddProgram(bare, 2))
+	assert.Equal(t, []float64{0.4, 0.1}, bare.Features)
+}
+
+func TestProgramDatabase_AddProgramRejectsFeatureMismatch(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity", "diversity"},
+		GridResolution: map[string]int{"complexity": 10, "diversity": 10},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}, "diversity": {0, 1}},
+	}
+	db := New(config, "")
+
+	var mismatch *FeatureMismatchError
+
+	// Too many features
+	err := db.AddProgram(&types.Program{ID: "long", Features: []float64{0.1, 0.2, 0.3}}, 1)
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, 3, mismatch.Got)
+	assert.Equal(t, 2, mismatch.Want)
+
+	// No extractor for the missing dimension
+	err = db.AddProgram(&types.Program{ID: "short", Features: []float64{0.1}}, 1)
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"diversity"}, mismatch.Missing)
+
+	_, exists := db.GetProgram("long")
+	assert.False(t, exists)
+	_, exists = db.GetProgram("short")
+	assert.False(t, exists)
+}
+
 func TestProgramDatabase_SampleFromIsland(t *testing.T) {
 	config := types.DatabaseConfig{
 		NumIslands:     2,
@@ -166,6 +646,128 @@ func TestProgramDatabase_SampleFromIsland(t *testing.T) {
 	assert.Contains(t, err.Error(), "is empty")
 }
 
+func TestProgramDatabase_SampleWithSeededRand(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 10},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	db := New(config, "")
+	for idx := 0; idx < 10; idx++ {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("p%d", idx),
+			Score:    float64(idx) / 10,
+			Features: []float64{float64(idx) / 10},
+		}, idx))
+	}
+
+	sample := func(seed int64) []string {
+		rng := rand.New(rand.NewSource(seed))
+		ids := make([]string, 0, 5)
+		for i := 0; i < 5; i++ {
+			program, err := db.SampleFromIslandWith(0, rng)
+			require.NoError(t, err)
+			ids = append(ids, program.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, sample(7), sample(7))
+}
+
+func TestProgramDatabase_SampleMultipleStrategies(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     3,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 10},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	db := New(config, "")
+	// Sparse islands: island 2 stays empty
+	for idx := 0; idx < 6; idx++ {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("p%d", idx),
+			Score:    float64(idx) / 10,
+			Features: []float64{float64(idx) / 5},
+			IslandID: idx % 2,
+		}, idx))
+	}
+
+	strategies := []string{
+		constants.SampleStrategyPerIsland,
+		constants.SampleStrategyEliteBiased,
+		constants.SampleStrategyDiverseCells,
+	}
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			programs, err := db.SampleMultipleWith(0, 4, strategy, rand.New(rand.NewSource(1)))
+			require.NoError(t, err)
+			assert.Len(t, programs, 4)
+
+			seen := make(map[string]bool)
+			for _, program := range programs {
+				assert.False(t, seen[program.ID], "duplicate %s", program.ID)
+				seen[program.ID] = true
+			}
+
+			// Asking for more than exists returns everything once
+			programs, err = db.SampleMultipleWith(0, 10, strategy, rand.New(rand.NewSource(1)))
+			require.NoError(t, err)
+			assert.Len(t, programs, 6)
+		})
+	}
+
+	_, err := db.SampleMultipleWith(0, 2, "unknown", nil)
+	assert.Error(t, err)
+	_, err = db.SampleMultipleWith(3, 2, constants.SampleStrategyPerIsland, nil)
+	assert.Error(t, err)
+}
+
+func TestProgramDatabase_SamplePerIslandIgnoresInsertionCursor(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     3,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 10},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+	for idx := 0; idx < 3; idx++ {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("p%d", idx),
+			Features: []float64{0.5},
+			IslandID: idx,
+		}, idx))
+	}
+
+	first, err := db.SampleMultipleWith(1, 1, constants.SampleStrategyPerIsland, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	// Another island inserting a program moves the shared cursor
+	require.NoError(t, db.AddProgram(&types.Program{ID: "other", Features: []float64{0.1}, IslandID: 0}, 3))
+
+	second, err := db.SampleMultipleWith(1, 1, constants.SampleStrategyPerIsland, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	assert.Equal(t, "p1", first[0].ID)
+	assert.Equal(t, first[0].ID, second[0].ID)
+}
+
+func TestSampleEliteBiasedFavoursFitPrograms(t *testing.T) {
+	pool := make([]*types.Program, 10)
+	for idx := range pool {
+		pool[idx] = &types.Program{ID: fmt.Sprintf("p%d", idx), Score: float64(idx)}
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	counts := make(map[string]int)
+	for i := 0; i < 500; i++ {
+		counts[sampleEliteBiased(append([]*types.Program(nil), pool...), 1, false, rng)[0].ID]++
+	}
+	assert.Greater(t, counts["p9"], counts["p0"])
+}
+
 func TestProgramDatabase_Migration(t *testing.T) {
 	config := types.DatabaseConfig{
 		NumIslands:        3,
@@ -203,6 +805,209 @@ func TestProgramDatabase_Migration(t *testing.T) {
 		totalPrograms += len(island.Programs)
 	}
 	assert.Equal(t, 12, totalPrograms) // Total should remain the same
+
+	// Each island counts only the programs it sent
+	sent := make(map[int]int)
+	for _, event := range db.MigrationHistory() {
+		sent[event.From]++
+	}
+	for _, island := range db.islands {
+		assert.Equal(t, sent[island.ID], island.Migrated, "island %d", island.ID)
+	}
+}
+
+func TestProgramDatabase_CopyMigration(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:        2,
+		MigrationInterval: 1,
+		MigrationRate:     1,
+		CopyMigrants:      true,
+		GridDimensions:    []string{"complexity"},
+		GridResolution:    map[string]int{"complexity": 5},
+		GridBounds:        map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+
+	require.NoError(t, db.AddProgram(&types.Program{ID: "best", Code: "best", Score: 0.9, Features: []float64{0.9}, IslandID: 0}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "other", Code: "other", Score: 0.85, Features: []float64{0.1}, IslandID: 1}, 1))
+
+	require.NoError(t, db.MigratePrograms())
+
+	// Originals stay home, even the champion, and each island gains a copy
+	assert.Contains(t, db.islands[0].Programs, "best")
+	assert.Contains(t, db.islands[1].Programs, "other")
+	assert.Len(t, db.islands[0].Programs, 2)
+	assert.Len(t, db.islands[1].Programs, 2)
+	assert.Len(t, db.programs, 4)
+	assert.NoError(t, db.CheckChampion())
+
+	var copied *types.Program
+	for id, program := range db.islands[1].Programs {
+		if id != "other" {
+			copied = program
+		}
+	}
+	require.NotNil(t, copied)
+	assert.Equal(t, "best", copied.Code)
+	assert.Equal(t, "best", copied.ParentID)
+	assert.Equal(t, 1, copied.IslandID)
+	assert.Same(t, copied, db.islands[1].GetFromGrid(copied.Features))
+
+	// Code an island already holds is not copied again
+	require.NoError(t, db.MigratePrograms())
+	assert.Len(t, db.islands[0].Programs, 2)
+	assert.Len(t, db.islands[1].Programs, 2)
+}
+
+func TestProgramDatabase_MigrationHistory(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:        2,
+		MigrationInterval: 1,
+		MigrationRate:     1,
+		CopyMigrants:      true,
+		GridDimensions:    []string{"complexity"},
+		GridResolution:    map[string]int{"complexity": 5},
+		GridBounds:        map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, tempDir)
+
+	require.NoError(t, db.AddProgram(&types.Program{ID: "best", Code: "best", Score: 0.9, Features: []float64{0.9}, IslandID: 0}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "other", Code: "other", Score: 0.85, Features: []float64{0.1}, IslandID: 1}, 1))
+	db.IncrementIslandGeneration(0)
+	require.NoError(t, db.MigratePrograms())
+
+	history := db.MigrationHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, "best", history[0].ProgramID)
+	assert.Equal(t, 0, history[0].From)
+	assert.Equal(t, 1, history[0].To)
+	assert.Equal(t, 1, history[0].Generation)
+	assert.Equal(t, 0.9, history[0].Score)
+	assert.Contains(t, db.islands[1].Programs, history[0].CopyID)
+	assert.Equal(t, "other", history[1].ProgramID)
+	assert.Equal(t, 1, history[1].From)
+	assert.Equal(t, 0, history[1].To)
+
+	// The history survives a checkpoint round trip
+	require.NoError(t, db.SaveCheckpoint(1))
+	db2 := New(config, tempDir)
+	require.NoError(t, db2.LoadCheckpoint(filepath.Join(tempDir, "checkpoint_1.json")))
+	assert.Equal(t, history[0].CopyID, db2.MigrationHistory()[0].CopyID)
+	assert.Len(t, db2.MigrationHistory(), 2)
+
+	// A partial load starts a new run without it
+	db3 := New(config, tempDir)
+	require.NoError(t, db3.LoadCheckpointWith(filepath.Join(tempDir, "checkpoint_1.json"), LoadOptions{ElitesOnly: true}))
+	assert.Empty(t, db3.MigrationHistory())
+}
+
+func TestProgramDatabase_ChampionSnapshot(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+		OutputDir:      t.TempDir(),
+	}
+	db := New(config, "")
+
+	read := func() ChampionSnapshot {
+		data, err := os.ReadFile(db.ChampionPath())
+		require.NoError(t, err)
+		var snapshot ChampionSnapshot
+		require.NoError(t, json.Unmarshal(data, &snapshot))
+		return snapshot
+	}
+
+	require.NoError(t, db.AddProgram(&types.Program{ID: "seed", Code: "seed", Score: 0.2, Features: []float64{0.5}}, 0))
+	assert.Equal(t, "seed", read().ProgramID)
+
+	child := &types.Program{ID: "child", ParentID: "seed", Code: "child", Score: 0.7, Generation: 1, Changes: "faster loop",
+		Metrics: map[string]float64{"speed": 3}, Features: []float64{0.2}}
+	require.NoError(t, db.AddProgram(child, 4))
+	snapshot := read()
+	assert.Equal(t, "child", snapshot.ProgramID)
+	assert.Equal(t, "child", snapshot.Code)
+	assert.Equal(t, 4, snapshot.Iteration)
+	assert.Equal(t, 3.0, snapshot.Metrics["speed"])
+	require.Len(t, snapshot.Lineage, 2)
+	assert.Equal(t, "seed", snapshot.Lineage[0].ID)
+	assert.Equal(t, "faster loop", snapshot.Lineage[1].Changes)
+
+	// A worse program leaves the snapshot alone
+	require.NoError(t, db.AddProgram(&types.Program{ID: "worse", ParentID: "child", Code: "worse", Score: 0.1, Features: []float64{0.9}}, 5))
+	assert.Equal(t, "child", read().ProgramID)
+}
+
+func TestProgramDatabase_ShouldMigrate(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:        2,
+		GridDimensions:    []string{"complexity"},
+		GridResolution:    map[string]int{"complexity": 5},
+		GridBounds:        map[string][2]float64{"complexity": {0, 1}},
+		MigrationInterval: 2,
+		MigrationRate:     0.5,
+	}
+
+	db := New(config, "")
+	db.IncrementIslandGeneration(0)
+	db.IncrementIslandGeneration(0)
+	db.IncrementIslandGeneration(1)
+
+	// Migration waits for the slowest island
+	assert.False(t, db.ShouldMigrate())
+
+	db.IncrementIslandGeneration(1)
+	assert.True(t, db.ShouldMigrate())
+
+	require.NoError(t, db.MigratePrograms())
+	assert.False(t, db.ShouldMigrate())
+}
+
+func TestProgramDatabase_MigrateIfDue(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		t.Run(fmt.Sprintf("async=%v", async), func(t *testing.T) {
+			db := New(types.DatabaseConfig{
+				NumIslands:        2,
+				GridDimensions:    []string{"complexity"},
+				GridResolution:    map[string]int{"complexity": 5},
+				GridBounds:        map[string][2]float64{"complexity": {0, 1}},
+				MigrationInterval: 1,
+				MigrationRate:     1,
+				AsyncMigration:    async,
+			}, "")
+			for i := 0; i < 2; i++ {
+				require.NoError(t, db.AddProgram(&types.Program{ID: fmt.Sprintf("p%d", i), Code: fmt.Sprintf("p%d", i), Score: 0.5, Features: []float64{0.5}, IslandID: i}, 1))
+			}
+			assert.False(t, db.MigrateIfDue())
+
+			db.IncrementIslandGeneration(0)
+			db.IncrementIslandGeneration(1)
+
+			// Concurrent callers start exactly one migration, which runs
+			// alongside programs being added
+			var started atomic.Int64
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					if db.MigrateIfDue() {
+						started.Add(1)
+					}
+					db.AddProgram(&types.Program{ID: fmt.Sprintf("c%d", i), Code: fmt.Sprintf("c%d", i), Score: 0.1, Features: []float64{0.1}, IslandID: i % 2}, 2)
+				}(i)
+			}
+			wg.Wait()
+			db.WaitMigrations()
+
+			assert.Equal(t, int64(1), started.Load())
+			assert.NotEmpty(t, db.MigrationHistory())
+			assert.False(t, db.ShouldMigrate())
+			assert.False(t, db.MigrateIfDue())
+		})
+	}
 }
 
 func TestProgramDatabase_SaveAndLoadCheckpoint(t *testing.T) {
@@ -268,6 +1073,243 @@ func TestProgramDatabase_SaveAndLoadCheckpoint(t *testing.T) {
 	assert.Equal(t, "test2", best.ID) // Should be the higher scoring program
 }
 
+func TestProgramDatabase_CheckpointKeepsArtifacts(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	db1 := New(config, tempDir)
+	program := &types.Program{
+		ID:        "with-artifacts",
+		Code:      "func test() {}",
+		Score:     0.6,
+		Features:  []float64{0.4},
+		Artifacts: map[string]string{"stderr": "undefined: foo"},
+	}
+	require.NoError(t, db1.AddProgram(program, 1))
+	require.NoError(t, db1.SaveCheckpoint(1))
+
+	db2 := New(config, tempDir)
+	require.NoError(t, db2.LoadCheckpoint(tempDir+"/checkpoint_1.json"))
+
+	loaded, exists := db2.GetProgram("with-artifacts")
+	require.True(t, exists)
+	assert.Equal(t, "undefined: foo", loaded.Artifacts["stderr"])
+
+	// Grid cells and global best must point at the same instance as the population
+	stored := db2.programs["with-artifacts"]
+	assert.Same(t, stored, db2.globalBest.Load())
+	for _, cell := range db2.islands[0].Grid.Cells {
+		assert.Same(t, stored, cell)
+	}
+}
+
+func TestProgramDatabase_ChampionKeepsItsCell(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+		NoveltyWeight:  0.5,
+	}
+	db := New(config, "")
+
+	champion := &types.Program{ID: "champion", Score: 0.9, Fitness: 0.1, NoveltyBlended: true, Features: []float64{0.5}}
+	require.NoError(t, db.AddProgram(champion, 1))
+
+	// Fitter on the grid but not a better score: must not take the cell
+	rival := &types.Program{ID: "rival", Score: 0.5, Fitness: 0.8, NoveltyBlended: true, Features: []float64{0.5}}
+	require.NoError(t, db.AddProgram(rival, 2))
+
+	assert.Same(t, champion, db.islands[0].GetFromGrid(champion.Features))
+	assert.NoError(t, db.CheckChampion())
+}
+
+func TestProgramDatabase_MigrationKeepsChampion(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		MigrationRate:  1.0,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+
+	champion := &types.Program{ID: "champion", Score: 0.9, Features: []float64{0.1}, IslandID: 0}
+	runnerUp := &types.Program{ID: "runner-up", Score: 0.85, Features: []float64{0.9}, IslandID: 0}
+	require.NoError(t, db.AddProgram(champion, 1))
+	require.NoError(t, db.AddProgram(runnerUp, 1))
+
+	require.NoError(t, db.MigratePrograms())
+
+	assert.Equal(t, 0, champion.IslandID)
+	assert.Same(t, champion, db.islands[0].Programs["champion"])
+	assert.NoError(t, db.CheckChampion())
+
+	// Nothing on an island may point at a program that moved away
+	for _, island := range db.islands {
+		for _, cell := range island.Grid.Cells {
+			assert.Equal(t, island.ID, cell.IslandID)
+		}
+		if island.BestProgram != nil {
+			assert.Equal(t, island.ID, island.BestProgram.IslandID)
+		}
+	}
+}
+
+func TestProgramDatabase_LoadLatest(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	_, err := New(config, tempDir).LoadLatest(tempDir)
+	assert.Error(t, err)
+
+	db := New(config, tempDir)
+	require.NoError(t, db.AddProgram(&types.Program{ID: "first", Score: 0.1, Features: []float64{0.1}}, 2))
+	require.NoError(t, db.SaveCheckpoint(2))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "second", Score: 0.5, Features: []float64{0.5}, IslandID: 1}, 10))
+	require.NoError(t, db.SaveCheckpoint(10))
+
+	resumed := New(config, tempDir)
+	iteration, err := resumed.LoadLatest(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, 10, iteration)
+	assert.Equal(t, "second", resumed.GetGlobalBest().ID)
+
+	// A torn latest checkpoint and a newest one missing an island fall
+	// back to the newest intact checkpoint
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "latest.json"), []byte(`{"iteration": 10, "islands": {`), 0644))
+	checkpoint, err := ReadCheckpoint(filepath.Join(tempDir, "checkpoint_10.json"))
+	require.NoError(t, err)
+	delete(checkpoint.Islands, 0)
+	data, err := json.Marshal(checkpoint)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "checkpoint_10.json"), data, 0644))
+	assert.Error(t, New(config, tempDir).LoadCheckpoint(filepath.Join(tempDir, "checkpoint_10.json")))
+
+	resumed = New(config, tempDir)
+	iteration, err = resumed.LoadLatest(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, iteration)
+	assert.Equal(t, 2, resumed.LastIteration())
+	assert.Equal(t, "first", resumed.GetGlobalBest().ID)
+
+	// Nothing intact is an error
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "checkpoint_2.json"), []byte("garbage"), 0644))
+	_, err = New(config, tempDir).LoadLatest(tempDir)
+	assert.Error(t, err)
+}
+
+func TestProgramDatabase_LoadCheckpointRestoresChampion(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	db1 := New(config, tempDir)
+	require.NoError(t, db1.AddProgram(&types.Program{ID: "root", Score: 0.1, Features: []float64{0.1}, IslandID: 1}, 0))
+	require.NoError(t, db1.AddProgram(&types.Program{ID: "mid", ParentID: "root", Score: 0.5, Features: []float64{0.5}, IslandID: 1}, 1))
+	require.NoError(t, db1.AddProgram(&types.Program{ID: "best", ParentID: "mid", Score: 0.9, Features: []float64{0.9}, IslandID: 1}, 2))
+	require.NoError(t, db1.SaveCheckpoint(2))
+
+	// Simulate a checkpoint whose islands lost the champion
+	path := filepath.Join(tempDir, "checkpoint_2.json")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var checkpoint types.Checkpoint
+	require.NoError(t, json.Unmarshal(data, &checkpoint))
+	island := checkpoint.Islands[1]
+	delete(island.Programs, "best")
+	for key, cell := range island.Grid.Cells {
+		if cell.ID == "best" {
+			delete(island.Grid.Cells, key)
+		}
+	}
+	data, err = json.Marshal(checkpoint)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	db2 := New(config, tempDir)
+	require.NoError(t, db2.LoadCheckpoint(path))
+
+	best, exists := db2.programs["best"]
+	require.True(t, exists)
+	assert.Same(t, best, db2.globalBest.Load())
+	assert.Same(t, best, db2.islands[1].Programs["best"])
+	assert.NoError(t, db2.CheckChampion())
+
+	lineage, err := db2.GetLineage("best")
+	require.NoError(t, err)
+	require.Len(t, lineage, 3)
+	assert.Equal(t, "root", lineage[0].ID)
+}
+
+func TestProgramDatabase_Lineage(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	db := New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "root", Features: []float64{0.1}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "child", ParentID: "root", Features: []float64{0.5}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "grandchild", ParentID: "child", Features: []float64{0.9}}, 2))
+
+	lineage, err := db.GetLineage("grandchild")
+	require.NoError(t, err)
+	require.Len(t, lineage, 3)
+	assert.Equal(t, "root", lineage[0].ID)
+	assert.Equal(t, "grandchild", lineage[2].ID)
+
+	_, err = db.GetLineage("missing")
+	assert.Error(t, err)
+}
+
+func TestProgramDatabase_GetLineageAndDescendants(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+	}
+
+	db := New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "root", Features: []float64{0.1}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "b", ParentID: "root", Generation: 1, Features: []float64{0.5}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "a", ParentID: "root", Generation: 1, Features: []float64{0.5}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "leaf", ParentID: "b", Generation: 2, InspirationIDs: []string{"a"}, Features: []float64{0.9}}, 2))
+
+	lineage, err := db.GetLineage("leaf")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "b", "leaf"}, programIDs(lineage))
+
+	descendants, err := db.GetDescendants("root")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "leaf"}, programIDs(descendants))
+
+	descendants, err = db.GetDescendants("leaf")
+	require.NoError(t, err)
+	assert.Empty(t, descendants)
+
+	_, err = db.GetLineage("missing")
+	assert.Error(t, err)
+	_, err = db.GetDescendants("missing")
+	assert.Error(t, err)
+}
+
 func TestProgramDatabase_GetStats(t *testing.T) {
 	config := types.DatabaseConfig{
 		NumIslands: 1,
@@ -275,21 +1317,61 @@ func TestProgramDatabase_GetStats(t *testing.T) {
 
 	db := New(config, "")
 
-	// Add some programs
-	for i := 0; i < 3; i++ {
+	// Add some programs, one failed with a score above the rest
+	for i := 0; i < 4; i++ {
 		program := &types.Program{
-			ID:    fmt.Sprintf("test%d", i),
-			Code:  fmt.Sprintf("func test%d() {}", i),
-			Score: float64(i) * 0.3,
+			ID:     fmt.Sprintf("test%d", i),
+			Code:   fmt.Sprintf("func test%d() {}", i),
+			Score:  float64(i)*0.3 - 0.3,
+			Failed: i == 3,
 		}
 		db.AddProgram(program, 1)
 	}
 
 	stats := db.GetStats()
-	assert.Equal(t, int64(3), stats.TotalEvaluations)
-	assert.Equal(t, int64(2), stats.SuccessfulEvals) // Only scores > 0 count as successful
-	assert.Equal(t, 0.6, stats.BestScore)            // Highest score
-	assert.Equal(t, 0.3, stats.AvgScore)             // Average of 0, 0.3, 0.6
+	assert.Equal(t, int64(4), stats.TotalEvaluations)
+	assert.Equal(t, int64(3), stats.SuccessfulEvals) // Negative and zero scores still succeed
+	assert.Equal(t, int64(1), stats.FailedEvals)
+	assert.InDelta(t, 0.3, stats.BestScore, 1e-9)    // Highest score that did not fail
+	assert.InDelta(t, 0.0, stats.AvgScore, 1e-9)     // Average of -0.3, 0, 0.3
+}
+
+func TestProgramDatabase_NegativeScores(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:        2,
+		MigrationInterval: 1,
+		MigrationRate:     1,
+		GridDimensions:    []string{"complexity"},
+		GridResolution:    map[string]int{"complexity": 5},
+		GridBounds:        map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+
+	// A failed program holds the lead only until a program succeeds
+	require.NoError(t, db.AddProgram(&types.Program{ID: "failed", Code: "a", Score: 0, Failed: true, Features: []float64{0.5}, IslandID: 0}, 1))
+	assert.Equal(t, "failed", db.GetGlobalBest().ID)
+	for i, score := range []float64{-12, -3.5, -4} {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("loss%d", i),
+			Code:     fmt.Sprintf("b%d", i),
+			Score:    score,
+			Features: []float64{0.5},
+			IslandID: 0,
+		}, i+2))
+	}
+	assert.Equal(t, "loss1", db.GetGlobalBest().ID)
+	assert.Equal(t, "loss1", db.GetIslandBest()[0].ID)
+
+	// The failed program lost its cell to a program that succeeded
+	island := db.islands[0]
+	assert.Equal(t, "loss1", island.Grid.Cells[island.calculateCellKey([]float64{0.5})].ID)
+
+	// The best of a negative-scoring island still migrates
+	require.NoError(t, db.MigratePrograms())
+	assert.NotEmpty(t, db.MigrationHistory())
+
+	ranked := db.Query(Query{})
+	assert.Equal(t, "failed", ranked[len(ranked)-1].ID)
 }
 
 func TestIslandCalculateCellKey(t *testing.T) {
@@ -315,6 +1397,118 @@ func TestIslandCalculateCellKey(t *testing.T) {
 	assert.Equal(t, "complexity:2;diversity:2;", key)
 }
 
+func TestIslandRebalanceBins(t *testing.T) {
+	config := types.DatabaseConfig{
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 4},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	island := NewIsland(0, config)
+
+	// Everything crowds into the lowest uniform bin
+	for idx, feature := range []float64{0.01, 0.02, 0.03, 0.04, 0.05, 0.06, 0.07, 0.08} {
+		program := &types.Program{
+			ID:       fmt.Sprintf("p%d", idx),
+			Score:    float64(idx) / 10,
+			Features: []float64{feature},
+		}
+		island.Programs[program.ID] = program
+		island.AddToGrid(program)
+	}
+	assert.Equal(t, 1, island.Grid.FilledCells)
+
+	require.True(t, island.RebalanceBins())
+	assert.Len(t, island.Grid.Edges["complexity"], 3)
+	assert.Equal(t, 4, island.Grid.TotalCells)
+	assert.Equal(t, 4, island.Grid.FilledCells)
+
+	// The fittest program of each quantile keeps its cell
+	assert.Equal(t, "p1", island.GetFromGrid([]float64{0.02}).ID)
+	assert.Equal(t, "p7", island.GetFromGrid([]float64{0.5}).ID)
+}
+
+func TestIslandRecomputeBounds(t *testing.T) {
+	config := types.DatabaseConfig{
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 4},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	island := NewIsland(0, config)
+	_, ok := island.RecomputeBounds()
+	assert.False(t, ok, "no feature range observed yet")
+
+	// The last program lies far outside the configured bounds
+	for idx, feature := range []float64{0.5, 0.9, 3.0} {
+		program := &types.Program{
+			ID:       fmt.Sprintf("p%d", idx),
+			Score:    []float64{0.2, 0.1, 0.3}[idx],
+			Features: []float64{feature},
+		}
+		island.Programs[program.ID] = program
+		island.AddToGrid(program)
+	}
+	assert.Equal(t, 3, island.Grid.FilledCells)
+
+	// Over the observed range p0 and p1 share a cell, and p1 loses it
+	displaced, ok := island.RecomputeBounds()
+	require.True(t, ok)
+	assert.Equal(t, 1, displaced)
+	assert.Equal(t, [2]float64{0.5, 3.0}, island.Grid.Bounds["complexity"])
+	assert.Equal(t, 2, island.Grid.FilledCells)
+	assert.Equal(t, "p0", island.GetFromGrid([]float64{0.9}).ID)
+	assert.Equal(t, "p2", island.GetFromGrid([]float64{3.0}).ID)
+	assert.Equal(t, [2]float64{0, 1}, config.GridBounds["complexity"], "configured bounds must not change")
+
+	_, ok = island.RecomputeBounds()
+	assert.False(t, ok, "bounds already match the observed range")
+}
+
+func TestIslandGridScaling(t *testing.T) {
+	config := types.DatabaseConfig{
+		GridDimensions: []string{"runtime", "size"},
+		GridResolution: map[string]int{"runtime": 4, "size": 4},
+		GridBounds:     map[string][2]float64{"runtime": {0, 1}, "size": {0, 1}},
+		GridScaling:    map[string]string{"runtime": constants.GridScalingLog, "size": constants.GridScalingQuantile},
+	}
+
+	island := NewIsland(0, config)
+	assert.InDeltaSlice(t, []float64{1.0 / 15, 3.0 / 15, 7.0 / 15}, island.Grid.Edges["runtime"], 1e-9)
+	assert.True(t, island.quantileBinning())
+
+	// Skewed runtimes that linear bins would lump together get cells of
+	// their own, and sizes crowded at the bottom are spread by quantiles
+	for idx, runtime := range []float64{0.01, 0.1, 0.3, 0.9} {
+		program := &types.Program{
+			ID:       fmt.Sprintf("p%d", idx),
+			Score:    0.5,
+			Features: []float64{runtime, 0.01 * float64(idx+1)},
+		}
+		island.Programs[program.ID] = program
+		island.AddToGrid(program)
+	}
+	assert.Equal(t, 4, island.Grid.FilledCells)
+	assert.Equal(t, "runtime:0;size:0;", island.calculateCellKey([]float64{0.01, 0.01}))
+	assert.Equal(t, "runtime:3;size:0;", island.calculateCellKey([]float64{0.9, 0.04}))
+
+	require.True(t, island.RebalanceBins())
+	assert.InDeltaSlice(t, []float64{1.0 / 15, 3.0 / 15, 7.0 / 15}, island.Grid.Edges["runtime"], 1e-9)
+	assert.Len(t, island.Grid.Edges["size"], 3)
+	assert.Equal(t, "runtime:3;size:3;", island.calculateCellKey([]float64{0.9, 0.04}))
+
+	linear := NewIsland(0, types.DatabaseConfig{GridDimensions: []string{"size"}, GridResolution: map[string]int{"size": 4}})
+	assert.False(t, linear.quantileBinning())
+	assert.Empty(t, linear.Grid.Edges)
+}
+
+func TestQuantileEdgesCollapseDuplicates(t *testing.T) {
+	edges := quantileEdges([]float64{0.5, 0.5, 0.5, 0.5, 0.9}, 5)
+	assert.Equal(t, []float64{0.5}, edges)
+
+	assert.Empty(t, quantileEdges([]float64{0.3, 0.3, 0.3}, 4))
+}
+
 func TestIslandScaleFeatures(t *testing.T) {
 	island := NewIsland(0, types.DatabaseConfig{
 		GridDimensions: []string{"complexity", "diversity"},
@@ -340,6 +1534,36 @@ func TestIslandScaleFeatures(t *testing.T) {
 	assert.Equal(t, 0.5, scaled[1])  // (0-(-2))/(2-(-2)) = 0.5
 }
 
+func TestIslandScaleFeaturesMethods(t *testing.T) {
+	island := NewIsland(0, types.DatabaseConfig{
+		GridDimensions: []string{"quantile", "log", "zscore"},
+		GridResolution: map[string]int{"quantile": 10, "log": 10, "zscore": 10},
+		FeatureScaling: map[string]string{"quantile": "quantile", "log": "log", "zscore": "zscore"},
+	})
+
+	// Without samples values pass through, as with min-max
+	assert.Equal(t, []float64{5, 5, 5}, island.ScaleFeatures([]float64{5, 5, 5}))
+
+	// A heavy tail: one value far above the rest
+	for _, value := range []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 1000} {
+		island.observeFeatures([]float64{value, value, value}, nil)
+	}
+	scaled := island.ScaleFeatures([]float64{5, 5, 5})
+
+	// Min-max would squash 5 to 0.004; quantile and log keep it mid-grid
+	assert.InDelta(t, 0.45, scaled[0], 1e-9)
+	assert.InDelta(t, math.Log1p(4)/math.Log1p(999), scaled[1], 1e-9)
+	assert.Greater(t, scaled[1], 0.2)
+	mean, std := meanStd([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 1000})
+	assert.InDelta(t, 0.5*(1+math.Erf((5-mean)/(std*math.Sqrt2))), scaled[2], 1e-9)
+
+	// Values outside the samples clamp to the grid
+	scaled = island.ScaleFeatures([]float64{-10, -10, 1e9})
+	assert.Equal(t, 0.0, scaled[0])
+	assert.Equal(t, 0.0, scaled[1])
+	assert.InDelta(t, 1.0, scaled[2], 1e-9)
+}
+
 func BenchmarkProgramDatabase_AddProgram(b *testing.B) {
 	config := types.DatabaseConfig{
 		NumIslands: 10,
@@ -357,4 +1581,1496 @@ func BenchmarkProgramDatabase_AddProgram(b *testing.B) {
 		}
 		db.AddProgram(program, i)
 	}
-}
\ No newline at end of file
+}
+func TestProgramDatabase_ParetoFront(t *testing.T) {
+	objectives := []types.Objective{{Metric: "speed"}, {Metric: "memory", Minimize: true}}
+	db := New(types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		Objectives:     objectives,
+	}, "")
+
+	add := func(id string, speed, memory float64) {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       id,
+			Features: []float64{0.5},
+			Metrics:  map[string]float64{"speed": speed, "memory": memory},
+		}, 0))
+	}
+	add("slow-small", 1, 10)
+	add("fast-big", 5, 50)
+	add("dominated", 1, 60)
+	add("fast-small", 5, 10)
+
+	// fast-small dominates both earlier trade-offs
+	front, err := db.ParetoFront(0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fast-small"}, programIDs(front))
+
+	fronts := nonDominatedSort(sortedPrograms(db.islands[0].Programs), objectives)
+	require.Len(t, fronts, 3)
+	assert.Equal(t, []string{"fast-small"}, programIDs(fronts[0]))
+	assert.ElementsMatch(t, []string{"fast-big", "slow-small"}, programIDs(fronts[1]))
+	assert.Equal(t, []string{"dominated"}, programIDs(fronts[2]))
+
+	// Removing the front's only member promotes the next front
+	db.islands[0].remove(db.islands[0].Programs["fast-small"])
+	front, err = db.ParetoFront(0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fast-big", "slow-small"}, programIDs(front))
+}
+
+func TestProgramDatabase_ParetoParentSelection(t *testing.T) {
+	db := New(types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		Objectives:     []types.Objective{{Metric: "speed"}, {Metric: "accuracy"}},
+	}, "")
+
+	// The best-scoring program is dominated on both objectives
+	require.NoError(t, db.AddProgram(&types.Program{ID: "scorer", Score: 0.9, Features: []float64{0.5},
+		Metrics: map[string]float64{"speed": 1, "accuracy": 1}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "pareto", Score: 0.1, Features: []float64{0.5},
+		Metrics: map[string]float64{"speed": 2, "accuracy": 2}}, 0))
+
+	rng := rand.New(rand.NewSource(7))
+	counts := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		parent, err := db.SampleFromIslandWith(0, rng)
+		require.NoError(t, err)
+		counts[parent.ID]++
+	}
+	// Tournaments only pick the dominated program when it meets itself
+	assert.Greater(t, counts["pareto"], 120)
+	assert.Greater(t, counts["pareto"], counts["scorer"])
+}
+
+func TestProgramDatabase_CheckpointKeepsEnvironment(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+	}
+	environment := &types.Environment{GoVersion: "go1.21.0", OS: "linux", Arch: "amd64", EvaluatorHash: "abc"}
+
+	db1 := New(config, tempDir)
+	db1.SetEnvironment(environment)
+	require.NoError(t, db1.AddProgram(&types.Program{ID: "a", Features: []float64{0.5}}, 0))
+	require.NoError(t, db1.SaveCheckpoint(1))
+
+	db2 := New(config, tempDir)
+	require.NoError(t, db2.LoadCheckpoint(filepath.Join(tempDir, "checkpoint_1.json")))
+	require.NotNil(t, db2.Environment())
+	assert.Equal(t, *environment, *db2.Environment())
+}
+
+func TestProgramDatabase_ExportArchive(t *testing.T) {
+	db := New(types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}, "")
+	for idx, score := range []float64{0.2, 0.9, 0.5} {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:         fmt.Sprintf("p%d", idx),
+			Code:       fmt.Sprintf("package main\n\n// program %d\n", idx),
+			Score:      score,
+			Generation: idx,
+			IslandID:   idx % 2,
+			Features:   []float64{0.5},
+		}, idx))
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, db.ExportArchive(dir, 2))
+
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	require.NoError(t, err)
+	var manifest ArchiveManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	require.Len(t, manifest.Programs, 2)
+	best := manifest.Programs[0]
+	assert.Equal(t, "p1", best.ID)
+	assert.Equal(t, 0.9, best.Score)
+	assert.Equal(t, 1, best.Generation)
+	assert.Equal(t, "p2", manifest.Programs[1].ID)
+
+	code, err := os.ReadFile(filepath.Join(dir, best.File))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\n// program 1\n", string(code))
+}
+
+func TestProgramDatabase_CheckpointFormats(t *testing.T) {
+	for _, test := range []struct {
+		format     string
+		compressed bool
+		file       string
+	}{
+		{constants.CheckpointFormatJSON, false, "checkpoint_3.json"},
+		{constants.CheckpointFormatJSON, true, "checkpoint_3.json.gz"},
+		{constants.CheckpointFormatGob, false, "checkpoint_3.gob"},
+		{constants.CheckpointFormatGob, true, "checkpoint_3.gob.gz"},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			tempDir := t.TempDir()
+			config := types.DatabaseConfig{
+				NumIslands:            2,
+				GridDimensions:        []string{"complexity"},
+				GridResolution:        map[string]int{"complexity": 5},
+				CheckpointFormat:      test.format,
+				CheckpointCompression: test.compressed,
+			}
+
+			db1 := New(config, tempDir)
+			require.NoError(t, db1.AddProgram(&types.Program{ID: "root", Code: strings.Repeat("x := 1\n", 100), Score: 0.3, Features: []float64{0.2}}, 0))
+			require.NoError(t, db1.AddProgram(&types.Program{ID: "best", ParentID: "root", Score: 0.8, Features: []float64{0.7}, IslandID: 1}, 1))
+			require.NoError(t, db1.SaveCheckpoint(3))
+
+			data, err := os.ReadFile(filepath.Join(tempDir, test.file))
+			require.NoError(t, err)
+			assert.Equal(t, test.compressed, bytes.HasPrefix(data, gzipMagic))
+
+			// Loading detects the format, whatever the database is configured with
+			db2 := New(types.DatabaseConfig{NumIslands: 2, GridDimensions: []string{"complexity"}}, tempDir)
+			require.NoError(t, db2.LoadCheckpoint(filepath.Join(tempDir, test.file)))
+
+			best := db2.GetGlobalBest()
+			require.NotNil(t, best)
+			assert.Equal(t, "best", best.ID)
+			root, exists := db2.GetProgram("root")
+			require.True(t, exists)
+			assert.Equal(t, strings.Repeat("x := 1\n", 100), root.Code)
+			assert.NoError(t, db2.CheckChampion())
+		})
+	}
+}
+
+func TestProgramDatabase_LoadCheckpointWithSelectedIslands(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     3,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+
+	db1 := New(config, tempDir)
+	for islandID := 0; islandID < 3; islandID++ {
+		// The second program takes the first one's cell
+		require.NoError(t, db1.AddProgram(&types.Program{ID: fmt.Sprintf("weak%d", islandID), Score: 0.1, Features: []float64{0.5}, IslandID: islandID}, 1))
+		require.NoError(t, db1.AddProgram(&types.Program{ID: fmt.Sprintf("strong%d", islandID), ParentID: fmt.Sprintf("weak%d", islandID), Score: 0.2 + float64(islandID)/10, Features: []float64{0.5}, IslandID: islandID}, 2))
+	}
+	db1.IncrementIslandGeneration(2)
+	require.NoError(t, db1.SaveCheckpoint(2))
+	path := filepath.Join(tempDir, "checkpoint_2.json")
+
+	db2 := New(types.DatabaseConfig{NumIslands: 4, GridDimensions: []string{"complexity"}}, tempDir)
+	require.NoError(t, db2.LoadCheckpointWith(path, LoadOptions{Islands: []int{2, 0}}))
+
+	assert.Equal(t, 4, db2.NumIslands())
+	assert.Equal(t, []string{"strong2", "weak2"}, programIDs(sortedPrograms(db2.islands[0].Programs)))
+	assert.Equal(t, []string{"strong0", "weak0"}, programIDs(sortedPrograms(db2.islands[1].Programs)))
+	assert.Empty(t, db2.islands[2].Programs)
+	assert.Empty(t, db2.islands[3].Programs)
+	assert.Zero(t, db2.islands[0].Generation)
+
+	// Programs move with their island and the best one kept leads the run
+	strong2, exists := db2.GetProgram("strong2")
+	require.True(t, exists)
+	assert.Equal(t, 0, strong2.IslandID)
+	assert.Equal(t, "strong2", db2.GetGlobalBest().ID)
+	assert.NoError(t, db2.CheckChampion())
+	_, exists = db2.GetProgram("strong1")
+	assert.False(t, exists)
+
+	// Only elites: the displaced programs stay behind
+	db3 := New(types.DatabaseConfig{NumIslands: 3, GridDimensions: []string{"complexity"}}, tempDir)
+	require.NoError(t, db3.LoadCheckpointWith(path, LoadOptions{ElitesOnly: true}))
+	assert.Len(t, db3.programs, 3)
+	for islandID, island := range db3.islands {
+		assert.Equal(t, []string{fmt.Sprintf("strong%d", islandID)}, programIDs(sortedPrograms(island.Programs)))
+	}
+	assert.Equal(t, "strong2", db3.GetGlobalBest().ID)
+
+	// Selections the database cannot hold are rejected
+	db4 := New(types.DatabaseConfig{NumIslands: 1}, tempDir)
+	assert.Error(t, db4.LoadCheckpointWith(path, LoadOptions{Islands: []int{0, 1}}))
+	assert.Error(t, db4.LoadCheckpointWith(path, LoadOptions{Islands: []int{7}}))
+	db5 := New(types.DatabaseConfig{NumIslands: 2}, tempDir)
+	assert.Error(t, db5.LoadCheckpointWith(path, LoadOptions{Islands: []int{1, 1}}))
+}
+
+func TestProgramDatabase_SaveCheckpointAsync(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+	}
+
+	db := New(config, tempDir)
+	root := &types.Program{ID: "root", Score: 0.5, Features: []float64{0.5}, Artifacts: map[string]string{"stage": "first"}}
+	require.NoError(t, db.AddProgram(root, 1))
+
+	var mu sync.Mutex
+	var order []int
+	record := func(iteration int) func(error) {
+		return func(err error) {
+			assert.NoError(t, err)
+			mu.Lock()
+			order = append(order, iteration)
+			mu.Unlock()
+		}
+	}
+	db.SaveCheckpointAsync(1, record(1))
+
+	// Changes after the snapshot stay out of the checkpoint being written
+	root.Artifacts["stage"] = "second"
+	require.NoError(t, db.AddProgram(&types.Program{ID: "child", ParentID: "root", Score: 0.9, Features: []float64{0.5}}, 2))
+	db.SaveCheckpointAsync(2, record(2))
+	require.NoError(t, db.WaitCheckpoints())
+	assert.Equal(t, []int{1, 2}, order)
+
+	first := New(config, tempDir)
+	require.NoError(t, first.LoadCheckpoint(filepath.Join(tempDir, "checkpoint_1.json")))
+	loaded, exists := first.GetProgram("root")
+	require.True(t, exists)
+	assert.Equal(t, "first", loaded.Artifacts["stage"])
+	_, exists = first.GetProgram("child")
+	assert.False(t, exists)
+
+	// The latest checkpoint is the last one requested
+	latest := New(config, tempDir)
+	require.NoError(t, latest.LoadCheckpoint(filepath.Join(tempDir, "latest.json")))
+	assert.Equal(t, "child", latest.GetGlobalBest().ID)
+}
+
+func TestProgramDatabase_SaveCheckpointAsyncReportsErrors(t *testing.T) {
+	// The checkpoint directory cannot be created under a regular file
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+
+	db := New(types.DatabaseConfig{NumIslands: 1}, filepath.Join(file, "checkpoints"))
+	reported := make(chan error, 1)
+	db.SaveCheckpointAsync(1, func(err error) { reported <- err })
+
+	assert.Error(t, <-reported)
+	assert.Error(t, db.WaitCheckpoints())
+	assert.NoError(t, db.WaitCheckpoints(), "errors are reported once")
+}
+
+func TestProgramDatabase_ImportCheckpointRemapsFeatures(t *testing.T) {
+	tempDir := t.TempDir()
+	source := New(types.DatabaseConfig{
+		NumIslands:     3,
+		GridDimensions: []string{"complexity", "custom"},
+		GridResolution: map[string]int{"complexity": 5, "custom": 5},
+	}, tempDir)
+	require.NoError(t, source.AddProgram(&types.Program{ID: "root", Score: 0.2, Features: []float64{0.1, 0.9}, IslandID: 2}, 1))
+	require.NoError(t, source.AddProgram(&types.Program{ID: "child", ParentID: "root", Score: 0.6, Features: []float64{0.3, 0.7}, IslandID: 2}, 2))
+	require.NoError(t, source.SaveCheckpoint(2))
+
+	checkpoint, err := ReadCheckpoint(filepath.Join(tempDir, "checkpoint_2.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"complexity", "custom"}, CheckpointDimensions(checkpoint))
+
+	// Shared dimensions move to their new position, new ones are extracted
+	target := New(types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"custom", "score"},
+		GridResolution: map[string]int{"custom": 5, "score": 5},
+		FeatureScaling: map[string]string{"custom": "quantile"},
+	}, "")
+	require.NoError(t, target.CheckDimensions(CheckpointDimensions(checkpoint)))
+	require.NoError(t, target.ImportCheckpoint(checkpoint))
+
+	child, exists := target.GetProgram("child")
+	require.True(t, exists)
+	assert.Equal(t, 0, child.IslandID, "island 2 wraps around to island 0")
+
+	// Carried features were scaled by the source and are not scaled again
+	for _, id := range []string{"root", "child"} {
+		saved, _ := source.GetProgram(id)
+		imported, _ := target.GetProgram(id)
+		assert.Equal(t, saved.Features[1], imported.Features[0], id)
+	}
+	root, exists := target.GetProgram("root")
+	require.True(t, exists)
+	assert.Equal(t, 1, root.Children)
+	assert.Equal(t, "child", target.GetGlobalBest().ID)
+	assert.NoError(t, target.CheckChampion())
+
+	// A dimension with no source and no extractor cannot be mapped
+	other := New(types.DatabaseConfig{NumIslands: 1, GridDimensions: []string{"custom", "diversity"}}, "")
+	assert.Error(t, other.CheckDimensions(CheckpointDimensions(checkpoint)))
+	assert.Error(t, other.ImportCheckpoint(checkpoint))
+}
+
+func TestProgramDatabase_JSONLArchive(t *testing.T) {
+	source := New(types.DatabaseConfig{
+		NumIslands:     3,
+		GridDimensions: []string{"complexity", "custom"},
+		GridResolution: map[string]int{"complexity": 5, "custom": 5},
+	}, "")
+	created := time.Now()
+	require.NoError(t, source.AddProgram(&types.Program{ID: "root", Code: "a", Score: -2, Features: []float64{0.1, 0.9}, IslandID: 2, CreatedAt: created}, 1))
+	require.NoError(t, source.AddProgram(&types.Program{
+		ID: "child", Code: "b", ParentID: "root", Generation: 1, Score: -1,
+		Metrics: map[string]float64{"loss": 1}, Features: []float64{0.3, 0.7}, IslandID: 2,
+		Changes: "tweak", CreatedAt: created.Add(time.Second),
+	}, 2))
+
+	path := filepath.Join(t.TempDir(), "programs.jsonl")
+	written, err := source.ExportJSONL(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, written)
+
+	// One record per line, parents first, features keyed by dimension
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var record ProgramRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "child", record.ID)
+	assert.Equal(t, "root", record.ParentID)
+	assert.Equal(t, map[string]float64{"loss": 1}, record.Metrics)
+	assert.Contains(t, record.Features, "custom")
+
+	// A database with another grid places the programs afresh
+	target := New(types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"custom", "score"},
+		GridResolution: map[string]int{"custom": 5, "score": 5},
+		FeatureScaling: map[string]string{"custom": "quantile"},
+	}, "")
+	imported, err := target.ImportJSONL(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+	child, exists := target.GetProgram("child")
+	require.True(t, exists)
+	assert.Equal(t, "tweak", child.Changes)
+	assert.Equal(t, 0, child.IslandID, "island 2 wraps around to island 0")
+	root, exists := target.GetProgram("root")
+	require.True(t, exists)
+	assert.Equal(t, 1, root.Children)
+	assert.Equal(t, "child", target.GetGlobalBest().ID)
+	assert.NoError(t, target.CheckChampion())
+
+	// Merging the same archive again adds nothing
+	imported, err = target.ImportJSONL(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, imported)
+	assert.Equal(t, int64(2), target.GetStats().TotalEvaluations)
+
+	// A dimension with no value and no extractor cannot be placed
+	other := New(types.DatabaseConfig{NumIslands: 1, GridDimensions: []string{"custom", "diversity"}}, "")
+	_, err = other.ImportJSONL(path)
+	assert.Error(t, err)
+}
+
+func TestProgramDatabase_AdaptiveMigration(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 10},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+		MigrationRate:  0.5,
+		AdaptiveMigration: types.AdaptiveMigrationConfig{
+			Enabled:     true,
+			Window:      2,
+			MinRate:     0,
+			MaxRate:     1,
+			TargetSlope: 0.1,
+		},
+	}
+	db := New(config, "")
+
+	// Island 0 improves every generation while island 1 stalls, holding
+	// the champion so island 0's best is free to migrate
+	for generation := 0; generation < 3; generation++ {
+		score := 0.5 + 0.2*float64(generation)
+		require.NoError(t, db.AddProgram(&types.Program{ID: fmt.Sprintf("improving%d", generation), Score: score, Features: []float64{0.1 * float64(generation+1)}, IslandID: 0}, generation))
+		require.NoError(t, db.AddProgram(&types.Program{ID: fmt.Sprintf("stalled%d", generation), Score: 0.95, Features: []float64{0.1 * float64(generation+1)}, IslandID: 1}, generation))
+		db.UpdateGeneration()
+	}
+
+	stats := db.GetStats()
+	require.Len(t, stats.Migration, 2)
+	assert.False(t, stats.Migration[0].Stagnating)
+	assert.InDelta(t, 0.2, stats.Migration[0].Slope, 1e-9)
+	assert.Equal(t, 0.0, stats.Migration[0].Rate, "an improving island stays isolated")
+	assert.True(t, stats.Migration[1].Stagnating)
+	assert.Equal(t, 1.0, stats.Migration[1].Rate, "a stalled island takes in every candidate")
+
+	require.NoError(t, db.MigratePrograms())
+	for id := range db.islands[1].Programs {
+		assert.NotContains(t, db.islands[0].Programs, id, "island 0 received a migrant")
+	}
+	_, received := db.islands[1].Programs["improving2"]
+	assert.True(t, received, "island 1 did not receive island 0's candidates")
+
+	// Without enough history the configured rate applies
+	fresh := New(config, "")
+	assert.Equal(t, 0.5, fresh.migration(fresh.islands[0]).Rate)
+}
+
+func TestLineDistance(t *testing.T) {
+	assert.Equal(t, 0.0, LineDistance("a\nb\nc", "a\nb\nc"))
+	assert.Equal(t, 0.0, LineDistance("a\n  b\nc", "a\nb\nc"), "indentation is not a difference")
+	assert.InDelta(t, 1.0/3, LineDistance("a\nb\nc", "a\nx\nc"), 1e-9)
+	assert.InDelta(t, 0.25, LineDistance("a\nb\nc", "a\nb\nc\nd"), 1e-9)
+	assert.Equal(t, 1.0, LineDistance("a\nb", "c\nd"))
+}
+
+func TestProgramDatabase_GetIslandDiversity(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 4},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+
+	now := time.Now()
+	require.NoError(t, db.AddProgram(&types.Program{ID: "a", Code: "a\nb", Score: 0.2, Features: []float64{0.1}, IslandID: 0, CreatedAt: now.Add(-3 * time.Hour)}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "b", Code: "c\nd", Score: 0.6, Features: []float64{0.9}, IslandID: 0, CreatedAt: now.Add(-time.Hour)}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "c", Code: "a\nb", Score: 0.5, Features: []float64{0.5}, IslandID: 1, CreatedAt: now}, 2))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "d", Code: "a\nb", Score: 0.5, Features: []float64{0.5}, IslandID: 1, CreatedAt: now}, 3))
+
+	diversity := db.GetIslandDiversity()
+	require.Len(t, diversity, 2)
+
+	varied := diversity[0]
+	assert.Equal(t, 2, varied.Programs)
+	assert.Equal(t, 0.5, varied.Occupancy)
+	assert.InDelta(t, 0.04, varied.ScoreVariance, 1e-9)
+	assert.Equal(t, 1.0, varied.CodeDistance)
+	assert.InDelta(t, float64(time.Hour), float64(varied.Age.Min), float64(time.Minute))
+	assert.InDelta(t, float64(3*time.Hour), float64(varied.Age.Max), float64(time.Minute))
+	assert.InDelta(t, float64(2*time.Hour), float64(varied.Age.Mean), float64(time.Minute))
+
+	// Identical programs with equal scores have converged
+	converged := diversity[1]
+	assert.Equal(t, 1, converged.IslandID)
+	assert.Zero(t, converged.ScoreVariance)
+	assert.Zero(t, converged.CodeDistance)
+}
+
+func TestProgramDatabase_QDHistory(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 4},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+
+	require.NoError(t, db.AddProgram(&types.Program{ID: "a", Score: 0.2, Features: []float64{0.1}, IslandID: 0}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "b", Score: 0.5, Features: []float64{0.9}, IslandID: 0}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "c", Score: 0.4, Features: []float64{0.5}, IslandID: 1}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "d", Score: 0.3, Features: []float64{0.5}, IslandID: 0}, 2))
+
+	// Programs added to an island in one iteration leave one sample
+	history := db.GetStats().QDHistory
+	require.Len(t, history, 3)
+	assert.Equal(t, types.QDSample{Iteration: 0, IslandID: 0, QDScore: 0.7, Coverage: 0.5}, history[0])
+	assert.Equal(t, 1, history[1].IslandID)
+	assert.InDelta(t, 0.4, history[1].QDScore, 1e-9)
+	assert.InDelta(t, 1.0, history[2].QDScore, 1e-9)
+	assert.Equal(t, 0.75, history[2].Coverage)
+
+	path := filepath.Join(t.TempDir(), "qd.csv")
+	require.NoError(t, db.ExportQDHistory(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "iteration,island,qd_score,coverage", lines[0])
+	assert.Equal(t, "0,0,0.7,0.5", lines[1])
+}
+
+func TestProgramDatabase_History(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 4},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, tempDir)
+
+	require.NoError(t, db.AddProgram(&types.Program{ID: "a", Score: 0.2, Features: []float64{0.1}, IslandID: 0}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "b", Score: 0.6, Features: []float64{0.9}, IslandID: 0}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "f", Score: 0.9, Features: []float64{0.5}, IslandID: 0, Failed: true}, 0))
+	db.IncrementIslandGeneration(0)
+	db.IncrementIslandGeneration(1)
+
+	history := db.GetHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, 0, history[0].IslandID)
+	assert.Equal(t, 1, history[0].Generation)
+	assert.Equal(t, 0.6, history[0].BestScore)
+	assert.InDelta(t, 0.4, history[0].AvgScore, 1e-9)
+	assert.Equal(t, 3, history[0].Programs)
+	assert.Equal(t, 0.75, history[0].Occupancy)
+	assert.False(t, history[0].Time.IsZero())
+
+	// An empty island has no best score yet
+	assert.Equal(t, types.HistorySample{IslandID: 1, Generation: 1, Time: history[1].Time}, history[1])
+
+	// A resumed run continues the timeline
+	require.NoError(t, db.SaveCheckpoint(1))
+	resumed := New(config, tempDir)
+	require.NoError(t, resumed.LoadCheckpoint(filepath.Join(tempDir, "checkpoint_1.json")))
+	resumed.UpdateGeneration()
+	resumedHistory := resumed.GetHistory()
+	require.Len(t, resumedHistory, 4)
+	assert.Equal(t, history[0].BestScore, resumedHistory[0].BestScore)
+	assert.True(t, history[0].Time.Equal(resumedHistory[0].Time))
+	assert.Equal(t, 2, resumedHistory[2].Generation)
+	assert.Equal(t, 0.6, resumedHistory[2].BestScore)
+}
+
+func TestProgramDatabase_ExportLineageDOT(t *testing.T) {
+	db := New(types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 4},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "root", Score: 0.1, Features: []float64{0.1}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "child", ParentID: "root", Score: 0.9, Features: []float64{0.5}, Generation: 1}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "broken", ParentID: "root", Score: 2, Failed: true, Features: []float64{0.9}, Generation: 1}, 2))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "orphan", ParentID: "gone", Score: 0.5, Features: []float64{0.3}, Generation: 3}, 3))
+
+	var out bytes.Buffer
+	require.NoError(t, db.ExportLineageDOT(&out))
+	dot := out.String()
+
+	assert.True(t, strings.HasPrefix(dot, "digraph lineage {\n"))
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+	assert.Contains(t, dot, `"root" [label="root\ngen 0  score 0.1", fillcolor="0.000 0.600 0.950"];`)
+	assert.Contains(t, dot, `"child" [label="child\ngen 1  score 0.9", fillcolor="0.333 0.600 0.950", penwidth=3];`)
+	assert.Contains(t, dot, `"broken" [label="broken\ngen 1  score 2\nfailed", fillcolor="0.000 0.000 0.750"];`)
+	assert.Contains(t, dot, `"root" -> "child" [color="0.333 0.600 0.950"];`)
+	assert.Contains(t, dot, `"root" -> "broken"`)
+
+	// Parents no longer in the database are left out
+	assert.Contains(t, dot, `"orphan" [`)
+	assert.NotContains(t, dot, `"gone"`)
+}
+
+func TestProgramDatabase_CVTArchive(t *testing.T) {
+	tempDir := t.TempDir()
+	dims := []string{"a", "b", "c", "d", "e"}
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: dims,
+		Archive:        constants.ArchiveCVT,
+		CVT:            types.CVTConfig{Cells: 16, Samples: 2000, Iterations: 10},
+	}
+	db := New(config, tempDir)
+	island := db.islands[0]
+	require.Len(t, island.Grid.Centroids, 16)
+	assert.Equal(t, 16, island.Grid.TotalCells)
+
+	// Every island and every rebuild gets the same cells
+	assert.Equal(t, island.Grid.Centroids, NewIsland(0, config).Grid.Centroids)
+
+	// Opposite corners of the feature space fall into different cells
+	low := island.calculateCellKey([]float64{0, 0, 0, 0, 0})
+	high := island.calculateCellKey([]float64{1, 1, 1, 1, 1})
+	assert.True(t, strings.HasPrefix(low, "cvt:"))
+	assert.NotEqual(t, low, high)
+
+	// Adding programs never fills more cells than the archive has
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		features := make([]float64, len(dims))
+		for dim := range features {
+			features[dim] = rng.Float64()
+		}
+		require.NoError(t, db.AddProgram(&types.Program{ID: fmt.Sprintf("p%d", i), Score: rng.Float64(), Features: features}, i))
+	}
+	assert.LessOrEqual(t, island.Grid.FilledCells, 16)
+	assert.Greater(t, island.Grid.FilledCells, 8)
+	assert.False(t, island.RebalanceBins(), "a CVT archive has no bins to rebalance")
+
+	// A resumed run keeps its cells
+	require.NoError(t, db.SaveCheckpoint(1))
+	db2 := New(config, tempDir)
+	require.NoError(t, db2.LoadCheckpoint(filepath.Join(tempDir, "checkpoint_1.json")))
+	assert.Equal(t, island.Grid.Centroids, db2.islands[0].Grid.Centroids)
+	for key, elite := range db2.islands[0].Grid.Cells {
+		assert.Equal(t, key, db2.islands[0].calculateCellKey(elite.Features))
+	}
+}
+
+func TestProgramDatabase_StaleScores(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+
+	// Two programs share a cell; the better one holds it and is the champion
+	require.NoError(t, db.AddProgram(&types.Program{ID: "a", Score: 0.9, Fitness: 0.9, Features: []float64{0.5}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "b", Score: 0.5, Fitness: 0.5, Features: []float64{0.5}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "c", Score: 0.3, Fitness: 0.3, Features: []float64{0.5}}, 2))
+	assert.Equal(t, "a", db.GetGlobalBest().ID)
+
+	assert.Equal(t, 3, db.MarkScoresStale(3, "new-hash"))
+	stats := db.GetStats()
+	assert.Equal(t, 3, stats.StalePrograms)
+	require.Len(t, stats.EvaluatorChanges, 1)
+	assert.Equal(t, "new-hash", stats.EvaluatorChanges[0].Hash)
+	assert.Equal(t, 3, stats.EvaluatorChanges[0].StalePrograms)
+
+	stale := db.StaleElites()
+	require.NotEmpty(t, stale)
+	assert.Equal(t, "a", stale[0].ID)
+
+	// The new evaluator likes the champion less than a rival
+	require.NoError(t, db.Rescore("a", 0.1, map[string]float64{"speed": 1}))
+	require.NoError(t, db.Rescore("c", 0.6, nil))
+	best := db.GetGlobalBest()
+	assert.Equal(t, "c", best.ID)
+	assert.False(t, best.Stale)
+	assert.Equal(t, 0.6, best.Fitness)
+	assert.Same(t, db.globalBest.Load(), db.islands[0].GetFromGrid(best.Features))
+	assert.Equal(t, "c", db.islands[0].BestID)
+	assert.NoError(t, db.CheckChampion())
+	assert.Equal(t, 1, db.GetStats().StalePrograms)
+
+	assert.Error(t, db.Rescore("missing", 1, nil))
+}
+
+func TestProgramDatabase_RecencyDecay(t *testing.T) {
+	newDB := func(decay bool) *ProgramDatabase {
+		db := New(types.DatabaseConfig{
+			NumIslands:     1,
+			GridDimensions: []string{"complexity"},
+			GridResolution: map[string]int{"complexity": 5},
+			GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+			RecencyDecay:   types.RecencyDecayConfig{Enabled: decay, MaxDiscount: 0.05, HalfLife: 3600},
+		}, "")
+		// An elite last evaluated long ago, then a slightly worse fresh
+		// program in its cell
+		old := time.Now().Add(-100 * time.Hour)
+		require.NoError(t, db.AddProgram(&types.Program{ID: "old", Score: 0.8, Fitness: 0.8, Features: []float64{0.5}, CreatedAt: old}, 0))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "rival", Score: 0.79, Fitness: 0.79, Features: []float64{0.5}}, 1))
+		return db
+	}
+
+	db := newDB(false)
+	assert.Equal(t, "old", db.GetGlobalBest().ID)
+	assert.Equal(t, "old", db.islands[0].GetFromGrid([]float64{0.5}).ID)
+
+	db = newDB(true)
+	assert.Equal(t, "rival", db.GetGlobalBest().ID)
+	assert.Equal(t, "rival", db.islands[0].GetFromGrid([]float64{0.5}).ID)
+	// Island bests still go by raw score
+	assert.Equal(t, "old", db.islands[0].BestID)
+
+	// Re-validating the elite restores it
+	require.NoError(t, db.Rescore("old", 0.8, nil))
+	assert.Equal(t, "old", db.GetGlobalBest().ID)
+	assert.Equal(t, "old", db.islands[0].GetFromGrid([]float64{0.5}).ID)
+	assert.NoError(t, db.CheckChampion())
+}
+
+func TestProgramDatabase_FitnessSharing(t *testing.T) {
+	newDB := func(sharing bool) *ProgramDatabase {
+		db := New(types.DatabaseConfig{
+			NumIslands:         1,
+			GridDimensions:     []string{"x"},
+			GridResolution:     map[string]int{"x": 5},
+			GridBounds:         map[string][2]float64{"x": {0, 1}},
+			MaxProgramsPerCell: 4,
+			ParentSelection:    "epsilon_greedy",
+			FitnessSharing:     types.FitnessSharingConfig{Enabled: sharing, Distance: "code", Radius: 0.5},
+		}, "")
+		// One lineage of near-identical programs crowds out a weaker loner
+		lineage := "a\nb\nc\nd"
+		for idx, score := range []float64{0.9, 0.88, 0.87} {
+			id := fmt.Sprintf("lineage%d", idx)
+			require.NoError(t, db.AddProgram(&types.Program{ID: id, Code: lineage, Score: score, Fitness: score, Features: []float64{0.5}}, idx))
+		}
+		require.NoError(t, db.AddProgram(&types.Program{ID: "loner", Code: "w\nx\ny\nz", Score: 0.5, Fitness: 0.5, Features: []float64{0.5}}, 3))
+		return db
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	db := newDB(false)
+	assert.Equal(t, "lineage0", db.islands[0].sampleExploit(rng).ID)
+
+	// Each lineage member shares its fitness three ways
+	db = newDB(true)
+	island := db.islands[0]
+	assert.InDelta(t, 3, island.sharing.niche(db.programs["lineage0"], island.elites()), 1e-9)
+	assert.InDelta(t, 1, island.sharing.niche(db.programs["loner"], island.elites()), 1e-9)
+	assert.Equal(t, "loner", island.sampleExploit(rng).ID)
+
+	// Crowding lowers negative scores too
+	assert.Equal(t, -1.0, share(-0.5, 2))
+	assert.Equal(t, 0.25, share(0.5, 2))
+}
+
+func TestProgramDatabase_Elitism(t *testing.T) {
+	newDB := func(elitism bool) *ProgramDatabase {
+		db := New(types.DatabaseConfig{
+			NumIslands:     2,
+			GridDimensions: []string{"x"},
+			GridResolution: map[string]int{"x": 10},
+			GridBounds:     map[string][2]float64{"x": {0, 1}},
+			MigrationRate:  1,
+			Extinction:     types.ExtinctionConfig{Enabled: true, Patience: 2},
+			Elitism:        elitism,
+		}, "")
+		require.NoError(t, db.AddProgram(&types.Program{ID: "champion", Score: 0.9, Fitness: 0.9, Features: []float64{0.1}, IslandID: 0}, 0))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "best1", Score: 0.5, Fitness: 0.5, Features: []float64{0.5}, IslandID: 1}, 1))
+		require.NoError(t, db.AddProgram(&types.Program{ID: "runner", Score: 0.45, Fitness: 0.45, Features: []float64{0.9}, IslandID: 1}, 2))
+		return db
+	}
+
+	// Without elitism only the champion stays put
+	db := newDB(false)
+	db.migratePrograms()
+	assert.Equal(t, 0, db.programs["champion"].IslandID)
+	assert.NotContains(t, db.islands[1].Programs, "best1")
+
+	db = newDB(true)
+	db.migratePrograms()
+	assert.Equal(t, 0, db.programs["champion"].IslandID)
+	assert.Equal(t, 1, db.programs["best1"].IslandID)
+	assert.Equal(t, "best1", db.islands[1].BestID)
+	assert.Contains(t, db.islands[0].Programs, "runner")
+	assert.NoError(t, db.CheckChampion())
+
+	// Pinned programs cannot be replaced under their IDs
+	err := db.AddProgram(&types.Program{ID: "best1", Score: 0.1, Fitness: 0.1, Features: []float64{0.5}, IslandID: 1}, 3)
+	assert.ErrorContains(t, err, "pinned")
+	assert.Equal(t, 0.5, db.programs["best1"].Score)
+
+	// Every checkpoint writes the pinned programs
+	checkpoint := db.snapshot(3)
+	assert.Contains(t, checkpoint.Islands[0].Programs, "champion")
+	assert.Contains(t, checkpoint.Islands[1].Programs, "best1")
+
+	// Extinction wipes everything but the island's best
+	db = newDB(true)
+	for generation := 0; generation < 3; generation++ {
+		db.UpdateGeneration()
+	}
+	assert.Equal(t, []int{1}, db.ReseedStagnantIslands())
+	assert.Len(t, db.islands[1].Programs, 2)
+	assert.Contains(t, db.islands[1].Programs, "best1")
+	assert.NotContains(t, db.programs, "runner")
+	assert.NoError(t, db.CheckChampion())
+}
+
+func TestProgramDatabase_EnterPhase(t *testing.T) {
+	db := New(types.DatabaseConfig{
+		NumIslands:            3,
+		ParentSelection:       "uniform",
+		IslandParentSelection: map[int]string{1: "rank"},
+		IslandOverrides:       map[int]types.IslandOverride{2: {ParentSelection: "boltzmann"}},
+	}, "")
+
+	// Islands with a strategy of their own keep it
+	db.EnterPhase(types.PhaseConfig{Name: "exploit", ParentSelection: "tournament"})
+	assert.Equal(t, "tournament", db.islands[0].selection())
+	assert.Equal(t, "rank", db.islands[1].selection())
+	assert.Equal(t, "boltzmann", db.islands[2].selection())
+	assert.Equal(t, "exploit", db.GetStats().Phase)
+
+	db.EnterPhase(types.PhaseConfig{Name: "finalize"})
+	assert.Equal(t, "uniform", db.islands[0].selection())
+	assert.Equal(t, "finalize", db.GetStats().Phase)
+}
+
+func TestProgramDatabase_PopulationCap(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:         1,
+		GridDimensions:     []string{"complexity"},
+		GridResolution:     map[string]int{"complexity": 2},
+		GridBounds:         map[string][2]float64{"complexity": {0, 1}},
+		MaxProgramsPerCell: 2,
+		PopulationSize:     4,
+	}
+
+	// Programs added through the database are evicted from it as well
+	db := New(config, "")
+	for i := 0; i < 6; i++ {
+		require.NoError(t, db.AddProgram(&types.Program{ID: fmt.Sprintf("p%d", i), Score: float64(i) / 10, Features: []float64{0.5}}, i))
+	}
+	assert.Len(t, db.islands[0].Programs, 4)
+	assert.Len(t, db.programs, 4)
+	assert.Equal(t, int64(2), db.GetStats().Evicted)
+	assert.Equal(t, "p5", db.GetGlobalBest().ID)
+
+	base := time.Now()
+	evictedBy := func(policy string) string {
+		config.Eviction = policy
+		db := New(config, "")
+		island := db.islands[0]
+		for i, program := range []*types.Program{
+			{ID: "eliteA", Score: 0.9, Features: []float64{0}},
+			{ID: "memberA", Score: 0.85, Features: []float64{0}},
+			{ID: "outside", Score: 0.8, Features: []float64{0}},
+			{ID: "eliteB", Score: 0.6, Features: []float64{1}},
+			{ID: "memberB", Score: 0.3, Features: []float64{1}},
+		} {
+			program.CreatedAt = base.Add(time.Duration(i) * time.Second)
+			db.programs[program.ID] = program
+			island.Programs[program.ID] = program
+			island.AddToGrid(program)
+		}
+		require.Len(t, island.Grid.Members, 2)
+
+		require.Equal(t, 1, db.enforcePopulationCap(island))
+		for id := range db.programs {
+			_, kept := island.Programs[id]
+			require.True(t, kept)
+		}
+		for _, id := range []string{"eliteA", "memberA", "outside", "eliteB", "memberB"} {
+			if _, kept := island.Programs[id]; !kept {
+				return id
+			}
+		}
+		return ""
+	}
+
+	assert.Equal(t, "memberB", evictedBy(constants.EvictionLowestFitness))
+	assert.Equal(t, "outside", evictedBy(constants.EvictionNonEliteFirst), "programs outside the grid go before cell members")
+	assert.Equal(t, "memberA", evictedBy(constants.EvictionOldest), "the oldest program that is not an elite goes")
+
+	// Elites are kept even when they alone exceed the cap
+	island := NewIsland(0, config)
+	for i, feature := range []float64{0, 1} {
+		program := &types.Program{ID: fmt.Sprintf("e%d", i), Score: 0.5, Features: []float64{feature}}
+		island.Programs[program.ID] = program
+		island.AddToGrid(program)
+	}
+	assert.Empty(t, island.evictionCandidates(constants.EvictionLowestFitness, nil))
+
+	// The champion's ancestors are kept so its lineage stays whole
+	config = types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"x"},
+		GridResolution: map[string]int{"x": 10},
+		GridBounds:     map[string][2]float64{"x": {0, 1}},
+		PopulationSize: 3,
+	}
+	db = New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "root", Score: 0.1, Features: []float64{0.5}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "rival", Score: 0.5, Features: []float64{0.5}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "champion", ParentID: "root", Score: 0.9, Features: []float64{0.9}}, 2))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "late", Score: 0.3, Features: []float64{0.1}}, 3))
+	assert.Contains(t, db.programs, "root")
+	assert.NoError(t, db.CheckChampion())
+}
+
+func TestProgramDatabase_PruneKeepsChampionLineage(t *testing.T) {
+	db := New(types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"x"},
+		GridResolution: map[string]int{"x": 10},
+		GridBounds:     map[string][2]float64{"x": {0, 1}},
+	}, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "root", Score: 0.1, Features: []float64{0.5}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "rival", Score: 0.5, Features: []float64{0.5}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "loser", Score: 0.2, Features: []float64{0.5}}, 2))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "champion", ParentID: "root", Score: 0.9, Features: []float64{0.9}}, 3))
+
+	// Pruning everything evictable spares the champion's parent
+	assert.Equal(t, 2, db.Prune(1))
+	assert.NotContains(t, db.programs, "loser")
+	assert.NotContains(t, db.programs, "rival")
+	assert.Contains(t, db.programs, "root")
+	assert.NoError(t, db.CheckChampion())
+}
+
+func TestProgramDatabase_Compact(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"x"},
+		GridResolution: map[string]int{"x": 5},
+		GridBounds:     map[string][2]float64{"x": {0, 1}},
+		Compaction:     types.CompactionConfig{Threshold: 0.1},
+	}
+	db := New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "parent", Score: 0.4, Fitness: 0.4, Features: []float64{0.5}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "elite", ParentID: "parent", Score: 0.9, Fitness: 0.9, Features: []float64{0.5}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "weak", Score: 0.5, Fitness: 0.5, Features: []float64{0.5}}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "close", Score: 0.85, Fitness: 0.85, Features: []float64{0.5}}, 2))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "alone", Score: 0.3, Fitness: 0.3, Features: []float64{0.5}, IslandID: 1}, 3))
+
+	// Only the program trailing its cell elite by more than the threshold
+	// goes; the champion's parent stays for its lineage
+	assert.Equal(t, 1, db.Compact())
+	_, exists := db.GetProgram("weak")
+	assert.False(t, exists)
+	for _, id := range []string{"parent", "elite", "close", "alone"} {
+		_, exists := db.GetProgram(id)
+		assert.True(t, exists, id)
+	}
+	assert.Len(t, db.islands[0].Programs, 3)
+	assert.Equal(t, int64(1), db.GetStats().Compacted)
+	assert.NoError(t, db.CheckChampion())
+	assert.Equal(t, 0, db.Compact())
+
+	// Growing past the limit compacts automatically
+	config.Compaction = types.CompactionConfig{MaxPrograms: 2}
+	db = New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "elite", Score: 0.9, Fitness: 0.9, Features: []float64{0.5}}, 0))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "a", Score: 0.5, Fitness: 0.5, Features: []float64{0.5}}, 1))
+	assert.Equal(t, int64(0), db.GetStats().Compacted)
+	require.NoError(t, db.AddProgram(&types.Program{ID: "b", Score: 0.6, Fitness: 0.6, Features: []float64{0.5}}, 2))
+	assert.Equal(t, int64(2), db.GetStats().Compacted)
+	assert.Len(t, db.islands[0].Programs, 1)
+}
+
+func TestProgramDatabase_ColdStorage(t *testing.T) {
+	outputDir := t.TempDir()
+	db := New(types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+		PopulationSize: 2,
+		OutputDir:      outputDir,
+		ColdStorage:    true,
+	}, "")
+	path := db.ColdStoragePath()
+	assert.Equal(t, filepath.Join(outputDir, constants.ColdStorageFile), path)
+
+	// Each newcomer takes the single-program cell; the third overflows the
+	// population and evicts the weakest
+	for i, score := range []float64{0.5, 0.7, 0.9} {
+		require.NoError(t, db.AddProgram(&types.Program{ID: fmt.Sprintf("p%d", i), Code: "code", Score: score, Features: []float64{0.5}}, i))
+	}
+
+	records, err := LoadColdStorage(path)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for i, want := range []struct{ reason, id string }{
+		{constants.ColdReasonReplaced, "p0"},
+		{constants.ColdReasonReplaced, "p1"},
+		{constants.ColdReasonEvicted, "p0"},
+	} {
+		assert.Equal(t, want.reason, records[i].Reason)
+		assert.Equal(t, want.id, records[i].Program.ID)
+		assert.Equal(t, "code", records[i].Program.Code)
+	}
+
+	// Disabled cold storage writes nothing
+	db = New(types.DatabaseConfig{NumIslands: 1, OutputDir: outputDir}, "")
+	assert.Empty(t, db.ColdStoragePath())
+}
+
+func TestProgramDatabase_Query(t *testing.T) {
+	db := New(types.DatabaseConfig{NumIslands: 2}, "")
+	base := time.Now()
+	for i, program := range []*types.Program{
+		{ID: "a", Score: 0.9, Generation: 3, IslandID: 0},
+		{ID: "b", Score: 0.5, Generation: 1, IslandID: 1},
+		{ID: "c", Score: 0.7, Generation: 2, IslandID: 1},
+		{ID: "d", Score: 0.7, Generation: 0, IslandID: 0},
+	} {
+		program.Features = []float64{0.5, 0.5}
+		program.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, db.AddProgram(program, i))
+	}
+
+	assert.Equal(t, []string{"a", "c"}, programIDs(db.GetTopK(2)))
+	assert.Equal(t, []string{"a", "c", "d", "b"}, programIDs(db.GetTopK(10)))
+	assert.Empty(t, db.GetTopK(0))
+
+	minScore, island := 0.6, 1
+	assert.Equal(t, []string{"a", "c", "d"}, programIDs(db.Query(Query{MinScore: &minScore})))
+	assert.Equal(t, []string{"c", "b"}, programIDs(db.Query(Query{Island: &island})))
+	assert.Equal(t, []string{"c", "b"}, programIDs(db.Query(Query{GenerationRange: &[2]int{1, 2}})))
+	assert.Equal(t, []string{"c", "d"}, programIDs(db.Query(Query{CreatedAfter: base.Add(time.Minute)})))
+	assert.Equal(t, []string{"c"}, programIDs(db.Query(Query{MinScore: &minScore, Island: &island, Limit: 1})))
+}
+
+func TestProgramDatabase_TagsAndMetadata(t *testing.T) {
+	for _, format := range []string{constants.CheckpointFormatJSON, constants.CheckpointFormatGob} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			config := types.DatabaseConfig{NumIslands: 1, CheckpointFormat: format}
+			db := New(config, dir)
+			require.NoError(t, db.AddProgram(&types.Program{
+				ID: "a", Score: 0.9, Features: []float64{0.5, 0.5},
+				Tags:     []string{"baseline"},
+				Metadata: map[string]any{"model": "gpt-4", "prompt": map[string]any{"template": "diff", "version": 2.0}},
+			}, 0))
+			require.NoError(t, db.AddProgram(&types.Program{ID: "b", Score: 0.5, Features: []float64{0.5, 0.5}}, 1))
+
+			require.NoError(t, db.TagProgram("a", "baseline", "reviewed"))
+			require.NoError(t, db.TagProgram("b", "reviewed"))
+			require.NoError(t, db.SetMetadata("b", "model", "llama"))
+			assert.Error(t, db.TagProgram("missing", "x"))
+
+			assert.Equal(t, []string{"a", "b"}, programIDs(db.Query(Query{Tags: []string{"reviewed"}})))
+			assert.Equal(t, []string{"a"}, programIDs(db.Query(Query{Tags: []string{"reviewed", "baseline"}})))
+			assert.Equal(t, []string{"b"}, programIDs(db.Query(Query{Metadata: map[string]string{"model": "llama"}})))
+
+			// Copies do not share nested metadata with the stored program
+			copied := db.Query(Query{Tags: []string{"baseline"}})[0]
+			copied.Metadata["prompt"].(map[string]any)["template"] = "full"
+			copied.Tags[0] = "changed"
+			stored, _ := db.GetProgram("a")
+			assert.Equal(t, "diff", stored.Metadata["prompt"].(map[string]any)["template"])
+			assert.Equal(t, []string{"baseline", "reviewed"}, stored.Tags)
+
+			// Both survive a checkpoint
+			require.NoError(t, db.SaveCheckpoint(1))
+			restored := New(config, dir)
+			_, err := restored.LoadLatest(dir)
+			require.NoError(t, err)
+			program, exists := restored.GetProgram("a")
+			require.True(t, exists)
+			assert.Equal(t, []string{"baseline", "reviewed"}, program.Tags)
+			assert.Equal(t, map[string]any{"template": "diff", "version": 2.0}, program.Metadata["prompt"])
+			assert.Equal(t, []string{"a"}, programIDs(restored.Query(Query{Metadata: map[string]string{"model": "gpt-4"}})))
+		})
+	}
+}
+
+func TestProgramDatabase_ConcurrentIslands(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:        4,
+		GridDimensions:    []string{"complexity"},
+		GridResolution:    map[string]int{"complexity": 5},
+		GridBounds:        map[string][2]float64{"complexity": {0, 1}},
+		MigrationInterval: 1,
+		MigrationRate:     0.5,
+		RandomSeed:        1,
+	}
+	db := New(config, "")
+
+	// An island busy with its own work does not hold up the others
+	db.islands[0].mu.Lock()
+	added := make(chan error)
+	go func() {
+		added <- db.AddProgram(&types.Program{ID: "free", Score: 0.1, Features: []float64{0.5}, IslandID: 1}, 0)
+	}()
+	select {
+	case err := <-added:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("adding to island 1 waited for island 0")
+	}
+	db.islands[0].mu.Unlock()
+
+	const perWorker = 50
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			islandID := worker % config.NumIslands
+			for n := 0; n < perWorker; n++ {
+				program := &types.Program{
+					ID:       fmt.Sprintf("w%d-%d", worker, n),
+					Score:    float64(worker*perWorker+n) / 1000,
+					Features: []float64{float64(n%10) / 10},
+					IslandID: islandID,
+				}
+				if parent, err := db.SampleFromIsland(islandID); err == nil {
+					program.ParentID = parent.ID
+				}
+				assert.NoError(t, db.AddProgram(program, n))
+				db.IncrementIslandGeneration(islandID)
+			}
+		}(worker)
+	}
+
+	// Readers and migrations run alongside the writers
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			db.GetStats()
+			db.GetTopK(5)
+			db.GetGlobalBest()
+			_, err := db.SampleMultiple(3)
+			assert.NoError(t, err)
+			if db.ShouldMigrate() {
+				assert.NoError(t, db.MigratePrograms())
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	stats := db.GetStats()
+	assert.Equal(t, int64(8*perWorker+1), stats.TotalEvaluations)
+	assert.Equal(t, float64(8*perWorker-1)/1000, stats.BestScore)
+	assert.Equal(t, "w7-49", db.GetGlobalBest().ID)
+	assert.NoError(t, db.CheckChampion())
+}
+
+func TestProgramDatabase_Hooks(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:        2,
+		MigrationInterval: 1,
+		MigrationRate:     1,
+		CopyMigrants:      true,
+		GridDimensions:    []string{"complexity"},
+		GridResolution:    map[string]int{"complexity": 5},
+		GridBounds:        map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, t.TempDir())
+
+	var bests []string
+	var migrations []types.MigrationEvent
+	var checkpoints []string
+	db.OnNewGlobalBest(func(program *types.Program, iteration int) {
+		// Subscribers run outside the database locks
+		assert.Equal(t, program.ID, db.GetGlobalBest().ID)
+		assert.NotZero(t, db.GetStats().TotalEvaluations)
+		bests = append(bests, fmt.Sprintf("%s@%d", program.ID, iteration))
+	})
+	db.OnMigration(func(event types.MigrationEvent) {
+		assert.NotEmpty(t, db.MigrationHistory())
+		migrations = append(migrations, event)
+	})
+	db.OnCheckpoint(func(iteration int, path string) {
+		assert.FileExists(t, path)
+		checkpoints = append(checkpoints, fmt.Sprintf("%d:%s", iteration, filepath.Base(path)))
+	})
+
+	for i, score := range []float64{0.5, 0.3, 0.9} {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("p%d", i),
+			Code:     fmt.Sprintf("func p%d() {}", i),
+			Score:    score,
+			Features: []float64{float64(i) * 0.3},
+			IslandID: 0,
+		}, i+1))
+	}
+	assert.Equal(t, []string{"p0@1", "p2@3"}, bests)
+
+	require.NoError(t, db.MigratePrograms())
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, db.MigrationHistory(), migrations)
+
+	require.NoError(t, db.SaveCheckpoint(3))
+	db.SaveCheckpointAsync(4, nil)
+	require.NoError(t, db.WaitCheckpoints())
+	assert.Equal(t, []string{"3:checkpoint_3" + db.checkpointExt(), "4:checkpoint_4" + db.checkpointExt()}, checkpoints)
+}
+
+func TestProgramDatabase_ReseedsStagnantIslands(t *testing.T) {
+	outputDir := t.TempDir()
+	db := New(types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+		OutputDir:      outputDir,
+		ColdStorage:    true,
+		Extinction:     types.ExtinctionConfig{Enabled: true, Patience: 2},
+	}, "")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.AddProgram(&types.Program{ID: fmt.Sprintf("weak%d", i), Code: fmt.Sprintf("w%d", i), Score: 0.1 * float64(i), Features: []float64{0.3 * float64(i)}, IslandID: 0}, 1))
+	}
+	require.NoError(t, db.AddProgram(&types.Program{ID: "best", Code: "b", Score: 0.9, Features: []float64{0.5}, IslandID: 1}, 1))
+
+	// Neither island improves after the first generation; only the one
+	// without the global best goes extinct
+	db.UpdateGeneration()
+	db.UpdateGeneration()
+	assert.Empty(t, db.StagnantIslands())
+	db.UpdateGeneration()
+	assert.Equal(t, []int{0}, db.StagnantIslands())
+
+	assert.Equal(t, []int{0}, db.ReseedStagnantIslands())
+	assert.Empty(t, db.StagnantIslands())
+
+	island := db.islands[0]
+	require.Len(t, island.Programs, 1)
+	for _, seed := range island.Programs {
+		assert.Equal(t, "best", seed.ParentID)
+		assert.Equal(t, "b", seed.Code)
+		assert.Equal(t, seed, island.BestProgram)
+	}
+	_, exists := db.GetProgram("weak0")
+	assert.False(t, exists)
+	assert.Equal(t, "best", db.GetGlobalBest().ID)
+	assert.Equal(t, int64(1), db.GetStats().Extinctions)
+	assert.NoError(t, db.CheckChampion())
+
+	// The wiped island's elites went to cold storage
+	records, err := LoadColdStorage(db.ColdStoragePath())
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for _, record := range records {
+		assert.Equal(t, constants.ColdReasonExtinct, record.Reason)
+		assert.Equal(t, 0, record.IslandID)
+	}
+}
+
+func TestProgramDatabase_ReseedKeepsChampionLineage(t *testing.T) {
+	db := New(types.DatabaseConfig{
+		NumIslands: 2,
+		Extinction: types.ExtinctionConfig{Enabled: true, Patience: 1},
+	}, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "root", Code: "r", Score: 0.2, IslandID: 0}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "other", Code: "o", Score: 0.1, IslandID: 0}, 1))
+	require.NoError(t, db.AddProgram(&types.Program{ID: "best", Code: "b", Score: 0.9, ParentID: "root", IslandID: 1}, 1))
+
+	db.UpdateGeneration()
+	db.UpdateGeneration()
+	assert.Equal(t, []int{0}, db.ReseedStagnantIslands())
+
+	// The champion's parent survives on the wiped island; the rest goes
+	_, exists := db.GetProgram("root")
+	assert.True(t, exists)
+	_, exists = db.GetProgram("other")
+	assert.False(t, exists)
+	assert.Len(t, db.islands[0].Programs, 2)
+	assert.NoError(t, db.CheckChampion())
+}
+
+func TestProgramDatabase_Merge(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}},
+	}
+	db := New(config, "")
+	other := New(config, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "mine", Code: "a", Score: 0.4, Features: []float64{0.5}}, 1))
+	require.NoError(t, other.AddProgram(&types.Program{ID: "theirs", Code: "b", Score: 0.8, Features: []float64{0.5}}, 1))
+	require.NoError(t, other.AddProgram(&types.Program{ID: "child", Code: "c", ParentID: "theirs", Score: 0.3, Features: []float64{0.5}}, 2))
+
+	merged, err := db.Merge(other, "run-b")
+	require.NoError(t, err)
+	assert.Equal(t, 2, merged)
+
+	// The shared cell goes to the fitter program, from whichever run
+	island := db.islands[0]
+	elite := island.Grid.Cells[island.calculateCellKey([]float64{0.5})]
+	assert.Equal(t, "theirs", elite.ID)
+	assert.Equal(t, "run-b", elite.Origin)
+	assert.Equal(t, "theirs", db.GetGlobalBest().ID)
+	mine, exists := db.GetProgram("mine")
+	require.True(t, exists)
+	assert.Empty(t, mine.Origin)
+	theirs, exists := db.GetProgram("theirs")
+	require.True(t, exists)
+	assert.Equal(t, 1, theirs.Children)
+	assert.Equal(t, int64(3), db.GetStats().TotalEvaluations)
+	assert.NoError(t, db.CheckChampion())
+
+	// The other database is left alone, and merging it again adds nothing
+	original, exists := other.GetProgram("theirs")
+	require.True(t, exists)
+	assert.Empty(t, original.Origin)
+	merged, err = db.Merge(other, "run-b")
+	require.NoError(t, err)
+	assert.Equal(t, 0, merged)
+	assert.Equal(t, int64(3), db.GetStats().TotalEvaluations)
+}
+
+func TestProgramDatabase_NoveltyArchive(t *testing.T) {
+	tempDir := t.TempDir()
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+		NoveltyArchive: types.NoveltyArchiveConfig{Enabled: true, Neighbors: 1, Size: 2},
+	}
+	db := New(config, tempDir)
+	assert.Equal(t, 1.0, db.BehaviorNovelty([]float64{0, 0}, nil))
+
+	add := func(id string, behavior []float64, failed bool) {
+		require.NoError(t, db.AddProgram(&types.Program{ID: id, Code: id, Score: 0.5, Failed: failed, Behavior: behavior, Features: []float64{0.5}}, 1))
+	}
+	add("origin", []float64{0, 0}, false)
+	add("far", []float64{3, 4}, false)
+	add("failed", []float64{100, 100}, true)
+
+	// Novelty is the distance to the nearest behavior relative to the
+	// sparsest seen; failed programs are not archived
+	assert.Equal(t, 0.0, db.BehaviorNovelty([]float64{0, 0}, nil))
+	assert.InDelta(t, 0.5, db.BehaviorNovelty([]float64{0, 2.5}, nil), 1e-9)
+	assert.Equal(t, 1.0, db.BehaviorNovelty([]float64{50, 50}, nil))
+	assert.Equal(t, 1.0, db.BehaviorNovelty(nil, []float64{0.5}), "no descriptor of that length yet")
+
+	// The oldest behavior makes way once the archive is full, and the
+	// archive outlives the programs, surviving a checkpoint
+	add("corner", []float64{10, 10}, false)
+	want := 5 / math.Hypot(7, 6)
+	assert.InDelta(t, want, db.BehaviorNovelty([]float64{0, 0}, nil), 1e-9)
+	require.NoError(t, db.SaveCheckpoint(1))
+
+	restored := New(config, tempDir)
+	require.NoError(t, restored.LoadCheckpoint(filepath.Join(tempDir, "checkpoint_1.json")))
+	assert.InDelta(t, want, restored.BehaviorNovelty([]float64{0, 0}, nil), 1e-9)
+
+	// Without an archive every behavior is novel
+	config.NoveltyArchive.Enabled = false
+	assert.Equal(t, 1.0, New(config, "").BehaviorNovelty([]float64{0, 0}, nil))
+}
+
+func TestProgramDatabase_CodeFeatures(t *testing.T) {
+	code := `package main
+
+// abs returns the magnitude of x
+func abs(x int) int {
+	if x < 0 && x != -1 {
+		return -x
+	}
+	return x
+}
+
+func sum(xs []int) int {
+	total := 0
+	for _, x := range xs {
+		total += abs(x)
+	}
+	return total
+}
+`
+	extractors := codeFeatureExtractors()
+	program := &types.Program{Code: code}
+	assert.Equal(t, 14.0, extractors[FeatureLinesOfCode](program))
+	assert.Equal(t, 2.0, extractors[FeatureFunctions](program))
+	// Each function contributes one, plus the if, the && and the range
+	assert.Equal(t, 5.0, extractors[FeatureCyclomaticComplexity](program))
+	assert.Greater(t, extractors[FeatureASTNodes](program), 30.0)
+
+	// Code that is not Go is measured from its tokens
+	script := &types.Program{Code: "if [ -n \"$1\" ] || [ -z \"$2\" ]; then echo ok; fi\n"}
+	assert.Equal(t, 1.0, extractors[FeatureLinesOfCode](script))
+	assert.Equal(t, 0.0, extractors[FeatureFunctions](script))
+	assert.Equal(t, 2.0, extractors[FeatureCyclomaticComplexity](script))
+	assert.Positive(t, extractors[FeatureASTNodes](script))
+
+	// Code dimensions are measured; the others take the reported features
+	// in order and the rest are dropped
+	config := types.DatabaseConfig{
+		NumIslands:     1,
+		GridDimensions: []string{FeatureFunctions, "score", FeatureLinesOfCode},
+		GridResolution: map[string]int{FeatureFunctions: 5, "score": 5, FeatureLinesOfCode: 5},
+		GridBounds: map[string][2]float64{
+			FeatureFunctions: {0, 10}, "score": {0, 1}, FeatureLinesOfCode: {0, 100},
+		},
+	}
+	db := New(config, "")
+	assert.Equal(t, []float64{2, 0.7, 14}, db.GridFeatures(code, nil, []float64{0.7, 1.5}))
+	assert.Equal(t, []float64{2}, db.GridFeatures(code, nil, nil))
+
+	// A short vector is completed by the extractors when the program is added
+	added := &types.Program{ID: "added", Code: code, Score: 0.7, Features: db.GridFeatures(code, nil, nil)}
+	require.NoError(t, db.AddProgram(added, 1))
+	assert.Equal(t, []float64{2, 0.7, 14}, added.Features)
+
+	// Grids without code dimensions keep the reported features
+	config.GridDimensions = []string{"complexity", "diversity"}
+	assert.Equal(t, []float64{0.7, 1.5}, New(config, "").GridFeatures(code, nil, []float64{0.7, 1.5}))
+
+	// Named values take their dimension, whatever its position; the other
+	// dimensions still take the positional features in order
+	config.GridDimensions = []string{"complexity", "memory_mb", FeatureFunctions}
+	named := map[string]float64{"memory_mb": 12.5, "unused": 3}
+	assert.Equal(t, []float64{0.7, 12.5, 2}, New(config, "").GridFeatures(code, named, []float64{0.7, 1.5}))
+	config.GridDimensions = []string{"memory_mb", "complexity"}
+	assert.Equal(t, []float64{12.5, 0.7}, New(config, "").GridFeatures(code, named, []float64{0.7, 1.5}))
+}
+
+func TestProgramDatabase_ReturnsCopies(t *testing.T) {
+	config := types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity"},
+		GridResolution: map[string]int{"complexity": 5},
+	}
+	db := New(config, "")
+	for islandID := 0; islandID < 2; islandID++ {
+		require.NoError(t, db.AddProgram(&types.Program{
+			ID:       fmt.Sprintf("root-%d", islandID),
+			Score:    0.5 - float64(islandID)/10,
+			Features: []float64{0.5},
+			Metrics:  map[string]float64{"speed": 1},
+			IslandID: islandID,
+		}, 0))
+	}
+
+	// Changing a returned program leaves the database untouched
+	got, exists := db.GetProgram("root-0")
+	require.True(t, exists)
+	got.Score = 7
+	got.Features[0] = 9
+	got.Metrics["speed"] = 2
+	stored, _ := db.GetProgram("root-0")
+	assert.Equal(t, 0.5, stored.Score)
+	assert.Equal(t, []float64{0.5}, stored.Features)
+	assert.Equal(t, map[string]float64{"speed": 1}, stored.Metrics)
+	best := db.GetGlobalBest()
+	best.Score = 100
+	assert.Equal(t, 0.5, db.GetGlobalBest().Score)
+
+	// Workers that modify what they sample do not race with each other or
+	// with the database
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				parent, err := db.SampleFromIsland(w % 2)
+				if !assert.NoError(t, err) {
+					return
+				}
+				parent.Features[0] = -1
+				parent.Metrics = nil
+				child := &types.Program{
+					ID:       fmt.Sprintf("child-%d-%d", w, i),
+					ParentID: parent.ID,
+					Score:    float64(i%7) / 10,
+					Features: []float64{float64(i%5) / 5},
+					IslandID: w % 2,
+				}
+				assert.NoError(t, db.AddProgram(child, i))
+				for _, top := range db.GetTopK(3) {
+					top.Features[0] = -1
+				}
+				lineage, err := db.GetLineage(child.ID)
+				if assert.NoError(t, err) {
+					lineage[0].Score = -1
+				}
+				db.GetGlobalBest().Children = 0
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.NoError(t, db.CheckChampion())
+	for _, program := range db.Query(Query{}) {
+		assert.NotEqual(t, -1.0, program.Features[0], "program %s was changed through a copy", program.ID)
+	}
+	root, _ := db.GetProgram("root-0")
+	assert.Equal(t, 0.5, root.Score)
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// maxDiversitySample caps how many programs of an island are compared
+// pairwise for code distance
+const maxDiversitySample = 32
+
+// IslandDiversity describes how varied an island's population is; an island
+// whose code distance and score variance collapse has converged
+type IslandDiversity struct {
+	IslandID int `json:"island_id"`
+	Programs int `json:"programs"`
+	// Share of grid cells holding an elite
+	Occupancy     float64 `json:"occupancy"`
+	ScoreVariance float64 `json:"score_variance"`
+	// Mean line distance between pairs of programs, over a sample of at
+	// most maxDiversitySample programs
+	CodeDistance float64         `json:"code_distance"`
+	Age          AgeDistribution `json:"age"`
+}
+
+// AgeDistribution summarizes how long ago an island's programs were created
+type AgeDistribution struct {
+	Min    time.Duration `json:"min"`
+	Median time.Duration `json:"median"`
+	Mean   time.Duration `json:"mean"`
+	Max    time.Duration `json:"max"`
+}
+
+// GetIslandDiversity returns diversity metrics for every island
+func (db *ProgramDatabase) GetIslandDiversity() []IslandDiversity {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+
+	now := time.Now()
+	diversity := make([]IslandDiversity, 0, len(db.islands))
+	for _, island := range db.islands {
+		programs := sortedPrograms(island.Programs)
+		diversity = append(diversity, IslandDiversity{
+			IslandID:      island.ID,
+			Programs:      len(programs),
+			Occupancy:     island.GetOccupancy(),
+			ScoreVariance: scoreVariance(programs),
+			CodeDistance:  meanCodeDistance(programs),
+			Age:           ageDistribution(programs, now),
+		})
+	}
+	return diversity
+}
+
+// scoreVariance returns the population variance of the programs' scores
+func scoreVariance(programs []*types.Program) float64 {
+	if len(programs) == 0 {
+		return 0
+	}
+	mean := 0.0
+	for _, program := range programs {
+		mean += program.Score
+	}
+	mean /= float64(len(programs))
+
+	variance := 0.0
+	for _, program := range programs {
+		variance += (program.Score - mean) * (program.Score - mean)
+	}
+	return variance / float64(len(programs))
+}
+
+// meanCodeDistance returns the mean LineDistance over all pairs of an
+// evenly spaced sample of programs
+func meanCodeDistance(programs []*types.Program) float64 {
+	if len(programs) > maxDiversitySample {
+		sample := make([]*types.Program, maxDiversitySample)
+		for idx := range sample {
+			sample[idx] = programs[idx*len(programs)/maxDiversitySample]
+		}
+		programs = sample
+	}
+
+	total, pairs := 0.0, 0
+	for a := 0; a < len(programs); a++ {
+		for b := a + 1; b < len(programs); b++ {
+			total += LineDistance(programs[a].Code, programs[b].Code)
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return total / float64(pairs)
+}
+
+// ageDistribution summarizes the time since each program was created
+func ageDistribution(programs []*types.Program, now time.Time) AgeDistribution {
+	if len(programs) == 0 {
+		return AgeDistribution{}
+	}
+	ages := make([]time.Duration, len(programs))
+	var total time.Duration
+	for idx, program := range programs {
+		if age := now.Sub(program.CreatedAt); age > 0 {
+			ages[idx] = age
+		}
+		total += ages[idx]
+	}
+	sort.Slice(ages, func(a, b int) bool { return ages[a] < ages[b] })
+
+	return AgeDistribution{
+		Min:    ages[0],
+		Median: ages[len(ages)/2],
+		Mean:   total / time.Duration(len(ages)),
+		Max:    ages[len(ages)-1],
+	}
+}
+
+// LineDistance returns the line-level edit distance between two programs
+// as a fraction of the longer one: 0 for identical code, 1 when no line
+// is shared in order
+func LineDistance(a, b string) float64 {
+	linesA := strings.Split(strings.TrimSpace(a), "\n")
+	linesB := strings.Split(strings.TrimSpace(b), "\n")
+	longest := len(linesA)
+	if len(linesB) > longest {
+		longest = len(linesB)
+	}
+	if a == b || longest == 0 {
+		return 0
+	}
+
+	// Levenshtein over lines, keeping only the previous row
+	previous := make([]int, len(linesB)+1)
+	current := make([]int, len(linesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(linesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(linesB); j++ {
+			cost := 1
+			if strings.TrimSpace(linesA[i-1]) == strings.TrimSpace(linesB[j-1]) {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return float64(previous[len(linesB)]) / float64(longest)
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// ArchiveEntry describes one exported program in the archive manifest
+type ArchiveEntry struct {
+	ID         string             `json:"id"`
+	File       string             `json:"file"`
+	Score      float64            `json:"score"`
+	Features   []float64          `json:"features"`
+	Metrics    map[string]float64 `json:"metrics,omitempty"`
+	Island     int                `json:"island"`
+	Generation int                `json:"generation"`
+	ParentID   string             `json:"parent_id,omitempty"`
+}
+
+// ArchiveManifest lists the programs of an exported archive, best first
+type ArchiveManifest struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Programs  []ArchiveEntry `json:"programs"`
+}
+
+// ExportArchive writes the grid elites of every island to dir, one source
+// file per program, plus a manifest.json with their scores, features,
+// islands and generations. A positive topK exports only the topK best
+// elites.
+func (db *ProgramDatabase) ExportArchive(dir string, topK int) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+
+	elites := db.cellElites()
+	sort.SliceStable(elites, func(a, b int) bool {
+		return rankScore(elites[a]) > rankScore(elites[b])
+	})
+	if topK > 0 && len(elites) > topK {
+		elites = elites[:topK]
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	manifest := ArchiveManifest{
+		CreatedAt: time.Now(),
+		Programs:  make([]ArchiveEntry, 0, len(elites)),
+	}
+	for rank, program := range elites {
+		file := fmt.Sprintf("%03d_%s%s", rank+1, program.ID, constants.GoExt)
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(program.Code), 0644); err != nil {
+			return fmt.Errorf("failed to write program %s: %w", program.ID, err)
+		}
+		manifest.Programs = append(manifest.Programs, archiveEntry(program, file))
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+
+	db.logger.WithField("programs", len(elites)).WithField("dir", dir).Info("Exported archive")
+	return nil
+}
+
+func archiveEntry(program *types.Program, file string) ArchiveEntry {
+	return ArchiveEntry{
+		ID:         program.ID,
+		File:       file,
+		Score:      program.Score,
+		Features:   program.Features,
+		Metrics:    program.Metrics,
+		Island:     program.IslandID,
+		Generation: program.Generation,
+		ParentID:   program.ParentID,
+	}
+}
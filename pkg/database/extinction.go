@@ -0,0 +1,147 @@
+package database
+
+import (
+	"math"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// recordImprovement notes the generation when the island's best score last
+// rose, at the end of a generation
+func (i *Island) recordImprovement() {
+	if i.BestScore > i.improvedBest {
+		i.improvedBest = i.BestScore
+		i.improvedAt = i.Generation
+	}
+}
+
+// clear removes every program from the island, keeping its generation,
+// feature statistics and grid layout
+func (i *Island) clear() {
+	i.Programs = make(map[string]*types.Program)
+	i.Grid.Cells = make(map[string]*types.Program)
+	i.Grid.Members = make(map[string][]*types.Program)
+	i.Grid.FilledCells = 0
+	i.BestProgram = nil
+	i.BestID = ""
+	i.BestScore = math.Inf(-1)
+	i.front = nil
+	i.bestHistory = nil
+}
+
+// StagnantIslands returns the islands that have gone the extinction
+// patience without improving. The island holding the global best is never
+// stagnant, and nothing is when extinction is disabled or there is only
+// one island.
+func (db *ProgramDatabase) StagnantIslands() []int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+
+	return db.stagnantIslands()
+}
+
+func (db *ProgramDatabase) stagnantIslands() []int {
+	extinction := db.config.Extinction
+	if !extinction.Enabled || len(db.islands) < 2 {
+		return nil
+	}
+	patience := extinction.Patience
+	if patience <= 0 {
+		patience = constants.DefaultExtinctionPatience
+	}
+
+	champion := -1
+	if best := db.globalBest.Load(); best != nil {
+		champion = best.IslandID
+	}
+	var stagnant []int
+	for _, island := range db.islands {
+		if island.ID != champion && island.Generation-island.improvedAt >= patience {
+			stagnant = append(stagnant, island.ID)
+		}
+	}
+	return stagnant
+}
+
+// ReseedStagnantIslands wipes every stagnant island, sending its grid
+// elites to cold storage, and reseeds it with a copy of the global best.
+// The copy is left unmutated; the iterations that follow sample it as a
+// parent and produce the variants. Ancestors of the global best survive
+// the wipe so its lineage stays intact, and under elitism the island keeps
+// its best. It returns the islands reseeded.
+func (db *ProgramDatabase) ReseedStagnantIslands() []int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	best := db.globalBest.Load()
+	if best == nil {
+		return nil
+	}
+	keep := db.championLineage()
+	stagnant := db.stagnantIslands()
+	for _, id := range stagnant {
+		island := db.islands[id]
+
+		// The champion's ancestors and, under elitism, the island's best
+		// survive the wipe
+		var survivors []*types.Program
+		for _, program := range sortedPrograms(island.Programs) {
+			if keep[program.ID] || (db.config.Elitism && program.ID == island.BestID) {
+				survivors = append(survivors, program)
+			}
+		}
+		surviving := make(map[string]bool, len(survivors))
+		for _, program := range survivors {
+			surviving[program.ID] = true
+		}
+
+		for _, elite := range island.Grid.Cells {
+			if !surviving[elite.ID] {
+				db.archiveCold(elite, island, constants.ColdReasonExtinct)
+			}
+		}
+		wiped := len(island.Programs) - len(survivors)
+		for programID := range island.Programs {
+			if !surviving[programID] {
+				delete(db.programs, programID)
+			}
+		}
+		island.clear()
+		for _, program := range survivors {
+			island.Programs[program.ID] = program
+			island.AddToGrid(program)
+			island.updateFront(program)
+			if rankScore(program) > island.BestScore {
+				island.BestProgram = program
+				island.BestScore = program.Score
+				island.BestID = program.ID
+			}
+		}
+
+		seed := migrantCopy(best, island.ID)
+		db.programs[seed.ID] = seed
+		island.Programs[seed.ID] = seed
+		island.AddToGrid(seed)
+		island.updateFront(seed)
+		if rankScore(seed) > island.BestScore {
+			island.BestProgram = seed
+			island.BestScore = seed.Score
+			island.BestID = seed.ID
+		}
+		island.improvedBest = island.BestScore
+		island.improvedAt = island.Generation
+
+		db.stats.Extinctions++
+		db.logger.WithFields(logrus.Fields{
+			"island":     island.ID,
+			"generation": island.Generation,
+			"wiped":      wiped,
+			"seed":       seed.ID,
+		}).Info("Island stagnated; wiped and reseeded from the global best")
+	}
+	return stagnant
+}
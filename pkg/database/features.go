@@ -0,0 +1,94 @@
+package database
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// complexityScale is the code length, in bytes, that maps to complexity 1.0
+const complexityScale = 10000.0
+
+// FeatureExtractor derives the value of one grid dimension from a program
+type FeatureExtractor func(program *types.Program) float64
+
+// FeatureMismatchError reports a program whose feature vector does not fit
+// the grid and could not be completed by the registered extractors
+type FeatureMismatchError struct {
+	ProgramID string
+	Got       int
+	Want      int
+	// Missing lists the dimensions no extractor could derive
+	Missing []string
+}
+
+func (e *FeatureMismatchError) Error() string {
+	if len(e.Missing) > 0 {
+		return fmt.Sprintf("program %s has %d features, grid needs %d (no extractor for %v)",
+			e.ProgramID, e.Got, e.Want, e.Missing)
+	}
+	return fmt.Sprintf("program %s has %d features, grid needs %d", e.ProgramID, e.Got, e.Want)
+}
+
+// defaultFeatureExtractors covers the dimensions that can be derived from a
+// program alone
+func defaultFeatureExtractors() map[string]FeatureExtractor {
+	extractors := map[string]FeatureExtractor{
+		"score": func(program *types.Program) float64 {
+			return program.Score
+		},
+		"complexity": func(program *types.Program) float64 {
+			return math.Min(float64(len(program.Code))/complexityScale, 1.0)
+		},
+	}
+	for dimension, extractor := range codeFeatureExtractors() {
+		extractors[dimension] = extractor
+	}
+	return extractors
+}
+
+// RegisterFeatureExtractor sets the extractor used to fill in a grid
+// dimension missing from a program's features
+func (db *ProgramDatabase) RegisterFeatureExtractor(dimension string, extractor FeatureExtractor) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.extractors[dimension] = extractor
+}
+
+// completeFeatures pads a short feature vector using the extractors of the
+// missing trailing dimensions. Vectors that are too long, or whose missing
+// dimensions have no extractor, are rejected. A database without grid
+// dimensions accepts any vector.
+func (db *ProgramDatabase) completeFeatures(program *types.Program) error {
+	dimensions := db.config.GridDimensions
+	if len(dimensions) == 0 || len(program.Features) == len(dimensions) {
+		return nil
+	}
+
+	mismatch := &FeatureMismatchError{
+		ProgramID: program.ID,
+		Got:       len(program.Features),
+		Want:      len(dimensions),
+	}
+	if len(program.Features) > len(dimensions) {
+		return mismatch
+	}
+
+	features := append([]float64(nil), program.Features...)
+	for _, dimension := range dimensions[len(features):] {
+		extractor, ok := db.extractors[dimension]
+		if !ok {
+			mismatch.Missing = append(mismatch.Missing, dimension)
+			continue
+		}
+		features = append(features, extractor(program))
+	}
+	if len(mismatch.Missing) > 0 {
+		return mismatch
+	}
+
+	program.Features = features
+	return nil
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"math"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// recordHistory appends the island's state at the end of a generation to
+// the statistics, which checkpoints carry so resumed runs continue the
+// timeline
+func (db *ProgramDatabase) recordHistory(island *Island) {
+	sample := types.HistorySample{
+		IslandID:   island.ID,
+		Generation: island.Generation,
+		Programs:   len(island.Programs),
+		Occupancy:  island.GetOccupancy(),
+		Time:       time.Now(),
+	}
+	if !math.IsInf(island.BestScore, 0) {
+		sample.BestScore = island.BestScore
+	}
+	total, succeeded := 0.0, 0
+	for _, program := range island.Programs {
+		if !program.Failed {
+			total += program.Score
+			succeeded++
+		}
+	}
+	if succeeded > 0 {
+		sample.AvgScore = total / float64(succeeded)
+	}
+
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+	db.stats.History = append(db.stats.History, sample)
+}
+
+// GetHistory returns each island's best score, average score and occupancy
+// at the end of every generation so far, oldest first
+func (db *ProgramDatabase) GetHistory() []types.HistorySample {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	return append([]types.HistorySample(nil), db.stats.History...)
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// hooks holds the functions subscribed to database milestones. They are
+// called after the database has released its locks, so they may query it,
+// and may be called concurrently from several islands.
+type hooks struct {
+	mu         sync.RWMutex
+	newBest    []func(program *types.Program, iteration int)
+	migration  []func(event types.MigrationEvent)
+	checkpoint []func(iteration int, path string)
+}
+
+// OnNewGlobalBest subscribes fn to every added program that becomes the
+// global best, with the iteration it was added in
+func (db *ProgramDatabase) OnNewGlobalBest(fn func(program *types.Program, iteration int)) {
+	db.hooks.mu.Lock()
+	defer db.hooks.mu.Unlock()
+	db.hooks.newBest = append(db.hooks.newBest, fn)
+}
+
+// OnMigration subscribes fn to every program sent to another island
+func (db *ProgramDatabase) OnMigration(fn func(event types.MigrationEvent)) {
+	db.hooks.mu.Lock()
+	defer db.hooks.mu.Unlock()
+	db.hooks.migration = append(db.hooks.migration, fn)
+}
+
+// OnCheckpoint subscribes fn to every checkpoint written, with its
+// iteration and file. Checkpoints saved asynchronously report once written.
+func (db *ProgramDatabase) OnCheckpoint(fn func(iteration int, path string)) {
+	db.hooks.mu.Lock()
+	defer db.hooks.mu.Unlock()
+	db.hooks.checkpoint = append(db.hooks.checkpoint, fn)
+}
+
+// notifyNewBest calls the new global best subscribers, each with its own
+// copy of program
+func (db *ProgramDatabase) notifyNewBest(program *types.Program, iteration int) {
+	db.hooks.mu.RLock()
+	subscribers := db.hooks.newBest
+	db.hooks.mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(copyProgram(program), iteration)
+	}
+}
+
+// notifyMigrations calls the migration subscribers once per event
+func (db *ProgramDatabase) notifyMigrations(events []types.MigrationEvent) {
+	db.hooks.mu.RLock()
+	subscribers := db.hooks.migration
+	db.hooks.mu.RUnlock()
+	for _, event := range events {
+		for _, fn := range subscribers {
+			fn(event)
+		}
+	}
+}
+
+// notifyCheckpoint calls the checkpoint subscribers
+func (db *ProgramDatabase) notifyCheckpoint(iteration int, path string) {
+	db.hooks.mu.RLock()
+	subscribers := db.hooks.checkpoint
+	db.hooks.mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(iteration, path)
+	}
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// ReadCheckpoint decodes a checkpoint file in any supported format without
+// loading it into a database
+func ReadCheckpoint(checkpointPath string) (*types.Checkpoint, error) {
+	data, err := ioutil.ReadFile(checkpointPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+	return decodeCheckpoint(data)
+}
+
+// CheckpointDimensions returns the grid dimensions a checkpoint's programs
+// were placed by
+func CheckpointDimensions(checkpoint *types.Checkpoint) []string {
+	for _, island := range checkpoint.Islands {
+		if len(island.Grid.Dimensions) > 0 {
+			return island.Grid.Dimensions
+		}
+	}
+	return nil
+}
+
+// CheckDimensions reports whether programs placed by the grid dimensions
+// from can be placed on this database's grid: every dimension must either
+// be one of from or have a feature extractor
+func (db *ProgramDatabase) CheckDimensions(from []string) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	_, err := db.featureMapping(from)
+	return err
+}
+
+// featureMapping returns, for each of the database's grid dimensions, its
+// index in from, or -1 when an extractor derives it instead
+func (db *ProgramDatabase) featureMapping(from []string) ([]int, error) {
+	index := make(map[string]int, len(from))
+	for i, dimension := range from {
+		index[dimension] = i
+	}
+
+	mapping := make([]int, len(db.config.GridDimensions))
+	var missing []string
+	for i, dimension := range db.config.GridDimensions {
+		if source, ok := index[dimension]; ok {
+			mapping[i] = source
+			continue
+		}
+		if _, ok := db.extractors[dimension]; !ok {
+			missing = append(missing, dimension)
+		}
+		mapping[i] = -1
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("grid dimensions %v are not in the checkpoint and have no feature extractor", missing)
+	}
+	return mapping, nil
+}
+
+// ImportCheckpoint adds every program of a checkpoint to the database,
+// placing it afresh on this database's grid and islands. Features of
+// dimensions the checkpoint shares are carried over as they are, already
+// scaled by the database that saved them; the others are derived by
+// extractors and scaled. Programs are added as by addImported.
+func (db *ProgramDatabase) ImportCheckpoint(checkpoint *types.Checkpoint) error {
+	db.mu.RLock()
+	mapping, err := db.featureMapping(CheckpointDimensions(checkpoint))
+	extractors := make(map[string]FeatureExtractor, len(db.extractors))
+	for dimension, extractor := range db.extractors {
+		extractors[dimension] = extractor
+	}
+	dimensions := db.config.GridDimensions
+	db.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	programs := make(map[string]*types.Program)
+	for _, island := range checkpoint.Islands {
+		for id, program := range island.Programs {
+			programs[id] = program
+		}
+	}
+	ordered := sortedPrograms(programs)
+
+	prescaled := make(map[string][]bool, len(ordered))
+	for _, program := range ordered {
+		if len(mapping) > 0 {
+			features := make([]float64, len(mapping))
+			carried := make([]bool, len(mapping))
+			for i, source := range mapping {
+				extractor := extractors[dimensions[i]]
+				switch {
+				case source >= 0 && source < len(program.Features):
+					features[i] = program.Features[source]
+					carried[i] = true
+				case extractor != nil:
+					features[i] = extractor(program)
+				default:
+					return fmt.Errorf("failed to import program %s: %w", program.ID, &FeatureMismatchError{
+						ProgramID: program.ID,
+						Got:       len(program.Features),
+						Want:      len(mapping),
+						Missing:   []string{dimensions[i]},
+					})
+				}
+			}
+			program.Features = features
+			prescaled[program.ID] = carried
+		}
+	}
+
+	return db.addImported(ordered, prescaled, checkpoint.Iteration)
+}
+
+// addImported adds programs in creation order, so parents precede children
+// and child counts are rebuilt. Programs keep their island when it exists
+// and wrap around otherwise. prescaled marks, by program ID, the features
+// that are already on the grid's scale.
+func (db *ProgramDatabase) addImported(programs []*types.Program, prescaled map[string][]bool, iteration int) error {
+	sort.SliceStable(programs, func(a, b int) bool {
+		return programs[a].CreatedAt.Before(programs[b].CreatedAt)
+	})
+
+	numIslands := db.NumIslands()
+	for _, program := range programs {
+		program.IslandID %= numIslands
+		program.Children = 0
+		best, champion, err := db.addProgram(program, iteration, prescaled[program.ID])
+		if err != nil {
+			return fmt.Errorf("failed to import program %s: %w", program.ID, err)
+		}
+		db.saveChampion(champion)
+		if best != nil {
+			db.notifyNewBest(best, iteration)
+		}
+		db.compactIfOversized()
+	}
+	return nil
+}
+
+// Merge adds the programs of another run's database to this one, placing
+// them like ImportCheckpoint, so grid cells both runs filled go to the
+// fitter program and the statistics count the merged evaluations. Merged
+// programs are tagged with origin unless an earlier merge already tagged
+// them; programs already in this database are skipped. It returns the
+// number of programs merged.
+func (db *ProgramDatabase) Merge(other *ProgramDatabase, origin string) (int, error) {
+	checkpoint := other.snapshot(other.LastIteration())
+	checkpoint.Iteration = db.LastIteration()
+
+	merged := 0
+	for _, island := range checkpoint.Islands {
+		for id, program := range island.Programs {
+			if _, exists := db.GetProgram(id); exists {
+				delete(island.Programs, id)
+				continue
+			}
+			if program.Origin == "" {
+				program.Origin = origin
+			}
+			merged++
+		}
+	}
+	if err := db.ImportCheckpoint(checkpoint); err != nil {
+		return 0, fmt.Errorf("failed to merge %s: %w", origin, err)
+	}
+
+	db.logger.WithFields(logrus.Fields{
+		"origin":   origin,
+		"programs": merged,
+	}).Info("Merged programs from another run")
+	return merged, nil
+}
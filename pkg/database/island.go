@@ -3,14 +3,22 @@ package database
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
 )
 
 // Island represents an island in the island-based evolution model
 // Each island maintains its own MAP-Elites grid and population
 type Island struct {
+	// Guards the island within a database; see ProgramDatabase
+	mu sync.RWMutex
+
 	// Island identification
 	ID int `json:"id"`
 
@@ -31,6 +39,71 @@ type Island struct {
 
 	// Feature statistics for scaling
 	FeatureStats map[string]FeatureStats `json:"feature_stats"`
+
+	// Scaling method per dimension, and recent raw values of the
+	// dimensions whose method needs them
+	featureScaling map[string]string
+	samples        map[string]*featureSamples
+
+	// Binning strategy per dimension, and whether quantile is the default
+	gridScaling     map[string]string
+	adaptiveBinning bool
+
+	// How strongly grid sampling avoids stale elites; 0 samples uniformly
+	stalenessBias float64
+
+	// Random source for sampling without a caller-supplied one
+	rng *rand.Rand
+
+	// How parents are picked and the share picked uniformly regardless
+	parentSelection  string
+	// Selection strategy of the run phase, in place of parentSelection
+	// when set
+	phaseSelection   string
+	explorationRatio float64
+	rankExponent     float64
+	tournamentSize   int
+	boltzmann        types.BoltzmannConfig
+
+	// Whether programs compete on novelty-blended fitness
+	novelty bool
+
+	// Discounts elites not evaluated recently when they compete for
+	// cells; nil when recency decay is disabled
+	decay *recencyDecay
+
+	// Shares fitness among neighbors when picking parents; nil when
+	// fitness sharing is disabled
+	sharing *fitnessSharing
+
+	// Global best program of the database; when it lives on this island
+	// its cell is never handed to another program. Nil outside a database.
+	best *atomic.Pointer[types.Program]
+
+	// Whether the island's best is pinned like the global best
+	elitism bool
+
+	// How many programs a grid cell holds and which one a full cell evicts
+	cellCapacity    int
+	cellReplacement string
+
+	// Called with each program a full cell evicts to make room; nil when
+	// nothing needs to know
+	onReplace func(*types.Program)
+
+	// Metrics optimized in multi-objective mode and the island's
+	// non-dominated programs over them
+	objectives []types.Objective
+	front      []*types.Program
+
+	// Best score at the end of each recent generation, for adaptive
+	// migration
+	bestHistory []float64
+
+	// Best score the island last improved to and the generation it did, for
+	// extinction
+	improvedBest float64
+	improvedAt   int
 }
 
 // FeatureStats tracks statistics for a feature dimension
@@ -50,9 +123,21 @@ type MAPGrid struct {
 	Resolution map[string]int    `json:"resolution"`
 	Bounds     map[string][2]float64 `json:"bounds"`
 
-	// Grid cells - key is a serialized feature vector
+	// Grid cells - key is a serialized feature vector; each cell holds
+	// its elite, the fittest of its members
 	Cells map[string]*types.Program `json:"cells"`
 
+	// Sub-population of each cell in insertion order, elite included
+	Members map[string][]*types.Program `json:"members,omitempty"`
+
+	// Adaptive bin edges per dimension; dimensions without edges use
+	// uniform bins over Bounds
+	Edges map[string][]float64 `json:"edges,omitempty"`
+
+	// Cell centroids of a CVT archive in the normalized feature space; when
+	// set they replace the rectangular bins
+	Centroids [][]float64 `json:"centroids,omitempty"`
+
 	// Grid statistics
 	TotalCells int `json:"total_cells"`
 	FilledCells int `json:"filled_cells"`
@@ -60,12 +145,48 @@ type MAPGrid struct {
 
 // NewIsland creates a new island with the given ID and configuration
 func NewIsland(id int, config types.DatabaseConfig) *Island {
+	return newIslandWithCells(id, config, cvtCentroids(config))
+}
+
+// newIslandWithCells creates an island whose CVT archive, if configured,
+// uses the given centroids
+func newIslandWithCells(id int, config types.DatabaseConfig, centroids [][]float64) *Island {
 	// Initialize grid
 	grid := MAPGrid{
 		Dimensions: config.GridDimensions,
 		Resolution: config.GridResolution,
 		Bounds:     config.GridBounds,
 		Cells:      make(map[string]*types.Program),
+		Members:    make(map[string][]*types.Program),
+		Centroids:  centroids,
+	}
+
+	cellCapacity := config.MaxProgramsPerCell
+	if cellCapacity <= 0 {
+		cellCapacity = 1
+	}
+	cellReplacement := config.CellReplacement
+	if cellReplacement == "" {
+		cellReplacement = constants.CellReplacementWorstOut
+	}
+	parentSelection := config.ParentSelection
+	if selection, exists := config.IslandParentSelection[id]; exists {
+		parentSelection = selection
+	}
+	if override := config.IslandOverrides[id]; override.ParentSelection != "" {
+		parentSelection = override.ParentSelection
+	}
+	tournamentSize := config.TournamentSize
+	if tournamentSize <= 0 {
+		tournamentSize = constants.DefaultTournamentSize
+	}
+	boltzmann := config.Boltzmann
+	if boltzmann.Temperature <= 0 {
+		boltzmann.Temperature = constants.DefaultBoltzmannTemperature
+	}
+	seed := config.RandomSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
 
 	// Calculate total cells
@@ -78,6 +199,9 @@ func NewIsland(id int, config types.DatabaseConfig) *Island {
 		}
 	}
 	grid.TotalCells = totalCells
+	if len(centroids) > 0 {
+		grid.TotalCells = len(centroids)
+	}
 
 	// Initialize feature stats
 	featureStats := make(map[string]FeatureStats)
@@ -89,40 +213,217 @@ func NewIsland(id int, config types.DatabaseConfig) *Island {
 		}
 	}
 
-	return &Island{
+	island := &Island{
 		ID:           id,
 		Programs:     make(map[string]*types.Program),
 		Grid:         grid,
 		BestScore:    math.Inf(-1),
+		improvedBest: math.Inf(-1),
 		Generation:   0,
 		Migrated:     0,
 		FeatureStats: featureStats,
+		featureScaling: config.FeatureScaling,
+		samples:        make(map[string]*featureSamples),
+		gridScaling:     config.GridScaling,
+		adaptiveBinning: config.AdaptiveBinning,
+		stalenessBias: config.StalenessBias,
+		rng:           newLockedRand(seed + int64(id)),
+		parentSelection:  parentSelection,
+		explorationRatio: config.ExplorationRatio,
+		rankExponent:     config.RankExponent,
+		tournamentSize:   tournamentSize,
+		boltzmann:        boltzmann,
+		novelty:       config.NoveltyWeight > 0,
+		decay:         newRecencyDecay(config.RecencyDecay),
+		sharing:       newFitnessSharing(config.FitnessSharing),
+		elitism:       config.Elitism,
+		cellCapacity:    cellCapacity,
+		cellReplacement: cellReplacement,
+		objectives:      config.Objectives,
 	}
+	island.setLogEdges()
+	return island
 }
 
-// AddToGrid adds a program to the MAP-Elites grid if it's better than the current occupant
+// AddToGrid adds a program to its MAP-Elites cell if the cell has room or
+// the program beats the member the replacement policy would evict
 func (i *Island) AddToGrid(program *types.Program) bool {
 	// Calculate grid cell key
 	cellKey := i.calculateCellKey(program.Features)
 
-	// Check if cell is empty or new program is better
-	existing, exists := i.Grid.Cells[cellKey]
-	if !exists || program.Score > existing.Score {
-		// Add to grid
-		i.Grid.Cells[cellKey] = program
+	if !i.place(cellKey, program) {
+		return false
+	}
+	program.CellGeneration = i.Generation
+
+	// Update feature statistics
+	i.updateFeatureStats(program)
+
+	return true
+}
 
-		// Update filled cells count
-		if !exists {
-			i.Grid.FilledCells++
+// place adds program to the members of a cell, evicting one member when
+// the cell is full, and re-elects the cell's elite. It returns false when
+// the program does not get in.
+func (i *Island) place(cellKey string, program *types.Program) bool {
+	members := i.Grid.Members[cellKey]
+	for idx, member := range members {
+		if member.ID == program.ID {
+			members[idx] = program
+			i.Grid.Cells[cellKey] = i.fittest(members)
+			return true
 		}
+	}
+	if len(members) >= i.cellCapacity {
+		victim := i.victim(cellKey, members, program)
+		if victim < 0 {
+			return false
+		}
+		if i.onReplace != nil {
+			i.onReplace(members[victim])
+		}
+		members = append(members[:victim:victim], members[victim+1:]...)
+	}
+	members = append(members, program)
+
+	if _, filled := i.Grid.Cells[cellKey]; !filled {
+		i.Grid.FilledCells++
+	}
+	i.Grid.Members[cellKey] = members
+	i.Grid.Cells[cellKey] = i.fittest(members)
+	return true
+}
 
-		// Update feature statistics
-		i.updateFeatureStats(program)
+// victim returns the index of the member a full cell evicts to make room
+// for program, or -1 if program does not get in. Worst-out evicts the least
+// fit member if program beats it. Oldest-out evicts the oldest member that
+// is neither pinned nor the cell's elite; a cell with no such member falls
+// back to worst-out. Pinned programs are never evicted.
+func (i *Island) victim(cellKey string, members []*types.Program, program *types.Program) int {
+	if i.cellReplacement == constants.CellReplacementOldestOut {
+		elite := i.Grid.Cells[cellKey]
+		for idx, member := range members {
+			if !i.pinned(member.ID) && member != elite {
+				return idx
+			}
+		}
+	}
 
+	worst := -1
+	for idx, member := range members {
+		if i.pinned(member.ID) {
+			continue
+		}
+		if worst < 0 || i.cellFitness(member) < i.cellFitness(members[worst]) {
+			worst = idx
+		}
+	}
+	if worst < 0 || !i.displaces(program, members[worst]) {
+		return -1
+	}
+	return worst
+}
+
+// fittest returns the member that represents a cell: the champion if it is
+// a member, otherwise the fittest, earliest member
+func (i *Island) fittest(members []*types.Program) *types.Program {
+	champion := i.championID()
+	var elite *types.Program
+	for _, member := range members {
+		if member.ID == champion {
+			return member
+		}
+		if elite == nil || i.cellFitness(member) > i.cellFitness(elite) {
+			elite = member
+		}
+	}
+	return elite
+}
+
+// displaces reports whether program should take existing's grid cell. The
+// champion always holds its cell; otherwise the fitter program wins.
+func (i *Island) displaces(program, existing *types.Program) bool {
+	champion := i.championID()
+	if program.ID == champion {
 		return true
 	}
+	if existing.ID == champion {
+		return false
+	}
+	return i.cellFitness(program) > i.cellFitness(existing)
+}
+
+// cellFitness returns the fitness a program competes for a grid cell with,
+// discounted by its age under recency decay
+func (i *Island) cellFitness(program *types.Program) float64 {
+	return i.decay.apply(program, fitnessOf(program, i.novelty))
+}
+
+// pinned reports whether a program must stay where it is: the champion
+// always, and the island's best under elitism
+func (i *Island) pinned(id string) bool {
+	if id == "" {
+		return false
+	}
+	return id == i.championID() || (i.elitism && id == i.BestID)
+}
+
+// championID returns the ID of the global best program if it lives on
+// this island, or ""
+func (i *Island) championID() string {
+	if i.best == nil {
+		return ""
+	}
+	if best := i.best.Load(); best != nil && best.IslandID == i.ID {
+		return best.ID
+	}
+	return ""
+}
+
+// remove takes a program off the island, clearing any grid cell it holds
+// and recomputing the island best if it was the best
+func (i *Island) remove(program *types.Program) {
+	delete(i.Programs, program.ID)
+
+	for key, members := range i.Grid.Members {
+		kept := make([]*types.Program, 0, len(members))
+		for _, member := range members {
+			if member.ID != program.ID {
+				kept = append(kept, member)
+			}
+		}
+		if len(kept) == len(members) {
+			continue
+		}
+		if len(kept) == 0 {
+			delete(i.Grid.Members, key)
+			delete(i.Grid.Cells, key)
+			i.Grid.FilledCells--
+			continue
+		}
+		i.Grid.Members[key] = kept
+		i.Grid.Cells[key] = i.fittest(kept)
+	}
 
-	return false
+	for _, member := range i.front {
+		if member.ID == program.ID {
+			i.rebuildFront()
+			break
+		}
+	}
+
+	if i.BestID == program.ID {
+		i.BestProgram = nil
+		i.BestID = ""
+		i.BestScore = math.Inf(-1)
+		for _, candidate := range i.Programs {
+			if rankScore(candidate) > i.BestScore {
+				i.BestProgram = candidate
+				i.BestID = candidate.ID
+				i.BestScore = candidate.Score
+			}
+		}
+	}
 }
 
 // GetFromGrid retrieves a program from the grid by feature vector
@@ -133,23 +434,187 @@ func (i *Island) GetFromGrid(features []float64) *types.Program {
 
 // SampleFromGrid samples a program from the filled grid cells
 func (i *Island) SampleFromGrid() *types.Program {
+	return i.SampleFromGridWith(nil)
+}
+
+// SampleFromGridWith samples a program from the filled grid cells using rng.
+// Cells are visited in key order so a seeded rng gives reproducible picks;
+// a nil rng falls back to the island's own source.
+func (i *Island) SampleFromGridWith(rng *rand.Rand) *types.Program {
 	if len(i.Grid.Cells) == 0 {
 		return nil
 	}
+	if rng == nil {
+		rng = i.rng
+	}
 
-	// Convert to slice for random sampling
-	programs := make([]*types.Program, 0, len(i.Grid.Cells))
-	for _, program := range i.Grid.Cells {
-		programs = append(programs, program)
+	if i.exploits(rng) {
+		return i.sampleExploit(rng)
 	}
 
-	// Simple random sampling (can be enhanced with weighted sampling)
-	if len(programs) > 0 {
-		idx := time.Now().Nanosecond() % len(programs)
-		return programs[idx]
+	if i.stalenessBias > 0 {
+		return i.sampleFresh(rng)
 	}
 
-	return nil
+	elites := i.elites()
+	return elites[rng.Intn(len(elites))]
+}
+
+// Staleness returns how over-exploited an elite is: the children it has
+// spawned plus the generations it has held its cell
+func (i *Island) Staleness(program *types.Program) float64 {
+	age := i.Generation - program.CellGeneration
+	if age < 0 {
+		age = 0
+	}
+	return float64(program.Children + age)
+}
+
+// sampleFresh samples an elite with weight 1/(1 + bias*staleness), steering
+// parents toward under-explored cells
+func (i *Island) sampleFresh(rng *rand.Rand) *types.Program {
+	elites := i.elites()
+	weights := make([]float64, len(elites))
+	total := 0.0
+	for idx, program := range elites {
+		weights[idx] = 1 / (1 + i.stalenessBias*i.Staleness(program))
+		total += weights[idx]
+	}
+
+	r := rng.Float64() * total
+	for idx, weight := range weights {
+		if r < weight {
+			return elites[idx]
+		}
+		r -= weight
+	}
+	return elites[len(elites)-1]
+}
+
+// Novelty returns how far a scaled feature vector lies from its k nearest
+// neighbours in this island, normalized to [0, 1]. An empty island is
+// maximally novel.
+func (i *Island) Novelty(features []float64, k int) float64 {
+	if len(features) == 0 || len(i.Programs) == 0 {
+		return 1.0
+	}
+
+	distances := make([]float64, 0, len(i.Programs))
+	for _, program := range i.Programs {
+		if len(program.Features) != len(features) {
+			continue
+		}
+		sum := 0.0
+		for dimIdx, feature := range features {
+			delta := feature - program.Features[dimIdx]
+			sum += delta * delta
+		}
+		distances = append(distances, math.Sqrt(sum))
+	}
+	if len(distances) == 0 {
+		return 1.0
+	}
+
+	sort.Float64s(distances)
+	if k <= 0 || k > len(distances) {
+		k = len(distances)
+	}
+
+	total := 0.0
+	for _, distance := range distances[:k] {
+		total += distance
+	}
+
+	// Scaled features live in the unit hypercube, whose diagonal is sqrt(d)
+	novelty := total / float64(k) / math.Sqrt(float64(len(features)))
+	return math.Min(novelty, 1.0)
+}
+
+// RebalanceBins replaces uniform bins with quantile-based edges so every
+// dimension spreads the island's population evenly across its resolution,
+// then re-bins the population. Dimensions given linear or log grid scaling
+// keep their bins, and dimensions whose values are too concentrated to
+// separate end up with fewer bins. It returns false when the island has too
+// few programs to estimate quantiles.
+func (i *Island) RebalanceBins() bool {
+	if len(i.Programs) < 2 || len(i.Grid.Centroids) > 0 {
+		return false
+	}
+
+	edges := make(map[string][]float64, len(i.Grid.Dimensions))
+	totalCells := 1
+	for dimIdx, dim := range i.Grid.Dimensions {
+		resolution, ok := i.Grid.Resolution[dim]
+		if !ok {
+			resolution = 10 // Default resolution
+		}
+		if strategy := i.gridScaling[dim]; strategy == constants.GridScalingLinear || strategy == constants.GridScalingLog {
+			if dimEdges, ok := i.Grid.Edges[dim]; ok {
+				edges[dim] = dimEdges
+			}
+			totalCells *= resolution
+			continue
+		}
+
+		values := make([]float64, 0, len(i.Programs))
+		for _, program := range i.Programs {
+			if dimIdx < len(program.Features) {
+				values = append(values, program.Features[dimIdx])
+			}
+		}
+		if len(values) < 2 {
+			return false
+		}
+
+		edges[dim] = quantileEdges(values, resolution)
+		totalCells *= len(edges[dim]) + 1
+	}
+
+	i.Grid.Edges = edges
+	i.Grid.TotalCells = totalCells
+	i.rebin()
+
+	return true
+}
+
+// rebin empties the grid and places the island's population into it again
+func (i *Island) rebin() {
+	i.Grid.Cells = make(map[string]*types.Program)
+	i.Grid.Members = make(map[string][]*types.Program)
+	i.Grid.FilledCells = 0
+
+	// Re-bin oldest first so oldest-out keeps evicting in insertion order
+	programs := sortedPrograms(i.Programs)
+	sort.SliceStable(programs, func(a, b int) bool {
+		return programs[a].CreatedAt.Before(programs[b].CreatedAt)
+	})
+	for _, program := range programs {
+		i.place(i.calculateCellKey(program.Features), program)
+	}
+}
+
+// quantileEdges returns up to resolution-1 strictly increasing interior
+// edges that split values into equally populated bins. A value equal to an
+// edge falls into the bin below it.
+func quantileEdges(values []float64, resolution int) []float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	edges := make([]float64, 0, resolution)
+	for b := 1; b < resolution; b++ {
+		idx := b*len(sorted)/resolution - 1
+		if idx < 0 {
+			continue
+		}
+		edge := sorted[idx]
+		// Collapse duplicate edges and never split above the maximum
+		if edge >= sorted[len(sorted)-1] || (len(edges) > 0 && edge <= edges[len(edges)-1]) {
+			continue
+		}
+		edges = append(edges, edge)
+	}
+
+	return edges
 }
 
 // GetBestProgram returns the best program in this island
@@ -157,7 +622,7 @@ func (i *Island) GetBestProgram() *types.Program {
 	if i.BestProgram == nil && len(i.Programs) > 0 {
 		// Find best program if not cached
 		for _, program := range i.Programs {
-			if program.Score > i.BestScore {
+			if rankScore(program) > i.BestScore {
 				i.BestProgram = program
 				i.BestScore = program.Score
 				i.BestID = program.ID
@@ -187,6 +652,14 @@ func (i *Island) calculateCellKey(features []float64) string {
 		return ""
 	}
 
+	if len(i.Grid.Centroids) > 0 {
+		point := make([]float64, len(features))
+		for dimIdx, dim := range i.Grid.Dimensions {
+			point[dimIdx] = i.normalize(dim, features[dimIdx])
+		}
+		return fmt.Sprintf("cvt:%d", nearestCentroid(i.Grid.Centroids, point))
+	}
+
 	key := ""
 	for dimIdx, dim := range i.Grid.Dimensions {
 		if dimIdx >= len(features) {
@@ -195,11 +668,9 @@ func (i *Island) calculateCellKey(features []float64) string {
 
 		feature := features[dimIdx]
 
-		// Get bounds for this dimension
-		bounds, ok := i.Grid.Bounds[dim]
-		if !ok {
-			// Default bounds
-			bounds = [2]float64{0.0, 1.0}
+		if edges, ok := i.Grid.Edges[dim]; ok {
+			key += fmt.Sprintf("%s:%d;", dim, sort.SearchFloat64s(edges, feature))
+			continue
 		}
 
 		// Get resolution for this dimension
@@ -208,16 +679,8 @@ func (i *Island) calculateCellKey(features []float64) string {
 			resolution = 10 // Default resolution
 		}
 
-		// Normalize feature to [0, 1]
-		normalized := (feature - bounds[0]) / (bounds[1] - bounds[0])
-		if normalized < 0 {
-			normalized = 0
-		} else if normalized > 1 {
-			normalized = 1
-		}
-
 		// Convert to grid index
-		index := int(normalized * float64(resolution-1))
+		index := int(i.normalize(dim, feature) * float64(resolution-1))
 
 		key += fmt.Sprintf("%s:%d;", dim, index)
 	}
@@ -225,6 +688,23 @@ func (i *Island) calculateCellKey(features []float64) string {
 	return key
 }
 
+// normalize maps a feature to [0, 1] over its dimension's bounds
+func (i *Island) normalize(dim string, feature float64) float64 {
+	bounds, ok := i.Grid.Bounds[dim]
+	if !ok {
+		// Default bounds
+		bounds = [2]float64{0.0, 1.0}
+	}
+
+	normalized := (feature - bounds[0]) / (bounds[1] - bounds[0])
+	if normalized < 0 {
+		normalized = 0
+	} else if normalized > 1 {
+		normalized = 1
+	}
+	return normalized
+}
+
 // updateFeatureStats updates the running statistics for features
 func (i *Island) updateFeatureStats(program *types.Program) {
 	for dimIdx, dim := range i.Grid.Dimensions {
@@ -261,7 +741,9 @@ func (i *Island) updateFeatureStats(program *types.Program) {
 	}
 }
 
-// ScaleFeatures scales features using the configured method
+// ScaleFeatures scales features using each dimension's configured method:
+// min-max over the island's running statistics by default, or z-score,
+// quantile or log scaling over recent raw values
 func (i *Island) ScaleFeatures(features []float64) []float64 {
 	scaled := make([]float64, len(features))
 
@@ -271,8 +753,12 @@ func (i *Island) ScaleFeatures(features []float64) []float64 {
 		}
 
 		feature := features[dimIdx]
-		stats := i.FeatureStats[dim]
+		if method := i.featureScaling[dim]; sampledScaling(method) {
+			scaled[dimIdx] = i.scaleSampled(dim, feature, method)
+			continue
+		}
 
+		stats := i.FeatureStats[dim]
 		if stats.Count == 0 {
 			// No statistics yet, use as-is
 			scaled[dimIdx] = feature
@@ -295,4 +781,27 @@ func (i *Island) ScaleFeatures(features []float64) []float64 {
 	}
 
 	return scaled
-}
\ No newline at end of file
+}
+
+// fitnessOf returns the value programs compete on for grid cells. Programs
+// compete on their novelty-blended fitness only when novelty is enabled;
+// otherwise, or when no novelty was blended in, they compete on raw score.
+// Failed programs lose to every program that succeeded.
+func fitnessOf(program *types.Program, novelty bool) float64 {
+	if program.Failed {
+		return math.Inf(-1)
+	}
+	if novelty && program.NoveltyBlended {
+		return program.Fitness
+	}
+	return program.Score
+}
+
+// rankScore returns the score programs are ranked by: their score, or -Inf
+// for a failed program, which ranks below any score
+func rankScore(program *types.Program) float64 {
+	if program.Failed {
+		return math.Inf(-1)
+	}
+	return program.Score
+}
@@ -0,0 +1,198 @@
+package database
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// ProgramRecord is one line of a JSONL program archive. Features are keyed
+// by grid dimension, so archives can be read by databases with other grids
+// and by tools that know nothing of checkpoints.
+type ProgramRecord struct {
+	ID             string             `json:"id"`
+	Code           string             `json:"code"`
+	Score          float64            `json:"score"`
+	Failed         bool               `json:"failed,omitempty"`
+	Origin         string             `json:"origin,omitempty"`
+	Metrics        map[string]float64 `json:"metrics,omitempty"`
+	Features       map[string]float64 `json:"features,omitempty"`
+	Behavior       []float64          `json:"behavior,omitempty"`
+	Island         int                `json:"island"`
+	Generation     int                `json:"generation"`
+	ParentID       string             `json:"parent_id,omitempty"`
+	InspirationIDs []string           `json:"inspiration_ids,omitempty"`
+	Changes        string             `json:"changes,omitempty"`
+	Artifacts      map[string]string  `json:"artifacts,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// ExportJSONL writes every program to path, one JSON record per line in
+// creation order, so parents precede their children. It returns the
+// number of programs written.
+func (db *ProgramDatabase) ExportJSONL(path string) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create program archive: %w", err)
+	}
+	defer file.Close()
+
+	programs := sortedPrograms(db.programs)
+	sort.SliceStable(programs, func(a, b int) bool {
+		return programs[a].CreatedAt.Before(programs[b].CreatedAt)
+	})
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, program := range programs {
+		if err := encoder.Encode(db.programRecord(program)); err != nil {
+			return 0, fmt.Errorf("failed to encode program %s: %w", program.ID, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write program archive: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write program archive: %w", err)
+	}
+
+	db.logger.WithField("programs", len(programs)).WithField("file", path).Info("Exported programs")
+	return len(programs), nil
+}
+
+// ImportJSONL adds the programs of a JSONL archive to the database, placing
+// them afresh like ImportCheckpoint. Programs already in the database are
+// skipped, so archives of several runs can be merged. Features the archive
+// holds are already scaled and kept as they are; those of dimensions it
+// lacks are derived by extractors. It returns the
+// number of programs added.
+func (db *ProgramDatabase) ImportJSONL(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open program archive: %w", err)
+	}
+	defer file.Close()
+
+	var records []ProgramRecord
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var record ProgramRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to decode program %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+
+	db.mu.RLock()
+	dimensions := db.config.GridDimensions
+	extractors := make(map[string]FeatureExtractor, len(db.extractors))
+	for dimension, extractor := range db.extractors {
+		extractors[dimension] = extractor
+	}
+	db.mu.RUnlock()
+
+	programs := make([]*types.Program, 0, len(records))
+	prescaled := make(map[string][]bool, len(records))
+	seen := make(map[string]bool, len(records))
+	for _, record := range records {
+		if record.ID == "" {
+			return 0, fmt.Errorf("program archive has a program without an ID")
+		}
+		if _, exists := db.GetProgram(record.ID); exists || seen[record.ID] {
+			continue
+		}
+		seen[record.ID] = true
+
+		program := &types.Program{
+			ID:             record.ID,
+			Code:           record.Code,
+			Score:          record.Score,
+			Fitness:        record.Score,
+			Failed:         record.Failed,
+			Origin:         record.Origin,
+			Metrics:        record.Metrics,
+			Behavior:       record.Behavior,
+			IslandID:       record.Island,
+			Generation:     record.Generation,
+			ParentID:       record.ParentID,
+			InspirationIDs: record.InspirationIDs,
+			Changes:        record.Changes,
+			Artifacts:      record.Artifacts,
+			CreatedAt:      record.CreatedAt,
+			UpdatedAt:      record.CreatedAt,
+		}
+		if len(dimensions) > 0 {
+			program.Features = make([]float64, len(dimensions))
+			prescaled[program.ID] = make([]bool, len(dimensions))
+			for i, dimension := range dimensions {
+				if value, ok := record.Features[dimension]; ok {
+					program.Features[i] = value
+					prescaled[program.ID][i] = true
+				} else if extractor := extractors[dimension]; extractor != nil {
+					program.Features[i] = extractor(program)
+				} else {
+					return 0, fmt.Errorf("failed to import program %s: grid dimension %s is not in the archive and has no feature extractor", record.ID, dimension)
+				}
+			}
+		}
+		programs = append(programs, program)
+	}
+
+	if err := db.addImported(programs, prescaled, db.LastIteration()); err != nil {
+		return 0, err
+	}
+
+	db.logger.WithFields(logrus.Fields{
+		"programs": len(programs),
+		"skipped":  len(records) - len(programs),
+		"file":     path,
+	}).Info("Imported programs")
+	return len(programs), nil
+}
+
+// programRecord converts a program to its archive record, keying its
+// features by the grid dimension they belong to
+func (db *ProgramDatabase) programRecord(program *types.Program) ProgramRecord {
+	var features map[string]float64
+	if len(program.Features) > 0 {
+		features = make(map[string]float64, len(program.Features))
+		for i, value := range program.Features {
+			if i < len(db.config.GridDimensions) {
+				features[db.config.GridDimensions[i]] = value
+			}
+		}
+	}
+	return ProgramRecord{
+		ID:             program.ID,
+		Code:           program.Code,
+		Score:          program.Score,
+		Failed:         program.Failed,
+		Origin:         program.Origin,
+		Metrics:        program.Metrics,
+		Features:       features,
+		Behavior:       program.Behavior,
+		Island:         program.IslandID,
+		Generation:     program.Generation,
+		ParentID:       program.ParentID,
+		InspirationIDs: program.InspirationIDs,
+		Changes:        program.Changes,
+		Artifacts:      program.Artifacts,
+		CreatedAt:      program.CreatedAt,
+	}
+}
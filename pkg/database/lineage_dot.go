@@ -0,0 +1,84 @@
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// ExportLineageDOT writes the parent to child edges of every program as a
+// Graphviz DOT graph, rendered with e.g. `dot -Tsvg lineage.dot`. Programs
+// and the edges into them are colored from red for the lowest score to
+// green for the highest; failed programs are grey and the champion is
+// outlined in bold.
+func (db *ProgramDatabase) ExportLineageDOT(w io.Writer) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	programs := sortedPrograms(db.programs)
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, program := range programs {
+		if !program.Failed {
+			low = math.Min(low, program.Score)
+			high = math.Max(high, program.Score)
+		}
+	}
+	color := func(program *types.Program) string {
+		if program.Failed {
+			return "0.000 0.000 0.750"
+		}
+		share := 1.0
+		if high > low {
+			share = (program.Score - low) / (high - low)
+		}
+		// Hue runs from red at 0 to green at 1/3
+		return fmt.Sprintf("%.3f 0.600 0.950", share/3)
+	}
+	var championID string
+	if best := db.globalBest.Load(); best != nil {
+		championID = best.ID
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "digraph lineage {")
+	fmt.Fprintln(writer, "\trankdir=TB;")
+	fmt.Fprintln(writer, "\tnode [shape=box, style=filled, fontname=\"monospace\"];")
+	for _, program := range programs {
+		label := fmt.Sprintf("%s\ngen %d  score %s", shortID(program.ID), program.Generation, strconv.FormatFloat(program.Score, 'g', 4, 64))
+		if program.Failed {
+			label += "\nfailed"
+		}
+		attributes := fmt.Sprintf("label=%s, fillcolor=%q", strconv.Quote(label), color(program))
+		if program.ID == championID {
+			attributes += ", penwidth=3"
+		}
+		fmt.Fprintf(writer, "\t%s [%s];\n", strconv.Quote(program.ID), attributes)
+	}
+	for _, program := range programs {
+		if _, ok := db.programs[program.ParentID]; !ok {
+			continue
+		}
+		fmt.Fprintf(writer, "\t%s -> %s [color=%q];\n", strconv.Quote(program.ParentID), strconv.Quote(program.ID), color(program))
+	}
+	fmt.Fprintln(writer, "}")
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write lineage graph: %w", err)
+	}
+	return nil
+}
+
+// shortID abbreviates a program ID for display
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
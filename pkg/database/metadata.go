@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// TagProgram adds tags to a stored program, skipping those it already has
+func (db *ProgramDatabase) TagProgram(id string, tags ...string) error {
+	return db.updateProgram(id, func(program *types.Program) {
+		for _, tag := range tags {
+			if !hasTag(program, tag) {
+				program.Tags = append(program.Tags, tag)
+			}
+		}
+	})
+}
+
+// SetMetadata sets one metadata value of a stored program. Values should
+// survive a JSON round trip, as checkpoints store them that way.
+func (db *ProgramDatabase) SetMetadata(id, key string, value any) error {
+	return db.updateProgram(id, func(program *types.Program) {
+		if program.Metadata == nil {
+			program.Metadata = make(map[string]any)
+		}
+		program.Metadata[key] = value
+	})
+}
+
+// updateProgram applies update to a stored program under its island's lock
+func (db *ProgramDatabase) updateProgram(id string, update func(*types.Program)) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	db.index.RLock()
+	program, exists := db.programs[id]
+	db.index.RUnlock()
+	if !exists {
+		return fmt.Errorf("program not found: %s", id)
+	}
+
+	island := db.islands[program.IslandID]
+	island.mu.Lock()
+	defer island.mu.Unlock()
+	update(program)
+	return nil
+}
+
+// hasTag reports whether a program carries tag
+func hasTag(program *types.Program, tag string) bool {
+	for _, existing := range program.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// copyMetadata deep-copies a metadata value, recursing into the maps and
+// slices JSON decodes nested values into
+func copyMetadata(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(value))
+		for key, nested := range value {
+			copied[key] = copyMetadata(nested)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(value))
+		for i, nested := range value {
+			copied[i] = copyMetadata(nested)
+		}
+		return copied
+	}
+	return value
+}
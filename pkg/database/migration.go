@@ -0,0 +1,69 @@
+package database
+
+import (
+	"math"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// recordBest appends the island's best score at the end of a generation,
+// keeping enough history to measure the slope over window generations
+func (i *Island) recordBest(window int) {
+	if math.IsInf(i.BestScore, 0) {
+		return
+	}
+	i.bestHistory = append(i.bestHistory, i.BestScore)
+	if len(i.bestHistory) > window+1 {
+		i.bestHistory = i.bestHistory[len(i.bestHistory)-window-1:]
+	}
+}
+
+// bestSlope returns the island's best-score gain per generation over its
+// recorded history. It reports false until two generations are recorded.
+func (i *Island) bestSlope() (float64, bool) {
+	if len(i.bestHistory) < 2 {
+		return 0, false
+	}
+	first, last := i.bestHistory[0], i.bestHistory[len(i.bestHistory)-1]
+	return (last - first) / float64(len(i.bestHistory)-1), true
+}
+
+// migration decides the share of migration candidates an island takes in.
+// Without adaptive migration, or before an island has enough history, it
+// is the configured migration rate. Otherwise it falls linearly from the
+// maximum rate for an island that stopped improving to the minimum rate
+// for one improving at the target slope or faster.
+func (db *ProgramDatabase) migration(island *Island) types.IslandMigration {
+	decision := types.IslandMigration{IslandID: island.ID, Rate: db.config.MigrationRate}
+
+	adaptive := db.config.AdaptiveMigration
+	if !adaptive.Enabled {
+		return decision
+	}
+	slope, ok := island.bestSlope()
+	if !ok {
+		return decision
+	}
+
+	progress := 0.0
+	if adaptive.TargetSlope > 0 {
+		progress = math.Max(0, math.Min(1, slope/adaptive.TargetSlope))
+	}
+	decision.Slope = slope
+	decision.Stagnating = slope <= 0
+	decision.Rate = adaptive.MaxRate - (adaptive.MaxRate-adaptive.MinRate)*progress
+	return decision
+}
+
+// migrationStats returns the current migration decision for every island
+// when adaptive migration is enabled
+func (db *ProgramDatabase) migrationStats() []types.IslandMigration {
+	if !db.config.AdaptiveMigration.Enabled {
+		return nil
+	}
+	decisions := make([]types.IslandMigration, len(db.islands))
+	for i, island := range db.islands {
+		decisions[i] = db.migration(island)
+	}
+	return decisions
+}
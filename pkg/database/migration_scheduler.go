@@ -0,0 +1,47 @@
+package database
+
+import "github.com/ishanwen-byte/openevolve-go/internal/types"
+
+// MigrateIfDue migrates programs when every island has advanced a migration
+// interval since the last migration. At most one migration is in flight:
+// it returns false without waiting when one is already running or none is
+// due. With async migration the migration runs in the background, taking
+// the write lock so it never interleaves with programs being added, and
+// WaitMigrations waits for it.
+func (db *ProgramDatabase) MigrateIfDue() bool {
+	if !db.ShouldMigrate() || !db.migrating.CompareAndSwap(false, true) {
+		return false
+	}
+	if !db.config.AsyncMigration {
+		defer db.migrating.Store(false)
+		return db.migrateDue()
+	}
+
+	db.migrationsRunning.Add(1)
+	go func() {
+		defer db.migrationsRunning.Done()
+		defer db.migrating.Store(false)
+		db.migrateDue()
+	}()
+	return true
+}
+
+// WaitMigrations waits for a background migration to finish
+func (db *ProgramDatabase) WaitMigrations() {
+	db.migrationsRunning.Wait()
+}
+
+// migrateDue migrates if a migration is still due once the write lock is
+// held, and reports whether it did
+func (db *ProgramDatabase) migrateDue() bool {
+	db.mu.Lock()
+	due := db.migrationDue()
+	var events []types.MigrationEvent
+	if due {
+		events = db.migrateLocked()
+	}
+	db.mu.Unlock()
+
+	db.notifyMigrations(events)
+	return due
+}
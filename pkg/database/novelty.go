@@ -0,0 +1,157 @@
+package database
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// noveltyArchive holds the behavior descriptors of past programs, which
+// outlive the programs themselves. A nil archive scores nothing.
+type noveltyArchive struct {
+	config types.NoveltyArchiveConfig
+
+	mu          sync.Mutex
+	descriptors [][]float64
+	// Sparsest score seen, which novelty is normalized by
+	sparsest float64
+}
+
+// newNoveltyArchive returns an archive, or nil when it is disabled
+func newNoveltyArchive(config types.NoveltyArchiveConfig) *noveltyArchive {
+	if !config.Enabled {
+		return nil
+	}
+	return &noveltyArchive{config: config}
+}
+
+// sparseness returns the mean distance from descriptor to its nearest
+// neighbors in the archive, comparing only descriptors of its length, and
+// whether there were any
+func (a *noveltyArchive) sparseness(descriptor []float64) (float64, bool) {
+	distances := make([]float64, 0, len(a.descriptors))
+	for _, other := range a.descriptors {
+		if len(other) != len(descriptor) {
+			continue
+		}
+		sum := 0.0
+		for i, value := range descriptor {
+			delta := value - other[i]
+			sum += delta * delta
+		}
+		distances = append(distances, math.Sqrt(sum))
+	}
+	if len(distances) == 0 {
+		return 0, false
+	}
+
+	sort.Float64s(distances)
+	k := a.config.Neighbors
+	if k <= 0 {
+		k = constants.DefaultNoveltyNeighbors
+	}
+	if k > len(distances) {
+		k = len(distances)
+	}
+	total := 0.0
+	for _, distance := range distances[:k] {
+		total += distance
+	}
+	return total / float64(k), true
+}
+
+// novelty scores a descriptor in [0, 1]: its sparseness relative to the
+// sparsest seen, or 1 when nothing comparable is archived yet
+func (a *noveltyArchive) novelty(descriptor []float64) float64 {
+	if a == nil || len(descriptor) == 0 {
+		return 1.0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	score, ok := a.sparseness(descriptor)
+	if !ok {
+		return 1.0
+	}
+	if score >= a.sparsest {
+		return 1.0
+	}
+	return score / a.sparsest
+}
+
+// add archives a descriptor when it is at least the threshold away from
+// its neighbors, dropping the oldest descriptors beyond the size
+func (a *noveltyArchive) add(descriptor []float64) {
+	if a == nil || len(descriptor) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	score, ok := a.sparseness(descriptor)
+	if ok && score < a.config.Threshold {
+		return
+	}
+	a.sparsest = math.Max(a.sparsest, score)
+	a.descriptors = append(a.descriptors, append([]float64(nil), descriptor...))
+
+	size := a.config.Size
+	if size <= 0 {
+		size = constants.DefaultNoveltyArchiveSize
+	}
+	if len(a.descriptors) > size {
+		a.descriptors = append([][]float64(nil), a.descriptors[len(a.descriptors)-size:]...)
+	}
+}
+
+// snapshot copies the descriptors for a checkpoint
+func (a *noveltyArchive) snapshot() [][]float64 {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	descriptors := make([][]float64, len(a.descriptors))
+	for i, descriptor := range a.descriptors {
+		descriptors[i] = append([]float64(nil), descriptor...)
+	}
+	return descriptors
+}
+
+// restore replaces the descriptors with those of a checkpoint
+func (a *noveltyArchive) restore(descriptors [][]float64) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.descriptors = nil
+	a.sparsest = 0
+	for _, descriptor := range descriptors {
+		if score, ok := a.sparseness(descriptor); ok {
+			a.sparsest = math.Max(a.sparsest, score)
+		}
+		a.descriptors = append(a.descriptors, descriptor)
+	}
+}
+
+// behaviorOf returns the descriptor a program is archived by: its reported
+// behavior, or else its features
+func behaviorOf(behavior, features []float64) []float64 {
+	if len(behavior) > 0 {
+		return behavior
+	}
+	return features
+}
+
+// BehaviorNovelty scores how unlike the archived behavior of past programs
+// a program's behavior, or its raw features when it reported none, is, in
+// [0, 1]. Without a novelty archive it returns 1.
+func (db *ProgramDatabase) BehaviorNovelty(behavior, features []float64) float64 {
+	return db.behaviors.novelty(behaviorOf(behavior, features))
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// objectiveValue returns a program's value on an objective, oriented so
+// that larger is better. A missing metric is the worst possible value.
+func objectiveValue(program *types.Program, objective types.Objective) float64 {
+	value, ok := program.Metrics[objective.Metric]
+	if !ok || math.IsNaN(value) {
+		return math.Inf(-1)
+	}
+	if objective.Minimize {
+		return -value
+	}
+	return value
+}
+
+// dominates reports whether a is at least as good as b on every objective
+// and strictly better on at least one
+func dominates(a, b *types.Program, objectives []types.Objective) bool {
+	better := false
+	for _, objective := range objectives {
+		va, vb := objectiveValue(a, objective), objectiveValue(b, objective)
+		if va < vb {
+			return false
+		}
+		if va > vb {
+			better = true
+		}
+	}
+	return better
+}
+
+// nonDominatedSort splits programs into successive Pareto fronts: the first
+// holds the programs no other program dominates, the next those dominated
+// only by the first, and so on. Programs keep their input order within a
+// front.
+func nonDominatedSort(programs []*types.Program, objectives []types.Objective) [][]*types.Program {
+	dominatedBy := make([]int, len(programs))
+	dominating := make([][]int, len(programs))
+	for a := range programs {
+		for b := range programs {
+			if a != b && dominates(programs[a], programs[b], objectives) {
+				dominating[a] = append(dominating[a], b)
+				dominatedBy[b]++
+			}
+		}
+	}
+
+	var fronts [][]*types.Program
+	var current []int
+	for idx, count := range dominatedBy {
+		if count == 0 {
+			current = append(current, idx)
+		}
+	}
+	for len(current) > 0 {
+		front := make([]*types.Program, 0, len(current))
+		var next []int
+		for _, idx := range current {
+			front = append(front, programs[idx])
+			for _, dominated := range dominating[idx] {
+				dominatedBy[dominated]--
+				if dominatedBy[dominated] == 0 {
+					next = append(next, dominated)
+				}
+			}
+		}
+		sort.Ints(next)
+		fronts = append(fronts, front)
+		current = next
+	}
+
+	return fronts
+}
+
+// crowdingDistance measures how isolated each program of a front is in
+// objective space; the extremes of every objective are infinitely isolated
+func crowdingDistance(front []*types.Program, objectives []types.Objective) map[string]float64 {
+	distance := make(map[string]float64, len(front))
+	for _, program := range front {
+		distance[program.ID] = 0
+	}
+	if len(front) < 3 {
+		for _, program := range front {
+			distance[program.ID] = math.Inf(1)
+		}
+		return distance
+	}
+
+	sorted := append([]*types.Program(nil), front...)
+	for _, objective := range objectives {
+		sort.SliceStable(sorted, func(a, b int) bool {
+			return objectiveValue(sorted[a], objective) < objectiveValue(sorted[b], objective)
+		})
+		low, high := objectiveValue(sorted[0], objective), objectiveValue(sorted[len(sorted)-1], objective)
+		distance[sorted[0].ID] = math.Inf(1)
+		distance[sorted[len(sorted)-1].ID] = math.Inf(1)
+		if high <= low || math.IsInf(high-low, 0) {
+			continue
+		}
+		for idx := 1; idx < len(sorted)-1; idx++ {
+			gap := objectiveValue(sorted[idx+1], objective) - objectiveValue(sorted[idx-1], objective)
+			distance[sorted[idx].ID] += gap / (high - low)
+		}
+	}
+	return distance
+}
+
+// updateFront adds a program to the island's Pareto front if nothing on
+// the front dominates it, dropping the members it dominates
+func (i *Island) updateFront(program *types.Program) {
+	if len(i.objectives) == 0 {
+		return
+	}
+
+	kept := make([]*types.Program, 0, len(i.front)+1)
+	for _, member := range i.front {
+		if member.ID == program.ID {
+			continue
+		}
+		if dominates(member, program, i.objectives) {
+			return
+		}
+		if !dominates(program, member, i.objectives) {
+			kept = append(kept, member)
+		}
+	}
+	i.front = append(kept, program)
+}
+
+// rebuildFront recomputes the island's Pareto front from its population
+func (i *Island) rebuildFront() {
+	i.front = nil
+	if len(i.objectives) == 0 || len(i.Programs) == 0 {
+		return
+	}
+	i.front = nonDominatedSort(sortedPrograms(i.Programs), i.objectives)[0]
+}
+
+// sampleNonDominated picks a parent by binary tournament: of two random
+// programs the one on the better front wins, ties going to the one less
+// crowded in objective space
+func (i *Island) sampleNonDominated(rng *rand.Rand) *types.Program {
+	programs := sortedPrograms(i.Programs)
+	if len(programs) == 0 {
+		return nil
+	}
+
+	rank := make(map[string]int, len(programs))
+	crowding := make(map[string]float64, len(programs))
+	for idx, front := range nonDominatedSort(programs, i.objectives) {
+		for id, distance := range crowdingDistance(front, i.objectives) {
+			rank[id] = idx
+			crowding[id] = distance
+		}
+	}
+
+	a, b := programs[rng.Intn(len(programs))], programs[rng.Intn(len(programs))]
+	if rank[b.ID] < rank[a.ID] || (rank[b.ID] == rank[a.ID] && crowding[b.ID] > crowding[a.ID]) {
+		return b
+	}
+	return a
+}
+
+// ParetoFront returns copies of the non-dominated programs of an island,
+// ordered by ID. It is empty unless objectives are configured.
+func (db *ProgramDatabase) ParetoFront(islandID int) ([]*types.Program, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if islandID < 0 || islandID >= len(db.islands) {
+		return nil, fmt.Errorf("invalid island ID: %d", islandID)
+	}
+
+	island := db.islands[islandID]
+	island.mu.RLock()
+	defer island.mu.RUnlock()
+
+	front := make(map[string]*types.Program, len(island.front))
+	for _, program := range island.front {
+		front[program.ID] = program
+	}
+	return copyPrograms(sortedPrograms(front)), nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// LoadOptions selects what LoadCheckpointWith takes from a checkpoint. The
+// zero value loads everything.
+type LoadOptions struct {
+	// Islands lists the checkpoint islands to load, in the order they are
+	// placed on the database's islands 0, 1, ...; nil loads every island
+	// under its own ID
+	Islands []int
+
+	// ElitesOnly keeps just the grid occupants and best program of each
+	// island, dropping the rest of its population
+	ElitesOnly bool
+}
+
+// partial reports whether the options drop anything from a checkpoint
+func (o LoadOptions) partial() bool {
+	return o.Islands != nil || o.ElitesOnly
+}
+
+// selectIslands narrows a decoded checkpoint down to what opts asks for.
+// Selected islands are renumbered from 0 and their programs moved with them.
+// A partial load seeds a new run, so generations, migration counts, migration
+// history and statistics start over and the global best is the best program kept.
+func selectIslands(checkpoint *types.Checkpoint, opts LoadOptions, numIslands int) error {
+	ids := opts.Islands
+	if ids == nil {
+		for id := range checkpoint.Islands {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+	} else if len(ids) > numIslands {
+		return fmt.Errorf("cannot load %d islands into %d", len(ids), numIslands)
+	}
+
+	islands := make(map[int]*types.Island, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for slot, id := range ids {
+		island, exists := checkpoint.Islands[id]
+		if !exists {
+			return fmt.Errorf("checkpoint has no island %d", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("island %d selected twice", id)
+		}
+		seen[id] = true
+		if opts.Islands == nil {
+			slot = id
+		}
+
+		if opts.ElitesOnly {
+			island.Programs = islandElites(island)
+		}
+		for _, program := range island.Programs {
+			program.IslandID = slot
+		}
+		island.ID = slot
+		island.Generation = 0
+		island.Migrated = 0
+		islands[slot] = island
+	}
+
+	checkpoint.Islands = islands
+	checkpoint.Iteration = 0
+	checkpoint.Generation = 0
+	checkpoint.Stats = types.EvolutionStats{}
+	checkpoint.Migrations = nil
+	checkpoint.GlobalBest = nil
+	for _, island := range islands {
+		for _, program := range island.Programs {
+			best := checkpoint.GlobalBest
+			if best == nil || rankScore(program) > rankScore(best) || (rankScore(program) == rankScore(best) && program.ID < best.ID) {
+				checkpoint.GlobalBest = program
+			}
+		}
+	}
+	return nil
+}
+
+// islandElites returns the programs of a checkpoint island that hold a grid
+// cell, belong to a cell's sub-population or are the island's best
+func islandElites(island *types.Island) map[string]*types.Program {
+	elites := make(map[string]*types.Program)
+	keep := func(program *types.Program) {
+		if program == nil {
+			return
+		}
+		// Prefer the population's instance of a program
+		if stored, exists := island.Programs[program.ID]; exists {
+			program = stored
+		}
+		elites[program.ID] = program
+	}
+
+	for _, program := range island.Grid.Cells {
+		keep(program)
+	}
+	for _, members := range island.Grid.Members {
+		for _, program := range members {
+			keep(program)
+		}
+	}
+	if island.BestID != "" {
+		keep(island.Programs[island.BestID])
+	}
+	return elites
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// EnterPhase records the run phase the controller moved to, saved with
+// checkpoints, and switches the islands to the phase's parent selection.
+// Islands configured with a selection strategy of their own keep it, and a
+// phase without one restores every island's own.
+func (db *ProgramDatabase) EnterPhase(phase types.PhaseConfig) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, island := range db.islands {
+		island.phaseSelection = ""
+		if !db.ownsSelection(island.ID) {
+			island.phaseSelection = phase.ParentSelection
+		}
+	}
+	db.stats.Phase = phase.Name
+}
+
+// ownsSelection reports whether an island is configured with a parent
+// selection strategy of its own
+func (db *ProgramDatabase) ownsSelection(islandID int) bool {
+	if _, exists := db.config.IslandParentSelection[islandID]; exists {
+		return true
+	}
+	return db.config.IslandOverrides[islandID].ParentSelection != ""
+}
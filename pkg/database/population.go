@@ -0,0 +1,156 @@
+package database
+
+import (
+	"math"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// evictionCandidates returns the programs of an island the population cap
+// may evict, most evictable first. Grid elites, the island's best, the
+// champion and the programs in keep are never candidates.
+func (i *Island) evictionCandidates(policy string, keep map[string]bool) []*types.Program {
+	protected := make(map[string]bool, len(i.Grid.Cells)+len(keep)+2)
+	for _, elite := range i.Grid.Cells {
+		protected[elite.ID] = true
+	}
+	protected[i.BestID] = true
+	protected[i.championID()] = true
+	for id := range keep {
+		protected[id] = true
+	}
+
+	// Programs in a cell's sub-population still hold a place in the archive
+	archived := make(map[string]bool)
+	for _, members := range i.Grid.Members {
+		for _, member := range members {
+			archived[member.ID] = true
+		}
+	}
+
+	candidates := make([]*types.Program, 0, len(i.Programs))
+	for _, program := range sortedPrograms(i.Programs) {
+		if !protected[program.ID] {
+			candidates = append(candidates, program)
+		}
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		pa, pb := candidates[a], candidates[b]
+		switch policy {
+		case constants.EvictionOldest:
+			return pa.CreatedAt.Before(pb.CreatedAt)
+		case constants.EvictionNonEliteFirst:
+			if archived[pa.ID] != archived[pb.ID] {
+				return !archived[pa.ID]
+			}
+		}
+		return fitnessOf(pa, i.novelty) < fitnessOf(pb, i.novelty)
+	})
+	return candidates
+}
+
+// enforcePopulationCap evicts programs from an island until its population
+// fits the configured size and returns how many it evicted. Evicted programs
+// leave the database, for cold storage if it is enabled; an island whose
+// protected programs alone exceed the cap keeps them all.
+func (db *ProgramDatabase) enforcePopulationCap(island *Island) int {
+	size := db.config.PopulationSize
+	if size <= 0 || len(island.Programs) <= size {
+		return 0
+	}
+
+	evicted := 0
+	for _, program := range db.evictionCandidates(island) {
+		if len(island.Programs) <= size {
+			break
+		}
+		db.evict(island, program)
+		evicted++
+	}
+
+	if evicted > 0 {
+		db.statsMu.Lock()
+		db.stats.Evicted += int64(evicted)
+		db.statsMu.Unlock()
+		db.logger.WithFields(logrus.Fields{
+			"island":     island.ID,
+			"evicted":    evicted,
+			"population": len(island.Programs),
+		}).Debug("Evicted programs over the population cap")
+	}
+	return evicted
+}
+
+// Prune evicts the given fraction of every island's evictable programs,
+// chosen by the eviction policy, to free memory. It returns how many
+// programs it evicted; grid elites, the best programs and the global
+// best's ancestors always stay.
+func (db *ProgramDatabase) Prune(fraction float64) int {
+	if fraction <= 0 {
+		return 0
+	}
+	fraction = math.Min(fraction, 1)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	evicted := 0
+	for _, island := range db.islands {
+		candidates := db.evictionCandidates(island)
+		n := int(math.Ceil(float64(len(candidates)) * fraction))
+		for _, program := range candidates[:n] {
+			db.evict(island, program)
+		}
+		evicted += n
+	}
+
+	db.stats.Evicted += int64(evicted)
+	db.logger.WithFields(logrus.Fields{
+		"evicted":  evicted,
+		"fraction": fraction,
+	}).Info("Pruned island populations")
+	return evicted
+}
+
+// evictionCandidates returns an island's evictable programs under the
+// configured policy, protecting the global best and its lineage as well
+func (db *ProgramDatabase) evictionCandidates(island *Island) []*types.Program {
+	policy := db.config.Eviction
+	if policy == "" {
+		policy = constants.EvictionLowestFitness
+	}
+	return island.evictionCandidates(policy, db.championLineage())
+}
+
+// championLineage returns the IDs of the global best and every ancestor
+// of it still stored, which must stay so its lineage chain is unbroken.
+// The caller holds the read or write lock but not the index lock.
+func (db *ProgramDatabase) championLineage() map[string]bool {
+	lineage := make(map[string]bool)
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	for program := db.globalBest.Load(); program != nil && !lineage[program.ID]; {
+		lineage[program.ID] = true
+		if program.ParentID == "" {
+			break
+		}
+		program = db.programs[program.ParentID]
+	}
+	return lineage
+}
+
+// evict removes a program from an island and the database, for cold
+// storage if it is enabled
+func (db *ProgramDatabase) evict(island *Island, program *types.Program) {
+	island.remove(program)
+	db.index.Lock()
+	delete(db.programs, program.ID)
+	db.index.Unlock()
+	db.archiveCold(program, island, constants.ColdReasonEvicted)
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// QDScore returns the sum of the scores of the island's cell elites that
+// did not fail
+func (i *Island) QDScore() float64 {
+	total := 0.0
+	for _, elite := range i.Grid.Cells {
+		if !elite.Failed {
+			total += elite.Score
+		}
+	}
+	return total
+}
+
+// recordQD appends the island's quality-diversity to the statistics. Several
+// programs added to an island in one iteration leave a single sample.
+func (db *ProgramDatabase) recordQD(island *Island, iteration int) {
+	sample := types.QDSample{
+		Iteration: iteration,
+		IslandID:  island.ID,
+		QDScore:   island.QDScore(),
+		Coverage:  island.GetOccupancy(),
+	}
+
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	history := db.stats.QDHistory
+	for idx := len(history) - 1; idx >= 0 && history[idx].Iteration == iteration; idx-- {
+		if history[idx].IslandID == island.ID {
+			history[idx] = sample
+			return
+		}
+	}
+	db.stats.QDHistory = append(history, sample)
+}
+
+// QDHistory returns the quality-diversity samples recorded so far
+func (db *ProgramDatabase) QDHistory() []types.QDSample {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	return append([]types.QDSample(nil), db.stats.QDHistory...)
+}
+
+// ExportQDHistory writes the quality-diversity samples to path as CSV with
+// the columns iteration, island, qd_score and coverage
+func (db *ProgramDatabase) ExportQDHistory(path string) error {
+	history := db.QDHistory()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create QD history directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create QD history file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"iteration", "island", "qd_score", "coverage"})
+	for _, sample := range history {
+		writer.Write([]string{
+			strconv.Itoa(sample.Iteration),
+			strconv.Itoa(sample.IslandID),
+			strconv.FormatFloat(sample.QDScore, 'g', -1, 64),
+			strconv.FormatFloat(sample.Coverage, 'g', -1, 64),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write QD history: %w", err)
+	}
+	return file.Close()
+}
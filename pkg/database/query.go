@@ -0,0 +1,94 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Query selects programs from the database. Unset fields match every
+// program.
+type Query struct {
+	// MinScore drops programs scoring below it
+	MinScore *float64
+	// Island keeps only the programs on this island
+	Island *int
+	// GenerationRange keeps programs whose generation lies within
+	// [min, max], inclusive
+	GenerationRange *[2]int
+	// CreatedAfter drops programs created at or before it
+	CreatedAfter time.Time
+	// Tags keeps only programs carrying every one of them
+	Tags []string
+	// Metadata keeps only programs whose metadata holds each key with a
+	// value that prints as the given string
+	Metadata map[string]string
+	// Limit caps how many programs are returned; 0 returns them all
+	Limit int
+}
+
+// matches reports whether a program satisfies the query's filters
+func (q Query) matches(program *types.Program) bool {
+	if q.MinScore != nil && program.Score < *q.MinScore {
+		return false
+	}
+	if q.Island != nil && program.IslandID != *q.Island {
+		return false
+	}
+	if r := q.GenerationRange; r != nil && (program.Generation < r[0] || program.Generation > r[1]) {
+		return false
+	}
+	if !q.CreatedAfter.IsZero() && !program.CreatedAt.After(q.CreatedAfter) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !hasTag(program, tag) {
+			return false
+		}
+	}
+	for key, want := range q.Metadata {
+		value, exists := program.Metadata[key]
+		if !exists || fmt.Sprint(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Query returns copies of the programs matching q, best score first and by
+// ID among equal scores
+func (db *ProgramDatabase) Query(q Query) []*types.Program {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	programs := make([]*types.Program, 0)
+	for _, program := range db.programs {
+		if q.matches(program) {
+			programs = append(programs, program)
+		}
+	}
+
+	sort.Slice(programs, func(a, b int) bool {
+		if rankScore(programs[a]) != rankScore(programs[b]) {
+			return rankScore(programs[a]) > rankScore(programs[b])
+		}
+		return programs[a].ID < programs[b].ID
+	})
+	if q.Limit > 0 && len(programs) > q.Limit {
+		programs = programs[:q.Limit]
+	}
+	return copyPrograms(programs)
+}
+
+// GetTopK returns the n best-scoring programs across all islands
+func (db *ProgramDatabase) GetTopK(n int) []*types.Program {
+	if n <= 0 {
+		return []*types.Program{}
+	}
+	return db.Query(Query{Limit: n})
+}
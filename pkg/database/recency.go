@@ -0,0 +1,56 @@
+package database
+
+import (
+	"math"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// recencyDecay discounts programs by how long ago they were last evaluated
+// or rescored. A nil decay leaves every value unchanged.
+type recencyDecay struct {
+	maxDiscount float64
+	halfLife    time.Duration
+}
+
+// newRecencyDecay returns a decay, or nil when recency decay is disabled
+func newRecencyDecay(config types.RecencyDecayConfig) *recencyDecay {
+	if !config.Enabled {
+		return nil
+	}
+	decay := &recencyDecay{
+		maxDiscount: config.MaxDiscount,
+		halfLife:    time.Duration(config.HalfLife) * time.Second,
+	}
+	if decay.maxDiscount <= 0 {
+		decay.maxDiscount = constants.DefaultRecencyMaxDiscount
+	}
+	if decay.halfLife <= 0 {
+		decay.halfLife = constants.DefaultRecencyHalfLife * time.Second
+	}
+	return decay
+}
+
+// apply discounts value, a program's score or fitness, by the program's
+// age. The discount grows from nothing towards maxDiscount of the value's
+// magnitude, reaching half of that after one half-life.
+func (d *recencyDecay) apply(program *types.Program, value float64) float64 {
+	if d == nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return value
+	}
+	validated := program.CreatedAt
+	if program.UpdatedAt.After(validated) {
+		validated = program.UpdatedAt
+	}
+	if validated.IsZero() {
+		return value
+	}
+	age := time.Since(validated)
+	if age <= 0 {
+		return value
+	}
+	share := 1 - math.Exp2(-float64(age)/float64(d.halfLife))
+	return value - math.Abs(value)*d.maxDiscount*share
+}
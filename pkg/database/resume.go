@@ -0,0 +1,106 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// LoadLatest loads the most recent intact checkpoint in checkpointDir and
+// returns the iteration to resume from. The latest checkpoint is tried
+// first, then the numbered ones from the highest iteration down, skipping
+// any that cannot be read or fail verification.
+func (db *ProgramDatabase) LoadLatest(checkpointDir string) (int, error) {
+	candidates := latestCheckpointFiles(checkpointDir)
+	files := CheckpointFiles(checkpointDir)
+	for i := len(files) - 1; i >= 0; i-- {
+		candidates = append(candidates, files[i])
+	}
+	if len(candidates) == 0 {
+		return 0, fmt.Errorf("no checkpoint found in %s", checkpointDir)
+	}
+
+	for _, path := range candidates {
+		checkpoint, err := ReadCheckpoint(path)
+		if err == nil {
+			err = verifyCheckpoint(checkpoint)
+		}
+		if err != nil {
+			db.logger.WithFields(logrus.Fields{
+				"file":  path,
+				"error": err,
+			}).Warn("Skipping unusable checkpoint")
+			continue
+		}
+		if err := db.restoreCheckpoint(checkpoint, path, LoadOptions{}); err != nil {
+			return 0, err
+		}
+		return checkpoint.Iteration, nil
+	}
+	return 0, fmt.Errorf("no intact checkpoint found in %s", checkpointDir)
+}
+
+// CheckpointFiles lists the numbered checkpoints in dir in iteration order
+func CheckpointFiles(dir string) []string {
+	if dir == "" {
+		return []string{}
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "checkpoint_*"))
+
+	iterations := make(map[string]int, len(paths))
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		name := strings.TrimPrefix(filepath.Base(path), "checkpoint_")
+		n, err := strconv.Atoi(strings.SplitN(name, ".", 2)[0])
+		if err != nil {
+			continue
+		}
+		iterations[path] = n
+		files = append(files, path)
+	}
+	sort.Slice(files, func(a, b int) bool { return iterations[files[a]] < iterations[files[b]] })
+	return files
+}
+
+// latestCheckpointFiles lists the latest checkpoints in dir, most recently
+// written first; there is one per checkpoint format the directory was
+// written in
+func latestCheckpointFiles(dir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(dir, "latest.*"))
+	modified := make(map[string]int64, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modified[path] = info.ModTime().UnixNano()
+		}
+	}
+	sort.SliceStable(paths, func(a, b int) bool { return modified[paths[a]] > modified[paths[b]] })
+	return paths
+}
+
+// verifyCheckpoint checks that a checkpoint is whole enough to load: its
+// islands are numbered from 0 without gaps and hold every program under its
+// own ID. A lost champion is not an error; loading adopts it again.
+func verifyCheckpoint(checkpoint *types.Checkpoint) error {
+	if len(checkpoint.Islands) == 0 {
+		return fmt.Errorf("checkpoint has no islands")
+	}
+	for id := 0; id < len(checkpoint.Islands); id++ {
+		island, ok := checkpoint.Islands[id]
+		if !ok || island == nil {
+			return fmt.Errorf("checkpoint is missing island %d", id)
+		}
+		for key, program := range island.Programs {
+			if program == nil || program.ID != key {
+				return fmt.Errorf("island %d has a broken entry for program %s", id, key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// SampleMultiple samples count distinct programs using the configured
+// strategy, starting from the current island
+func (db *ProgramDatabase) SampleMultiple(count int) ([]*types.Program, error) {
+	return db.SampleMultipleWith(db.GetCurrentIsland(), count, db.config.SampleStrategy, nil)
+}
+
+// SampleMultipleWith samples copies of count distinct programs for the
+// given island using strategy and rng. Fewer programs are returned only when the database
+// holds fewer than count.
+//
+//   - per_island picks elites round-robin across islands, starting at
+//     islandID
+//   - elite_biased favours fitter programs, weighting by fitness rank
+//   - diverse_cells spreads picks over grid cells far apart in feature space
+//
+// A nil rng draws from the database's own seeded source.
+func (db *ProgramDatabase) SampleMultipleWith(islandID, count int, strategy string, rng *rand.Rand) ([]*types.Program, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+	db.index.RLock()
+	defer db.index.RUnlock()
+
+	if rng == nil {
+		rng = db.rng
+	}
+
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid sample count: %d", count)
+	}
+	if islandID < 0 || islandID >= len(db.islands) {
+		return nil, fmt.Errorf("invalid island ID: %d", islandID)
+	}
+
+	var programs []*types.Program
+	switch strategy {
+	case "", constants.SampleStrategyPerIsland:
+		programs = db.samplePerIsland(islandID, count, rng)
+	case constants.SampleStrategyEliteBiased:
+		programs = sampleEliteBiased(sortedPrograms(db.programs), count, db.config.NoveltyWeight > 0, rng)
+	case constants.SampleStrategyDiverseCells:
+		programs = sampleDiverse(db.cellElites(), count, rng)
+	default:
+		return nil, fmt.Errorf("unknown sample strategy: %s", strategy)
+	}
+
+	// Top up from the global pool when the strategy ran out of candidates
+	if len(programs) < count {
+		chosen := make(map[string]bool, len(programs))
+		for _, program := range programs {
+			chosen[program.ID] = true
+		}
+		var rest []*types.Program
+		for _, program := range sortedPrograms(db.programs) {
+			if !chosen[program.ID] {
+				rest = append(rest, program)
+			}
+		}
+		shuffle(rest, rng)
+		for _, program := range rest {
+			if len(programs) >= count {
+				break
+			}
+			programs = append(programs, program)
+		}
+	}
+
+	return copyPrograms(programs), nil
+}
+
+// samplePerIsland takes one random elite per island in turn, starting at
+// start, until count programs are chosen or every island is exhausted
+func (db *ProgramDatabase) samplePerIsland(start, count int, rng *rand.Rand) []*types.Program {
+	candidates := make([][]*types.Program, len(db.islands))
+	for i, island := range db.islands {
+		candidates[i] = island.elites()
+		shuffle(candidates[i], rng)
+	}
+
+	programs := make([]*types.Program, 0, count)
+	chosen := make(map[string]bool)
+	for len(programs) < count {
+		progressed := false
+		for i := range db.islands {
+			if len(programs) >= count {
+				break
+			}
+			islandID := (start + i) % len(db.islands)
+			for len(candidates[islandID]) > 0 {
+				program := candidates[islandID][0]
+				candidates[islandID] = candidates[islandID][1:]
+				if !chosen[program.ID] {
+					chosen[program.ID] = true
+					programs = append(programs, program)
+					progressed = true
+					break
+				}
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	return programs
+}
+
+// sampleEliteBiased draws without replacement, weighting the program of
+// fitness rank r by 1/(r+1)
+func sampleEliteBiased(pool []*types.Program, count int, novelty bool, rng *rand.Rand) []*types.Program {
+	sort.SliceStable(pool, func(a, b int) bool {
+		return fitnessOf(pool[a], novelty) > fitnessOf(pool[b], novelty)
+	})
+
+	weights := make([]float64, len(pool))
+	total := 0.0
+	for rank := range pool {
+		weights[rank] = 1 / float64(rank+1)
+		total += weights[rank]
+	}
+
+	programs := make([]*types.Program, 0, count)
+	for len(programs) < count && total > 0 {
+		r := rng.Float64() * total
+		pick := len(pool) - 1
+		for i, weight := range weights {
+			if weight == 0 {
+				continue
+			}
+			pick = i
+			if r < weight {
+				break
+			}
+			r -= weight
+		}
+		programs = append(programs, pool[pick])
+		total -= weights[pick]
+		weights[pick] = 0
+	}
+
+	return programs
+}
+
+// sampleDiverse starts from a random program and then repeatedly adds the
+// program farthest from everything chosen so far
+func sampleDiverse(pool []*types.Program, count int, rng *rand.Rand) []*types.Program {
+	if len(pool) == 0 {
+		return nil
+	}
+
+	programs := []*types.Program{pool[rng.Intn(len(pool))]}
+	minDistance := make([]float64, len(pool))
+	for i := range minDistance {
+		minDistance[i] = math.Inf(1)
+	}
+
+	for len(programs) < count && len(programs) < len(pool) {
+		last := programs[len(programs)-1]
+		farthest := -1
+		for i, program := range pool {
+			if program == last {
+				minDistance[i] = -1
+			}
+			if minDistance[i] < 0 {
+				continue
+			}
+			minDistance[i] = math.Min(minDistance[i], featureDistance(program.Features, last.Features))
+			if farthest < 0 || minDistance[i] > minDistance[farthest] {
+				farthest = i
+			}
+		}
+		if farthest < 0 {
+			break
+		}
+		programs = append(programs, pool[farthest])
+	}
+
+	return programs
+}
+
+// cellElites returns the distinct programs occupying grid cells on any
+// island, ordered by ID
+func (db *ProgramDatabase) cellElites() []*types.Program {
+	elites := make(map[string]*types.Program)
+	for _, island := range db.islands {
+		for _, members := range island.Grid.Members {
+			for _, program := range members {
+				elites[program.ID] = program
+			}
+		}
+	}
+	return sortedPrograms(elites)
+}
+
+// elites returns the island's grid occupants ordered by cell key, then by
+// insertion within a cell, falling back to its whole population when the
+// grid is empty
+func (i *Island) elites() []*types.Program {
+	if len(i.Grid.Cells) == 0 {
+		return sortedPrograms(i.Programs)
+	}
+
+	keys := make([]string, 0, len(i.Grid.Members))
+	for key := range i.Grid.Members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	elites := make([]*types.Program, 0, len(keys))
+	for _, key := range keys {
+		elites = append(elites, i.Grid.Members[key]...)
+	}
+	return elites
+}
+
+// featureDistance returns the Euclidean distance between two feature vectors
+func featureDistance(a, b []float64) float64 {
+	sum := 0.0
+	for i := 0; i < len(a) && i < len(b); i++ {
+		delta := a[i] - b[i]
+		sum += delta * delta
+	}
+	return math.Sqrt(sum)
+}
+
+// shuffle permutes programs in place using rng
+func shuffle(programs []*types.Program, rng *rand.Rand) {
+	rng.Shuffle(len(programs), func(a, b int) { programs[a], programs[b] = programs[b], programs[a] })
+}
+
+// lockedSource serializes a random source so one rand.Rand can be shared by
+// concurrent samplers
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+// newLockedRand returns a goroutine-safe rand.Rand seeded with seed
+func newLockedRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
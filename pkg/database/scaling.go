@@ -0,0 +1,131 @@
+package database
+
+import (
+	"math"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+)
+
+// maxFeatureSamples bounds the raw values kept per dimension for the
+// scaling methods that need more than running min and max
+const maxFeatureSamples = 1000
+
+// featureSamples holds the most recent raw values of one feature dimension
+type featureSamples struct {
+	values []float64
+	next   int
+}
+
+// add records a value, replacing the oldest once full
+func (s *featureSamples) add(value float64) {
+	if len(s.values) < maxFeatureSamples {
+		s.values = append(s.values, value)
+		return
+	}
+	s.values[s.next] = value
+	s.next = (s.next + 1) % maxFeatureSamples
+}
+
+// sampledScaling reports whether a scaling method works from samples of
+// raw values rather than the island's running min-max statistics
+func sampledScaling(method string) bool {
+	switch method {
+	case constants.FeatureScalingZScore, constants.FeatureScalingQuantile, constants.FeatureScalingLog:
+		return true
+	}
+	return false
+}
+
+// observeFeatures records the raw values of the dimensions scaled from
+// samples, skipping those marked prescaled; it must run before the
+// features are scaled in place
+func (i *Island) observeFeatures(features []float64, prescaled []bool) {
+	for dimIdx, dim := range i.Grid.Dimensions {
+		if dimIdx >= len(features) || !sampledScaling(i.featureScaling[dim]) {
+			continue
+		}
+		if dimIdx < len(prescaled) && prescaled[dimIdx] {
+			continue
+		}
+		samples, exists := i.samples[dim]
+		if !exists {
+			samples = &featureSamples{}
+			i.samples[dim] = samples
+		}
+		samples.add(features[dimIdx])
+	}
+}
+
+// scaleIncoming scales the features of a program joining the island,
+// keeping the dimensions marked prescaled, such as those an imported
+// program carries over from a checkpoint, as they are
+func (i *Island) scaleIncoming(features []float64, prescaled []bool) []float64 {
+	scaled := i.ScaleFeatures(features)
+	for dimIdx, keep := range prescaled {
+		if keep && dimIdx < len(features) {
+			scaled[dimIdx] = features[dimIdx]
+		}
+	}
+	return scaled
+}
+
+// scaleSampled maps a raw value into [0, 1] with a sample-based method.
+// Without samples the value is returned as-is, like min-max scaling.
+func (i *Island) scaleSampled(dim string, value float64, method string) float64 {
+	samples := i.samples[dim]
+	if samples == nil || len(samples.values) == 0 {
+		return value
+	}
+	values := samples.values
+
+	var scaled float64
+	switch method {
+	case constants.FeatureScalingZScore:
+		// The normal CDF of the z-score spreads normally distributed
+		// values evenly over the grid
+		mean, std := meanStd(values)
+		if std == 0 {
+			return 0.5
+		}
+		scaled = 0.5 * (1 + math.Erf((value-mean)/(std*math.Sqrt2)))
+	case constants.FeatureScalingQuantile:
+		// Mid-rank among the samples, so ties land in the middle
+		below, equal := 0, 0
+		for _, sample := range values {
+			if sample < value {
+				below++
+			} else if sample == value {
+				equal++
+			}
+		}
+		scaled = (float64(below) + 0.5*float64(equal)) / float64(len(values))
+	case constants.FeatureScalingLog:
+		// log(1 + v - min) / log(1 + max - min) compresses heavy tails
+		low, high := values[0], values[0]
+		for _, sample := range values[1:] {
+			low = math.Min(low, sample)
+			high = math.Max(high, sample)
+		}
+		if high <= low {
+			return 0.5
+		}
+		scaled = math.Log1p(math.Max(value-low, 0)) / math.Log1p(high-low)
+	}
+
+	return math.Max(0, math.Min(1, scaled))
+}
+
+// meanStd returns the mean and population standard deviation of values
+func meanStd(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
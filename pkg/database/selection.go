@@ -0,0 +1,199 @@
+package database
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// selection returns the island's parent selection strategy: the run
+// phase's if it sets one, otherwise the island's own
+func (i *Island) selection() string {
+	if i.phaseSelection != "" {
+		return i.phaseSelection
+	}
+	return i.parentSelection
+}
+
+// exploits decides whether the next grid parent is picked by the island's
+// selection strategy rather than the uniform (or staleness-biased) default.
+// Tournaments are run over the whole population by SampleFromIslandWith.
+func (i *Island) exploits(rng *rand.Rand) bool {
+	switch i.selection() {
+	case "", constants.ParentSelectionUniform, constants.ParentSelectionTournament:
+		return false
+	}
+	return !i.explores(rng)
+}
+
+// explores draws whether a pick is made uniformly to keep exploring
+func (i *Island) explores(rng *rand.Rand) bool {
+	return rng.Float64() < i.explorationRatio
+}
+
+// runsTournament decides whether the next parent is the winner of a
+// tournament over the island's population
+func (i *Island) runsTournament(rng *rand.Rand) bool {
+	return i.selection() == constants.ParentSelectionTournament && len(i.Programs) > 0 && !i.explores(rng)
+}
+
+// sampleTournament draws tournamentSize programs from the population with
+// replacement and returns the fittest. Larger tournaments favour the best
+// programs more strongly.
+func (i *Island) sampleTournament(rng *rand.Rand) *types.Program {
+	programs := sortedPrograms(i.Programs)
+	contenders := make([]*types.Program, i.tournamentSize)
+	for round := range contenders {
+		contenders[round] = programs[rng.Intn(len(programs))]
+	}
+	scores := i.sharedValues(contenders, programs, rankScore)
+
+	winner := 0
+	for round := range contenders {
+		if scores[round] > scores[winner] {
+			winner = round
+		}
+	}
+	return contenders[winner]
+}
+
+// sampleExploit picks an elite by the island's selection strategy
+func (i *Island) sampleExploit(rng *rand.Rand) *types.Program {
+	elites := i.elites()
+	switch i.selection() {
+	case constants.ParentSelectionEpsilonGreedy:
+		scores := i.sharedValues(elites, elites, rankScore)
+		best := 0
+		for idx := range elites {
+			if scores[idx] > scores[best] {
+				best = idx
+			}
+		}
+		return elites[best]
+	case constants.ParentSelectionRank:
+		return i.sampleRanked(rng, elites)
+	case constants.ParentSelectionBoltzmann:
+		return i.sampleBoltzmann(rng, elites)
+	case constants.ParentSelectionFitnessProportional:
+		weights := i.sharedValues(elites, elites, func(program *types.Program) float64 {
+			return fitnessOf(program, i.novelty)
+		})
+		return pickWeighted(rng, elites, positive(weights))
+	default:
+		weights := i.sharedValues(elites, elites, rankScore)
+		return pickWeighted(rng, elites, positive(weights))
+	}
+}
+
+// sampleRanked picks an elite with weight rank^-exponent, ranking by score
+// with ties broken by ID. Unlike score-proportional picks this depends only
+// on the order of scores, so the bias toward the best holds however close
+// their scores are, while low-ranked elites keep a nonzero chance.
+func (i *Island) sampleRanked(rng *rand.Rand, elites []*types.Program) *types.Program {
+	scores := make(map[*types.Program]float64, len(elites))
+	for idx, score := range i.sharedValues(elites, elites, rankScore) {
+		scores[elites[idx]] = score
+	}
+	ranked := append([]*types.Program(nil), elites...)
+	sort.SliceStable(ranked, func(a, b int) bool {
+		if scores[ranked[a]] != scores[ranked[b]] {
+			return scores[ranked[a]] > scores[ranked[b]]
+		}
+		return ranked[a].ID < ranked[b].ID
+	})
+
+	weights := make([]float64, len(ranked))
+	for idx := range ranked {
+		weights[idx] = math.Pow(float64(idx+1), -i.rankExponent)
+	}
+	return pickWeighted(rng, ranked, weights)
+}
+
+// temperature returns the island's boltzmann temperature, halved every
+// half-life generations down to the configured floor
+func (i *Island) temperature() float64 {
+	temperature := i.boltzmann.Temperature
+	if i.boltzmann.HalfLife > 0 {
+		temperature *= math.Pow(0.5, float64(i.Generation)/float64(i.boltzmann.HalfLife))
+	}
+	return math.Max(temperature, i.boltzmann.MinTemperature)
+}
+
+// sampleBoltzmann picks an elite with weight exp(score/temperature).
+// Failed elites get no weight, and a pick among only failed elites is
+// uniform.
+func (i *Island) sampleBoltzmann(rng *rand.Rand, elites []*types.Program) *types.Program {
+	// Shift by the best score so the exponentials cannot overflow
+	scores := i.sharedValues(elites, elites, rankScore)
+	best := math.Inf(-1)
+	for _, score := range scores {
+		best = math.Max(best, score)
+	}
+
+	temperature := i.temperature()
+	if temperature <= 0 {
+		temperature = math.SmallestNonzeroFloat64
+	}
+	weights := make([]float64, len(elites))
+	for idx, score := range scores {
+		if !math.IsInf(score, -1) {
+			weights[idx] = math.Exp((score - best) / temperature)
+		}
+	}
+	return pickWeighted(rng, elites, weights)
+}
+
+// proportionalEpsilon is the weight left to the worst program of a
+// proportional pick once weights are shifted to be positive
+const proportionalEpsilon = 1e-6
+
+// positive makes the weights of a proportional pick positive in place.
+// When any weight is zero or below, all are shifted by the lowest so it
+// becomes proportionalEpsilon, which keeps the pick biased toward higher
+// values under minimize and for negative scores. Failed programs, weighted
+// -Inf, get zero.
+func positive(weights []float64) []float64 {
+	lowest := math.Inf(1)
+	for _, weight := range weights {
+		if !math.IsInf(weight, 0) && !math.IsNaN(weight) {
+			lowest = math.Min(lowest, weight)
+		}
+	}
+
+	shift := 0.0
+	if lowest <= 0 {
+		shift = proportionalEpsilon - lowest
+	}
+	for idx, weight := range weights {
+		if math.IsInf(weight, -1) || math.IsNaN(weight) {
+			weights[idx] = 0
+		} else {
+			weights[idx] = weight + shift
+		}
+	}
+	return weights
+}
+
+// pickWeighted draws a program with probability proportional to its
+// weight, uniformly when every weight is zero
+func pickWeighted(rng *rand.Rand, programs []*types.Program, weights []float64) *types.Program {
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total <= 0 {
+		return programs[rng.Intn(len(programs))]
+	}
+
+	r := rng.Float64() * total
+	for idx, weight := range weights {
+		if r < weight {
+			return programs[idx]
+		}
+		r -= weight
+	}
+	return programs[len(programs)-1]
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"math"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// fitnessSharing divides the fitness of programs by their niche count, the
+// number of programs around them weighted by closeness, so that crowded
+// lineages are picked as parents less often. A nil sharing leaves every
+// value unchanged.
+type fitnessSharing struct {
+	distance string
+	radius   float64
+	alpha    float64
+}
+
+// newFitnessSharing returns a sharing, or nil when fitness sharing is
+// disabled
+func newFitnessSharing(config types.FitnessSharingConfig) *fitnessSharing {
+	if !config.Enabled {
+		return nil
+	}
+	sharing := &fitnessSharing{
+		distance: config.Distance,
+		radius:   config.Radius,
+		alpha:    config.Alpha,
+	}
+	if sharing.distance == "" {
+		sharing.distance = constants.SharingDistanceFeatures
+	}
+	if sharing.radius <= 0 {
+		sharing.radius = constants.DefaultSharingRadius
+	}
+	if sharing.alpha <= 0 {
+		sharing.alpha = constants.DefaultSharingAlpha
+	}
+	return sharing
+}
+
+// between returns the distance between two programs
+func (s *fitnessSharing) between(a, b *types.Program) float64 {
+	if s.distance == constants.SharingDistanceCode {
+		return LineDistance(a.Code, b.Code)
+	}
+	return featureDistance(a.Features, b.Features)
+}
+
+// niche returns the niche count of program among programs: 1 for itself
+// plus 1-(d/radius)^alpha for every other program within the radius. Code
+// distance is measured against an evenly spaced sample of at most
+// maxDiversitySample programs, scaled up to the whole population.
+func (s *fitnessSharing) niche(program *types.Program, programs []*types.Program) float64 {
+	neighbors := programs
+	if s.distance == constants.SharingDistanceCode && len(programs) > maxDiversitySample {
+		neighbors = make([]*types.Program, maxDiversitySample)
+		for idx := range neighbors {
+			neighbors[idx] = programs[idx*len(programs)/maxDiversitySample]
+		}
+	}
+
+	shared, compared := 0.0, 0
+	for _, other := range neighbors {
+		if other.ID == program.ID {
+			continue
+		}
+		compared++
+		if distance := s.between(program, other); distance < s.radius {
+			shared += 1 - math.Pow(distance/s.radius, s.alpha)
+		}
+	}
+	if len(neighbors) < len(programs) && compared > 0 {
+		shared *= float64(len(programs)-1) / float64(compared)
+	}
+	return 1 + shared
+}
+
+// share returns the value a program with the given niche count competes
+// with: positive values are divided by it and negative ones multiplied, so
+// crowding always lowers a program's standing
+func share(value, niche float64) float64 {
+	if value > 0 {
+		return value / niche
+	}
+	return value * niche
+}
+
+// sharedValues returns value of each program, shared among its neighbors
+// in population when the island shares fitness
+func (i *Island) sharedValues(programs, population []*types.Program, value func(*types.Program) float64) []float64 {
+	values := make([]float64, len(programs))
+	for idx, program := range programs {
+		values[idx] = value(program)
+		if i.sharing != nil && !math.IsInf(values[idx], 0) {
+			values[idx] = share(values[idx], i.sharing.niche(program, population))
+		}
+	}
+	return values
+}
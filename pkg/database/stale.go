@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// MarkScoresStale records that the evaluator program changed to the one
+// with the given hash at iteration, and marks every stored score stale, as
+// scores from different evaluators are not comparable. It returns how many
+// programs were marked.
+func (db *ProgramDatabase) MarkScoresStale(iteration int, hash string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	marked := 0
+	for _, program := range db.programs {
+		if !program.Stale {
+			program.Stale = true
+			marked++
+		}
+	}
+	db.stats.EvaluatorChanges = append(db.stats.EvaluatorChanges, types.EvaluatorChange{
+		Iteration:     iteration,
+		Hash:          hash,
+		StalePrograms: marked,
+		Time:          time.Now(),
+	})
+
+	db.logger.WithFields(logrus.Fields{
+		"iteration": iteration,
+		"stale":     marked,
+	}).Warn("Evaluator program changed, marked stored scores stale")
+	return marked
+}
+
+// StaleElites returns copies of the grid occupants and the global best whose
+// scores are stale, the programs worth re-evaluating after an evaluator change
+func (db *ProgramDatabase) StaleElites() []*types.Program {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	defer db.readIslands()()
+
+	stale := make([]*types.Program, 0)
+	for _, program := range db.cellElites() {
+		if program.Stale {
+			stale = append(stale, program)
+		}
+	}
+	if best := db.globalBest.Load(); best != nil && best.Stale && !containsProgram(stale, best) {
+		stale = append(stale, best)
+	}
+	return copyPrograms(stale)
+}
+
+// Rescore replaces a program's score and metrics with a fresh evaluation,
+// clears its stale mark and re-elects the cell elites, island bests and
+// global best
+func (db *ProgramDatabase) Rescore(id string, score float64, metrics map[string]float64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	program, exists := db.programs[id]
+	if !exists {
+		return fmt.Errorf("program not found: %s", id)
+	}
+
+	// Keep any novelty bonus blended into the fitness
+	program.Fitness += score - program.Score
+	program.Score = score
+	program.Metrics = metrics
+	program.Stale = false
+	program.UpdatedAt = time.Now()
+
+	db.reelect()
+	return nil
+}
+
+// reelect recomputes the global best, then every island's best, cell
+// elites and front after scores changed in place
+func (db *ProgramDatabase) reelect() {
+	var best *types.Program
+	for _, island := range db.islands {
+		for _, program := range sortedPrograms(island.Programs) {
+			if best == nil || db.decay.apply(program, rankScore(program)) > db.decay.apply(best, rankScore(best)) {
+				best = program
+			}
+		}
+	}
+	db.globalBest.Store(best)
+
+	for _, island := range db.islands {
+		island.BestProgram = nil
+		island.BestID = ""
+		island.BestScore = math.Inf(-1)
+		for _, program := range sortedPrograms(island.Programs) {
+			if rankScore(program) > island.BestScore {
+				island.BestProgram = program
+				island.BestID = program.ID
+				island.BestScore = program.Score
+			}
+		}
+
+		for key, members := range island.Grid.Members {
+			island.Grid.Cells[key] = island.fittest(members)
+		}
+		// A champion that had lost its cell takes it back
+		if id := island.championID(); id != "" && len(island.Grid.Dimensions) > 0 {
+			champion := island.Programs[id]
+			key := island.calculateCellKey(champion.Features)
+			if island.Grid.Cells[key] != champion {
+				island.place(key, champion)
+			}
+		}
+		island.rebuildFront()
+	}
+}
+
+// containsProgram reports whether program is in programs
+func containsProgram(programs []*types.Program, program *types.Program) bool {
+	for _, candidate := range programs {
+		if candidate == program {
+			return true
+		}
+	}
+	return false
+}
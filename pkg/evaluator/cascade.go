@@ -3,20 +3,34 @@ package evaluator
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/subprocess"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
 )
 
+// stageArtifactKey namespaces an artifact under the stage that produced it,
+// so stages do not overwrite each other's output
+func stageArtifactKey(stage, key string) string {
+	return stage + "/" + key
+}
+
 // CascadeStage represents a stage in the cascade evaluation
 type CascadeStage struct {
 	Name      string        `json:"name"`
 	Threshold float64       `json:"threshold"`
 	Timeout   time.Duration `json:"timeout"`
 	Critical  bool          `json:"critical"`
+	// Independent non-critical stages may run concurrently with adjacent
+	// independent stages
+	Independent bool `json:"independent"`
 }
 
 // CascadeEvaluator handles multi-stage cascade evaluation
@@ -24,6 +38,15 @@ type CascadeEvaluator struct {
 	stages    []CascadeStage
 	logger    *logrus.Logger
 	programPath string
+	auditor   *audit.Logger
+	// Runs stages without network access
+	denyNetwork bool
+
+	// Per-stage adaptive timeouts; stages without a tracker keep theirs
+	timeouts map[string]*timeoutTracker
+
+	// run executes one stage; replaced in tests
+	run func(ctx context.Context, stage CascadeStage, stageNumber int) (*types.EvaluationResult, error)
 }
 
 // NewCascadeEvaluator creates a new cascade evaluator
@@ -37,13 +60,37 @@ func NewCascadeEvaluator(stages []types.CascadeStage, programPath string) *Casca
 			Threshold: stage.Threshold,
 			Timeout:   time.Duration(stage.Timeout) * time.Second,
 			Critical:  stage.Critical,
+			Independent: stage.Independent,
 		}
 	}
 
-	return &CascadeEvaluator{
+	ce := &CascadeEvaluator{
 		stages:      cascadeStages,
 		logger:      logger,
 		programPath: programPath,
+		denyNetwork: true,
+	}
+	ce.run = ce.runStage
+	return ce
+}
+
+// SetAuditLogger records every stage subprocess in the audit log
+func (ce *CascadeEvaluator) SetAuditLogger(auditor *audit.Logger) {
+	ce.auditor = auditor
+}
+
+// SetNetworkPolicy sets whether stages may reach the network: deny (the
+// default) or allow
+func (ce *CascadeEvaluator) SetNetworkPolicy(policy string) {
+	ce.denyNetwork = policy != constants.NetworkAllow
+}
+
+// SetAdaptiveTimeout derives each stage's timeout from the durations of
+// its recent successful runs
+func (ce *CascadeEvaluator) SetAdaptiveTimeout(config types.AdaptiveTimeoutConfig) {
+	ce.timeouts = make(map[string]*timeoutTracker, len(ce.stages))
+	for _, stage := range ce.stages {
+		ce.timeouts[stage.Name] = newTimeoutTracker(config)
 	}
 }
 
@@ -60,56 +107,157 @@ func (ce *CascadeEvaluator) Evaluate(ctx context.Context) (*types.EvaluationResu
 		result.Duration = time.Since(startTime)
 	}()
 
-	// Run through each stage
-	for i, stage := range ce.stages {
-		stageResult, err := ce.runStage(ctx, stage, i+1)
-		if err != nil {
-			result.Error = err.Error()
-			result.Artifacts["failure_stage"] = stage.Name
-			result.Artifacts["stage_error"] = err.Error()
-			ce.logger.WithFields(logrus.Fields{
-				"stage": stage.Name,
-				"error": err,
-			}).Error("Stage evaluation failed")
+	// Run through the stages, group by group
+	for i := 0; i < len(ce.stages); {
+		end := ce.groupEnd(i)
+
+		// Stop before starting another stage if the caller gave up
+		if err := ctx.Err(); err != nil {
+			result.Error = fmt.Sprintf("Evaluation cancelled before stage %s: %v", ce.stages[i].Name, err)
+			result.Artifacts["failure_stage"] = ce.stages[i].Name
 			return result, err
 		}
 
-		// Check if stage passed threshold
-		if stageResult.Score < stage.Threshold {
-			result.Success = false
-			result.Score = stageResult.Score
-			result.Error = fmt.Sprintf("Stage %s failed threshold: %.3f < %.3f",
-				stage.Name, stageResult.Score, stage.Threshold)
-			result.Artifacts["failure_stage"] = stage.Name
-			result.Artifacts["threshold_failed"] = "true"
-
-			// If this is a critical stage, return immediately
-			if stage.Critical {
-				return result, fmt.Errorf("critical stage %s failed threshold", stage.Name)
-			}
+		stageResults, stageErrs := ce.runGroup(ctx, i, end)
 
-			// Non-critical stage failed, continue with warning
-			ce.logger.WithFields(logrus.Fields{
-				"stage":     stage.Name,
-				"score":     stageResult.Score,
-				"threshold": stage.Threshold,
-			}).Warn("Stage failed threshold but continuing")
+		// Merge in stage order so results match a sequential run
+		for j := i; j < end; j++ {
+			if err := ce.mergeStage(result, ce.stages[j], stageResults[j-i], stageErrs[j-i], j == 0); err != nil {
+				return result, err
+			}
 		}
+		i = end
+	}
+
+	// All stages completed successfully
+	result.Success = true
+	return result, nil
+}
+
+// groupEnd returns the index just past the group of stages starting at
+// start. Consecutive independent non-critical stages form one group; every
+// other stage runs on its own.
+func (ce *CascadeEvaluator) groupEnd(start int) int {
+	end := start + 1
+	if !ce.parallelizable(ce.stages[start]) {
+		return end
+	}
+	for end < len(ce.stages) && ce.parallelizable(ce.stages[end]) {
+		end++
+	}
+	return end
+}
+
+// parallelizable reports whether a stage may share a group with others
+func (ce *CascadeEvaluator) parallelizable(stage CascadeStage) bool {
+	return stage.Independent && !stage.Critical
+}
+
+// runGroup runs stages [start, end) concurrently
+func (ce *CascadeEvaluator) runGroup(ctx context.Context, start, end int) ([]*types.EvaluationResult, []error) {
+	results := make([]*types.EvaluationResult, end-start)
+	errs := make([]error, end-start)
+	if end-start == 1 {
+		results[0], errs[0] = ce.runTimed(ctx, start)
+		return results, errs
+	}
+
+	var wg sync.WaitGroup
+	for i := start; i < end; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i-start], errs[i-start] = ce.runTimed(ctx, i)
+		}(i)
+	}
+	wg.Wait()
 
-		// Update result with stage metrics
-		if stageResult.Score > result.Score {
-			result.Score = stageResult.Score
+	return results, errs
+}
+
+// runTimed runs stage i under its current timeout and records how long it
+// took
+func (ce *CascadeEvaluator) runTimed(ctx context.Context, i int) (*types.EvaluationResult, error) {
+	stage := ce.stages[i]
+	tracker := ce.timeouts[stage.Name]
+	stage.Timeout = tracker.Timeout(stage.Timeout)
+
+	start := time.Now()
+	result, err := ce.run(ctx, stage, i+1)
+	if result != nil {
+		result.Duration = time.Since(start)
+		result.Timeout = stage.Timeout
+		if err == nil && result.Success {
+			tracker.Record(result.Duration)
 		}
+	}
+	return result, err
+}
 
-		// Merge artifacts
+// mergeStage folds a stage outcome into the overall result, whose score is
+// the best stage score merged so far; first marks the cascade's first stage.
+// It returns an error when the cascade must stop.
+func (ce *CascadeEvaluator) mergeStage(result *types.EvaluationResult, stage CascadeStage, stageResult *types.EvaluationResult, err error, first bool) error {
+	// A diverging stage fails the cascade rather than scoring NaN or ±Inf
+	if err == nil && (math.IsNaN(stageResult.Score) || math.IsInf(stageResult.Score, 0)) {
+		err = fmt.Errorf("stage %s reported a non-finite score: %v", stage.Name, stageResult.Score)
+	}
+	if stageResult != nil {
 		for k, v := range stageResult.Artifacts {
-			result.Artifacts[k] = v
+			result.Artifacts[stageArtifactKey(stage.Name, k)] = v
+		}
+		result.Artifacts[stageArtifactKey(stage.Name, "duration")] = stageResult.Duration.String()
+		if stageResult.Timeout > 0 {
+			result.Artifacts[stageArtifactKey(stage.Name, "timeout")] = stageResult.Timeout.String()
+		}
+		if err == nil {
+			result.Artifacts[stageArtifactKey(stage.Name, "score")] = strconv.FormatFloat(stageResult.Score, 'f', -1, 64)
 		}
 	}
 
-	// All stages completed successfully
-	result.Success = true
-	return result, nil
+	if err != nil {
+		result.Error = err.Error()
+		result.Artifacts["failure_stage"] = stage.Name
+		result.Artifacts["stage_error"] = err.Error()
+		// The program fails to build whichever stage runs it first
+		if stageResult != nil && stageResult.Artifacts[CompileErrorsArtifact] != "" {
+			result.Artifacts[CompileErrorsArtifact] = stageResult.Artifacts[CompileErrorsArtifact]
+		}
+		ce.logger.WithFields(logrus.Fields{
+			"stage": stage.Name,
+			"error": err,
+		}).Error("Stage evaluation failed")
+		return err
+	}
+
+	// Check if stage passed threshold
+	if stageResult.Score < stage.Threshold {
+		result.Success = false
+		result.Score = stageResult.Score
+		result.Error = fmt.Sprintf("Stage %s failed threshold: %.3f < %.3f",
+			stage.Name, stageResult.Score, stage.Threshold)
+		result.Artifacts["failure_stage"] = stage.Name
+		result.Artifacts["threshold_failed"] = "true"
+
+		// If this is a critical stage, return immediately
+		if stage.Critical {
+			return fmt.Errorf("critical stage %s failed threshold", stage.Name)
+		}
+
+		// Non-critical stage failed, continue with warning
+		ce.logger.WithFields(logrus.Fields{
+			"stage":     stage.Name,
+			"score":     stageResult.Score,
+			"threshold": stage.Threshold,
+		}).Warn("Stage failed threshold but continuing")
+	}
+
+	// Update result with stage metrics
+	if first || stageResult.Score > result.Score {
+		result.Score = stageResult.Score
+	}
+
+	return nil
 }
 
 // runStage executes a single cascade stage
@@ -119,25 +267,35 @@ func (ce *CascadeEvaluator) runStage(ctx context.Context, stage CascadeStage, st
 		"number": stageNumber,
 	}).Debug("Running cascade stage")
 
-	// Create context with timeout
-	stageCtx, cancel := context.WithTimeout(ctx, stage.Timeout)
-	defer cancel()
+	// Create context with timeout; stages without one inherit the caller's deadline
+	stageCtx := ctx
+	if stage.Timeout > 0 {
+		var cancel context.CancelFunc
+		stageCtx, cancel = context.WithTimeout(ctx, stage.Timeout)
+		defer cancel()
+	}
 
 	// Prepare command to run stage evaluation function
-	cmd := exec.CommandContext(stageCtx, "go", "run",
+	output, err := subprocess.Run(stageCtx, subprocess.Options{Auditor: ce.auditor, DenyNetwork: ce.denyNetwork}, "go", "run",
 		"-tags", "evaluator",
 		ce.programPath,
 		fmt.Sprintf("--stage=stage%d", stageNumber))
 
-	// Run the command
-	output, err := cmd.CombinedOutput()
-
 	result := &types.EvaluationResult{
 		ID:        fmt.Sprintf("stage%d-%s", stageNumber, stage.Name),
 		Success:   false,
 		Artifacts: make(map[string]string),
 		Duration:  0,
 	}
+	if ce.denyNetwork {
+		defer recordNetworkAttempts(result, output)
+	}
+
+	// Cancellation from above is not the stage's fault
+	if ctx.Err() != nil {
+		result.Error = fmt.Sprintf("Stage %s cancelled: %v", stage.Name, ctx.Err())
+		return result, ctx.Err()
+	}
 
 	// Check for timeout
 	if stageCtx.Err() == context.DeadlineExceeded {
@@ -152,19 +310,20 @@ func (ce *CascadeEvaluator) runStage(ctx context.Context, stage CascadeStage, st
 		result.Error = fmt.Sprintf("Stage %s execution failed: %v", stage.Name, err)
 		result.Artifacts["stderr"] = string(output)
 		result.Artifacts["error"] = err.Error()
+		recordCompileErrors(result, output)
 		return result, fmt.Errorf("stage execution failed: %w", err)
 	}
 
 	// Parse output to extract score
 	// Expected format: "SCORE: <score>" or JSON output
-	score := ce.parseScoreOutput(string(output))
-	result.Score = score
+	score, ok := ce.parseScoreOutput(string(output))
 	result.Artifacts["stdout"] = string(output)
-
-	// If score is valid, mark as successful
-	if score >= 0 {
-		result.Success = true
+	if !ok {
+		result.Error = fmt.Sprintf("Stage %s output has no score", stage.Name)
+		return result, fmt.Errorf("stage %s output has no score", stage.Name)
 	}
+	result.Score = score
+	result.Success = true
 
 	ce.logger.WithFields(logrus.Fields{
 		"stage": stage.Name,
@@ -175,7 +334,7 @@ func (ce *CascadeEvaluator) runStage(ctx context.Context, stage CascadeStage, st
 }
 
 // parseScoreOutput extracts score from stage output
-func (ce *CascadeEvaluator) parseScoreOutput(output string) float64 {
+func (ce *CascadeEvaluator) parseScoreOutput(output string) (float64, bool) {
 	// Try to parse JSON first (simplified)
 	// In a real implementation, you'd use a proper JSON parser
 	lines := []string{output}
@@ -187,14 +346,13 @@ func (ce *CascadeEvaluator) parseScoreOutput(output string) float64 {
 			var score float64
 			_, err := fmt.Sscanf(line[7:], "%f", &score)
 			if err == nil {
-				return score
+				return score, true
 			}
 		}
 
 		// Add more parsing patterns as needed
 	}
 
-	// Default score if parsing fails
 	ce.logger.WithField("output", output).Warn("Could not parse score from output")
-	return -1.0
+	return 0, false
 }
\ No newline at end of file
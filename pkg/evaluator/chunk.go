@@ -0,0 +1,173 @@
+package evaluator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/goimport"
+)
+
+// chunkModulePath is the module path of a chunked program's go.mod
+const chunkModulePath = "program"
+
+// goDirectivePattern extracts the language version from a toolchain version
+var goDirectivePattern = regexp.MustCompile(`^go(\d+\.\d+)`)
+
+// splitProgram splits a Go program into files of roughly size bytes of
+// declarations each. Every file repeats the package clause and keeps only
+// the imports its declarations use. It returns false when the program
+// cannot be split safely: it does not parse, uses cgo or dot imports, or
+// imports a package whose name cannot be told from its path.
+func splitProgram(code string, size int) ([]string, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", code, parser.ParseComments)
+	if err != nil {
+		return nil, false
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	// Comments and build constraints ahead of the package clause go in
+	// every file
+	header := code[:offset(file.Package)] + "package " + file.Name.Name + "\n"
+
+	type importSpec struct {
+		name, spec string
+		used       bool
+	}
+	var imports, blank []*importSpec
+	body := offset(file.Name.End())
+	var decls []ast.Decl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+		body = offset(gen.End())
+		for _, s := range gen.Specs {
+			spec := s.(*ast.ImportSpec)
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || path == "C" {
+				return nil, false
+			}
+			name := goimport.Name(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if name == "." {
+				return nil, false
+			}
+			imported := &importSpec{name: name, spec: code[offset(spec.Pos()):offset(spec.End())]}
+			if name == "_" {
+				blank = append(blank, imported)
+			} else {
+				imports = append(imports, imported)
+			}
+		}
+	}
+
+	// Each chunk runs from the end of the previous declaration, so comments
+	// between declarations stay with the one that follows them
+	var chunks [][]ast.Decl
+	var texts []string
+	start := body
+	for _, decl := range decls {
+		end := offset(decl.End())
+		last := len(chunks) - 1
+		if last < 0 || len(texts[last]) >= size {
+			chunks = append(chunks, nil)
+			texts = append(texts, "")
+			last++
+		}
+		chunks[last] = append(chunks[last], decl)
+		texts[last] += code[start:end]
+		start = end
+	}
+	if len(chunks) == 0 {
+		return nil, false
+	}
+	texts[len(texts)-1] += code[start:]
+
+	files := make([]string, len(chunks))
+	for idx, chunk := range chunks {
+		used := referencedPackages(chunk)
+		var specs []string
+		if idx == 0 {
+			for _, imported := range blank {
+				specs = append(specs, imported.spec)
+			}
+		}
+		for _, imported := range imports {
+			if used[imported.name] {
+				imported.used = true
+				specs = append(specs, imported.spec)
+			}
+		}
+
+		var builder strings.Builder
+		builder.WriteString(header)
+		if len(specs) > 0 {
+			builder.WriteString("\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)\n")
+		}
+		builder.WriteString(texts[idx])
+		files[idx] = builder.String()
+	}
+
+	// An import no chunk refers to was guessed under the wrong name
+	for _, imported := range imports {
+		if !imported.used {
+			return nil, false
+		}
+	}
+	return files, true
+}
+
+// referencedPackages returns the unresolved identifiers that declarations
+// select from, which are the names of the packages they use
+func referencedPackages(decls []ast.Decl) map[string]bool {
+	used := make(map[string]bool)
+	for _, decl := range decls {
+		ast.Inspect(decl, func(node ast.Node) bool {
+			if selector, ok := node.(*ast.SelectorExpr); ok {
+				if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return used
+}
+
+// writeModule writes the files of a chunked program and a go.mod for them
+// into a new temporary directory and returns its path
+func writeModule(jobID string, files []string, goVersion string) (string, error) {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("eval-%s-*", jobID))
+	if err != nil {
+		return "", fmt.Errorf("failed to create module directory: %w", err)
+	}
+
+	goMod := "module " + chunkModulePath + "\n"
+	if match := goDirectivePattern.FindStringSubmatch(goVersion); match != nil {
+		goMod += "\ngo " + match[1] + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write go.mod: %w", err)
+	}
+	for idx, content := range files {
+		path := filepath.Join(dir, fmt.Sprintf("program_%03d.go", idx))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to write program chunk: %w", err)
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,196 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/subprocess"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// commandProgram checks a command program configuration and fills in its
+// defaults
+func commandProgram(config types.CommandProgramConfig) (*types.CommandProgramConfig, error) {
+	if len(config.Interpreter) == 0 {
+		config.Interpreter = []string{constants.DefaultCommandInterpreter}
+	}
+	if config.Extension == "" {
+		config.Extension = constants.DefaultCommandExtension
+	}
+	if _, err := exec.LookPath(config.Interpreter[0]); err != nil {
+		return nil, fmt.Errorf("failed to find interpreter: %w", err)
+	}
+	if config.Fixtures != "" {
+		info, err := os.Stat(config.Fixtures)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixtures: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("fixtures must be a directory: %s", config.Fixtures)
+		}
+	}
+	return &config, nil
+}
+
+// evaluateCommand runs a command program in a fresh directory holding a copy
+// of the fixtures, then runs the evaluator there to score it. Without an
+// evaluator the script's own output is parsed for a score.
+func (wp *WorkerPool) evaluateCommand(job *EvaluationJob, timeout time.Duration) *types.EvaluationResult {
+	result := &types.EvaluationResult{
+		Success:   false,
+		Artifacts: make(map[string]string),
+	}
+
+	// The script's output is kept outside its working directory so the
+	// script cannot overwrite what the evaluator reads
+	root, err := os.MkdirTemp("", fmt.Sprintf("eval-%s-*", job.ID))
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to create temp dir: %v", err)
+		return result
+	}
+	defer os.RemoveAll(root)
+
+	workDir := filepath.Join(root, "work")
+	if wp.command.Fixtures != "" {
+		err = copyDir(wp.command.Fixtures, workDir)
+	} else {
+		err = os.Mkdir(workDir, 0755)
+	}
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to prepare working directory: %v", err)
+		return result
+	}
+
+	scriptPath := filepath.Join(workDir, "program"+wp.command.Extension)
+	if err := os.WriteFile(scriptPath, []byte(job.Code), 0755); err != nil {
+		result.Error = fmt.Sprintf("Failed to write program code: %v", err)
+		return result
+	}
+
+	// One deadline covers the script and its evaluation
+	evalCtx, cancel := context.WithTimeout(job.Context, timeout)
+	defer cancel()
+
+	args := append(append([]string{}, wp.command.Interpreter[1:]...), scriptPath)
+	output, err := subprocess.Run(evalCtx, wp.commandOptions(workDir), wp.command.Interpreter[0], args...)
+	if wp.denyNetwork {
+		defer recordNetworkAttempts(result, output)
+	}
+
+	if job.Context.Err() != nil {
+		result.Error = fmt.Sprintf("Evaluation cancelled: %v", job.Context.Err())
+		return result
+	}
+
+	if evalCtx.Err() == context.DeadlineExceeded {
+		result.Error = "Script timed out"
+		result.Artifacts["timeout"] = "true"
+		result.Artifacts["script_output"] = string(output)
+		return result
+	}
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		result.Error = fmt.Sprintf("Script failed to start: %v", err)
+		return result
+	}
+
+	if job.ProgramPath == "" {
+		if exitCode != 0 {
+			result.Error = fmt.Sprintf("Script exited with status %d", exitCode)
+			result.Artifacts["stderr"] = string(output)
+			return result
+		}
+		result.Score, result.Success = wp.parseScoreOutput(string(output))
+		if !result.Success {
+			result.Error = "Script output has no score"
+		}
+		result.Artifacts["stdout"] = string(output)
+		return result
+	}
+
+	outputPath := filepath.Join(root, "output")
+	if err := os.WriteFile(outputPath, output, 0644); err != nil {
+		result.Error = fmt.Sprintf("Failed to write script output: %v", err)
+		return result
+	}
+
+	evalOutput, err := subprocess.Run(evalCtx, wp.commandOptions(workDir), "go", "run",
+		job.ProgramPath, scriptPath, outputPath, strconv.Itoa(exitCode))
+
+	if job.Context.Err() != nil {
+		result.Error = fmt.Sprintf("Evaluation cancelled: %v", job.Context.Err())
+		return result
+	}
+
+	if evalCtx.Err() == context.DeadlineExceeded {
+		result.Error = "Command evaluation timed out"
+		result.Artifacts["timeout"] = "true"
+	} else if err != nil {
+		result.Error = fmt.Sprintf("Command evaluation failed: %v", err)
+		result.Artifacts["stderr"] = string(evalOutput)
+	} else {
+		wp.parseEvaluatorOutput(result, evalOutput)
+	}
+
+	result.Artifacts["script_output"] = string(output)
+	result.Artifacts["script_exit_code"] = strconv.Itoa(exitCode)
+	return result
+}
+
+// copyDir copies the regular files and directories under src to dst
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+// copyFile copies a single file, creating it with mode
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
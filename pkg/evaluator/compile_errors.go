@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// CompileErrorsArtifact holds the errors go build or go run reported, as a
+// JSON list of types.CompileError
+const CompileErrorsArtifact = "compile_errors"
+
+// compileErrorLine matches the file:line[:column]: message lines the Go
+// toolchain reports errors with
+var compileErrorLine = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// ParseCompileErrors extracts the errors from go build or go run output.
+// Indented lines continue the error before them, such as the have and want
+// lines of a type mismatch; package headers and other lines are dropped.
+func ParseCompileErrors(output []byte) []types.CompileError {
+	var errors []types.CompileError
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "\t") {
+			if len(errors) > 0 && strings.TrimSpace(line) != "" {
+				last := &errors[len(errors)-1]
+				last.Message += "\n" + strings.TrimSpace(line)
+			}
+			continue
+		}
+		match := compileErrorLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		lineNumber, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		errors = append(errors, types.CompileError{
+			File:    filepath.Base(match[1]),
+			Line:    lineNumber,
+			Column:  column,
+			Message: match[4],
+		})
+	}
+	return errors
+}
+
+// recordCompileErrors adds the compile errors found in the output of a
+// failed go command to the result's compile_errors artifact
+func recordCompileErrors(result *types.EvaluationResult, output []byte) {
+	errors := ParseCompileErrors(output)
+	if len(errors) == 0 {
+		return
+	}
+	encoded, err := json.Marshal(errors)
+	if err != nil {
+		return
+	}
+	if result.Artifacts == nil {
+		result.Artifacts = make(map[string]string)
+	}
+	result.Artifacts[CompileErrorsArtifact] = string(encoded)
+}
+
+// CompileErrors decodes the compile_errors artifact, returning nil when
+// there is none or it cannot be decoded
+func CompileErrors(artifacts map[string]string) []types.CompileError {
+	encoded, ok := artifacts[CompileErrorsArtifact]
+	if !ok {
+		return nil
+	}
+	var errors []types.CompileError
+	if json.Unmarshal([]byte(encoded), &errors) != nil {
+		return nil
+	}
+	return errors
+}
@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// snapshotEnvironment records the toolchain, platform and evaluator program
+// that scores are produced with
+func snapshotEnvironment(evaluatorPath string) (*types.Environment, error) {
+	hash, err := hashFile(evaluatorPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Environment{
+		GoVersion:     toolchainVersion(),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		EvaluatorHash: hash,
+	}, nil
+}
+
+// hashFile returns the SHA-256 of the evaluator program
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash evaluation program: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CheckEvaluatorChange re-hashes the evaluator program and reports whether
+// it changed since the last check. Results carry the new hash from then on.
+func (e *Evaluator) CheckEvaluatorChange() (bool, error) {
+	hash, err := hashFile(e.programPath)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if hash == e.environment.EvaluatorHash {
+		return false, nil
+	}
+	environment := *e.environment
+	environment.EvaluatorHash = hash
+	e.environment = &environment
+	return true, nil
+}
+
+// toolchainVersion returns the version of the go command programs are run
+// with, falling back to the version this binary was built with
+func toolchainVersion() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "go", "env", "GOVERSION").Output()
+	if version := strings.TrimSpace(string(output)); err == nil && version != "" {
+		return version
+	}
+	return runtime.Version()
+}
@@ -4,17 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
+	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/subprocess"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
+	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
 )
 
 // Evaluator handles program evaluation with support for cascade evaluation
@@ -30,6 +39,12 @@ type Evaluator struct {
 	// Artifact storage
 	artifactsDir string
 	pendingArtifacts map[string]map[string]string
+
+	// Environment every result is produced in
+	environment *types.Environment
+
+	// Judges programs when LLM feedback is enabled; nil judges nothing
+	judgeLLM llm.Client
 }
 
 // WorkerPool manages parallel evaluation workers
@@ -40,6 +55,18 @@ type WorkerPool struct {
 	wg         sync.WaitGroup
 	ctx        context.Context
 	cancel     context.CancelFunc
+	auditor    atomic.Pointer[audit.Logger]
+	timeout    time.Duration
+	// Adapts timeout to observed durations; nil keeps it fixed
+	timeouts   *timeoutTracker
+	// Runs programs without network access
+	denyNetwork bool
+	// Runs jobs as command programs; nil runs them as Go
+	command *types.CommandProgramConfig
+	// Go programs larger than chunkSize bytes are split into a temporary
+	// module; 0 always runs them as a single file
+	chunkSize int
+	goVersion string
 }
 
 // EvaluationJob represents a single evaluation task
@@ -57,10 +84,28 @@ func New(config types.EvaluatorConfig, programPath string) (*Evaluator, error) {
 	if _, err := os.Stat(programPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("evaluation program not found: %s", programPath)
 	}
+	// Evaluators run from other directories, such as a command program's
+	// working directory, so a relative path would no longer resolve
+	programPath, err := filepath.Abs(programPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve evaluation program: %w", err)
+	}
 
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 
+	environment, err := snapshotEnvironment(programPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var command *types.CommandProgramConfig
+	if config.ProgramType == constants.ProgramTypeCommand {
+		if command, err = commandProgram(config.Command); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create artifacts directory if enabled
 	var artifactsDir string
 	if config.CollectArtifacts {
@@ -76,10 +121,22 @@ func New(config types.EvaluatorConfig, programPath string) (*Evaluator, error) {
 		logger:          logger,
 		artifactsDir:    artifactsDir,
 		pendingArtifacts: make(map[string]map[string]string),
+		environment:     environment,
 	}
 
 	// Initialize worker pool
 	evaluator.workerPool = NewWorkerPool(config.ParallelWorkers)
+	if config.Timeout > 0 {
+		evaluator.workerPool.timeout = time.Duration(config.Timeout) * time.Second
+	}
+	evaluator.workerPool.timeouts = newTimeoutTracker(config.AdaptiveTimeout)
+	evaluator.workerPool.denyNetwork = config.Network != constants.NetworkAllow
+	evaluator.workerPool.command = command
+	evaluator.workerPool.chunkSize = config.ChunkSize
+	evaluator.workerPool.goVersion = environment.GoVersion
+	if evaluator.workerPool.denyNetwork && !subprocess.NetworkIsolationSupported {
+		logger.Warn("Network isolation is not supported on this platform; evaluated programs keep network access")
+	}
 	go evaluator.workerPool.Start()
 
 	logger.WithFields(logrus.Fields{
@@ -87,6 +144,10 @@ func New(config types.EvaluatorConfig, programPath string) (*Evaluator, error) {
 		"parallel":     config.ParallelWorkers,
 		"cascade":      len(config.CascadeStages) > 0,
 		"artifacts":    config.CollectArtifacts,
+		"deny_network": evaluator.workerPool.denyNetwork,
+		"program_type": config.ProgramType,
+		"go_version":   environment.GoVersion,
+		"evaluator_hash": environment.EvaluatorHash,
 	}).Info("Initialized evaluator")
 
 	return evaluator, nil
@@ -101,6 +162,7 @@ func NewWorkerPool(maxWorkers int) *WorkerPool {
 		results:    make(chan *types.EvaluationResult, maxWorkers),
 		ctx:        ctx,
 		cancel:     cancel,
+		timeout:    time.Duration(constants.DefaultTimeout) * time.Second,
 	}
 }
 
@@ -157,6 +219,67 @@ func (wp *WorkerPool) processJob(job *EvaluationJob) *types.EvaluationResult {
 		result.Duration = time.Since(startTime)
 	}()
 
+	// Skip jobs whose caller gave up while they were queued
+	if err := job.Context.Err(); err != nil {
+		result.Error = fmt.Sprintf("Evaluation cancelled: %v", err)
+		return result
+	}
+
+	// Choose evaluation method
+	timeout := wp.timeouts.Timeout(wp.timeout)
+	if wp.command != nil {
+		result = wp.evaluateCommand(job, timeout)
+	} else {
+		result = wp.evaluateGo(job, timeout)
+	}
+
+	rejectNonFinite(result)
+
+	// Keep the job ID so artifacts can be looked up by result
+	result.ID = job.ID
+	result.Timeout = timeout
+	if result.Success {
+		wp.timeouts.Record(time.Since(startTime))
+	}
+
+	return result
+}
+
+// evaluateGo compiles and runs a Go program, alongside the evaluator when
+// the job has one
+func (wp *WorkerPool) evaluateGo(job *EvaluationJob, timeout time.Duration) *types.EvaluationResult {
+	result := &types.EvaluationResult{
+		Success:  false,
+		Artifacts: make(map[string]string),
+	}
+
+	// Split programs too large for a single file into a module; programs
+	// that cannot be split run as one file and fail or pass on their own
+	if wp.chunkSize > 0 && len(job.Code) > wp.chunkSize {
+		if files, ok := splitProgram(job.Code, wp.chunkSize); ok && len(files) > 1 {
+			dir, err := writeModule(job.ID, files, wp.goVersion)
+			if err != nil {
+				result.Error = err.Error()
+				return result
+			}
+			defer os.RemoveAll(dir)
+			return wp.evaluateProgram(job, dir, timeout)
+		}
+	}
+
+	// A complete program cannot be compiled together with the evaluator,
+	// which would then declare main twice; the evaluator is handed the
+	// program as a module directory instead
+	if job.ProgramPath != "" && declaresMain(job.Code) {
+		dir, err := writeModule(job.ID, []string{job.Code}, wp.goVersion)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		defer os.RemoveAll(dir)
+		return wp.evaluateProgram(job, dir, timeout)
+	}
+
 	// Create temporary file for program code
 	tempFile, err := ioutil.TempFile("", fmt.Sprintf("eval-%s-*.go", job.ID))
 	if err != nil {
@@ -174,16 +297,33 @@ func (wp *WorkerPool) processJob(job *EvaluationJob) *types.EvaluationResult {
 	}
 	tempFile.Close()
 
-	// Choose evaluation method
+	return wp.evaluateProgram(job, tempPath, timeout)
+}
+
+// declaresMain reports whether Go source declares a main function, making
+// it a complete program rather than declarations for the evaluator to call
+func declaresMain(code string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateProgram evaluates a program file or module directory, with the
+// job's evaluator when it has one
+func (wp *WorkerPool) evaluateProgram(job *EvaluationJob, programPath string, timeout time.Duration) *types.EvaluationResult {
 	if len(job.ProgramPath) > 0 {
 		// Use cascade evaluation if configured
-		result = wp.evaluateCascade(job.Context, tempPath, job.ProgramPath)
-	} else {
-		// Direct evaluation
-		result = wp.evaluateDirect(job.Context, tempPath)
+		return wp.evaluateCascade(job.Context, programPath, job.ProgramPath, timeout)
 	}
-
-	return result
+	// Direct evaluation
+	return wp.evaluateDirect(job.Context, programPath, timeout)
 }
 
 // Evaluate evaluates a single program
@@ -213,6 +353,12 @@ func (e *Evaluator) Evaluate(ctx context.Context, code string) (*types.Evaluatio
 	// Wait for result
 	select {
 	case result := <-resultChan:
+		result.Environment = e.Environment()
+		if e.config.ScoreDirection == constants.ScoreDirectionMinimize {
+			result.Score = -result.Score
+		}
+		e.judge(ctx, code, result)
+
 		// Store artifacts if enabled
 		if e.config.CollectArtifacts && len(result.Artifacts) > 0 {
 			e.mu.Lock()
@@ -272,19 +418,30 @@ func (e *Evaluator) EvaluateBatch(ctx context.Context, programs []string) ([]*ty
 }
 
 // evaluateDirect performs direct program evaluation
-func (wp *WorkerPool) evaluateDirect(ctx context.Context, programPath string) *types.EvaluationResult {
+func (wp *WorkerPool) evaluateDirect(ctx context.Context, programPath string, timeout time.Duration) *types.EvaluationResult {
 	result := &types.EvaluationResult{
 		Success:  false,
 		Artifacts: make(map[string]string),
 	}
 
-	// Create context with timeout
-	evalCtx, cancel := context.WithTimeout(ctx, 30*time.Second) // Default timeout
+	// Create context with timeout, bounded by the caller's deadline
+	evalCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Run the program
-	cmd := exec.CommandContext(evalCtx, "go", "run", programPath)
-	output, err := cmd.CombinedOutput()
+	// Run the program; a module is run from its own directory
+	opts, target := wp.commandOptions(""), programPath
+	if info, err := os.Stat(programPath); err == nil && info.IsDir() {
+		opts, target = wp.commandOptions(programPath), "."
+	}
+	output, err := subprocess.Run(evalCtx, opts, "go", "run", target)
+	if wp.denyNetwork {
+		defer recordNetworkAttempts(result, output)
+	}
+
+	if ctx.Err() != nil {
+		result.Error = fmt.Sprintf("Evaluation cancelled: %v", ctx.Err())
+		return result
+	}
 
 	if evalCtx.Err() == context.DeadlineExceeded {
 		result.Error = "Program evaluation timed out"
@@ -295,19 +452,25 @@ func (wp *WorkerPool) evaluateDirect(ctx context.Context, programPath string) *t
 	if err != nil {
 		result.Error = fmt.Sprintf("Program execution failed: %v", err)
 		result.Artifacts["stderr"] = string(output)
+		recordCompileErrors(result, output)
 		return result
 	}
 
 	// Parse output for score
-	result.Score = wp.parseScoreOutput(string(output))
-	result.Success = result.Score >= 0
+	result.Score, result.Success = wp.parseScoreOutput(string(output))
+	if !result.Success {
+		result.Error = "Program output has no score"
+	}
 	result.Artifacts["stdout"] = string(output)
 
 	return result
 }
 
-// evaluateCascade performs cascade evaluation
-func (wp *WorkerPool) evaluateCascade(ctx context.Context, programPath string, evaluatorPath string) *types.EvaluationResult {
+// evaluateCascade runs the evaluator on a program. A program file holds
+// declarations and is compiled together with the evaluator, which must then
+// sit in the same directory; a module directory holds a complete program
+// and is passed to the evaluator as its argument.
+func (wp *WorkerPool) evaluateCascade(ctx context.Context, programPath string, evaluatorPath string, timeout time.Duration) *types.EvaluationResult {
 	// For now, implement a simple cascade evaluation
 	// In a full implementation, you would load the evaluator and call cascade stages
 
@@ -316,13 +479,20 @@ func (wp *WorkerPool) evaluateCascade(ctx context.Context, programPath string, e
 		Artifacts: make(map[string]string),
 	}
 
-	// Create context with timeout
-	evalCtx, cancel := context.WithTimeout(ctx, 60*time.Second) // Default timeout
+	// Create context with timeout, bounded by the caller's deadline
+	evalCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Run the evaluator with the program as argument
-	cmd := exec.CommandContext(evalCtx, "go", "run", evaluatorPath, programPath)
-	output, err := cmd.CombinedOutput()
+	output, err := subprocess.Run(evalCtx, wp.commandOptions(""), "go", "run", evaluatorPath, programPath)
+	if wp.denyNetwork {
+		defer recordNetworkAttempts(result, output)
+	}
+
+	if ctx.Err() != nil {
+		result.Error = fmt.Sprintf("Evaluation cancelled: %v", ctx.Err())
+		return result
+	}
 
 	if evalCtx.Err() == context.DeadlineExceeded {
 		result.Error = "Cascade evaluation timed out"
@@ -333,9 +503,17 @@ func (wp *WorkerPool) evaluateCascade(ctx context.Context, programPath string, e
 	if err != nil {
 		result.Error = fmt.Sprintf("Cascade evaluation failed: %v", err)
 		result.Artifacts["stderr"] = string(output)
+		recordCompileErrors(result, output)
 		return result
 	}
 
+	wp.parseEvaluatorOutput(result, output)
+	return result
+}
+
+// parseEvaluatorOutput fills result from an evaluator's output: a JSON
+// result if it printed one, otherwise a plain score
+func (wp *WorkerPool) parseEvaluatorOutput(result *types.EvaluationResult, output []byte) {
 	// Try to parse JSON output first
 	var evalResult struct {
 		Score     float64            `json:"score"`
@@ -343,32 +521,44 @@ func (wp *WorkerPool) evaluateCascade(ctx context.Context, programPath string, e
 		Artifacts map[string]string  `json:"artifacts"`
 		Error     string             `json:"error"`
 		Metrics   map[string]float64 `json:"metrics"`
+		Behavior  []float64          `json:"behavior"`
+		Features  map[string]float64 `json:"features"`
 	}
 
 	if json.Unmarshal(output, &evalResult) == nil {
 		result.Score = evalResult.Score
 		result.Success = evalResult.Success
 		result.Error = evalResult.Error
+		result.Metrics = evalResult.Metrics
+		result.Behavior = evalResult.Behavior
+		result.NamedFeatures = evalResult.Features
 		if evalResult.Artifacts != nil {
 			result.Artifacts = evalResult.Artifacts
 		}
 	} else {
 		// Fallback to simple score parsing
-		result.Score = wp.parseScoreOutput(string(output))
-		result.Success = result.Score >= 0
+		result.Score, result.Success = wp.parseScoreOutput(string(output))
+		if !result.Success {
+			result.Error = "Evaluator output has no score"
+		}
 		result.Artifacts["stdout"] = string(output)
 	}
+}
 
-	return result
+// commandOptions returns the options evaluation commands run with
+func (wp *WorkerPool) commandOptions(dir string) subprocess.Options {
+	return subprocess.Options{Auditor: wp.auditor.Load(), DenyNetwork: wp.denyNetwork, Dir: dir}
 }
 
-// parseScoreOutput extracts score from program output
-func (wp *WorkerPool) parseScoreOutput(output string) float64 {
+// parseScoreOutput extracts score from program output and reports whether
+// there was one. Negative and unbounded numbers parse; NaN and infinities
+// are rejected afterwards by rejectNonFinite.
+func (wp *WorkerPool) parseScoreOutput(output string) (float64, bool) {
 	// Try to parse JSON
 	var result map[string]interface{}
 	if err := json.Unmarshal([]byte(output), &result); err == nil {
 		if score, ok := result["score"].(float64); ok {
-			return score
+			return score, true
 		}
 	}
 
@@ -380,18 +570,49 @@ func (wp *WorkerPool) parseScoreOutput(output string) float64 {
 			var score float64
 			_, err := fmt.Sscanf(line[7:], "%f", &score)
 			if err == nil {
-				return score
+				return score, true
 			}
 		}
 
 		// Try to parse as JSON number
 		var score float64
 		if _, err := fmt.Sscanf(line, "%f", &score); err == nil {
-			return score
+			return score, true
 		}
 	}
 
-	return -1.0
+	return 0, false
+}
+
+// rejectNonFinite fails a successful result whose score is NaN or
+// infinite, as a diverging evaluation reports. Such a score cannot be
+// saved as JSON and an infinite one would never be beaten.
+func rejectNonFinite(result *types.EvaluationResult) {
+	if !result.Success || !(math.IsNaN(result.Score) || math.IsInf(result.Score, 0)) {
+		return
+	}
+	result.Success = false
+	result.Error = fmt.Sprintf("Evaluator reported a non-finite score: %v", result.Score)
+	if result.Artifacts == nil {
+		result.Artifacts = make(map[string]string)
+	}
+	result.Artifacts["non_finite_score"] = strconv.FormatFloat(result.Score, 'f', -1, 64)
+	result.Score = 0
+}
+
+// Environment returns the toolchain, platform and evaluator program hash
+// recorded in every result
+func (e *Evaluator) Environment() *types.Environment {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.environment
+}
+
+// SetAuditLogger records every subprocess started by the evaluator in the audit log.
+// It may be called while evaluations are running.
+func (e *Evaluator) SetAuditLogger(auditor *audit.Logger) {
+	e.workerPool.auditor.Store(auditor)
 }
 
 // GetArtifacts retrieves stored artifacts for a program
@@ -0,0 +1,740 @@
+package evaluator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
+)
+
+func TestCascadeEvaluatorStopsWhenCancelled(t *testing.T) {
+	ce := NewCascadeEvaluator([]types.CascadeStage{
+		{Name: "validation", Threshold: 0, Timeout: 10, Critical: true},
+	}, "missing.go")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := ce.Evaluate(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "validation", result.Artifacts["failure_stage"])
+}
+
+func TestCascadeEvaluatorRunsIndependentStagesInParallel(t *testing.T) {
+	ce := NewCascadeEvaluator([]types.CascadeStage{
+		{Name: "validation", Threshold: 0, Critical: true},
+		{Name: "basic", Threshold: 0.5, Independent: true},
+		{Name: "comprehensive", Threshold: 0.9, Independent: true},
+		{Name: "benchmark", Threshold: 0.5, Independent: true},
+	}, "program.go")
+
+	scores := map[string]float64{"validation": 0.2, "basic": 0.6, "comprehensive": 0.7, "benchmark": 0.8}
+	ce.run = func(ctx context.Context, stage CascadeStage, stageNumber int) (*types.EvaluationResult, error) {
+		if stage.Independent {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return &types.EvaluationResult{
+			Score:     scores[stage.Name],
+			Success:   true,
+			Artifacts: map[string]string{"last_stage": stage.Name},
+		}, nil
+	}
+
+	start := time.Now()
+	result, err := ce.Evaluate(context.Background())
+	require.NoError(t, err)
+
+	// Three 200ms stages finish together rather than back to back
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.True(t, result.Success)
+	assert.Equal(t, 0.8, result.Score)
+	// Merging follows stage order regardless of completion order
+	assert.Equal(t, "comprehensive", result.Artifacts["failure_stage"])
+	// Every stage keeps its own artifacts
+	for name, score := range scores {
+		assert.Equal(t, name, result.Artifacts[name+"/last_stage"])
+		assert.Equal(t, strconv.FormatFloat(score, 'f', -1, 64), result.Artifacts[name+"/score"])
+		assert.NotEmpty(t, result.Artifacts[name+"/duration"])
+	}
+	assert.NotContains(t, result.Artifacts, "last_stage")
+}
+
+func TestCascadeEvaluatorAttributesStageFailure(t *testing.T) {
+	ce := NewCascadeEvaluator([]types.CascadeStage{
+		{Name: "validation", Critical: true},
+		{Name: "benchmark", Critical: true},
+	}, "program.go")
+
+	ce.run = func(ctx context.Context, stage CascadeStage, stageNumber int) (*types.EvaluationResult, error) {
+		if stage.Name == "benchmark" {
+			return &types.EvaluationResult{
+				Artifacts: map[string]string{"stderr": "panic: benchmark"},
+			}, fmt.Errorf("stage execution failed")
+		}
+		return &types.EvaluationResult{
+			Score:     1,
+			Success:   true,
+			Artifacts: map[string]string{"stdout": "SCORE: 1"},
+		}, nil
+	}
+
+	result, err := ce.Evaluate(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "benchmark", result.Artifacts["failure_stage"])
+	assert.Equal(t, "SCORE: 1", result.Artifacts["validation/stdout"])
+	assert.Equal(t, "panic: benchmark", result.Artifacts["benchmark/stderr"])
+	assert.Equal(t, "1", result.Artifacts["validation/score"])
+	assert.NotContains(t, result.Artifacts, "benchmark/score")
+	assert.Contains(t, result.Artifacts, "benchmark/duration")
+}
+
+func TestCascadeEvaluatorFailsNonFiniteStageScores(t *testing.T) {
+	ce := NewCascadeEvaluator([]types.CascadeStage{
+		{Name: "validation"},
+		{Name: "benchmark"},
+	}, "program.go")
+
+	ce.run = func(ctx context.Context, stage CascadeStage, stageNumber int) (*types.EvaluationResult, error) {
+		score := 1.0
+		if stage.Name == "benchmark" {
+			score = math.Inf(1)
+		}
+		return &types.EvaluationResult{Score: score, Success: true, Artifacts: map[string]string{}}, nil
+	}
+
+	result, err := ce.Evaluate(context.Background())
+	require.Error(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 1.0, result.Score)
+	assert.Equal(t, "benchmark", result.Artifacts["failure_stage"])
+	assert.NotContains(t, result.Artifacts, "benchmark/score")
+}
+
+func TestCascadeEvaluatorKeepsNegativeScores(t *testing.T) {
+	ce := NewCascadeEvaluator([]types.CascadeStage{
+		{Name: "validation", Threshold: -10},
+		{Name: "benchmark", Threshold: -10},
+	}, "program.go")
+
+	scores := map[string]float64{"validation": -5, "benchmark": -2}
+	ce.run = func(ctx context.Context, stage CascadeStage, stageNumber int) (*types.EvaluationResult, error) {
+		return &types.EvaluationResult{Score: scores[stage.Name], Success: true, Artifacts: map[string]string{}}, nil
+	}
+
+	result, err := ce.Evaluate(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, -2.0, result.Score)
+}
+
+func TestCascadeEvaluatorGroupsOnlyIndependentNonCriticalStages(t *testing.T) {
+	ce := NewCascadeEvaluator([]types.CascadeStage{
+		{Name: "a", Independent: true},
+		{Name: "b", Independent: true},
+		{Name: "c", Independent: true, Critical: true},
+		{Name: "d"},
+		{Name: "e", Independent: true},
+	}, "program.go")
+
+	assert.Equal(t, 2, ce.groupEnd(0))
+	assert.Equal(t, 3, ce.groupEnd(2))
+	assert.Equal(t, 4, ce.groupEnd(3))
+	assert.Equal(t, 5, ce.groupEnd(4))
+}
+
+func TestEvaluatorSetAuditLoggerWhileRunning(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	evaluatorPath := filepath.Join(dir, "evaluator.go")
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	e, err := New(types.EvaluatorConfig{ParallelWorkers: 2, Timeout: 60}, evaluatorPath)
+	require.NoError(t, err)
+	defer e.Close()
+
+	auditor, err := audit.New(types.AuditConfig{Enabled: true, Path: filepath.Join(dir, "audit.jsonl")})
+	require.NoError(t, err)
+	defer auditor.Close()
+
+	// Swapping the logger mid-run must not race with the workers
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = e.EvaluateBatch(context.Background(), []string{"package main", "package main"})
+	}()
+	e.SetAuditLogger(auditor)
+	<-done
+}
+
+func TestTimeoutTrackerAdaptsToObservedDurations(t *testing.T) {
+	tracker := newTimeoutTracker(types.AdaptiveTimeoutConfig{
+		Enabled:    true,
+		Percentile: 0.9,
+		Multiplier: 2,
+		MinSamples: 3,
+		Window:     10,
+		MinTimeout: 1,
+		MaxTimeout: 30,
+	})
+	fixed := 60 * time.Second
+
+	// Too few samples keeps the fixed timeout
+	tracker.Record(2 * time.Second)
+	assert.Equal(t, fixed, tracker.Timeout(fixed))
+
+	for _, seconds := range []int{3, 4, 5, 6, 7, 8, 9, 10, 11} {
+		tracker.Record(time.Duration(seconds) * time.Second)
+	}
+	assert.Equal(t, 20*time.Second, tracker.Timeout(fixed), "90th percentile of 2..11s, doubled")
+
+	// Slow outliers are clamped to the ceiling, fast runs to the floor
+	for i := 0; i < 10; i++ {
+		tracker.Record(time.Minute)
+	}
+	assert.Equal(t, 30*time.Second, tracker.Timeout(fixed))
+	for i := 0; i < 10; i++ {
+		tracker.Record(time.Millisecond)
+	}
+	assert.Equal(t, time.Second, tracker.Timeout(fixed))
+
+	var disabled *timeoutTracker
+	disabled.Record(time.Second)
+	assert.Equal(t, fixed, disabled.Timeout(fixed))
+}
+
+func TestCascadeEvaluatorAdaptsStageTimeouts(t *testing.T) {
+	ce := NewCascadeEvaluator([]types.CascadeStage{
+		{Name: "benchmark", Timeout: 60},
+	}, "program.go")
+	ce.SetAdaptiveTimeout(types.AdaptiveTimeoutConfig{Enabled: true, Percentile: 1, Multiplier: 3, MinSamples: 2, Window: 5})
+
+	var timeouts []time.Duration
+	ce.run = func(ctx context.Context, stage CascadeStage, stageNumber int) (*types.EvaluationResult, error) {
+		timeouts = append(timeouts, stage.Timeout)
+		time.Sleep(10 * time.Millisecond)
+		return &types.EvaluationResult{Score: 1, Success: true}, nil
+	}
+
+	var result *types.EvaluationResult
+	for i := 0; i < 3; i++ {
+		var err error
+		result, err = ce.Evaluate(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, time.Minute, timeouts[0])
+	assert.Equal(t, time.Minute, timeouts[1])
+	assert.Less(t, timeouts[2], time.Second)
+	assert.Equal(t, timeouts[2].String(), result.Artifacts["benchmark/timeout"])
+}
+
+func TestEvaluatorRecordsEnvironment(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	evaluatorPath := filepath.Join(dir, "evaluator.go")
+	source := "package main\n\nfunc main() { println(\"SCORE: 1\") }\n"
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte(source), 0644))
+
+	e, err := New(types.EvaluatorConfig{ParallelWorkers: 1, Timeout: 60}, evaluatorPath)
+	require.NoError(t, err)
+	defer e.Close()
+
+	environment := e.Environment()
+	require.NotNil(t, environment)
+	sum := sha256.Sum256([]byte(source))
+	assert.Equal(t, hex.EncodeToString(sum[:]), environment.EvaluatorHash)
+	assert.Equal(t, runtime.GOOS, environment.OS)
+	assert.Equal(t, runtime.GOARCH, environment.Arch)
+	assert.True(t, strings.HasPrefix(environment.GoVersion, "go"), environment.GoVersion)
+
+	result, err := e.Evaluate(context.Background(), "package main\n\nfunc main() {}\n")
+	require.NoError(t, err)
+	assert.Same(t, environment, result.Environment)
+}
+
+func TestEvaluatorDetectsEvaluatorChange(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	evaluatorPath := filepath.Join(dir, "evaluator.go")
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte("package main\n\nfunc main() { println(\"SCORE: 1\") }\n"), 0644))
+
+	e, err := New(types.EvaluatorConfig{ParallelWorkers: 1, Timeout: 60}, evaluatorPath)
+	require.NoError(t, err)
+	defer e.Close()
+	before := e.Environment()
+
+	changed, err := e.CheckEvaluatorChange()
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	source := "package main\n\nfunc main() { println(\"SCORE: 2\") }\n"
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte(source), 0644))
+	changed, err = e.CheckEvaluatorChange()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// The change is reported once and later results carry the new hash
+	changed, err = e.CheckEvaluatorChange()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	sum := sha256.Sum256([]byte(source))
+	assert.Equal(t, hex.EncodeToString(sum[:]), e.Environment().EvaluatorHash)
+	assert.NotEqual(t, before.EvaluatorHash, e.Environment().EvaluatorHash)
+	assert.Equal(t, before.GoVersion, e.Environment().GoVersion)
+}
+
+func TestDetectNetworkAttempts(t *testing.T) {
+	output := []byte("starting\n" +
+		"dial tcp 10.0.0.1:80: connect: network is unreachable\n" +
+		"dial tcp 10.0.0.1:80: connect: network is unreachable\n" +
+		"lookup example.com: no such host\n" +
+		"SCORE: 0.5\n")
+
+	assert.Equal(t, "dial tcp 10.0.0.1:80: connect: network is unreachable\n"+
+		"lookup example.com: no such host", detectNetworkAttempts(output))
+	assert.Empty(t, detectNetworkAttempts([]byte("SCORE: 0.5\n")))
+}
+
+func TestParseCompileErrors(t *testing.T) {
+	output := []byte("# command-line-arguments\n" +
+		"./program.go:5:2: undefined: x\n" +
+		"/tmp/run/program.go:9:9: cannot use s (variable of type string) as int value in return statement\n" +
+		"\thave string\n" +
+		"\twant int\n" +
+		"program.go:12: syntax error: unexpected newline\n" +
+		"exit status 1\n")
+
+	assert.Equal(t, []types.CompileError{
+		{File: "program.go", Line: 5, Column: 2, Message: "undefined: x"},
+		{File: "program.go", Line: 9, Column: 9, Message: "cannot use s (variable of type string) as int value in return statement\nhave string\nwant int"},
+		{File: "program.go", Line: 12, Message: "syntax error: unexpected newline"},
+	}, ParseCompileErrors(output))
+
+	// Runtime panics print positions without an error message
+	assert.Empty(t, ParseCompileErrors([]byte("panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/tmp/program.go:4 +0x25\n")))
+}
+
+func TestEvaluateDirectReportsCompileErrors(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	programPath := filepath.Join(t.TempDir(), "program.go")
+	require.NoError(t, os.WriteFile(programPath, []byte("package main\n\nfunc main() {\n\tundefined()\n}\n"), 0644))
+
+	wp := NewWorkerPool(1)
+	defer wp.Stop()
+
+	result := wp.evaluateDirect(context.Background(), programPath, time.Minute)
+	assert.False(t, result.Success)
+	errors := CompileErrors(result.Artifacts)
+	require.Len(t, errors, 1)
+	assert.Equal(t, "program.go", errors[0].File)
+	assert.Equal(t, 4, errors[0].Line)
+	assert.Contains(t, errors[0].Message, "undefined")
+}
+
+func TestEvaluateDirectDeniesNetwork(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("network isolation requires linux")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	programPath := filepath.Join(t.TempDir(), "program.go")
+	source := fmt.Sprintf(`package main
+
+import (
+	"fmt"
+	"net"
+)
+
+func main() {
+	if _, err := net.Dial("tcp", %q); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("SCORE: 1")
+}
+`, listener.Addr().String())
+	require.NoError(t, os.WriteFile(programPath, []byte(source), 0644))
+
+	wp := NewWorkerPool(1)
+	defer wp.Stop()
+
+	wp.denyNetwork = true
+	denied := wp.evaluateDirect(context.Background(), programPath, time.Minute)
+	if strings.Contains(denied.Error, "without network access") {
+		t.Skip("network namespaces unavailable: ", denied.Error)
+	}
+	assert.Contains(t, denied.Artifacts["network_attempts"], "dial tcp")
+	assert.NotEqual(t, 1.0, denied.Score)
+
+	wp.denyNetwork = false
+	allowed := wp.evaluateDirect(context.Background(), programPath, time.Minute)
+	assert.Equal(t, 1.0, allowed.Score)
+	assert.NotContains(t, allowed.Artifacts, "network_attempts")
+}
+
+func TestEvaluatorRunsCommandPrograms(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	fixtures := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "input.txt"), []byte("hello\n"), 0644))
+
+	// The evaluator scores the script's output against the expected result
+	evaluatorPath := filepath.Join(t.TempDir(), "evaluator.go")
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte(`package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	output, _ := os.ReadFile(os.Args[2])
+	score := 0.0
+	if strings.TrimSpace(string(output)) == "HELLO" && os.Args[3] == "0" {
+		score = 1
+	}
+	fmt.Printf("{\"score\": %v, \"success\": true}\n", score)
+}
+`), 0644))
+
+	e, err := New(types.EvaluatorConfig{
+		ParallelWorkers: 1,
+		Timeout:         60,
+		ProgramType:     "command",
+		Command:         types.CommandProgramConfig{Interpreter: []string{"sh", "-e"}, Fixtures: fixtures},
+	}, evaluatorPath)
+	require.NoError(t, err)
+	defer e.Close()
+
+	result, err := e.Evaluate(context.Background(), "tr a-z A-Z < input.txt\n")
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Error)
+	assert.Equal(t, 1.0, result.Score)
+	assert.Equal(t, "HELLO\n", result.Artifacts["script_output"])
+	assert.Equal(t, "0", result.Artifacts["script_exit_code"])
+
+	// Scripts run on a copy, so they cannot alter the fixtures
+	result, err = e.Evaluate(context.Background(), "echo broken > input.txt\nexit 3\n")
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, result.Score)
+	assert.Equal(t, "3", result.Artifacts["script_exit_code"])
+	data, err := os.ReadFile(filepath.Join(fixtures, "input.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+}
+
+func TestParseScoreOutputSeparatesSuccessFromScore(t *testing.T) {
+	wp := NewWorkerPool(1)
+	for output, want := range map[string]float64{
+		"SCORE: -2.5":     -2.5,
+		"SCORE: 0":        0,
+		`{"score": -1e9}`: -1e9,
+		"1234567":         1234567,
+	} {
+		score, ok := wp.parseScoreOutput(output)
+		assert.True(t, ok, output)
+		assert.Equal(t, want, score, output)
+	}
+
+	_, ok := wp.parseScoreOutput("no score here")
+	assert.False(t, ok)
+
+	result := &types.EvaluationResult{Artifacts: map[string]string{}}
+	wp.parseEvaluatorOutput(result, []byte("no score here"))
+	assert.False(t, result.Success)
+	assert.NotEmpty(t, result.Error)
+
+	result = &types.EvaluationResult{Artifacts: map[string]string{}}
+	wp.parseEvaluatorOutput(result, []byte(`{"score": -3, "success": true, "behavior": [0.5, 2], "features": {"memory_mb": 12.5}}`))
+	assert.True(t, result.Success)
+	assert.Equal(t, -3.0, result.Score)
+	assert.Equal(t, []float64{0.5, 2}, result.Behavior)
+	assert.Equal(t, map[string]float64{"memory_mb": 12.5}, result.NamedFeatures)
+}
+
+func TestEvaluatorMinimizesScores(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// The evaluator reports the script's output as its score
+	evaluatorPath := filepath.Join(t.TempDir(), "evaluator.go")
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte(`package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	output, _ := os.ReadFile(os.Args[2])
+	fmt.Printf("SCORE: %s", output)
+}
+`), 0644))
+
+	for direction, want := range map[string]float64{"maximize": -2.5, "minimize": 2.5} {
+		e, err := New(types.EvaluatorConfig{
+			ParallelWorkers: 1,
+			Timeout:         60,
+			ProgramType:     "command",
+			Command:         types.CommandProgramConfig{Interpreter: []string{"sh", "-e"}},
+			ScoreDirection:  direction,
+		}, evaluatorPath)
+		require.NoError(t, err)
+
+		result, err := e.Evaluate(context.Background(), "echo -2.5\n")
+		require.NoError(t, err)
+		assert.True(t, result.Success, result.Error)
+		assert.Equal(t, want, result.Score, direction)
+
+		result, err = e.Evaluate(context.Background(), "echo loss\n")
+		require.NoError(t, err)
+		assert.False(t, result.Success, direction)
+		e.Close()
+	}
+}
+
+func TestEvaluatorResolvesRelativeEvaluatorPath(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "evaluator.go"), []byte(`package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	output, _ := os.ReadFile(os.Args[2])
+	fmt.Printf("SCORE: %s", output)
+}
+`), 0644))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	// The evaluator runs inside the script's working directory
+	e, err := New(types.EvaluatorConfig{
+		ParallelWorkers: 1,
+		Timeout:         60,
+		ProgramType:     "command",
+		Command:         types.CommandProgramConfig{Interpreter: []string{"sh", "-e"}},
+	}, "evaluator.go")
+	require.NoError(t, err)
+	defer e.Close()
+
+	result, err := e.Evaluate(context.Background(), "echo 0.5\n")
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Error)
+	assert.Equal(t, 0.5, result.Score)
+}
+
+func TestEvaluatorRejectsNonFiniteScores(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	evaluatorPath := filepath.Join(t.TempDir(), "evaluator.go")
+	require.NoError(t, os.WriteFile(evaluatorPath, []byte(`package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	output, _ := os.ReadFile(os.Args[2])
+	fmt.Printf("SCORE: %s", output)
+}
+`), 0644))
+
+	e, err := New(types.EvaluatorConfig{
+		ParallelWorkers: 1,
+		Timeout:         60,
+		ProgramType:     "command",
+		Command:         types.CommandProgramConfig{Interpreter: []string{"sh", "-e"}},
+	}, evaluatorPath)
+	require.NoError(t, err)
+	defer e.Close()
+
+	// A diverging loss is a failed evaluation whose result still saves as JSON
+	for _, loss := range []string{"NaN", "+Inf", "-Inf"} {
+		result, err := e.Evaluate(context.Background(), "echo "+loss+"\n")
+		require.NoError(t, err)
+		assert.False(t, result.Success, loss)
+		assert.Contains(t, result.Error, "non-finite score", loss)
+		assert.Zero(t, result.Score, loss)
+		_, err = json.Marshal(result)
+		assert.NoError(t, err, loss)
+	}
+}
+
+// judgeStub answers every prompt with a fixed judgment
+type judgeStub struct {
+	answer  string
+	prompts []string
+}
+
+func (j *judgeStub) Generate(ctx context.Context, prompt string) (*types.LLMResponse, error) {
+	j.prompts = append(j.prompts, prompt)
+	return &types.LLMResponse{Content: j.answer}, nil
+}
+
+func (j *judgeStub) GenerateWithSystemMessage(ctx context.Context, systemMessage string, messages []types.LLMMessage) (*types.LLMResponse, error) {
+	return j.Generate(ctx, messages[len(messages)-1].Content)
+}
+
+func TestEvaluatorRecordsLLMFeedback(t *testing.T) {
+	judge := &judgeStub{answer: "Sure:\n```json\n{\"readability\": 0.8, \"efficiency\": 1.4, \"reasoning\": \"clear\"}\n```"}
+	e := &Evaluator{
+		config: types.EvaluatorConfig{UseLLMFeedback: true, LLMFeedbackWeight: 0.5},
+		logger: logrus.New(),
+	}
+	e.SetJudge(judge)
+
+	result := &types.EvaluationResult{Success: true, Score: 1}
+	e.judge(context.Background(), "package main", result)
+	require.Len(t, judge.prompts, 1)
+	assert.True(t, strings.HasSuffix(judge.prompts[0], "package main"))
+	assert.Equal(t, 0.8, result.Metrics["llm_readability"])
+	// Scores are clamped to [0, 1]
+	assert.Equal(t, 1.0, result.Metrics["llm_efficiency"])
+	assert.InDelta(t, 1.45, result.Score, 1e-9)
+	assert.Equal(t, "clear", result.Artifacts["llm_feedback"])
+
+	// Failed programs are not judged, and nor is anything without the option
+	e.judge(context.Background(), "package main", &types.EvaluationResult{})
+	e.config.UseLLMFeedback = false
+	e.judge(context.Background(), "package main", &types.EvaluationResult{Success: true})
+	assert.Len(t, judge.prompts, 1)
+
+	// Answers without scores are ignored
+	e.config.UseLLMFeedback = true
+	judge.answer = "I cannot judge this"
+	result = &types.EvaluationResult{Success: true, Score: 1}
+	e.judge(context.Background(), "package main", result)
+	assert.Equal(t, 1.0, result.Score)
+	assert.Empty(t, result.Metrics)
+}
+
+// chunkedSource is a program whose functions use different packages
+const chunkedSource = `// Package main is split across files.
+package main
+
+import (
+	"fmt"
+	str "strings"
+	_ "embed"
+)
+
+// greeting is built by a helper in another chunk
+var greeting = upper("score")
+
+func upper(s string) string {
+	return str.ToUpper(s)
+}
+
+// Comments between declarations stay with the next one
+func main() {
+	fmt.Println(greeting + ": 1")
+}
+`
+
+func TestSplitProgram(t *testing.T) {
+	files, ok := splitProgram(chunkedSource, 1)
+	require.True(t, ok)
+	require.Len(t, files, 3)
+
+	// Each file keeps only the imports it uses; blank imports go in the first
+	assert.Contains(t, files[0], `_ "embed"`)
+	assert.NotContains(t, files[0], `"fmt"`)
+	assert.Contains(t, files[1], `str "strings"`)
+	assert.NotContains(t, files[1], `_ "embed"`)
+	assert.Contains(t, files[2], `"fmt"`)
+	assert.Contains(t, files[2], "// Comments between declarations")
+	for _, file := range files {
+		assert.True(t, strings.HasPrefix(file, "// Package main is split across files.\npackage main\n"))
+	}
+
+	// A program fitting one chunk stays whole
+	files, ok = splitProgram(chunkedSource, len(chunkedSource))
+	require.True(t, ok)
+	assert.Len(t, files, 1)
+
+	_, ok = splitProgram("package main\n\nfunc main() {", 1)
+	assert.False(t, ok, "unparsable programs are not split")
+	_, ok = splitProgram("package main\n\nimport . \"fmt\"\n\nfunc main() { Println() }\n", 1)
+	assert.False(t, ok, "dot imports cannot be assigned to chunks")
+}
+
+func TestEvaluateChunkedProgram(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	wp := NewWorkerPool(1)
+	defer wp.Stop()
+	wp.chunkSize = 1
+	wp.goVersion = toolchainVersion()
+
+	job := &EvaluationJob{ID: "chunked", Code: chunkedSource, Context: context.Background()}
+	result := wp.evaluateGo(job, time.Minute)
+	require.True(t, result.Success, result.Error+result.Artifacts["stderr"])
+	assert.Equal(t, 1.0, result.Score)
+}
@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
+)
+
+// judgePrompt asks an LLM to judge a program; the program follows it
+const judgePrompt = `Evaluate the following program on a scale of 0.0 to 1.0 for:
+1. Readability: how easy the code is to read and understand
+2. Maintainability: how easy the code would be to change
+3. Efficiency: how well the code uses time and memory
+
+Answer with only a JSON object such as:
+{"readability": 0.8, "maintainability": 0.7, "efficiency": 0.9, "reasoning": "one or two sentences"}
+
+Program:
+`
+
+// SetJudge sets the LLM that judges programs when LLM feedback is enabled,
+// normally an ensemble of the evaluator models
+func (e *Evaluator) SetJudge(judge llm.Client) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.judgeLLM = judge
+}
+
+// judge asks the judge LLM to score a successfully evaluated program and
+// records its scores as llm_ metrics, its reasoning in the llm_feedback
+// artifact and, when weighted, its mean score in the result's score. A
+// judge that fails or answers in the wrong form is logged and ignored.
+func (e *Evaluator) judge(ctx context.Context, code string, result *types.EvaluationResult) {
+	e.mu.RLock()
+	judge := e.judgeLLM
+	e.mu.RUnlock()
+	if judge == nil || !e.config.UseLLMFeedback || !result.Success {
+		return
+	}
+
+	response, err := judge.Generate(ctx, judgePrompt+code)
+	if err != nil {
+		e.logger.WithError(err).Warn("Failed to get LLM feedback")
+		return
+	}
+	scores, reasoning, err := parseJudgment(response.Content)
+	if err != nil {
+		e.logger.WithFields(logrus.Fields{
+			"model": response.Model,
+		}).WithError(err).Warn("Failed to parse LLM feedback")
+		return
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]float64)
+	}
+	total := 0.0
+	for name, score := range scores {
+		result.Metrics["llm_"+name] = score
+		total += score
+	}
+	result.Score += e.config.LLMFeedbackWeight * total / float64(len(scores))
+	if reasoning != "" {
+		if result.Artifacts == nil {
+			result.Artifacts = make(map[string]string)
+		}
+		result.Artifacts["llm_feedback"] = reasoning
+	}
+}
+
+// parseJudgment reads the JSON object of a judge's answer: its numeric
+// fields are scores, clamped to [0, 1], and its reasoning field explains
+// them
+func parseJudgment(content string) (map[string]float64, string, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, "", fmt.Errorf("no JSON object in answer")
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(content[start:end+1]), &fields); err != nil {
+		return nil, "", fmt.Errorf("failed to decode answer: %w", err)
+	}
+
+	scores := make(map[string]float64)
+	var reasoning string
+	for name, field := range fields {
+		switch value := field.(type) {
+		case float64:
+			scores[strings.ToLower(name)] = min(max(value, 0), 1)
+		case string:
+			if name == "reasoning" {
+				reasoning = value
+			}
+		}
+	}
+	if len(scores) == 0 {
+		return nil, "", fmt.Errorf("no scores in answer")
+	}
+	return scores, reasoning, nil
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// networkAttemptMarkers are output fragments that show a program tried to
+// reach the network: Go dial errors, and the errors a connection gets
+// inside an isolated network namespace
+var networkAttemptMarkers = []string{
+	"dial tcp",
+	"dial udp",
+	"network is unreachable",
+	"no such host",
+	"connection refused",
+}
+
+// detectNetworkAttempts returns the distinct output lines that show a
+// connection attempt, joined by newlines, or "" if there are none
+func detectNetworkAttempts(output []byte) string {
+	var attempts []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		for _, marker := range networkAttemptMarkers {
+			if strings.Contains(lower, marker) && !seen[line] {
+				seen[line] = true
+				attempts = append(attempts, line)
+				break
+			}
+		}
+	}
+	return strings.Join(attempts, "\n")
+}
+
+// recordNetworkAttempts adds the connection attempts found in output to the
+// result's network_attempts artifact
+func recordNetworkAttempts(result *types.EvaluationResult, output []byte) {
+	attempts := detectNetworkAttempts(output)
+	if attempts == "" {
+		return
+	}
+	if result.Artifacts == nil {
+		result.Artifacts = make(map[string]string)
+	}
+	result.Artifacts["network_attempts"] = attempts
+}
@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// timeoutTracker adapts a timeout to the durations of recent successful
+// evaluations. A nil tracker always uses the fixed timeout.
+type timeoutTracker struct {
+	config types.AdaptiveTimeoutConfig
+
+	mu        sync.Mutex
+	durations []time.Duration
+	next      int
+}
+
+// newTimeoutTracker returns a tracker, or nil when adaptive timeouts are off
+func newTimeoutTracker(config types.AdaptiveTimeoutConfig) *timeoutTracker {
+	if !config.Enabled || config.Window <= 0 {
+		return nil
+	}
+	return &timeoutTracker{config: config}
+}
+
+// Record adds the duration of a successful evaluation, dropping the oldest
+// once the window is full
+func (t *timeoutTracker) Record(duration time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.durations) < t.config.Window {
+		t.durations = append(t.durations, duration)
+		return
+	}
+	t.durations[t.next] = duration
+	t.next = (t.next + 1) % t.config.Window
+}
+
+// Timeout returns the percentile of recorded durations times the
+// multiplier, clamped to the floor and ceiling. Until enough durations are
+// recorded it returns fixed, which is also the ceiling unless one is set.
+func (t *timeoutTracker) Timeout(fixed time.Duration) time.Duration {
+	if t == nil {
+		return fixed
+	}
+
+	t.mu.Lock()
+	if len(t.durations) == 0 || len(t.durations) < t.config.MinSamples {
+		t.mu.Unlock()
+		return fixed
+	}
+	sorted := append([]time.Duration(nil), t.durations...)
+	t.mu.Unlock()
+
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+	idx := int(math.Ceil(t.config.Percentile*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	timeout := time.Duration(float64(sorted[idx]) * t.config.Multiplier)
+
+	ceiling := time.Duration(t.config.MaxTimeout) * time.Second
+	if ceiling <= 0 {
+		ceiling = fixed
+	}
+	if floor := time.Duration(t.config.MinTimeout) * time.Second; timeout < floor {
+		timeout = floor
+	}
+	if ceiling > 0 && timeout > ceiling {
+		timeout = ceiling
+	}
+	return timeout
+}
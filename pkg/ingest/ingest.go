@@ -0,0 +1,491 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/goimport"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/database"
+	"github.com/ishanwen-byte/openevolve-go/pkg/iteration"
+)
+
+// Options selects which functions of a package are ingested
+type Options struct {
+	// Signature the function must have, e.g. "func(x, y float64) float64".
+	// Parameter names are ignored; empty matches any signature.
+	Signature string
+	// Marker that must appear in the function's doc comment; empty matches
+	// any function
+	Marker string
+	// Rename gives every candidate this name so it fits the evaluator's
+	// harness; empty keeps the original name
+	Rename string
+	// IncludeTests also scans _test.go files
+	IncludeTests bool
+}
+
+// Candidate is a function extracted from an existing codebase, wrapped as
+// a program for the evaluator to be compiled with
+type Candidate struct {
+	Name string
+	File string
+	Code string
+}
+
+// Evaluator scores candidate programs
+type Evaluator interface {
+	Evaluate(ctx context.Context, code string) (*types.EvaluationResult, error)
+}
+
+// Scan parses the Go package in dir and returns every top-level function
+// matching opts as a program for the evaluator to be compiled with
+func Scan(dir string, opts Options) ([]Candidate, error) {
+	var wantSignature string
+	if opts.Signature != "" {
+		expr, err := parser.ParseExpr(opts.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signature %q: %w", opts.Signature, err)
+		}
+		funcType, ok := expr.(*ast.FuncType)
+		if !ok {
+			return nil, fmt.Errorf("signature %q is not a function type", opts.Signature)
+		}
+		wantSignature = signatureOf(funcType)
+	}
+
+	files, err := parsePackage(dir, opts.IncludeTests)
+	if err != nil {
+		return nil, err
+	}
+	index := indexDecls(files)
+
+	var candidates []Candidate
+	for _, file := range files {
+		for _, decl := range file.ast.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv != nil || funcDecl.Body == nil {
+				continue
+			}
+			if funcDecl.Name.Name == "main" || funcDecl.Name.Name == "init" {
+				continue
+			}
+			if wantSignature != "" && signatureOf(funcDecl.Type) != wantSignature {
+				continue
+			}
+			if opts.Marker != "" && (funcDecl.Doc == nil || !strings.Contains(funcDecl.Doc.Text(), opts.Marker)) {
+				continue
+			}
+
+			code, err := buildProgram(file, funcDecl, index, opts.Rename)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract %s from %s: %w", funcDecl.Name.Name, file.path, err)
+			}
+			candidates = append(candidates, Candidate{
+				Name: funcDecl.Name.Name,
+				File: file.path,
+				Code: code,
+			})
+		}
+	}
+
+	return candidates, nil
+}
+
+// sourceFile is a parsed Go file of the scanned package
+type sourceFile struct {
+	path string
+	src  []byte
+	fset *token.FileSet
+	ast  *ast.File
+}
+
+// source returns the text of node, including its doc comment
+func (f *sourceFile) source(node ast.Node, doc *ast.CommentGroup) string {
+	start := f.fset.Position(node.Pos()).Offset
+	if doc != nil {
+		start = f.fset.Position(doc.Pos()).Offset
+	}
+	return string(f.src[start:f.fset.Position(node.End()).Offset])
+}
+
+// packageDecl is a top-level declaration other functions may depend on
+type packageDecl struct {
+	file  *sourceFile
+	decl  ast.Decl
+	order int
+}
+
+// parsePackage parses every Go file of the package in dir, in name order
+func parsePackage(dir string, includeTests bool) ([]*sourceFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package directory: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	var files []*sourceFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, constants.GoExt) {
+			continue
+		}
+		if !includeTests && strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files = append(files, &sourceFile{path: path, src: src, fset: fset, ast: file})
+	}
+
+	return files, nil
+}
+
+// indexDecls maps every package-level name to the declaration defining it.
+// Methods are indexed under their receiver type so they travel with it.
+func indexDecls(files []*sourceFile) map[string][]*packageDecl {
+	index := make(map[string][]*packageDecl)
+	order := 0
+	for _, file := range files {
+		for _, decl := range file.ast.Decls {
+			entry := &packageDecl{file: file, decl: decl, order: order}
+			order++
+
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					index[d.Name.Name] = append(index[d.Name.Name], entry)
+				} else if typeName := receiverType(d); typeName != "" {
+					index[typeName] = append(index[typeName], entry)
+				}
+			case *ast.GenDecl:
+				if d.Tok == token.IMPORT {
+					continue
+				}
+				for _, spec := range d.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						index[spec.Name.Name] = append(index[spec.Name.Name], entry)
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							index[name.Name] = append(index[name.Name], entry)
+						}
+					}
+				}
+			}
+		}
+	}
+	return index
+}
+
+// receiverType returns the type name of a method's receiver
+func receiverType(funcDecl *ast.FuncDecl) string {
+	if len(funcDecl.Recv.List) == 0 {
+		return ""
+	}
+	expr := funcDecl.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// dependencies returns the package-level declarations root needs, directly
+// or transitively, in source order
+func dependencies(root ast.Decl, index map[string][]*packageDecl) []*packageDecl {
+	included := make(map[ast.Decl]*packageDecl)
+	queue := []ast.Decl{root}
+	for len(queue) > 0 {
+		decl := queue[0]
+		queue = queue[1:]
+		for name := range referencedNames(decl) {
+			for _, dep := range index[name] {
+				if dep.decl == root || included[dep.decl] != nil {
+					continue
+				}
+				included[dep.decl] = dep
+				queue = append(queue, dep.decl)
+			}
+		}
+	}
+
+	deps := make([]*packageDecl, 0, len(included))
+	for _, dep := range included {
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(a, b int) bool { return deps[a].order < deps[b].order })
+	return deps
+}
+
+// referencedNames collects the unqualified identifiers used in node. The
+// selected names of selector expressions are skipped; they are fields,
+// methods or imported names, never package-level declarations.
+func referencedNames(node ast.Node) map[string]bool {
+	names := make(map[string]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, func(x ast.Node) bool {
+				if ident, ok := x.(*ast.Ident); ok {
+					names[ident.Name] = true
+				}
+				return true
+			})
+			return false
+		case *ast.Ident:
+			names[n.Name] = true
+		}
+		return true
+	})
+	return names
+}
+
+// Seed evaluates candidates and adds every successful one to the database,
+// spreading them across islands. Candidates that fail evaluation are logged
+// and skipped.
+func Seed(ctx context.Context, candidates []Candidate, eval Evaluator, db *database.ProgramDatabase, logger *logrus.Logger) ([]*types.Program, error) {
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	numIslands := db.NumIslands()
+	seeded := make([]*types.Program, 0, len(candidates))
+	for _, candidate := range candidates {
+		if err := ctx.Err(); err != nil {
+			return seeded, err
+		}
+
+		result, err := eval.Evaluate(ctx, candidate.Code)
+		if err != nil {
+			return seeded, fmt.Errorf("failed to evaluate %s: %w", candidate.Name, err)
+		}
+		if !result.Success {
+			logger.WithFields(logrus.Fields{
+				"function": candidate.Name,
+				"file":     candidate.File,
+				"error":    result.Error,
+			}).Warn("Skipping candidate that failed evaluation")
+			continue
+		}
+
+		now := time.Now()
+		program := &types.Program{
+			ID:        uuid.New().String(),
+			Code:      candidate.Code,
+			Score:     result.Score,
+			Features:  db.GridFeatures(candidate.Code, result.NamedFeatures, iteration.ExtractFeatures(result)),
+			IslandID:  len(seeded) % numIslands,
+			Artifacts: result.Artifacts,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := db.AddProgram(program, 0); err != nil {
+			return seeded, fmt.Errorf("failed to add %s: %w", candidate.Name, err)
+		}
+		seeded = append(seeded, program)
+
+		logger.WithFields(logrus.Fields{
+			"function": candidate.Name,
+			"file":     candidate.File,
+			"score":    result.Score,
+			"island":   program.IslandID,
+		}).Info("Seeded archive with existing function")
+	}
+
+	return seeded, nil
+}
+
+// buildProgram turns a function into a main package without a main
+// function, which the evaluator supplies when it is compiled together with
+// the program: the function, marked as an evolve block, plus every
+// package-level declaration it depends on and the imports they use
+func buildProgram(file *sourceFile, funcDecl *ast.FuncDecl, index map[string][]*packageDecl, rename string) (string, error) {
+	source := file.source(funcDecl, funcDecl.Doc)
+	deps := dependencies(funcDecl, index)
+	imports := make(map[string]bool)
+	for _, spec := range usedImports(file.ast, funcDecl) {
+		imports[spec] = true
+	}
+	for _, dep := range deps {
+		for _, spec := range usedImports(dep.file.ast, dep.decl) {
+			imports[spec] = true
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("package main\n\n")
+	if len(imports) > 0 {
+		specs := make([]string, 0, len(imports))
+		for spec := range imports {
+			specs = append(specs, spec)
+		}
+		sort.Strings(specs)
+		builder.WriteString("import (\n")
+		for _, spec := range specs {
+			builder.WriteString("\t" + spec + "\n")
+		}
+		builder.WriteString(")\n\n")
+	}
+	for _, dep := range deps {
+		builder.WriteString(dep.file.source(dep.decl, declDoc(dep.decl)))
+		builder.WriteString("\n\n")
+	}
+	builder.WriteString("// " + constants.EvolveBlockStartMarker + "\n")
+	builder.WriteString(source)
+	builder.WriteString("\n// " + constants.EvolveBlockEndMarker + "\n")
+
+	fset := token.NewFileSet()
+	program, err := parser.ParseFile(fset, "", builder.String(), parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse program: %w", err)
+	}
+	if rename != "" && rename != funcDecl.Name.Name {
+		renameFunc(program, funcDecl.Name.Name, rename)
+	}
+
+	var formatted bytes.Buffer
+	if err := format.Node(&formatted, fset, program); err != nil {
+		return "", fmt.Errorf("failed to format program: %w", err)
+	}
+	return string(bytes.TrimSpace(formatted.Bytes())) + "\n", nil
+}
+
+// renameFunc renames the package-level function name of program to rename,
+// along with every identifier that refers to it, such as recursive calls
+// and calls from its dependencies. Fields, methods and locals that merely
+// share the name are left alone.
+func renameFunc(program *ast.File, name, rename string) {
+	var target *ast.Object
+	for _, decl := range program.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil && funcDecl.Name.Name == name {
+			target = funcDecl.Name.Obj
+		}
+	}
+	if target == nil {
+		return
+	}
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Obj == target {
+			ident.Name = rename
+		}
+		return true
+	})
+}
+
+// declDoc returns the doc comment attached to a top-level declaration
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// usedImports returns the import specs of file referenced by node
+func usedImports(file *ast.File, node ast.Node) []string {
+	referenced := make(map[string]bool)
+	ast.Inspect(node, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				referenced[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var specs []string
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := goimport.Name(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if !referenced[name] {
+			continue
+		}
+		if spec.Name != nil {
+			specs = append(specs, spec.Name.Name+" "+spec.Path.Value)
+		} else {
+			specs = append(specs, spec.Path.Value)
+		}
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// signatureOf renders a function type without parameter names, e.g.
+// "func(float64, float64) float64"
+func signatureOf(funcType *ast.FuncType) string {
+	params := fieldTypes(funcType.Params)
+	results := fieldTypes(funcType.Results)
+
+	signature := "func(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		signature += " " + results[0]
+	default:
+		signature += " (" + strings.Join(results, ", ") + ")"
+	}
+	return signature
+}
+
+// fieldTypes lists one type per declared name in a field list
+func fieldTypes(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+
+	var typeNames []string
+	for _, field := range fields.List {
+		typeName := gotypes.ExprString(field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			typeNames = append(typeNames, typeName)
+		}
+	}
+	return typeNames
+}
@@ -0,0 +1,295 @@
+package ingest
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/database"
+	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
+)
+
+const samplePackage = `package minimize
+
+import (
+	"math"
+	"strings"
+)
+
+// Search finds the minimum with a coarse grid.
+// openevolve:seed
+func Search(lo, hi float64) float64 {
+	return math.Min(lo, hi)
+}
+
+// Golden uses golden-section search.
+func Golden(lo float64, hi float64) float64 {
+	return (lo + hi) / 2
+}
+
+func describe(name string) string {
+	return strings.ToUpper(name)
+}
+
+type solver struct{}
+
+func (solver) Search(lo, hi float64) float64 {
+	return lo
+}
+`
+
+// fakeEvaluator scores programs by whether they contain a keyword
+type fakeEvaluator struct{}
+
+func (fakeEvaluator) Evaluate(ctx context.Context, code string) (*types.EvaluationResult, error) {
+	if strings.Contains(code, "math.Min") {
+		return &types.EvaluationResult{Score: 0.7, Success: true, Duration: time.Second}, nil
+	}
+	return &types.EvaluationResult{Success: false, Error: "too slow"}, nil
+}
+
+func writePackage(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "minimize.go"), []byte(samplePackage), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "minimize_test.go"), []byte("package minimize\n\nfunc helper(a, b float64) float64 { return a }\n"), 0644))
+	return dir
+}
+
+func TestScanMatchesSignature(t *testing.T) {
+	candidates, err := Scan(writePackage(t), Options{Signature: "func(a, b float64) float64"})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+	// Methods and test files are skipped
+	assert.Equal(t, []string{"Search", "Golden"}, names)
+}
+
+func TestScanMatchesMarkerAndRenames(t *testing.T) {
+	candidates, err := Scan(writePackage(t), Options{Marker: "openevolve:seed", Rename: "Minimize"})
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+
+	code := candidates[0].Code
+	assert.Contains(t, code, "package main")
+	assert.Contains(t, code, "func Minimize(lo, hi float64) float64")
+	assert.Contains(t, code, `"math"`)
+	assert.NotContains(t, code, `"strings"`, "unused imports must be dropped")
+	assert.Contains(t, code, constants.EvolveBlockStartMarker)
+	assert.Contains(t, code, constants.EvolveBlockEndMarker)
+}
+
+const recursivePackage = `package fib
+
+type memo struct{ Fib map[int]int }
+
+func (m memo) lookup(n int) int {
+	if v, ok := m.Fib[n]; ok {
+		return v
+	}
+	return Fib(n)
+}
+
+// Fib is recursive and called from a method.
+// openevolve:seed
+func Fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return Fib(n-1) + memo{}.lookup(n-2)
+}
+`
+
+func TestScanRenamesEveryUse(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fib.go"), []byte(recursivePackage), 0644))
+
+	candidates, err := Scan(dir, Options{Marker: "openevolve:seed", Rename: "Compute"})
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+
+	code := candidates[0].Code
+	assert.Contains(t, code, "func Compute(n int) int")
+	assert.Contains(t, code, "return Compute(n-1) + memo{}.lookup(n-2)")
+	assert.Contains(t, code, "return Compute(n)")
+	assert.Contains(t, code, "m.Fib[n]", "fields sharing the name must not be renamed")
+	assert.NotContains(t, code, "Fib(")
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, 0)
+	require.NoError(t, err)
+	conf := gotypes.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("main", fset, []*ast.File{file}, nil)
+	require.NoError(t, err, code)
+}
+
+const helperPackage = `package scale
+
+import "strings"
+
+// factor scales every result
+const factor = 2
+
+type point struct{ x float64 }
+
+func (p point) scaled() float64 {
+	return p.x * factor
+}
+`
+
+const scaledPackage = `package scale
+
+import (
+	"fmt"
+	"math"
+)
+
+// Scaled uses package-level helpers.
+// openevolve:seed
+func Scaled(x float64) float64 {
+	return clamp(point{x: x}.scaled())
+}
+
+func clamp(v float64) float64 {
+	return math.Min(v, limit)
+}
+
+var limit = 10.0
+
+func unused() string {
+	return fmt.Sprint(limit)
+}
+`
+
+func TestScanIncludesPackageLevelDependencies(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "helpers.go"), []byte(helperPackage), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scale.go"), []byte(scaledPackage), 0644))
+
+	candidates, err := Scan(dir, Options{Marker: "openevolve:seed"})
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+
+	code := candidates[0].Code
+	assert.Contains(t, code, "package main")
+	assert.NotContains(t, code, "func main(", "the evaluator supplies main")
+	for _, decl := range []string{"func clamp(", "var limit", "const factor", "type point", "func (p point) scaled()"} {
+		assert.Contains(t, code, decl)
+	}
+	assert.NotContains(t, code, "func unused(")
+	assert.NotContains(t, code, `"fmt"`)
+	assert.NotContains(t, code, `"strings"`)
+
+	// The program must type-check on its own
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, 0)
+	require.NoError(t, err)
+	conf := gotypes.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("main", fset, []*ast.File{file}, nil)
+	require.NoError(t, err, code)
+}
+
+func TestScanRejectsInvalidSignature(t *testing.T) {
+	_, err := Scan(writePackage(t), Options{Signature: "float64"})
+	assert.Error(t, err)
+}
+
+func TestSeedAddsPassingCandidates(t *testing.T) {
+	candidates, err := Scan(writePackage(t), Options{Signature: "func(float64, float64) float64"})
+	require.NoError(t, err)
+
+	db := database.New(types.DatabaseConfig{
+		NumIslands:     2,
+		GridDimensions: []string{"complexity", "diversity"},
+		GridResolution: map[string]int{"complexity": 5, "diversity": 5},
+		GridBounds:     map[string][2]float64{"complexity": {0, 1}, "diversity": {0, 1}},
+	}, "")
+
+	seeded, err := Seed(context.Background(), candidates, fakeEvaluator{}, db, nil)
+	require.NoError(t, err)
+	require.Len(t, seeded, 1)
+	assert.Equal(t, 0.7, seeded[0].Score)
+
+	best := db.GetGlobalBest()
+	require.NotNil(t, best)
+	assert.Equal(t, seeded[0].ID, best.ID)
+}
+
+func TestSeedRunsCandidatesThroughEvaluator(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	// The evaluator is compiled together with each candidate, so it must
+	// sit in the directory programs are written to
+	evaluatorFile, err := os.CreateTemp("", "evaluator-*.go")
+	require.NoError(t, err)
+	evaluatorPath := evaluatorFile.Name()
+	defer os.Remove(evaluatorPath)
+	_, err = evaluatorFile.WriteString(`package main
+
+import (
+	"fmt"
+	"math"
+)
+
+func main() { fmt.Printf("SCORE: %g\n", 1/(1+math.Abs(search(3, 5)-3))) }
+`)
+	require.NoError(t, err)
+	require.NoError(t, evaluatorFile.Close())
+
+	eval, err := evaluator.New(types.EvaluatorConfig{ParallelWorkers: 1, Timeout: 60}, evaluatorPath)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	candidates, err := Scan(writePackage(t), Options{Signature: "func(float64, float64) float64", Rename: "search"})
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+
+	db := database.New(types.DatabaseConfig{NumIslands: 1}, "")
+	seeded, err := Seed(context.Background(), candidates, eval, db, nil)
+	require.NoError(t, err)
+	require.Len(t, seeded, 2)
+	scores := map[float64]bool{}
+	for _, program := range seeded {
+		scores[program.Score] = true
+	}
+	assert.Equal(t, map[float64]bool{1: true, 0.5: true}, scores)
+}
+
+func TestUsedImportsResolvesVersionedPaths(t *testing.T) {
+	src := `package p
+
+import (
+	"math/rand/v2"
+	"gopkg.in/yaml.v3"
+	"github.com/x/go-foo"
+	"strings"
+)
+
+func f() {
+	_ = rand.IntN(3)
+	_, _ = yaml.Marshal(foo.Value)
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`"github.com/x/go-foo"`, `"gopkg.in/yaml.v3"`, `"math/rand/v2"`}, usedImports(file, file.Decls[1]))
+}
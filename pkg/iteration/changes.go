@@ -0,0 +1,57 @@
+package iteration
+
+import (
+	"strings"
+)
+
+// changesPrefix opens the change summary the prompt asks the LLM for
+const changesPrefix = "changes:"
+
+// maxChangesLength caps a change summary so a rambling answer cannot bloat
+// every program that records it
+const maxChangesLength = 500
+
+// extractChangesSummary returns the "Changes:" summary from an LLM
+// response, or "" if it has none. The summary runs from the prefix to the
+// next blank line or code fence; lines inside code blocks are ignored.
+func extractChangesSummary(response string) string {
+	inCode := false
+	var summary []string
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if summary != nil {
+				break
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		if summary != nil {
+			if trimmed == "" {
+				break
+			}
+			summary = append(summary, trimmed)
+			continue
+		}
+
+		// Tolerate Markdown emphasis around the prefix
+		bare := strings.TrimLeft(trimmed, "*_#> ")
+		if len(bare) >= len(changesPrefix) && strings.EqualFold(bare[:len(changesPrefix)], changesPrefix) {
+			first := strings.TrimSpace(strings.TrimLeft(bare[len(changesPrefix):], "*_ "))
+			summary = []string{}
+			if first != "" {
+				summary = append(summary, first)
+			}
+		}
+	}
+
+	text := strings.Join(summary, " ")
+	if len(text) > maxChangesLength {
+		text = strings.TrimSpace(text[:maxChangesLength]) + "..."
+	}
+	return text
+}
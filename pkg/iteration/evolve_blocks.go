@@ -0,0 +1,111 @@
+package iteration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+)
+
+// EvolveBlockViolation reports a child that changed code outside the
+// EVOLVE-BLOCK regions of its parent
+type EvolveBlockViolation struct {
+	// Regions describes each fixed region that was modified
+	Regions []string
+}
+
+func (v *EvolveBlockViolation) Error() string {
+	return fmt.Sprintf("code outside evolve blocks was modified: %s", strings.Join(v.Regions, "; "))
+}
+
+// Feedback returns the message appended to the prompt when re-prompting
+func (v *EvolveBlockViolation) Feedback() string {
+	var builder strings.Builder
+	builder.WriteString("Your previous answer was rejected because it modified code outside the evolve blocks:\n")
+	for _, region := range v.Regions {
+		builder.WriteString("- ")
+		builder.WriteString(region)
+		builder.WriteString("\n")
+	}
+	builder.WriteString(fmt.Sprintf("Only change code between %s and %s markers, and keep all other code and the markers themselves exactly as they are.",
+		constants.EvolveBlockStartMarker, constants.EvolveBlockEndMarker))
+	return builder.String()
+}
+
+// validateEvolveBlocks checks that child differs from parent only inside
+// evolve blocks. Parents without evolve blocks may be changed freely.
+func validateEvolveBlocks(parentCode, childCode string) *EvolveBlockViolation {
+	parentFixed, ok := splitEvolveBlocks(parentCode)
+	if !ok || len(parentFixed) < 2 {
+		return nil
+	}
+
+	childFixed, ok := splitEvolveBlocks(childCode)
+	if !ok || len(childFixed) != len(parentFixed) {
+		return &EvolveBlockViolation{Regions: []string{
+			fmt.Sprintf("expected %d evolve block(s) with intact markers, found %d",
+				len(parentFixed)-1, max(len(childFixed)-1, 0)),
+		}}
+	}
+
+	var regions []string
+	for i := range parentFixed {
+		if normalizeFixedRegion(parentFixed[i]) == normalizeFixedRegion(childFixed[i]) {
+			continue
+		}
+		switch {
+		case i == 0:
+			regions = append(regions, "code before the first evolve block")
+		case i == len(parentFixed)-1:
+			regions = append(regions, "code after the last evolve block")
+		default:
+			regions = append(regions, fmt.Sprintf("code between evolve blocks %d and %d", i, i+1))
+		}
+	}
+
+	if len(regions) == 0 {
+		return nil
+	}
+	return &EvolveBlockViolation{Regions: regions}
+}
+
+// splitEvolveBlocks returns the fixed regions surrounding the evolve blocks.
+// N blocks yield N+1 fixed regions; ok is false when markers are unbalanced.
+func splitEvolveBlocks(code string) ([]string, bool) {
+	var fixed []string
+	var current strings.Builder
+	inBlock := false
+
+	for _, line := range strings.SplitAfter(code, "\n") {
+		switch {
+		case strings.Contains(line, constants.EvolveBlockStartMarker):
+			if inBlock {
+				return nil, false
+			}
+			fixed = append(fixed, current.String())
+			current.Reset()
+			inBlock = true
+		case strings.Contains(line, constants.EvolveBlockEndMarker):
+			if !inBlock {
+				return nil, false
+			}
+			inBlock = false
+		case !inBlock:
+			current.WriteString(line)
+		}
+	}
+
+	if inBlock {
+		return nil, false
+	}
+	return append(fixed, current.String()), true
+}
+
+// normalizeFixedRegion ignores trailing whitespace and surrounding blank lines
+func normalizeFixedRegion(region string) string {
+	lines := strings.Split(region, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
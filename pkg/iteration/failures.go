@@ -0,0 +1,58 @@
+package iteration
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
+)
+
+// compileErrorPattern matches the file:line:column positions the Go
+// compiler reports; runtime panics print positions without a column
+var compileErrorPattern = regexp.MustCompile(`\.go:\d+:\d+: `)
+
+// IterationError is an iteration failure tagged with its category
+type IterationError struct {
+	Category string
+	Err      error
+}
+
+func (e *IterationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *IterationError) Unwrap() error {
+	return e.Err
+}
+
+// failure tags err with a failure category
+func failure(category string, err error) error {
+	return &IterationError{Category: category, Err: err}
+}
+
+// FailureCategory returns the category of an error returned by an
+// iteration, or other when it was not tagged with one
+func FailureCategory(err error) string {
+	var iterationErr *IterationError
+	if errors.As(err, &iterationErr) {
+		return iterationErr.Category
+	}
+	return constants.FailureOther
+}
+
+// EvaluationFailure returns the category of a failed evaluation: a timeout,
+// a compile error or an error while running; empty when it succeeded
+func EvaluationFailure(result *types.EvaluationResult) string {
+	switch {
+	case result == nil || result.Success:
+		return ""
+	case result.Artifacts["timeout"] == "true":
+		return constants.FailureEvalTimeout
+	case result.Artifacts[evaluator.CompileErrorsArtifact] != "" || compileErrorPattern.MatchString(result.Artifacts["stderr"]):
+		return constants.FailureCompileError
+	default:
+		return constants.FailureEvalError
+	}
+}
@@ -0,0 +1,66 @@
+package iteration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
+)
+
+// Generation is the outcome of a generation step run on its own
+type Generation struct {
+	Prompt      PromptData `json:"prompt"`
+	LLMResponse string     `json:"llm_response"`
+	// Model is the ensemble member that answered
+	Model     string `json:"model,omitempty"`
+	ChildCode string `json:"child_code"`
+	Changes   string `json:"changes"`
+}
+
+// Generate runs exactly one generation step on parent: it builds the
+// prompt, asks the LLM and extracts the child, without sampling from the
+// database or evaluating the child. When the response yields no child, the
+// prompt and response are returned along with the error.
+func (iw *IterationWorker) Generate(ctx context.Context, parent *types.Program, inspirations []*types.Program, iteration int) (*Generation, error) {
+	if iw.llmEnsemble == nil {
+		return nil, fmt.Errorf("worker has no LLM ensemble to generate with")
+	}
+
+	// Hide protected regions from the model as an iteration would
+	promptParent := *parent
+	var protected *protectedRegions
+	promptParent.Code, protected = stripProtected(parent.Code, iw.config.Prompt.ProtectedRegions)
+	inspirations = stripPrograms(inspirations, iw.config.Prompt.ProtectedRegions)
+	iw.summarizeInspirations(ctx, inspirations)
+
+	prompt, err := iw.buildPrompt(&promptParent, inspirations, iteration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
+	generation := &Generation{Prompt: prompt}
+
+	pool := iw.editMode()
+	override := iw.config.Database.IslandOverrides[parent.IslandID]
+	if len(override.Models) > 0 {
+		pool = llm.IslandPool(parent.IslandID)
+	}
+	opts := llm.GenerateOptions{
+		Seed:        deriveLLMSeed(iw.iterationSeed(iteration)),
+		Pool:        pool,
+		Temperature: override.Temperature,
+	}
+
+	fullPrompt := fmt.Sprintf("System: %s\n\nUser: %s", prompt.System, prompt.User)
+	childCode, changes, response, err := iw.generateChild(ctx, fullPrompt, promptParent.Code, protected, opts)
+	if response != nil {
+		generation.LLMResponse = response.Content
+		generation.Model = response.Member
+	}
+	if err != nil {
+		return generation, err
+	}
+	generation.ChildCode = childCode
+	generation.Changes = changes
+	return generation, nil
+}
@@ -1,12 +1,20 @@
 package iteration
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
 	"github.com/ishanwen-byte/openevolve-go/pkg/database"
 	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
@@ -36,6 +44,17 @@ func TestNewIterationWorker(t *testing.T) {
 	assert.Equal(t, 0.5, worker.config.Prompt.Stochasticity)
 }
 
+func TestWorkersWithoutEvaluatorHaveNilEvaluator(t *testing.T) {
+	var eval *evaluator.Evaluator
+	worker := NewIterationWorker(types.Config{}, nil, eval, nil)
+	// A typed nil in the interface would compare unequal to nil
+	assert.True(t, worker.evaluator == nil)
+
+	worker = NewGenerationWorker(types.Config{}, nil)
+	assert.True(t, worker.evaluator == nil)
+	assert.Nil(t, worker.db)
+}
+
 func TestExtractCodeBlocks(t *testing.T) {
 	worker := &IterationWorker{}
 
@@ -59,6 +78,13 @@ func TestExtractCodeBlocks(t *testing.T) {
 				"code2",
 			},
 		},
+		{
+			name: "shell block",
+			text: "Script:\n```sh\ntr a-z A-Z < input.txt\n```",
+			expected: []string{
+				"tr a-z A-Z < input.txt",
+			},
+		},
 		{
 			name:     "no blocks",
 			text:     "Just plain text without code blocks",
@@ -79,6 +105,41 @@ func TestExtractCodeBlocks(t *testing.T) {
 	}
 }
 
+func TestExtractChangesSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{
+			name:     "summary line",
+			response: "Changes: Replaced the linear scan with binary search.\n\n```go\nfunc f() {}\n```",
+			expected: "Replaced the linear scan with binary search.",
+		},
+		{
+			name:     "emphasized multi-line summary",
+			response: "**Changes:** Cached results\nto avoid recomputation.\n```go\nfunc f() {}\n```",
+			expected: "Cached results to avoid recomputation.",
+		},
+		{
+			name:     "prefix inside code is ignored",
+			response: "```go\n// Changes: none\nfunc f() {}\n```",
+			expected: "",
+		},
+		{
+			name:     "no summary",
+			response: "Here is the code:\n```go\nfunc f() {}\n```",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, extractChangesSummary(test.response))
+		})
+	}
+}
+
 func TestParseFullRewrite(t *testing.T) {
 	worker := &IterationWorker{}
 
@@ -143,6 +204,29 @@ func TestCalculateFitness(t *testing.T) {
 	assert.Equal(t, 0.8, fitness) // No bonus for regression
 }
 
+func TestNoveltyWeightSchedules(t *testing.T) {
+	worker := &IterationWorker{config: types.Config{
+		Database: types.DatabaseConfig{NoveltyWeight: 0.4},
+	}}
+	assert.Equal(t, 0.4, worker.noveltyWeight(0))
+	assert.Equal(t, 0.4, worker.noveltyWeight(1000))
+
+	worker.config.Database.NoveltyDecay = constants.NoveltyDecayLinear
+	worker.config.Database.NoveltyDecayIterations = 100
+	assert.InDelta(t, 0.4, worker.noveltyWeight(0), 1e-9)
+	assert.InDelta(t, 0.2, worker.noveltyWeight(50), 1e-9)
+	assert.Equal(t, 0.0, worker.noveltyWeight(150))
+
+	worker.config.Database.NoveltyDecay = constants.NoveltyDecayExponential
+	assert.InDelta(t, 0.2, worker.noveltyWeight(100), 1e-9)
+	assert.InDelta(t, 0.1, worker.noveltyWeight(200), 1e-9)
+}
+
+func TestBlendNovelty(t *testing.T) {
+	assert.Equal(t, 0.8, blendNovelty(0.8, 0.1, 0))
+	assert.InDelta(t, 0.5, blendNovelty(0.8, 0.2, 0.5), 1e-9)
+}
+
 func TestExtractFeatures(t *testing.T) {
 	worker := &IterationWorker{}
 
@@ -164,6 +248,7 @@ func TestBuildPrompt(t *testing.T) {
 			Prompt: types.PromptConfig{
 				SystemMessage:   "Test system",
 				EvolutionPrompt: "Improve this code",
+				ChangesInstruction: "Start with Changes:",
 			},
 		},
 	}
@@ -187,10 +272,43 @@ func TestBuildPrompt(t *testing.T) {
 	assert.Contains(t, prompt.User, "func test() {}")
 	assert.Contains(t, prompt.User, "func better() {}")
 	assert.Contains(t, prompt.User, "Improve this code")
+	assert.True(t, strings.HasSuffix(prompt.User, "Start with Changes:"))
 	assert.Contains(t, prompt.Context, "Iteration: 10")
 	assert.Contains(t, prompt.Context, "Generation: 5")
 }
 
+func TestBuildPromptIncludesArtifacts(t *testing.T) {
+	worker := &IterationWorker{
+		config: types.Config{
+			Evaluator: types.EvaluatorConfig{
+				CollectArtifacts: true,
+				ArtifactMaxSize:  10,
+			},
+		},
+	}
+
+	parent := &types.Program{
+		Code:      "func test() {}",
+		Artifacts: map[string]string{"stderr": "main.go:3: undefined: foo and more"},
+	}
+
+	prompt, err := worker.buildPrompt(parent, nil, 1)
+	require.NoError(t, err)
+	assert.Contains(t, prompt.User, "Execution feedback")
+	assert.Contains(t, prompt.User, "stderr:")
+	assert.Contains(t, prompt.User, "main.go:3:")
+	assert.NotContains(t, prompt.User, "and more")
+
+	// Compile errors are listed by line rather than shown as JSON
+	parent.Artifacts = map[string]string{
+		evaluator.CompileErrorsArtifact: `[{"file":"program.go","line":4,"column":2,"message":"undefined: foo"}]`,
+	}
+	prompt, err = worker.buildPrompt(parent, nil, 1)
+	require.NoError(t, err)
+	assert.Contains(t, prompt.User, "Compile errors:\n- program.go line 4: undefined: foo\n")
+	assert.NotContains(t, prompt.User, `"line"`)
+}
+
 func TestGetMaxCodeLength(t *testing.T) {
 	worker := &IterationWorker{}
 	maxLength := worker.getMaxCodeLength()
@@ -234,6 +352,12 @@ func TestIterationResult_GetIterationStats(t *testing.T) {
 	assert.Equal(t, "Full rewrite", stats["changes_type"])
 	assert.Equal(t, true, stats["evaluation_success"])
 	assert.Equal(t, 1, stats["artifacts_count"])
+	assert.Equal(t, false, stats["rolled_back"])
+}
+
+func TestMeanScore(t *testing.T) {
+	assert.Equal(t, 0.0, meanScore(nil))
+	assert.InDelta(t, 0.8, meanScore([]float64{0.9, 0.7, 0.8}), 1e-9)
 }
 
 func TestIterationResult_ToJSON(t *testing.T) {
@@ -307,4 +431,586 @@ func BenchmarkBuildPrompt(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = worker.buildPrompt(parent, inspirations, 10)
 	}
-}
\ No newline at end of file
+}
+func TestProtectedRegionsRoundTrip(t *testing.T) {
+	config := types.ProtectedRegionsConfig{
+		Enabled:     true,
+		Header:      true,
+		Imports:     true,
+		StartMarker: "// PROTECTED-REGION-START",
+		EndMarker:   "// PROTECTED-REGION-END",
+	}
+
+	parent := "// Copyright 2024 Example\n//go:build linux\n\npackage main\n\nimport (\n\t\"fmt\"\n)\n\n" +
+		"// PROTECTED-REGION-START\nconst magic = 42\n// PROTECTED-REGION-END\n\nfunc main() { fmt.Println(magic) }\n"
+
+	stripped, protected := stripProtected(parent, config)
+	assert.NotContains(t, stripped, "Copyright")
+	assert.NotContains(t, stripped, "const magic")
+	assert.Contains(t, stripped, protectedImportsPlaceholder)
+	assert.Contains(t, stripped, "// <protected region 1: kept verbatim>")
+
+	// The model rewrites the body, drops the header and invents its own imports
+	child := "package main\n\nimport \"os\"\n\n// <protected region 1: kept verbatim>\n\nfunc main() { fmt.Println(magic + 1) }\n"
+	restored, err := protected.restore(child)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(restored, "// Copyright 2024 Example\n//go:build linux\n\npackage main"))
+	assert.Contains(t, restored, "import (\n\t\"fmt\"\n)")
+	assert.NotContains(t, restored, "\"os\"")
+	assert.Contains(t, restored, "// PROTECTED-REGION-START\nconst magic = 42\n// PROTECTED-REGION-END")
+	assert.Contains(t, restored, "magic + 1")
+}
+
+func TestProtectedRegionsMissingPlaceholder(t *testing.T) {
+	config := types.ProtectedRegionsConfig{
+		Enabled:     true,
+		StartMarker: "// PROTECTED-REGION-START",
+		EndMarker:   "// PROTECTED-REGION-END",
+	}
+
+	_, protected := stripProtected("package main\n// PROTECTED-REGION-START\nvar x = 1\n// PROTECTED-REGION-END\n", config)
+
+	_, err := protected.restore("package main\n")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "protected region 1")
+}
+
+func TestProtectedRegionsDisabled(t *testing.T) {
+	code := "// header\npackage main\n"
+	stripped, protected := stripProtected(code, types.ProtectedRegionsConfig{Header: true})
+	assert.Equal(t, code, stripped)
+
+	restored, err := protected.restore("package main\n")
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", restored)
+}
+
+func TestValidateEvolveBlocks(t *testing.T) {
+	parent := "package main\n\nimport \"fmt\"\n\n// EVOLVE-BLOCK-START\nfunc solve() int { return 1 }\n// EVOLVE-BLOCK-END\n\nfunc main() { fmt.Println(solve()) }\n"
+
+	// Changes inside the block are allowed
+	child := "package main\n\nimport \"fmt\"\n\n// EVOLVE-BLOCK-START\nfunc solve() int {\n\treturn 2\n}\n// EVOLVE-BLOCK-END\n\nfunc main() { fmt.Println(solve()) }  \n"
+	assert.Nil(t, validateEvolveBlocks(parent, child))
+
+	// Changes after the block are rejected
+	child = "package main\n\nimport \"fmt\"\n\n// EVOLVE-BLOCK-START\nfunc solve() int { return 2 }\n// EVOLVE-BLOCK-END\n\nfunc main() { fmt.Println(solve() + 1) }\n"
+	violation := validateEvolveBlocks(parent, child)
+	require.NotNil(t, violation)
+	assert.Equal(t, []string{"code after the last evolve block"}, violation.Regions)
+	assert.Contains(t, violation.Feedback(), "EVOLVE-BLOCK-START")
+
+	// Dropping the markers is rejected
+	violation = validateEvolveBlocks(parent, "package main\n\nfunc main() {}\n")
+	require.NotNil(t, violation)
+	assert.Contains(t, violation.Error(), "expected 1 evolve block(s)")
+
+	// Parents without markers can be rewritten freely
+	assert.Nil(t, validateEvolveBlocks("package main\n", "package other\n"))
+}
+
+func TestRepetitionTrackerObserve(t *testing.T) {
+	tracker := newRepetitionTracker(3)
+
+	assert.Equal(t, 1, tracker.observe("parent", "func a() {}"))
+	assert.Equal(t, 2, tracker.observe("parent", "func a() {}\n"))
+	assert.Equal(t, 1, tracker.observe("other", "func a() {}"))
+	assert.Equal(t, 1, tracker.observe("parent", "func b() {}"))
+	assert.Equal(t, 1, tracker.observe("parent", "func c() {}"))
+
+	// The first two outputs have slid out of the window
+	assert.Equal(t, 1, tracker.observe("parent", "func a() {}"))
+
+	var nilTracker *repetitionTracker
+	assert.Equal(t, 0, nilTracker.observe("parent", "func a() {}"))
+}
+
+func TestEscalate(t *testing.T) {
+	worker := &IterationWorker{
+		config: types.Config{
+			LLM: types.LLMConfig{Temperature: 0.5},
+			Prompt: types.PromptConfig{
+				MutationPrompt: "Try something different.",
+				Repetition:     types.RepetitionConfig{TemperatureStep: 0.5},
+			},
+		},
+	}
+
+	var opts llm.GenerateOptions
+	assert.Contains(t, worker.escalate(escalateTemperature, 0, &opts, "gpt-4"), "raised temperature")
+	assert.InDelta(t, 1.0, opts.Temperature, 1e-9)
+	assert.Empty(t, opts.ExcludeModels)
+
+	assert.Contains(t, worker.escalate(escalateTemplate, 0, &opts, "gpt-4"), "mutation template")
+	assert.InDelta(t, 1.5, opts.Temperature, 1e-9)
+
+	assert.Contains(t, worker.escalate(escalateModel, 0, &opts, "gpt-4"), "gpt-4")
+	assert.InDelta(t, constants.MaxEscalationTemperature, opts.Temperature, 1e-9)
+	assert.Equal(t, []string{"gpt-4"}, opts.ExcludeModels)
+}
+
+func TestModelRouter(t *testing.T) {
+	router := newModelRouter(types.RoutingConfig{Enabled: true, StagnationIterations: 2, RefineChampion: true})
+
+	pool, reason := router.route(0, false)
+	assert.Equal(t, constants.ModelPoolCheap, pool)
+	assert.Empty(t, reason)
+
+	pool, reason = router.route(0, true)
+	assert.Equal(t, constants.ModelPoolStrong, pool)
+	assert.Equal(t, constants.RouteReasonChampion, reason)
+
+	// Two iterations without beating the island's best escalate it
+	router.observe(0, 0.5)
+	router.observe(0, 0.4)
+	pool, _ = router.route(0, false)
+	assert.Equal(t, constants.ModelPoolCheap, pool)
+	router.observe(0, 0.5)
+	pool, reason = router.route(0, false)
+	assert.Equal(t, constants.ModelPoolStrong, pool)
+	assert.Equal(t, constants.RouteReasonStagnation, reason)
+
+	// Other islands are unaffected, and an improvement resets the count
+	pool, _ = router.route(1, false)
+	assert.Equal(t, constants.ModelPoolCheap, pool)
+	router.observe(0, 0.6)
+	pool, _ = router.route(0, false)
+	assert.Equal(t, constants.ModelPoolCheap, pool)
+
+	router.record(constants.ModelPoolCheap, types.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5})
+	router.record(constants.ModelPoolCheap, types.TokenUsage{TotalTokens: 1})
+	router.record(constants.ModelPoolStrong, types.TokenUsage{TotalTokens: 7})
+	spend := router.snapshot()
+	assert.Equal(t, 2, spend[constants.ModelPoolCheap].Iterations)
+	assert.Equal(t, 6, spend[constants.ModelPoolCheap].Tokens.TotalTokens)
+	assert.Equal(t, 7, spend[constants.ModelPoolStrong].Tokens.TotalTokens)
+
+	// Disabled routing leaves pool choice to the edit mode
+	disabled := newModelRouter(types.RoutingConfig{})
+	assert.Nil(t, disabled)
+	pool, _ = disabled.route(0, true)
+	assert.Empty(t, pool)
+	assert.Nil(t, disabled.snapshot())
+}
+
+func TestRunIterationRoutesChampionToStrongPool(t *testing.T) {
+	worker := newTestWorker(t, fixedEvaluator{score: 0.1}, "```go\n"+childCode+"\n```")
+	worker.router = newModelRouter(types.RoutingConfig{Enabled: true, RefineChampion: true})
+
+	// The only program in the archive is the champion
+	result, err := worker.RunIteration(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, constants.ModelPoolStrong, result.Seeds.Pool)
+	assert.Equal(t, 1, worker.RoutingSpend()[constants.ModelPoolStrong].Iterations)
+}
+
+func TestRunIterationAppliesIslandOverrides(t *testing.T) {
+	var temperatures []float64
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Temperature float64 `json:"temperature"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		temperatures = append(temperatures, request.Temperature)
+		mu.Unlock()
+		content, _ := json.Marshal("```go\n" + childCode + "\n```")
+		fmt.Fprintf(w, `{"model": "island", "choices": [{"message": {"role": "assistant", "content": %s}}]}`, content)
+	}))
+	defer server.Close()
+
+	worker := newTestWorker(t, fixedEvaluator{score: 0.1}, "unused")
+	worker.config.Database.IslandOverrides = map[int]types.IslandOverride{0: {
+		Models:        []types.LLMModelConfig{{Name: "island-model", Weight: 1, APIBase: server.URL}},
+		Temperature:   0.3,
+		SystemMessage: "You write numerical code.",
+	}}
+	require.NoError(t, worker.llmEnsemble.AddIslandPools(worker.config.Database.IslandOverrides, worker.config.LLM))
+
+	result, err := worker.RunIteration(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "island-model", result.Seeds.Model)
+	assert.Equal(t, "You write numerical code.", result.Prompt.System)
+	assert.Equal(t, []float64{0.3}, temperatures)
+}
+
+func TestRunIterationAppliesPhase(t *testing.T) {
+	var temperatures []float64
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Temperature float64 `json:"temperature"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		temperatures = append(temperatures, request.Temperature)
+		mu.Unlock()
+		content, _ := json.Marshal("```go\n" + childCode + "\n```")
+		fmt.Fprintf(w, `{"model": "phase", "choices": [{"message": {"role": "assistant", "content": %s}}]}`, content)
+	}))
+	defer server.Close()
+
+	worker := newTestWorker(t, fixedEvaluator{score: 0.1}, "unused")
+	worker.config.LLM.Models = []types.LLMModelConfig{
+		{Name: "fast", Weight: 1, APIBase: server.URL},
+		{Name: "careful", Weight: 1, APIBase: server.URL},
+	}
+	ensemble, err := llm.NewEnsembleFromConfig(worker.config.LLM)
+	require.NoError(t, err)
+	worker.llmEnsemble = ensemble
+	worker.router = newModelRouter(types.RoutingConfig{Enabled: true, RefineChampion: true})
+
+	// The phase's model and temperature replace routing and the model's own
+	worker.SetPhase(types.PhaseConfig{Name: "refine", Temperature: 0.2, Model: "careful"})
+	worker.config.Database.Tags = []string{"experiment-7"}
+	for i := 1; i <= 3; i++ {
+		result, err := worker.RunIteration(context.Background(), i)
+		require.NoError(t, err)
+		assert.Equal(t, "careful", result.Seeds.Model)
+		assert.Empty(t, result.Seeds.Pool)
+		assert.Equal(t, map[string]any{constants.MetadataModel: "careful", constants.MetadataPhase: "refine"}, result.ChildProgram.Metadata)
+		assert.Equal(t, []string{"experiment-7"}, result.ChildProgram.Tags)
+	}
+	assert.Equal(t, []float64{0.2, 0.2, 0.2}, temperatures)
+
+	// Island overrides take precedence over the phase
+	worker.config.Database.IslandOverrides = map[int]types.IslandOverride{0: {Temperature: 0.9}}
+	assert.Equal(t, 0.9, worker.temperature(0))
+	worker.SetPhase(types.PhaseConfig{})
+	assert.Zero(t, worker.temperature(1))
+}
+
+func TestFailureCategories(t *testing.T) {
+	err := fmt.Errorf("failed to parse LLM response: %w", failure(constants.FailureNoCodeBlock, fmt.Errorf("no code")))
+	assert.Equal(t, constants.FailureNoCodeBlock, FailureCategory(err))
+	assert.Equal(t, constants.FailureOther, FailureCategory(fmt.Errorf("sampling failed")))
+
+	assert.Empty(t, EvaluationFailure(&types.EvaluationResult{Success: true}))
+	assert.Equal(t, constants.FailureEvalTimeout, EvaluationFailure(&types.EvaluationResult{
+		Artifacts: map[string]string{"timeout": "true"},
+	}))
+	assert.Equal(t, constants.FailureCompileError, EvaluationFailure(&types.EvaluationResult{
+		Artifacts: map[string]string{"stderr": "# command-line-arguments\n./eval-1.go:4:2: undefined: x\n"},
+	}))
+	assert.Equal(t, constants.FailureEvalError, EvaluationFailure(&types.EvaluationResult{
+		Artifacts: map[string]string{"stderr": "panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/tmp/eval-1.go:4 +0x25\n"},
+	}))
+
+	// A reply without code fails the iteration under its category
+	worker := newTestWorker(t, fixedEvaluator{score: 0.1}, "I would rather not.")
+	_, err = worker.RunIteration(context.Background(), 1)
+	require.Error(t, err)
+	assert.Equal(t, constants.FailureNoCodeBlock, FailureCategory(err))
+}
+
+func TestPromptShrinker(t *testing.T) {
+	shrinker := newPromptShrinker(types.ShrinkConfig{Enabled: true, Window: 4, FailureRate: 0.5, MaxLevel: 2})
+	assert.Equal(t, constants.DefaultNumInspirations, shrinker.inspirations())
+	assert.Equal(t, 1.0, shrinker.tokenFactor())
+
+	// Half of a window failing shrinks the prompt one level
+	for _, malformed := range []bool{true, false, true} {
+		move, _ := shrinker.observe(malformed)
+		assert.Zero(t, move)
+	}
+	move, rate := shrinker.observe(false)
+	assert.Equal(t, 1, move)
+	assert.Equal(t, 0.5, rate)
+	assert.Equal(t, 1, shrinker.inspirations())
+	assert.Equal(t, constants.InspirationExcerptLength/2, shrinker.excerptLength())
+	assert.Equal(t, constants.DefaultShrinkTokenFactor, shrinker.tokenFactor())
+
+	// It stops at the max level
+	for i := 0; i < 8; i++ {
+		shrinker.observe(true)
+	}
+	assert.Equal(t, 2, shrinker.current())
+	assert.Zero(t, shrinker.inspirations())
+
+	// A quiet window restores a level
+	for i := 0; i < 3; i++ {
+		shrinker.observe(false)
+	}
+	move, _ = shrinker.observe(false)
+	assert.Equal(t, -1, move)
+	assert.Equal(t, 1, shrinker.current())
+
+	assert.True(t, malformedOutput(failure(constants.FailureOverLength, fmt.Errorf("too long"))))
+	assert.False(t, malformedOutput(failure(constants.FailureLLMError, fmt.Errorf("unavailable"))))
+
+	disabled := newPromptShrinker(types.ShrinkConfig{})
+	assert.Nil(t, disabled)
+	assert.Equal(t, constants.DefaultNumInspirations, disabled.inspirations())
+	assert.Equal(t, 1.0, disabled.tokenFactor())
+}
+
+func TestRunIterationShrinksPromptOnMalformedOutput(t *testing.T) {
+	var maxTokens []int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			MaxTokens int `json:"max_tokens"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		maxTokens = append(maxTokens, request.MaxTokens)
+		mu.Unlock()
+		fmt.Fprint(w, `{"model": "test", "choices": [{"message": {"role": "assistant", "content": "I would rather not."}}]}`)
+	}))
+	defer server.Close()
+
+	worker := newTestWorker(t, fixedEvaluator{score: 0.1}, "unused")
+	worker.config.LLM.APIBase = server.URL
+	worker.config.LLM.MaxTokens = 1000
+	ensemble, err := llm.NewEnsembleFromConfig(worker.config.LLM)
+	require.NoError(t, err)
+	worker.llmEnsemble = ensemble
+	worker.shrinker = newPromptShrinker(types.ShrinkConfig{Enabled: true, Window: 2})
+
+	for i := 1; i <= 3; i++ {
+		_, err := worker.RunIteration(context.Background(), i)
+		require.Error(t, err)
+	}
+	assert.Equal(t, 1, worker.shrinker.current())
+	assert.Equal(t, []int{1000, 1000, 750}, maxTokens)
+}
+
+func TestIterationSeeds(t *testing.T) {
+	worker := &IterationWorker{
+		config: types.Config{Controller: types.ControllerConfig{Seed: 42}},
+	}
+
+	assert.Equal(t, worker.iterationSeed(3), worker.iterationSeed(3))
+	assert.NotEqual(t, worker.iterationSeed(3), worker.iterationSeed(4))
+
+	llmSeed := deriveLLMSeed(worker.iterationSeed(3))
+	assert.Positive(t, llmSeed)
+	assert.Equal(t, llmSeed, deriveLLMSeed(worker.iterationSeed(3)))
+}
+
+// scriptedEvaluator returns queued scores per program and records which
+// evaluations had their artifacts cleared
+type scriptedEvaluator struct {
+	mu      sync.Mutex
+	scores  map[string][]float64
+	runs    int
+	cleared []string
+}
+
+func (e *scriptedEvaluator) Evaluate(ctx context.Context, code string) (*types.EvaluationResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	queue := e.scores[code]
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("unexpected evaluation of %q", code)
+	}
+	e.scores[code] = queue[1:]
+	e.runs++
+	return &types.EvaluationResult{
+		ID:        fmt.Sprintf("run%d", e.runs),
+		Score:     queue[0],
+		Success:   true,
+		Artifacts: map[string]string{"stdout": "ok"},
+	}, nil
+}
+
+func (e *scriptedEvaluator) ClearArtifacts(programID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cleared = append(e.cleared, programID)
+}
+
+// newTestWorker builds a worker whose LLM always answers with reply and
+// whose archive holds a single parent scoring 0.5
+func newTestWorker(t *testing.T, eval Evaluator, reply string) *IterationWorker {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := json.Marshal(reply)
+		fmt.Fprintf(w, `{"model": "test", "choices": [{"message": {"role": "assistant", "content": %s}}]}`, content)
+	}))
+	t.Cleanup(server.Close)
+
+	config := types.Config{
+		LLM: types.LLMConfig{
+			APIBase: server.URL,
+			APIKey:  "test-key",
+			Models:  []types.LLMModelConfig{{Name: "test", Weight: 1}},
+		},
+		Database: types.DatabaseConfig{
+			NumIslands:     1,
+			GridDimensions: []string{"complexity", "diversity"},
+			GridResolution: map[string]int{"complexity": 5, "diversity": 5},
+			GridBounds:     map[string][2]float64{"complexity": {0, 1}, "diversity": {0, 1}},
+		},
+		Evaluator: types.EvaluatorConfig{AcceptanceWindow: 2},
+	}
+
+	ensemble, err := llm.NewEnsembleFromConfig(config.LLM)
+	require.NoError(t, err)
+	db := database.New(config.Database, "")
+	require.NoError(t, db.AddProgram(&types.Program{ID: "parent", Code: parentCode, Score: 0.5, Features: []float64{0.5, 0.5}}, 0))
+
+	worker := NewIterationWorker(config, db, nil, ensemble)
+	worker.evaluator = eval
+	return worker
+}
+
+// newAcceptanceWorker builds a test worker whose LLM rewrites the parent
+// into childCode
+func newAcceptanceWorker(t *testing.T, eval *scriptedEvaluator) *IterationWorker {
+	return newTestWorker(t, eval, "```go\n"+childCode+"\n```")
+}
+
+const (
+	parentCode = "package main\n\nfunc main() {}"
+	childCode  = "package main\n\nfunc main() { println(1) }"
+)
+
+func TestRunIterationRollsBackNoisyImprovement(t *testing.T) {
+	eval := &scriptedEvaluator{scores: map[string][]float64{
+		// A lucky first run, then the child falls back below its parent
+		childCode:  {0.9, 0.3, 0.3},
+		parentCode: {0.55, 0.6},
+	}}
+	worker := newAcceptanceWorker(t, eval)
+
+	result, err := worker.RunIteration(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.True(t, result.RolledBack)
+	assert.Equal(t, []float64{0.3, 0.3}, result.Reevaluations)
+	assert.Equal(t, []float64{0.55, 0.6}, result.ParentReevaluations)
+	_, stored := worker.db.GetProgram(result.ChildProgram.ID)
+	assert.False(t, stored)
+
+	// Every evaluation's artifacts were released, re-runs included
+	assert.ElementsMatch(t, []string{"run1", "run2", "run3", "run4", "run5"}, eval.cleared)
+}
+
+func TestRunIterationAcceptsConfirmedImprovement(t *testing.T) {
+	eval := &scriptedEvaluator{scores: map[string][]float64{
+		childCode:  {0.9, 0.8, 0.85},
+		parentCode: {0.5, 0.45},
+	}}
+	worker := newAcceptanceWorker(t, eval)
+
+	result, err := worker.RunIteration(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.False(t, result.RolledBack)
+	stored, exists := worker.db.GetProgram(result.ChildProgram.ID)
+	require.True(t, exists)
+	assert.InDelta(t, 0.85, stored.Score, 1e-9)
+	assert.Equal(t, "ok", stored.Artifacts["stdout"])
+	assert.Equal(t, "parent", stored.ParentID)
+}
+
+func TestRunIterationRollsBackChildThatWinsOnlyOnePair(t *testing.T) {
+	eval := &scriptedEvaluator{scores: map[string][]float64{
+		// The child's mean beats the parent's, but it wins only the first pair
+		childCode:  {0.9, 0.95, 0.45},
+		parentCode: {0.4, 0.5},
+	}}
+	worker := newAcceptanceWorker(t, eval)
+
+	result, err := worker.RunIteration(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.True(t, result.RolledBack)
+	assert.Equal(t, []float64{0.95, 0.45}, result.Reevaluations)
+	_, stored := worker.db.GetProgram(result.ChildProgram.ID)
+	assert.False(t, stored)
+}
+
+// fixedEvaluator gives every program the same successful score
+type fixedEvaluator struct {
+	score float64
+}
+
+func (e fixedEvaluator) Evaluate(ctx context.Context, code string) (*types.EvaluationResult, error) {
+	return &types.EvaluationResult{Score: e.score, Success: true}, nil
+}
+
+func (e fixedEvaluator) ClearArtifacts(programID string) {}
+
+func TestRunIterationHidesProtectedInspirationCode(t *testing.T) {
+	region := "// PROTECTED-REGION-START\nconst secret = 42\n// PROTECTED-REGION-END\n"
+	reply := "```go\npackage main\n\n// <protected region 1: kept verbatim>\n\nfunc main() {}\n```"
+	worker := newTestWorker(t, fixedEvaluator{score: 0.1}, reply)
+	worker.config.Prompt.ProtectedRegions = types.ProtectedRegionsConfig{
+		Enabled:     true,
+		StartMarker: "// PROTECTED-REGION-START",
+		EndMarker:   "// PROTECTED-REGION-END",
+	}
+
+	// Whichever program is sampled as parent, the other one is an inspiration
+	protectedParent := "package main\n\n" + region + "\nfunc main() {}"
+	for _, program := range []*types.Program{
+		{ID: "first", Code: protectedParent, Score: 0.6, Features: []float64{0.1, 0.1}},
+		{ID: "second", Code: protectedParent + "\n// variant", Score: 0.7, Features: []float64{0.9, 0.9}},
+	} {
+		require.NoError(t, worker.db.AddProgram(program, 0))
+	}
+	parent, _ := worker.db.GetProgram("parent")
+	parent.Code = protectedParent + "\n// original"
+
+	result, err := worker.RunIteration(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.NotContains(t, result.Prompt.User, "const secret")
+	assert.NotContains(t, result.Prompt.System, "const secret")
+	stored, _ := worker.db.GetProgram("second")
+	assert.Contains(t, stored.Code, "const secret", "archived inspirations must keep their protected code")
+
+	// The child remembers which programs inspired it, never its own parent
+	child := result.ChildProgram
+	assert.NotEmpty(t, child.InspirationIDs)
+	assert.NotContains(t, child.InspirationIDs, child.ParentID)
+}
+
+func TestDiverseInspirationsDropsNearCopies(t *testing.T) {
+	config := types.Config{}
+	config.Prompt.InspirationMinDistance = 0.3
+	worker := &IterationWorker{config: config}
+
+	parent := &types.Program{ID: "parent", Code: "a\nb\nc\nd"}
+	candidates := []*types.Program{
+		parent,
+		{ID: "copy", Code: "a\nb\nc\nd"},
+		{ID: "tweak", Code: "a\nb\nc\nx"},
+		{ID: "far", Code: "a\nx\ny\nz"},
+		{ID: "other", Code: "w\nx\ny\nz"},
+		{ID: "half", Code: "a\nb\ny\nz"},
+		{ID: "extra", Code: "q\nr\ns\nt"},
+	}
+
+	inspirations := worker.diverseInspirations(parent, candidates)
+	assert.Equal(t, []string{"far", "other", "half"}, programIDs(inspirations))
+}
+
+func TestBuildPromptSummarizesInspirations(t *testing.T) {
+	worker := newTestWorker(t, fixedEvaluator{score: 0.1}, "Sorts once, then answers queries by binary search.")
+	worker.config.Prompt.SummarizeInspirations = true
+
+	inspiration := &types.Program{
+		ID:      "inspiration",
+		Code:    "package main\n\n" + strings.Repeat("// long body\n", 200),
+		Score:   0.8,
+		Metrics: map[string]float64{"latency": 12.5, "accuracy": 0.9},
+	}
+	worker.summarizeInspirations(context.Background(), []*types.Program{inspiration})
+
+	parent, _ := worker.db.GetProgram("parent")
+	prompt, err := worker.buildPrompt(parent, []*types.Program{inspiration}, 1)
+	require.NoError(t, err)
+	assert.Contains(t, prompt.User, "Approach: Sorts once, then answers queries by binary search.")
+	assert.Contains(t, prompt.User, "Metrics: accuracy=0.9, latency=12.5")
+	assert.NotContains(t, prompt.User, "// long body")
+
+	// Without a summary the inspiration falls back to truncated code
+	worker.summaries = newSummaryCache()
+	prompt, err = worker.buildPrompt(parent, []*types.Program{inspiration}, 1)
+	require.NoError(t, err)
+	assert.Contains(t, prompt.User, "... (truncated)")
+}
@@ -0,0 +1,29 @@
+package iteration
+
+import (
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// SetPhase applies a run phase's temperature and model to the iterations
+// that start afterwards. It is safe to call while iterations run.
+func (iw *IterationWorker) SetPhase(phase types.PhaseConfig) {
+	iw.phase.Store(&phase)
+}
+
+// currentPhase returns the phase iterations run in, or the zero phase
+func (iw *IterationWorker) currentPhase() types.PhaseConfig {
+	if phase := iw.phase.Load(); phase != nil {
+		return *phase
+	}
+	return types.PhaseConfig{}
+}
+
+// temperature returns the temperature an island's iterations sample at in
+// place of the model's: the island's own, else the phase's, else 0 to keep
+// the model's
+func (iw *IterationWorker) temperature(islandID int) float64 {
+	if override := iw.config.Database.IslandOverrides[islandID]; override.Temperature > 0 {
+		return override.Temperature
+	}
+	return iw.currentPhase().Temperature
+}
@@ -0,0 +1,192 @@
+package iteration
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Placeholders left in the prompt where protected code was removed
+const (
+	protectedImportsPlaceholder = "// <protected imports: kept verbatim>"
+	protectedRegionPlaceholder  = "// <protected region %d: kept verbatim>"
+)
+
+// protectedRegions holds code removed from a parent before prompting so it
+// can be re-attached to the child exactly as it was
+type protectedRegions struct {
+	header  string
+	imports string
+	regions []string
+}
+
+// stripProtected removes the configured protected regions from code and
+// returns the remaining code together with what was removed
+func stripProtected(code string, config types.ProtectedRegionsConfig) (string, *protectedRegions) {
+	protected := &protectedRegions{}
+	if !config.Enabled {
+		return code, protected
+	}
+
+	// Marker regions are replaced first so header and import detection
+	// never see their contents
+	if config.StartMarker != "" && config.EndMarker != "" {
+		code, protected.regions = extractMarkedRegions(code, config.StartMarker, config.EndMarker)
+	}
+
+	if config.Header {
+		if offset := packageClauseOffset(code); offset > 0 {
+			protected.header = code[:offset]
+			code = code[offset:]
+		}
+	}
+
+	if config.Imports {
+		if start, end, ok := importRange(code); ok {
+			protected.imports = code[start:end]
+			code = code[:start] + protectedImportsPlaceholder + code[end:]
+		}
+	}
+
+	return code, protected
+}
+
+// stripPrograms returns copies of programs with their protected regions
+// removed, leaving the archived programs untouched
+func stripPrograms(programs []*types.Program, config types.ProtectedRegionsConfig) []*types.Program {
+	if !config.Enabled {
+		return programs
+	}
+
+	stripped := make([]*types.Program, len(programs))
+	for i, program := range programs {
+		clone := *program
+		clone.Code, _ = stripProtected(program.Code, config)
+		stripped[i] = &clone
+	}
+	return stripped
+}
+
+// restore re-attaches protected code to a child produced from stripped code
+func (p *protectedRegions) restore(code string) (string, error) {
+	if p == nil {
+		return code, nil
+	}
+
+	if p.imports != "" {
+		switch {
+		case strings.Contains(code, protectedImportsPlaceholder):
+			code = strings.Replace(code, protectedImportsPlaceholder, p.imports, 1)
+		default:
+			// The model wrote its own imports; swap them for the protected ones
+			if start, end, ok := importRange(code); ok {
+				code = code[:start] + p.imports + code[end:]
+			} else if offset := packageClauseEnd(code); offset >= 0 {
+				code = code[:offset] + "\n\n" + p.imports + code[offset:]
+			} else {
+				return "", fmt.Errorf("protected imports could not be re-attached")
+			}
+		}
+	}
+
+	if p.header != "" {
+		// Drop whatever header the model produced in favour of the original
+		if offset := packageClauseOffset(code); offset > 0 {
+			code = code[offset:]
+		}
+		code = p.header + code
+	}
+
+	for i, region := range p.regions {
+		placeholder := fmt.Sprintf(protectedRegionPlaceholder, i+1)
+		if !strings.Contains(code, placeholder) {
+			return "", fmt.Errorf("protected region %d was removed by the rewrite", i+1)
+		}
+		code = strings.Replace(code, placeholder, region, 1)
+	}
+
+	return code, nil
+}
+
+// extractMarkedRegions replaces every start/end marker pair (inclusive)
+// with a numbered placeholder
+func extractMarkedRegions(code, startMarker, endMarker string) (string, []string) {
+	var regions []string
+	var builder strings.Builder
+
+	rest := code
+	for {
+		start := strings.Index(rest, startMarker)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], endMarker)
+		if end < 0 {
+			break
+		}
+		end += start + len(endMarker)
+
+		regions = append(regions, rest[start:end])
+		builder.WriteString(rest[:start])
+		builder.WriteString(fmt.Sprintf(protectedRegionPlaceholder, len(regions)))
+		rest = rest[end:]
+	}
+	builder.WriteString(rest)
+
+	return builder.String(), regions
+}
+
+// packageClauseOffset returns the byte offset of the package clause, or -1.
+// Everything before it is the file header: license comments, build
+// constraints and generated-code markers.
+func packageClauseOffset(code string) int {
+	offset := 0
+	for _, line := range strings.SplitAfter(code, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "package ") {
+			return offset
+		}
+		offset += len(line)
+	}
+	return -1
+}
+
+// packageClauseEnd returns the byte offset just past the package clause line, or -1
+func packageClauseEnd(code string) int {
+	offset := packageClauseOffset(code)
+	if offset < 0 {
+		return -1
+	}
+	if newline := strings.Index(code[offset:], "\n"); newline >= 0 {
+		return offset + newline
+	}
+	return len(code)
+}
+
+// importRange returns the byte range spanning all import declarations
+func importRange(code string) (int, int, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", code, parser.ImportsOnly)
+	if err != nil || len(file.Imports) == 0 {
+		return 0, 0, false
+	}
+
+	start, end := -1, -1
+	for _, decl := range file.Decls {
+		pos := fset.Position(decl.Pos()).Offset
+		stop := fset.Position(decl.End()).Offset
+		if start < 0 || pos < start {
+			start = pos
+		}
+		if stop > end {
+			end = stop
+		}
+	}
+	if start < 0 || end > len(code) {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
@@ -0,0 +1,116 @@
+package iteration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
+)
+
+// maxTrackedParents bounds how many parents the repetition tracker remembers
+const maxTrackedParents = 1024
+
+// Escalation levels applied when the LLM keeps returning the same code
+const (
+	escalateTemperature = iota + 1
+	escalateTemplate
+	escalateModel
+	maxEscalationLevel = escalateModel
+)
+
+// repetitionTracker remembers hashes of recent LLM outputs per parent.
+// A nil tracker records nothing.
+type repetitionTracker struct {
+	mu     sync.Mutex
+	window int
+	recent map[string][]string
+	order  []string
+}
+
+// newRepetitionTracker creates a tracker keeping window outputs per parent
+func newRepetitionTracker(window int) *repetitionTracker {
+	if window <= 0 {
+		window = constants.DefaultRepetitionWindow
+	}
+	return &repetitionTracker{
+		window: window,
+		recent: make(map[string][]string),
+	}
+}
+
+// observe records code generated for a parent and returns how many of the
+// parent's recent outputs, including this one, are identical to it
+func (t *repetitionTracker) observe(parentID, code string) int {
+	if t == nil {
+		return 0
+	}
+
+	sum := sha256.Sum256([]byte(strings.TrimSpace(code)))
+	hash := hex.EncodeToString(sum[:])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hashes, exists := t.recent[parentID]
+	if !exists {
+		t.order = append(t.order, parentID)
+		if len(t.order) > maxTrackedParents {
+			delete(t.recent, t.order[0])
+			t.order = t.order[1:]
+		}
+	}
+
+	hashes = append(hashes, hash)
+	if len(hashes) > t.window {
+		hashes = hashes[len(hashes)-t.window:]
+	}
+	t.recent[parentID] = hashes
+
+	count := 0
+	for _, previous := range hashes {
+		if previous == hash {
+			count++
+		}
+	}
+	return count
+}
+
+// escalate applies the given escalation level to the generation options and
+// returns a description of the intervention. member is the ensemble member
+// that produced the repeated output on the given island.
+func (iw *IterationWorker) escalate(level, islandID int, opts *llm.GenerateOptions, member string) string {
+	base := iw.config.LLM.Temperature
+	if temperature := iw.temperature(islandID); temperature > 0 {
+		base = temperature
+	}
+	if base <= 0 {
+		base = constants.DefaultTemperature
+	}
+	step := iw.config.Prompt.Repetition.TemperatureStep
+	if step <= 0 {
+		step = constants.DefaultEscalationTemperatureStep
+	}
+	opts.Temperature = math.Min(base+step*float64(level), constants.MaxEscalationTemperature)
+
+	switch level {
+	case escalateTemperature:
+		return fmt.Sprintf("raised temperature to %.2f", opts.Temperature)
+	case escalateTemplate:
+		if iw.config.Prompt.MutationPrompt == "" {
+			return fmt.Sprintf("raised temperature to %.2f (no mutation template configured)", opts.Temperature)
+		}
+		return fmt.Sprintf("switched to mutation template at temperature %.2f", opts.Temperature)
+	default:
+		// A forced model would override the exclusion
+		opts.Model = ""
+		if member != "" {
+			opts.ExcludeModels = append(opts.ExcludeModels, member)
+		}
+		return fmt.Sprintf("switched away from model %q at temperature %.2f", member, opts.Temperature)
+	}
+}
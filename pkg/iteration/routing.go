@@ -0,0 +1,130 @@
+package iteration
+
+import (
+	"math"
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// PoolSpend is what the iterations routed to one model pool have used
+type PoolSpend struct {
+	Iterations int              `json:"iterations"`
+	Tokens     types.TokenUsage `json:"tokens"`
+}
+
+// modelRouter picks the model pool of each iteration. Routine iterations go
+// to the cheap pool; an island that has stopped improving, or an iteration
+// refining the champion, goes to the strong pool. A nil router routes
+// nothing.
+type modelRouter struct {
+	mu      sync.Mutex
+	config  types.RoutingConfig
+	islands map[int]*islandProgress
+	spend   map[string]*PoolSpend
+}
+
+// islandProgress tracks how recently an island last improved
+type islandProgress struct {
+	iterations   int
+	best         float64
+	lastImproved int
+}
+
+// newModelRouter creates a router, or returns nil when routing is disabled
+func newModelRouter(config types.RoutingConfig) *modelRouter {
+	if !config.Enabled {
+		return nil
+	}
+	return &modelRouter{
+		config:  config,
+		islands: make(map[int]*islandProgress),
+		spend:   make(map[string]*PoolSpend),
+	}
+}
+
+// route returns the pool for the next iteration on an island and, when it
+// escalates to the strong pool, why
+func (r *modelRouter) route(islandID int, champion bool) (string, string) {
+	if r == nil {
+		return "", ""
+	}
+	if champion && r.config.RefineChampion {
+		return constants.ModelPoolStrong, constants.RouteReasonChampion
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n := r.config.StagnationIterations; n > 0 {
+		if progress := r.islands[islandID]; progress != nil && progress.iterations-progress.lastImproved >= n {
+			return constants.ModelPoolStrong, constants.RouteReasonStagnation
+		}
+	}
+	return constants.ModelPoolCheap, ""
+}
+
+// observe records the score of a child produced on an island. A child that
+// beats the island's best so far resets its stagnation count.
+func (r *modelRouter) observe(islandID int, score float64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	progress, exists := r.islands[islandID]
+	if !exists {
+		progress = &islandProgress{best: math.Inf(-1)}
+		r.islands[islandID] = progress
+	}
+	progress.iterations++
+	if score > progress.best {
+		progress.best = score
+		progress.lastImproved = progress.iterations
+	}
+}
+
+// record adds the tokens of an iteration to its pool's spend
+func (r *modelRouter) record(pool string, usage types.TokenUsage) {
+	if r == nil || pool == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spend, exists := r.spend[pool]
+	if !exists {
+		spend = &PoolSpend{}
+		r.spend[pool] = spend
+	}
+	spend.Iterations++
+	spend.Tokens.PromptTokens += usage.PromptTokens
+	spend.Tokens.CompletionTokens += usage.CompletionTokens
+	spend.Tokens.TotalTokens += usage.TotalTokens
+}
+
+// snapshot returns a copy of the spend per pool
+func (r *modelRouter) snapshot() map[string]PoolSpend {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spend := make(map[string]PoolSpend, len(r.spend))
+	for pool, s := range r.spend {
+		spend[pool] = *s
+	}
+	return spend
+}
+
+// RoutingSpend returns the iterations and tokens spent per model pool, or
+// nil when model routing is disabled
+func (iw *IterationWorker) RoutingSpend() map[string]PoolSpend {
+	return iw.router.snapshot()
+}
@@ -0,0 +1,118 @@
+package iteration
+
+import (
+	"math"
+	"sync"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// promptShrinker tracks how many recent iterations failed with over-length
+// or malformed output and sets how far the prompt is simplified. A nil
+// shrinker leaves the prompt alone.
+type promptShrinker struct {
+	mu       sync.Mutex
+	config   types.ShrinkConfig
+	outcomes []bool
+	level    int
+}
+
+// newPromptShrinker creates a shrinker, or returns nil when shrinkage is
+// disabled. Unset fields take their defaults.
+func newPromptShrinker(config types.ShrinkConfig) *promptShrinker {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Window <= 0 {
+		config.Window = constants.DefaultShrinkWindow
+	}
+	if config.FailureRate <= 0 {
+		config.FailureRate = constants.DefaultShrinkFailureRate
+	}
+	if config.MaxLevel <= 0 {
+		config.MaxLevel = constants.DefaultShrinkMaxLevel
+	}
+	if config.TokenFactor <= 0 {
+		config.TokenFactor = constants.DefaultShrinkTokenFactor
+	}
+	return &promptShrinker{config: config}
+}
+
+// malformedOutput reports whether an iteration error means the LLM's output
+// was too long or could not be used
+func malformedOutput(err error) bool {
+	switch FailureCategory(err) {
+	case constants.FailureOverLength, constants.FailureNoCodeBlock, constants.FailureDiffMismatch:
+		return true
+	}
+	return false
+}
+
+// observe records whether an iteration failed with malformed output. Once
+// the window is full it moves a level up when the failure rate reaches the
+// threshold, or down when it falls below half of it, and starts the window
+// over. It returns how the level moved, -1, 0 or 1, and the failure rate
+// of a completed window.
+func (s *promptShrinker) observe(malformed bool) (int, float64) {
+	if s == nil {
+		return 0, 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outcomes = append(s.outcomes, malformed)
+	if len(s.outcomes) < s.config.Window {
+		return 0, 0
+	}
+
+	failed := 0
+	for _, outcome := range s.outcomes {
+		if outcome {
+			failed++
+		}
+	}
+	rate := float64(failed) / float64(len(s.outcomes))
+	s.outcomes = s.outcomes[:0]
+
+	switch {
+	case rate >= s.config.FailureRate && s.level < s.config.MaxLevel:
+		s.level++
+		return 1, rate
+	case rate < s.config.FailureRate/2 && s.level > 0:
+		s.level--
+		return -1, rate
+	}
+	return 0, rate
+}
+
+// current returns the shrink level
+func (s *promptShrinker) current() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.level
+}
+
+// inspirations returns how many inspirations a prompt shows
+func (s *promptShrinker) inspirations() int {
+	return constants.DefaultNumInspirations >> s.current()
+}
+
+// excerptLength returns how much of each inspiration's code a prompt shows
+func (s *promptShrinker) excerptLength() int {
+	return constants.InspirationExcerptLength >> s.current()
+}
+
+// tokenFactor returns the factor to scale max tokens by, 1 when unshrunk
+func (s *promptShrinker) tokenFactor() float64 {
+	level := s.current()
+	if level == 0 {
+		return 1
+	}
+	return math.Pow(s.config.TokenFactor, float64(level))
+}
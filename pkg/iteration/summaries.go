@@ -0,0 +1,118 @@
+package iteration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// maxSummarizedPrograms bounds how many inspiration summaries are cached
+const maxSummarizedPrograms = 1024
+
+// maxSummaryCodeLength caps the code sent to the LLM for summarizing
+const maxSummaryCodeLength = 8000
+
+// summaryCache remembers LLM summaries of inspiration programs by ID, so a
+// program is summarized once however often it inspires. A nil cache holds
+// nothing.
+type summaryCache struct {
+	mu        sync.Mutex
+	summaries map[string]string
+	order     []string
+}
+
+// newSummaryCache creates an empty summary cache
+func newSummaryCache() *summaryCache {
+	return &summaryCache{summaries: make(map[string]string)}
+}
+
+// get returns the cached summary of a program, or ""
+func (c *summaryCache) get(programID string) string {
+	if c == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.summaries[programID]
+}
+
+// put caches a program's summary, evicting the oldest when full
+func (c *summaryCache) put(programID, summary string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.summaries[programID]; !exists {
+		c.order = append(c.order, programID)
+		if len(c.order) > maxSummarizedPrograms {
+			delete(c.summaries, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.summaries[programID] = summary
+}
+
+// summarizeInspirations makes sure every inspiration has a cached summary
+// when inspiration summaries are enabled. Programs that cannot be
+// summarized are shown as truncated code instead.
+func (iw *IterationWorker) summarizeInspirations(ctx context.Context, inspirations []*types.Program) {
+	if !iw.config.Prompt.SummarizeInspirations || iw.llmEnsemble == nil {
+		return
+	}
+
+	for _, program := range inspirations {
+		if iw.summaries.get(program.ID) != "" {
+			continue
+		}
+		response, err := iw.llmEnsemble.Generate(ctx, summaryPrompt(program))
+		if err != nil {
+			iw.logger.WithFields(logrus.Fields{
+				"program": program.ID,
+				"error":   err,
+			}).Warn("Failed to summarize inspiration, showing its code")
+			continue
+		}
+		if summary := strings.TrimSpace(response.Content); summary != "" {
+			iw.summaries.put(program.ID, summary)
+		}
+	}
+}
+
+// summaryPrompt asks for a one-paragraph description of a program's approach
+func summaryPrompt(program *types.Program) string {
+	code := program.Code
+	if len(code) > maxSummaryCodeLength {
+		code = code[:maxSummaryCodeLength] + "\n... (truncated)"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Summarize the approach of the following program in one short paragraph: ")
+	builder.WriteString("the algorithm, data structures and key techniques it relies on. Do not include code.\n\n")
+	builder.WriteString("```\n")
+	builder.WriteString(code)
+	builder.WriteString("\n```\n")
+	return builder.String()
+}
+
+// formatMetrics renders metrics as sorted key=value pairs
+func formatMetrics(metrics map[string]float64) string {
+	keys := make([]string, 0, len(metrics))
+	for key := range metrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%.4g", key, metrics[key])
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -4,26 +4,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand"
 	"regexp"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
 	"github.com/ishanwen-byte/openevolve-go/pkg/database"
 	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
 	"github.com/ishanwen-byte/openevolve-go/pkg/llm"
 )
 
+// Evaluator scores child programs; *evaluator.Evaluator implements it
+type Evaluator interface {
+	Evaluate(ctx context.Context, code string) (*types.EvaluationResult, error)
+	ClearArtifacts(programID string)
+}
+
 // IterationWorker handles single evolution iterations
 type IterationWorker struct {
 	config         types.Config
 	db             *database.ProgramDatabase
-	evaluator      *evaluator.Evaluator
+	evaluator      Evaluator
 	llmEnsemble    *llm.Ensemble
 	logger         *logrus.Logger
+	repetition     *repetitionTracker
+	summaries      *summaryCache
+	router         *modelRouter
+	shrinker       *promptShrinker
+	// Run phase whose overrides iterations apply; nil outside phases
+	phase          atomic.Pointer[types.PhaseConfig]
 }
 
 // IterationResult represents the result of a single iteration
@@ -37,6 +54,24 @@ type IterationResult struct {
 	Duration       time.Duration          `json:"duration"`
 	Artifacts      map[string]string      `json:"artifacts"`
 	Changes        string                 `json:"changes"`
+	Reevaluations  []float64              `json:"reevaluations,omitempty"`
+	ParentReevaluations []float64         `json:"parent_reevaluations,omitempty"`
+	RolledBack     bool                   `json:"rolled_back"`
+	Interventions  []string               `json:"interventions,omitempty"`
+	// Failure categorizes an unsuccessful evaluation of the child
+	Failure        string                 `json:"failure,omitempty"`
+	Seeds          IterationSeeds         `json:"seeds"`
+}
+
+// IterationSeeds records the random choices made in an iteration so that it
+// can be replayed in isolation
+type IterationSeeds struct {
+	Island  int    `json:"island"`
+	RNGSeed int64  `json:"rng_seed"`
+	LLMSeed int    `json:"llm_seed"`
+	Model   string `json:"model"`
+	// Pool is the model pool routing chose, empty when routing is disabled
+	Pool    string `json:"pool,omitempty"`
 }
 
 // PromptData contains the prompt information for an iteration
@@ -53,38 +88,149 @@ func NewIterationWorker(
 	evaluator *evaluator.Evaluator,
 	llmEnsemble *llm.Ensemble,
 ) *IterationWorker {
+	worker := newWorker(config, db, llmEnsemble)
+	// A nil *Evaluator stored in the interface would not compare equal to nil
+	if evaluator != nil {
+		worker.evaluator = evaluator
+	}
+	return worker
+}
+
+// NewGenerationWorker creates a worker without a database or an evaluator,
+// which can only run Generate
+func NewGenerationWorker(config types.Config, llmEnsemble *llm.Ensemble) *IterationWorker {
+	return newWorker(config, nil, llmEnsemble)
+}
+
+// newWorker creates a worker without an evaluator
+func newWorker(config types.Config, db *database.ProgramDatabase, llmEnsemble *llm.Ensemble) *IterationWorker {
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 
 	return &IterationWorker{
 		config:      config,
 		db:          db,
-		evaluator:   evaluator,
 		llmEnsemble: llmEnsemble,
 		logger:      logger,
+		repetition:  newRepetitionTracker(config.Prompt.Repetition.Window),
+		summaries:   newSummaryCache(),
+		router:      newModelRouter(config.LLM.Routing),
+		shrinker:    newPromptShrinker(config.Prompt.Shrink),
 	}
 }
 
 // RunIteration executes a single evolution iteration
 func (iw *IterationWorker) RunIteration(ctx context.Context, iteration int) (*IterationResult, error) {
+	return iw.RunIslandIteration(ctx, iw.db.GetCurrentIsland(), iteration)
+}
+
+// RunIslandIteration executes a single evolution iteration that samples its
+// parent from the given island. It is safe to call concurrently for
+// different islands.
+func (iw *IterationWorker) RunIslandIteration(ctx context.Context, islandID, iteration int) (*IterationResult, error) {
+	return iw.runIteration(ctx, iteration, IterationSeeds{
+		Island:  islandID,
+		RNGSeed: iw.iterationSeed(iteration),
+	})
+}
+
+// ReplayIteration re-runs an iteration with the seeds and model recorded in
+// an earlier IterationResult. Against the same database state it samples the
+// same parent and inspirations and sends the same LLM request.
+func (iw *IterationWorker) ReplayIteration(ctx context.Context, iteration int, seeds IterationSeeds) (*IterationResult, error) {
+	return iw.runIteration(ctx, iteration, seeds)
+}
+
+// runIteration executes a single evolution iteration with the given seeds
+// and adapts the prompt to how often the LLM's output is unusable
+func (iw *IterationWorker) runIteration(ctx context.Context, iteration int, seeds IterationSeeds) (*IterationResult, error) {
+	result, err := iw.iterate(ctx, iteration, seeds)
+
+	// LLM, sampling and cancellation errors say nothing about the prompt
+	if category := FailureCategory(err); err == nil || (category != constants.FailureOther && category != constants.FailureLLMError) {
+		move, rate := iw.shrinker.observe(malformedOutput(err))
+		entry := iw.logger.WithFields(logrus.Fields{
+			"iteration":    iteration,
+			"level":        iw.shrinker.current(),
+			"failure_rate": rate,
+			"inspirations": iw.shrinker.inspirations(),
+			"token_factor": iw.shrinker.tokenFactor(),
+		})
+		switch move {
+		case 1:
+			entry.Warn("Too many malformed LLM outputs, shrinking the prompt")
+		case -1:
+			entry.Info("Malformed LLM outputs subsided, restoring the prompt")
+		}
+	}
+	return result, err
+}
+
+// iterate runs the steps of a single evolution iteration
+func (iw *IterationWorker) iterate(ctx context.Context, iteration int, seeds IterationSeeds) (*IterationResult, error) {
 	iw.logger.WithField("iteration", iteration).Debug("Starting iteration")
 
 	startTime := time.Now()
+	if seeds.LLMSeed == 0 {
+		seeds.LLMSeed = deriveLLMSeed(seeds.RNGSeed)
+	}
 	result := &IterationResult{
 		Iteration: iteration,
 		Artifacts: make(map[string]string),
+		Seeds:     seeds,
 	}
+	rng := rand.New(rand.NewSource(seeds.RNGSeed))
 
 	// Sample parent program and inspirations
-	parentProgram, inspirations, err := iw.samplePrograms()
+	parentProgram, inspirations, err := iw.samplePrograms(seeds.Island, rng)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sample programs: %w", err)
 	}
 
 	result.ParentProgram = parentProgram
 
+	// Islands with models of their own skip routing; others are routed to
+	// the cheap or strong pool unless replaying a recorded choice
+	override := iw.config.Database.IslandOverrides[seeds.Island]
+	phase := iw.currentPhase()
+	if result.Seeds.Pool == "" && len(override.Models) == 0 && phase.Model == "" {
+		best := iw.db.GetGlobalBest()
+		pool, reason := iw.router.route(seeds.Island, best != nil && best.ID == parentProgram.ID)
+		result.Seeds.Pool = pool
+		if reason != "" {
+			iw.logger.WithFields(logrus.Fields{
+				"iteration": iteration,
+				"island":    seeds.Island,
+				"reason":    reason,
+			}).Info("Routing iteration to the strong model pool")
+		}
+	}
+	pool := iw.editMode()
+	if len(override.Models) > 0 {
+		pool = llm.IslandPool(seeds.Island)
+	} else if phase.Model != "" {
+		// The phase's model is a member of the ensemble itself
+		pool = ""
+	} else if result.Seeds.Pool != "" {
+		pool = result.Seeds.Pool
+	}
+	var usage types.TokenUsage
+	defer func() { iw.router.record(result.Seeds.Pool, usage) }()
+
+	// Stop early if the run was cancelled while sampling
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Hide protected regions from the model; they are re-attached to the child below
+	promptParent := *parentProgram
+	var protected *protectedRegions
+	promptParent.Code, protected = stripProtected(parentProgram.Code, iw.config.Prompt.ProtectedRegions)
+	inspirations = stripPrograms(inspirations, iw.config.Prompt.ProtectedRegions)
+	iw.summarizeInspirations(ctx, inspirations)
+
 	// Build prompt
-	prompt, err := iw.buildPrompt(parentProgram, inspirations, iteration)
+	prompt, err := iw.buildPrompt(&promptParent, inspirations, iteration)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build prompt: %w", err)
 	}
@@ -94,76 +240,171 @@ func (iw *IterationWorker) RunIteration(ctx context.Context, iteration int) (*It
 	// Generate code modification using LLM
 	// Combine system and user messages into a single prompt
 	fullPrompt := fmt.Sprintf("System: %s\n\nUser: %s", prompt.System, prompt.User)
-	llmResponse, err := iw.llmEnsemble.Generate(ctx, fullPrompt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate LLM response: %w", err)
-	}
-
-	result.LLMResponse = llmResponse.Content
 
-	// Parse the LLM response to extract new code
-	var childCode string
-	var changes string
-
-	if iw.config.Prompt.Stochasticity > 0.5 {
-		// Use diff-based evolution
-		childCode, changes, err = iw.applyDiffs(parentProgram.Code, llmResponse.Content)
-	} else {
-		// Use full rewrite
-		childCode = iw.parseFullRewrite(llmResponse.Content)
-		changes = "Full rewrite"
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	// Re-prompt when the model repeats itself or touches code outside the
+	// evolve blocks
+	var childCode, changes string
+	opts := llm.GenerateOptions{Seed: seeds.LLMSeed, Model: seeds.Model, Pool: pool, Temperature: iw.temperature(seeds.Island),
+		MaxTokensFactor: iw.shrinker.tokenFactor()}
+	if opts.Model == "" && len(override.Models) == 0 {
+		opts.Model = phase.Model
 	}
+	escalation, violations := 0, 0
+	for {
+		var response *types.LLMResponse
+		childCode, changes, response, err = iw.generateChild(ctx, fullPrompt, promptParent.Code, protected, opts)
+		if response != nil {
+			usage.PromptTokens += response.Usage.PromptTokens
+			usage.CompletionTokens += response.Usage.CompletionTokens
+			usage.TotalTokens += response.Usage.TotalTokens
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.LLMResponse = response.Content
+		result.Seeds.Model = response.Member
+
+		threshold := iw.config.Prompt.Repetition.Threshold
+		if threshold > 0 && escalation < maxEscalationLevel &&
+			iw.repetition.observe(parentProgram.ID, childCode) >= threshold {
+			escalation++
+			intervention := iw.escalate(escalation, seeds.Island, &opts, response.Member)
+			if escalation == escalateTemplate && iw.config.Prompt.MutationPrompt != "" {
+				prompt, err = iw.buildPromptWithInstructions(&promptParent, inspirations, iteration, iw.config.Prompt.MutationPrompt)
+				if err != nil {
+					return nil, fmt.Errorf("failed to build prompt: %w", err)
+				}
+				result.Prompt = prompt
+				fullPrompt = fmt.Sprintf("System: %s\n\nUser: %s", prompt.System, prompt.User)
+			}
+			result.Interventions = append(result.Interventions, intervention)
+
+			iw.logger.WithFields(logrus.Fields{
+				"iteration":    iteration,
+				"parent":       parentProgram.ID,
+				"level":        escalation,
+				"intervention": intervention,
+			}).Warn("LLM keeps returning the same code, escalating")
+			continue
+		}
 
-	if childCode == "" {
-		return nil, fmt.Errorf("no valid code generated")
+		violation := validateEvolveBlocks(parentProgram.Code, childCode)
+		if violation == nil {
+			break
+		}
+		if violations >= iw.config.Prompt.EvolveBlockRetries {
+			return nil, failure(constants.FailureEvolveBlock, fmt.Errorf("child rejected: %w", violation))
+		}
+		violations++
+
+		iw.logger.WithFields(logrus.Fields{
+			"iteration": iteration,
+			"attempt":   violations,
+			"violation": violation.Error(),
+		}).Warn("Child modified code outside evolve blocks, re-prompting")
+		fullPrompt += "\n\n" + violation.Feedback()
 	}
 
 	// Check code length
 	if len(childCode) > iw.getMaxCodeLength() {
-		return nil, fmt.Errorf("generated code exceeds maximum length: %d > %d",
-			len(childCode), iw.getMaxCodeLength())
+		return nil, failure(constants.FailureOverLength, fmt.Errorf("generated code exceeds maximum length: %d > %d",
+			len(childCode), iw.getMaxCodeLength()))
+	}
+
+	// Don't start an evaluation the caller no longer wants
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// Evaluate the child program
 	evalResult, err := iw.evaluator.Evaluate(ctx, childCode)
 	if err != nil {
-		return nil, fmt.Errorf("evaluation failed: %w", err)
+		return nil, failure(constants.FailureEvalError, fmt.Errorf("evaluation failed: %w", err))
 	}
 
 	result.EvaluationResult = evalResult
+	result.Failure = EvaluationFailure(evalResult)
+
+	// Keep artifacts with the program itself; the evaluator's copy is
+	// process-local and would be lost on migration or resume
+	if len(evalResult.Artifacts) > 0 {
+		result.Artifacts = iw.truncateArtifacts(evalResult.Artifacts)
+	}
+	if evalResult.ID != "" {
+		iw.evaluator.ClearArtifacts(evalResult.ID)
+	}
 
-	// Get artifacts if available
-	if childID := evalResult.ID; childID != "" {
-		artifacts, _ := iw.evaluator.GetArtifacts(childID)
-		if artifacts != nil {
-			result.Artifacts = artifacts
+	// A child that beats its parent must keep beating it on re-evaluation
+	// before it is allowed into the archive
+	childScore := evalResult.Score
+	if iw.config.Evaluator.AcceptanceWindow > 0 && evalResult.Success && childScore > parentProgram.Score {
+		childScores, parentScores, accepted, err := iw.confirmImprovement(ctx, childCode, childScore, parentProgram)
+		if err != nil {
+			return nil, failure(constants.FailureEvalError, fmt.Errorf("re-evaluation failed: %w", err))
+		}
+		result.Reevaluations = childScores
+		result.ParentReevaluations = parentScores
+		result.RolledBack = !accepted
+		if accepted {
+			childScore = meanScore(append([]float64{evalResult.Score}, childScores...))
 		}
 	}
 
+	// A failed or rolled back child did not improve the island
+	if result.RolledBack || !evalResult.Success {
+		iw.router.observe(seeds.Island, math.Inf(-1))
+	} else {
+		iw.router.observe(seeds.Island, childScore)
+	}
+
+	// Blend novelty into fitness so exploration pressure is configurable
+	features := iw.db.GridFeatures(childCode, evalResult.NamedFeatures, iw.extractFeatures(evalResult))
+	fitness := iw.calculateFitness(childScore, parentProgram)
+	weight := iw.noveltyWeight(iteration)
+	if weight > 0 {
+		novelty := iw.db.Novelty(parentProgram.IslandID, features)
+		if iw.config.Database.NoveltyArchive.Enabled {
+			novelty = iw.db.BehaviorNovelty(evalResult.Behavior, features)
+		}
+		fitness = blendNovelty(fitness, novelty, weight)
+	}
+
 	// Create child program
 	childProgram := &types.Program{
 		ID:         uuid.New().String(),
 		Code:       childCode,
-		Score:      evalResult.Score,
-		Fitness:    iw.calculateFitness(evalResult.Score, parentProgram),
-		Features:   iw.extractFeatures(evalResult),
+		Score:      childScore,
+		Failed:     !evalResult.Success,
+		Metrics:    evalResult.Metrics,
+		Behavior:   evalResult.Behavior,
+		Fitness:    fitness,
+		NoveltyBlended: weight > 0,
+		Features:   features,
 		Generation: parentProgram.Generation + 1,
 		IslandID:   parentProgram.IslandID,
+		ParentID:   parentProgram.ID,
+		InspirationIDs: programIDs(inspirations),
+		Changes:    changes,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 		Artifacts:  result.Artifacts,
+		Tags:       append([]string(nil), iw.config.Database.Tags...),
+		Metadata:   childMetadata(result.Seeds, phase.Name),
 	}
 
 	result.ChildProgram = childProgram
 	result.Changes = changes
 	result.Duration = time.Since(startTime)
 
-	// Add child program to database
-	if err := iw.db.AddProgram(childProgram, iteration); err != nil {
+	// Add child program to database unless its improvement was rolled back
+	if result.RolledBack {
+		iw.logger.WithFields(logrus.Fields{
+			"iteration":     iteration,
+			"score":         evalResult.Score,
+			"parent_score":  parentProgram.Score,
+			"reevaluations": result.Reevaluations,
+		}).Info("Child improvement did not hold up on re-evaluation, rolled back")
+	} else if err := iw.db.AddProgram(childProgram, iteration); err != nil {
 		iw.logger.WithError(err).Warn("Failed to add child program to database")
 	}
 
@@ -172,19 +413,184 @@ func (iw *IterationWorker) RunIteration(ctx context.Context, iteration int) (*It
 		"score":     evalResult.Score,
 		"duration":  result.Duration,
 		"success":   evalResult.Success,
+		"rng_seed":  result.Seeds.RNGSeed,
+		"llm_seed":  result.Seeds.LLMSeed,
+		"model":     result.Seeds.Model,
 	}).Info("Iteration completed")
 
 	return result, nil
 }
 
+// confirmImprovement re-evaluates a child that beat its parent, together
+// with the parent, across the acceptance window. The child is accepted only
+// when every re-run succeeds and beats the parent's paired re-run, so one
+// lucky sample cannot carry a child that loses the others. A failed parent
+// re-run is paired with the parent's archived score instead.
+func (iw *IterationWorker) confirmImprovement(ctx context.Context, childCode string, childScore float64, parent *types.Program) ([]float64, []float64, bool, error) {
+	window := iw.config.Evaluator.AcceptanceWindow
+	childScores := make([]float64, 0, window)
+	parentScores := make([]float64, 0, window)
+	accepted := true
+	for i := 0; i < window; i++ {
+		child, err := iw.reevaluate(ctx, childCode)
+		if err != nil {
+			return childScores, parentScores, false, err
+		}
+		childScores = append(childScores, child.Score)
+		if !child.Success {
+			return childScores, parentScores, false, nil
+		}
+
+		// A failed parent re-run says nothing about the child; pair it with the archived score
+		parentResult, err := iw.reevaluate(ctx, parent.Code)
+		if err != nil {
+			return childScores, parentScores, false, err
+		}
+		parentScore := parent.Score
+		if parentResult.Success {
+			parentScore = parentResult.Score
+			parentScores = append(parentScores, parentScore)
+		}
+		if child.Score <= parentScore {
+			accepted = false
+		}
+	}
+
+	return childScores, parentScores, accepted, nil
+}
+
+// reevaluate scores code again, discarding the artifacts the evaluator kept
+// for the run; only the first evaluation's artifacts belong to the child
+func (iw *IterationWorker) reevaluate(ctx context.Context, code string) (*types.EvaluationResult, error) {
+	result, err := iw.evaluator.Evaluate(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if result.ID != "" {
+		iw.evaluator.ClearArtifacts(result.ID)
+	}
+	return result, nil
+}
+
+// meanScore returns the arithmetic mean of scores
+func meanScore(scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, score := range scores {
+		total += score
+	}
+	return total / float64(len(scores))
+}
+
+// programIDs returns the IDs of programs in order
+func programIDs(programs []*types.Program) []string {
+	if len(programs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(programs))
+	for i, program := range programs {
+		ids[i] = program.ID
+	}
+	return ids
+}
+
+// childMetadata records how a child was generated: the model that wrote it,
+// the pool routing chose and the run phase, where known
+func childMetadata(seeds IterationSeeds, phase string) map[string]any {
+	metadata := make(map[string]any)
+	if seeds.Model != "" {
+		metadata[constants.MetadataModel] = seeds.Model
+	}
+	if seeds.Pool != "" {
+		metadata[constants.MetadataPool] = seeds.Pool
+	}
+	if phase != "" {
+		metadata[constants.MetadataPhase] = phase
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// generateChild asks the LLM for a modification of parentCode and extracts the
+// resulting child program, with protected regions re-attached
+func (iw *IterationWorker) generateChild(ctx context.Context, fullPrompt, parentCode string, protected *protectedRegions, opts llm.GenerateOptions) (string, string, *types.LLMResponse, error) {
+	llmResponse, err := iw.llmEnsemble.GenerateWithOptions(ctx, fullPrompt, opts)
+	if err != nil {
+		return "", "", nil, failure(constants.FailureLLMError, fmt.Errorf("failed to generate LLM response: %w", err))
+	}
+
+	// Parse the LLM response to extract new code
+	var childCode string
+	var changes string
+
+	if iw.editMode() == constants.EditModeDiff {
+		// Use diff-based evolution
+		childCode, changes, err = iw.applyDiffs(parentCode, llmResponse.Content)
+	} else {
+		// Use full rewrite
+		childCode = iw.parseFullRewrite(llmResponse.Content)
+		changes = "Full rewrite"
+	}
+
+	if err != nil {
+		// A response without code is its own category; anything else
+		// failed to apply to the parent
+		if FailureCategory(err) == constants.FailureOther {
+			err = failure(constants.FailureDiffMismatch, err)
+		}
+		return "", "", llmResponse, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	if summary := extractChangesSummary(llmResponse.Content); summary != "" {
+		changes = summary
+	}
+
+	if childCode == "" {
+		return "", "", llmResponse, failure(constants.FailureNoCodeBlock, fmt.Errorf("no valid code generated"))
+	}
+
+	childCode, err = protected.restore(childCode)
+	if err != nil {
+		return "", "", llmResponse, fmt.Errorf("failed to restore protected regions: %w", err)
+	}
+
+	return childCode, changes, llmResponse, nil
+}
+
+// iterationSeed derives the sampling seed for an iteration from the run
+// seed, or from the clock when the run is unseeded
+func (iw *IterationWorker) iterationSeed(iteration int) int64 {
+	if iw.config.Controller.Seed == 0 {
+		return time.Now().UnixNano()
+	}
+	return int64(iw.config.Controller.Seed)*1000003 + int64(iteration)
+}
+
+// deriveLLMSeed derives a positive LLM sampling seed from an iteration seed
+// without consuming the iteration's own random stream
+func deriveLLMSeed(rngSeed int64) int {
+	return int(rand.New(rand.NewSource(rngSeed)).Int31n(math.MaxInt32-1)) + 1
+}
+
+// editMode returns whether iterations ask the LLM for diffs or full rewrites
+func (iw *IterationWorker) editMode() string {
+	if iw.config.Prompt.Stochasticity > 0.5 {
+		return constants.EditModeDiff
+	}
+	return constants.EditModeRewrite
+}
+
 // samplePrograms samples a parent program and inspirations from the database
-func (iw *IterationWorker) samplePrograms() (*types.Program, []*types.Program, error) {
+func (iw *IterationWorker) samplePrograms(islandID int, rng *rand.Rand) (*types.Program, []*types.Program, error) {
 	// Sample parent program
-	parent, err := iw.db.SampleFromIsland(iw.db.GetCurrentIsland())
+	parent, err := iw.db.SampleFromIslandWith(islandID, rng)
 	if err != nil {
 		// Fallback to any island
 		for i := 0; i < iw.config.Database.NumIslands; i++ {
-			parent, err = iw.db.SampleFromIsland(i)
+			parent, err = iw.db.SampleFromIslandWith(i, rng)
 			if err == nil {
 				break
 			}
@@ -194,26 +600,69 @@ func (iw *IterationWorker) samplePrograms() (*types.Program, []*types.Program, e
 		}
 	}
 
-	// Sample inspiration programs
-	inspirations, err := iw.db.SampleMultiple(3) // Get 3 inspirations
+	// A fully shrunk prompt shows no inspirations
+	limit := iw.shrinker.inspirations()
+	if limit == 0 {
+		return parent, []*types.Program{}, nil
+	}
+
+	// Sample extra candidates so enough survive the diversity filter
+	candidates, err := iw.db.SampleMultipleWith(islandID, 3*limit, iw.config.Database.SampleStrategy, rng)
 	if err != nil {
 		iw.logger.WithError(err).Warn("Failed to sample inspirations, continuing without them")
-		inspirations = []*types.Program{}
+		candidates = []*types.Program{}
 	}
 
-	return parent, inspirations, nil
+	return parent, iw.diverseInspirations(parent, candidates), nil
+}
+
+// diverseInspirations keeps the first candidates that differ enough from
+// the parent; near-copies waste context and invite no-op rewrites
+func (iw *IterationWorker) diverseInspirations(parent *types.Program, candidates []*types.Program) []*types.Program {
+	limit := iw.shrinker.inspirations()
+	inspirations := make([]*types.Program, 0, limit)
+	for _, candidate := range candidates {
+		if len(inspirations) >= limit {
+			break
+		}
+		if candidate.ID == parent.ID {
+			continue
+		}
+		distance := database.LineDistance(parent.Code, candidate.Code)
+		if distance == 0 || distance < iw.config.Prompt.InspirationMinDistance {
+			continue
+		}
+		if iw.config.Prompt.InspirationDistinctCells && iw.db.SameCell(parent, candidate) {
+			continue
+		}
+		inspirations = append(inspirations, candidate)
+	}
+	return inspirations
 }
 
 // buildPrompt constructs the evolution prompt
 func (iw *IterationWorker) buildPrompt(parent *types.Program, inspirations []*types.Program, iteration int) (PromptData, error) {
+	instructions := iw.config.Prompt.EvolutionPrompt
+	if override := iw.config.Database.IslandOverrides[parent.IslandID]; override.EvolutionPrompt != "" {
+		instructions = override.EvolutionPrompt
+	}
+	return iw.buildPromptWithInstructions(parent, inspirations, iteration, instructions)
+}
+
+// buildPromptWithInstructions constructs the evolution prompt with the given
+// instruction template
+func (iw *IterationWorker) buildPromptWithInstructions(parent *types.Program, inspirations []*types.Program, iteration int, instructions string) (PromptData, error) {
 	// Build system message
 	systemMsg := iw.config.Prompt.SystemMessage
+	if override := iw.config.Database.IslandOverrides[parent.IslandID]; override.SystemMessage != "" {
+		systemMsg = override.SystemMessage
+	}
 	if systemMsg == "" {
 		systemMsg = "You are an expert programmer helping to evolve and improve code."
 	}
 
 	// Build user prompt with context
-	userPrompt := iw.buildUserPrompt(parent, inspirations, iteration)
+	userPrompt := iw.buildUserPrompt(parent, inspirations, iteration, instructions)
 
 	return PromptData{
 		System:  systemMsg,
@@ -223,7 +672,7 @@ func (iw *IterationWorker) buildPrompt(parent *types.Program, inspirations []*ty
 }
 
 // buildUserPrompt builds the user portion of the prompt
-func (iw *IterationWorker) buildUserPrompt(parent *types.Program, inspirations []*types.Program, iteration int) string {
+func (iw *IterationWorker) buildUserPrompt(parent *types.Program, inspirations []*types.Program, iteration int, instructions string) string {
 	promptBuilder := strings.Builder{}
 
 	promptBuilder.WriteString(fmt.Sprintf("Current code to improve (Generation %d, Score: %.3f):\n\n",
@@ -236,35 +685,95 @@ func (iw *IterationWorker) buildUserPrompt(parent *types.Program, inspirations [
 		promptBuilder.WriteString("Here are some high-scoring similar programs for inspiration:\n\n")
 		for i, insp := range inspirations {
 			promptBuilder.WriteString(fmt.Sprintf("Example %d (Score: %.3f):\n", i+1, insp.Score))
+			if summary := iw.summaries.get(insp.ID); summary != "" {
+				if len(insp.Metrics) > 0 {
+					promptBuilder.WriteString(fmt.Sprintf("Metrics: %s\n", formatMetrics(insp.Metrics)))
+				}
+				promptBuilder.WriteString(fmt.Sprintf("Approach: %s\n\n", summary))
+				continue
+			}
 			promptBuilder.WriteString("```\n")
 			// Truncate very long programs
 			code := insp.Code
-			if len(code) > 1000 {
-				code = code[:1000] + "\n... (truncated)"
+			if excerpt := iw.shrinker.excerptLength(); len(code) > excerpt {
+				code = code[:excerpt] + "\n... (truncated)"
 			}
 			promptBuilder.WriteString(code)
 			promptBuilder.WriteString("\n```\n\n")
 		}
 	}
 
+	// Feed back what the evaluator reported for the current program
+	if iw.config.Evaluator.CollectArtifacts && len(parent.Artifacts) > 0 {
+		promptBuilder.WriteString("Execution feedback from the current program:\n\n")
+		keys := make([]string, 0, len(parent.Artifacts))
+		for key := range parent.Artifacts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			// Compile errors read better as a list than as their JSON
+			if key == evaluator.CompileErrorsArtifact {
+				if compileErrors := evaluator.CompileErrors(parent.Artifacts); len(compileErrors) > 0 {
+					promptBuilder.WriteString("Compile errors:\n")
+					for _, compileError := range compileErrors {
+						promptBuilder.WriteString(fmt.Sprintf("- %s line %d: %s\n", compileError.File, compileError.Line, compileError.Message))
+					}
+					promptBuilder.WriteString("\n")
+					continue
+				}
+			}
+			promptBuilder.WriteString(fmt.Sprintf("%s:\n```\n%s\n```\n\n", key, iw.truncateArtifact(parent.Artifacts[key])))
+		}
+	}
+
 	// Add evolution instructions
-	if iw.config.Prompt.EvolutionPrompt != "" {
+	if instructions != "" {
 		promptBuilder.WriteString("Instructions:\n")
-		promptBuilder.WriteString(iw.config.Prompt.EvolutionPrompt)
+		promptBuilder.WriteString(instructions)
 	} else {
 		promptBuilder.WriteString("Please improve this code to achieve better performance. ")
 		promptBuilder.WriteString("Focus on algorithmic improvements, bug fixes, and optimizations. ")
 	}
 
+	if changes := iw.config.Prompt.ChangesInstruction; changes != "" {
+		promptBuilder.WriteString("\n\n")
+		promptBuilder.WriteString(changes)
+	}
+
 	return promptBuilder.String()
 }
 
+// truncateArtifacts copies artifacts, capping each value at the configured size
+func (iw *IterationWorker) truncateArtifacts(artifacts map[string]string) map[string]string {
+	truncated := make(map[string]string, len(artifacts))
+	for key, value := range artifacts {
+		// Cut short, the compile errors would no longer decode; the
+		// toolchain reports only a handful anyway
+		if key == evaluator.CompileErrorsArtifact {
+			truncated[key] = value
+			continue
+		}
+		truncated[key] = iw.truncateArtifact(value)
+	}
+	return truncated
+}
+
+// truncateArtifact caps a single artifact value at the configured size
+func (iw *IterationWorker) truncateArtifact(value string) string {
+	maxSize := iw.config.Evaluator.ArtifactMaxSize
+	if maxSize > 0 && len(value) > maxSize {
+		return value[:maxSize] + "\n... (truncated)"
+	}
+	return value
+}
+
 // applyDiffs applies diff-based modifications to the code
 func (iw *IterationWorker) applyDiffs(parentCode, llmResponse string) (string, string, error) {
 	// Simple diff parser - looks for code blocks with specific markers
 	codeBlocks := iw.extractCodeBlocks(llmResponse)
 	if len(codeBlocks) == 0 {
-		return "", "", fmt.Errorf("no code blocks found in LLM response")
+		return "", "", failure(constants.FailureNoCodeBlock, fmt.Errorf("no code blocks found in LLM response"))
 	}
 
 	// For simplicity, use the first code block as the new code
@@ -293,11 +802,11 @@ func (iw *IterationWorker) parseFullRewrite(llmResponse string) string {
 	return largestBlock
 }
 
-// extractCodeBlocks extracts Go code blocks from text
+// extractCodeBlocks extracts code blocks from text
 func (iw *IterationWorker) extractCodeBlocks(text string) []string {
-	// Pattern to match ```go ... ``` or just ``` ... ```
+	// Pattern to match ```go ... ```, ```sh ... ``` or just ``` ... ```
 	// Use a simpler approach since Go doesn't support negative lookahead
-	pattern := regexp.MustCompile("```(?:go)?\n?([^`]*)```")
+	pattern := regexp.MustCompile("```(?:[\\w+-]*\n)?([^`]*)```")
 	matches := pattern.FindAllStringSubmatch(text, -1)
 
 	blocks := make([]string, 0)
@@ -327,8 +836,40 @@ func (iw *IterationWorker) calculateFitness(score float64, parent *types.Program
 	return fitness
 }
 
+// noveltyWeight returns the novelty weight for an iteration, following the
+// configured decay schedule
+func (iw *IterationWorker) noveltyWeight(iteration int) float64 {
+	weight := math.Max(0, math.Min(1, iw.config.Database.NoveltyWeight))
+	horizon := float64(iw.config.Database.NoveltyDecayIterations)
+	if weight == 0 || horizon <= 0 {
+		return weight
+	}
+
+	switch iw.config.Database.NoveltyDecay {
+	case constants.NoveltyDecayLinear:
+		// Reaches zero after the configured number of iterations
+		weight *= math.Max(0, 1-float64(iteration)/horizon)
+	case constants.NoveltyDecayExponential:
+		// Halves every configured number of iterations
+		weight *= math.Pow(0.5, float64(iteration)/horizon)
+	}
+
+	return weight
+}
+
+// blendNovelty mixes a novelty score into fitness: (1-w)*fitness + w*novelty
+func blendNovelty(fitness, novelty, weight float64) float64 {
+	return (1-weight)*fitness + weight*novelty
+}
+
 // extractFeatures extracts features from evaluation result
 func (iw *IterationWorker) extractFeatures(result *types.EvaluationResult) []float64 {
+	return ExtractFeatures(result)
+}
+
+// ExtractFeatures maps an evaluation result to the feature vector used to
+// place a program in the MAP-Elites grid
+func ExtractFeatures(result *types.EvaluationResult) []float64 {
 	// Simple feature extraction - can be enhanced
 	features := make([]float64, 2) // complexity, diversity
 
@@ -360,6 +901,11 @@ func (ir *IterationResult) GetIterationStats() map[string]interface{} {
 		"generation":         ir.ChildProgram.Generation,
 		"changes_type":       ir.Changes,
 		"evaluation_success": ir.EvaluationResult.Success,
+		"rolled_back":        ir.RolledBack,
+	}
+
+	if len(ir.Interventions) > 0 {
+		stats["interventions"] = ir.Interventions
 	}
 
 	if len(ir.Artifacts) > 0 {
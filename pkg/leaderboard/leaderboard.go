@@ -0,0 +1,148 @@
+// Package leaderboard keeps the best result of every run against a task in
+// one file, so runs with different prompts or configurations can be
+// compared on the same benchmark.
+package leaderboard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/atomicfile"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Entry is one run's result on the leaderboard
+type Entry struct {
+	// RunID identifies the run, by its output directory; a resumed run
+	// replaces its earlier entry
+	RunID      string    `json:"run_id"`
+	Task       string    `json:"task"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Score of the run's best program, in the evaluator's units
+	Score       float64 `json:"score"`
+	Minimize    bool    `json:"minimize,omitempty"`
+	ProgramID   string  `json:"program_id"`
+	ProgramHash string  `json:"program_hash"`
+	Iterations  int     `json:"iterations"`
+	ConfigHash  string  `json:"config_hash"`
+	Config      Summary `json:"config"`
+}
+
+// Summary is the part of a configuration runs are usually compared by
+type Summary struct {
+	Models          []string `json:"models"`
+	Temperature     float64  `json:"temperature"`
+	Islands         int      `json:"islands"`
+	MaxIterations   int      `json:"max_iterations"`
+	ParentSelection string   `json:"parent_selection"`
+	// PromptHash identifies the system message and prompt templates
+	PromptHash string `json:"prompt_hash"`
+}
+
+// Summarize returns the summary of config shown on the leaderboard
+func Summarize(config types.Config) Summary {
+	summary := Summary{
+		Temperature:     config.LLM.Temperature,
+		Islands:         config.Database.NumIslands,
+		MaxIterations:   config.Controller.MaxIterations,
+		ParentSelection: config.Database.ParentSelection,
+	}
+	for _, model := range config.LLM.Models {
+		summary.Models = append(summary.Models, model.Name)
+	}
+	if data, err := json.Marshal(config.Prompt); err == nil {
+		sum := sha256.Sum256(data)
+		summary.PromptHash = hex.EncodeToString(sum[:])
+	}
+	return summary
+}
+
+// File returns the leaderboard file for task in dir
+func File(dir, task string) string {
+	return filepath.Join(dir, task+".json")
+}
+
+// Load reads a leaderboard, best run first. A leaderboard that does not
+// exist yet has no entries.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse leaderboard %s: %w", path, err)
+	}
+	rank(entries)
+	return entries, nil
+}
+
+// recordMu serializes records within a process; the lock file next to
+// each leaderboard serializes them across processes
+var recordMu sync.Mutex
+
+// Record adds entry to the leaderboard at path, replacing the entry of
+// the same run, and rewrites it atomically. Concurrent records, from this
+// process or others, are applied one after another, so none is lost.
+func Record(path string, entry Entry) error {
+	recordMu.Lock()
+	defer recordMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create leaderboard directory: %w", err)
+	}
+	lock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, existing := range entries {
+		if existing.RunID != entry.RunID {
+			kept = append(kept, existing)
+		}
+	}
+	entries = append(kept, entry)
+	rank(entries)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal leaderboard: %w", err)
+	}
+	return atomicfile.Write(path, data)
+}
+
+// rank orders entries best first, earlier runs winning ties
+func rank(entries []Entry) {
+	sort.SliceStable(entries, func(a, b int) bool {
+		ea, eb := entries[a], entries[b]
+		if ea.rankScore() != eb.rankScore() {
+			return ea.rankScore() > eb.rankScore()
+		}
+		return ea.FinishedAt.Before(eb.FinishedAt)
+	})
+}
+
+// rankScore returns the entry's score with higher always better
+func (e Entry) rankScore() float64 {
+	if e.Minimize {
+		return -e.Score
+	}
+	return e.Score
+}
@@ -0,0 +1,99 @@
+package leaderboard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+func TestRecordRanksRuns(t *testing.T) {
+	path := File(t.TempDir(), "task")
+
+	entries, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	start := time.Now()
+	require.NoError(t, Record(path, Entry{RunID: "a", Score: 0.5, FinishedAt: start}))
+	require.NoError(t, Record(path, Entry{RunID: "b", Score: 0.9, FinishedAt: start.Add(time.Second)}))
+	require.NoError(t, Record(path, Entry{RunID: "c", Score: 0.5, FinishedAt: start.Add(2 * time.Second)}))
+
+	entries, err = Load(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{"b", "a", "c"}, []string{entries[0].RunID, entries[1].RunID, entries[2].RunID})
+
+	// A resumed run replaces its entry
+	require.NoError(t, Record(path, Entry{RunID: "a", Score: 1, FinishedAt: start.Add(3 * time.Second)}))
+	entries, err = Load(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "a", entries[0].RunID)
+	assert.Equal(t, 1.0, entries[0].Score)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestRecordRanksMinimizedScores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "task.json")
+	require.NoError(t, Record(path, Entry{RunID: "slow", Score: 12, Minimize: true}))
+	require.NoError(t, Record(path, Entry{RunID: "fast", Score: 3, Minimize: true}))
+
+	entries, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "fast", entries[0].RunID)
+}
+
+func TestLoadRejectsCorruptLeaderboard(t *testing.T) {
+	path := File(t.TempDir(), "task")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err := Load(path)
+	assert.Error(t, err)
+	assert.Error(t, Record(path, Entry{RunID: "a"}))
+}
+
+func TestSummarize(t *testing.T) {
+	config := types.Config{}
+	config.LLM.Models = []types.LLMModelConfig{{Name: "small"}, {Name: "large"}}
+	config.LLM.Temperature = 0.7
+	config.Database.NumIslands = 3
+	config.Prompt.SystemMessage = "Be terse."
+
+	summary := Summarize(config)
+	assert.Equal(t, []string{"small", "large"}, summary.Models)
+	assert.Equal(t, 0.7, summary.Temperature)
+	assert.Equal(t, 3, summary.Islands)
+	assert.NotEmpty(t, summary.PromptHash)
+
+	config.Prompt.SystemMessage = "Be thorough."
+	assert.NotEqual(t, summary.PromptHash, Summarize(config).PromptHash)
+}
+
+func TestRecordKeepsConcurrentRuns(t *testing.T) {
+	path := File(t.TempDir(), "task")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, Record(path, Entry{RunID: fmt.Sprintf("run-%d", i), Score: float64(i)}))
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, entries, 20)
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package leaderboard
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockFile opens the lock file at path without locking it on platforms
+// without flock; the package mutex still serializes records within a
+// process
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open leaderboard lock: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package leaderboard
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on path, waiting for other processes
+// that hold it. The lock is released when the returned file is closed,
+// or when the process holding it dies.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open leaderboard lock: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock leaderboard: %w", err)
+	}
+	return file, nil
+}
@@ -3,12 +3,16 @@ package llm
 import (
 	"context"
 	"fmt"
-	"log"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
 )
 
 // Client interface defines the common interface for LLM clients
@@ -17,13 +21,105 @@ type Client interface {
 	GenerateWithSystemMessage(ctx context.Context, systemMessage string, messages []types.LLMMessage) (*types.LLMResponse, error)
 }
 
+// GenerateOptions overrides sampling settings for a single call
+type GenerateOptions struct {
+	// Temperature replaces the model's temperature when positive
+	Temperature float64
+	// Seed replaces the model's random seed when positive
+	Seed int
+	// MaxTokensFactor scales the model's max tokens when between 0 and 1
+	MaxTokensFactor float64
+	// Pool routes the call to the named model pool, falling back to the
+	// ensemble's own models when no such pool was added
+	Pool string
+	// Model forces the named ensemble member, e.g. to replay an iteration
+	Model string
+	// ExcludeModels names ensemble members that must not be selected
+	ExcludeModels []string
+}
+
+// optionsClient is implemented by clients that accept per-call overrides
+type optionsClient interface {
+	GenerateWithOptions(ctx context.Context, prompt string, opts GenerateOptions) (*types.LLMResponse, error)
+}
+
 // Ensemble implements an ensemble of LLM clients with weighted selection
 type Ensemble struct {
 	clients   []Client
+	names     []string
 	weights   []float64
 	totalWeight float64
 	rand      *rand.Rand
 	mu        sync.RWMutex
+
+	// Named sub-ensembles, e.g. cheap models for diffs and strong ones for rewrites
+	pools     map[string]*Ensemble
+
+	// Tokens used by every call, shared with the pools
+	usage *tokenCounter
+
+	logger *logrus.Logger
+}
+
+// tokenCounter accumulates token usage across concurrent calls
+type tokenCounter struct {
+	prompt     atomic.Int64
+	completion atomic.Int64
+	total      atomic.Int64
+}
+
+// record adds the usage of a response
+func (t *tokenCounter) record(response *types.LLMResponse) {
+	if response == nil {
+		return
+	}
+	t.prompt.Add(int64(response.Usage.PromptTokens))
+	t.completion.Add(int64(response.Usage.CompletionTokens))
+	t.total.Add(int64(response.Usage.TotalTokens))
+}
+
+// Usage returns the tokens used by every call made through the ensemble
+// and its pools so far
+func (e *Ensemble) Usage() types.TokenUsage {
+	return types.TokenUsage{
+		PromptTokens:     int(e.usage.prompt.Load()),
+		CompletionTokens: int(e.usage.completion.Load()),
+		TotalTokens:      int(e.usage.total.Load()),
+	}
+}
+
+// Throttled returns the time every client of the ensemble and its pools
+// has spent waiting out rate limits so far
+func (e *Ensemble) Throttled() time.Duration {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var total time.Duration
+	for _, client := range e.clients {
+		if throttled, ok := client.(interface{ Throttled() time.Duration }); ok {
+			total += throttled.Throttled()
+		}
+	}
+	for _, pool := range e.pools {
+		total += pool.Throttled()
+	}
+	return total
+}
+
+// Close releases the connections held by every client of the ensemble and
+// its pools
+func (e *Ensemble) Close() {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, client := range e.clients {
+		if closer, ok := client.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+	for _, pool := range e.pools {
+		pool.Close()
+	}
 }
 
 // NewEnsemble creates a new LLM ensemble from the given configuration
@@ -34,7 +130,10 @@ func NewEnsemble(configs []types.LLMModelConfig) (*Ensemble, error) {
 
 	ensemble := &Ensemble{
 		clients: make([]Client, 0, len(configs)),
+		names:   make([]string, 0, len(configs)),
 		weights: make([]float64, len(configs)),
+		usage:   &tokenCounter{},
+		logger:  logrus.New(),
 	}
 
 	// Initialize clients and normalize weights
@@ -47,6 +146,7 @@ func NewEnsemble(configs []types.LLMModelConfig) (*Ensemble, error) {
 		}
 
 		ensemble.clients = append(ensemble.clients, client)
+		ensemble.names = append(ensemble.names, cfg.Name)
 		ensemble.weights[i] = cfg.Weight
 		totalWeight += cfg.Weight
 	}
@@ -74,14 +174,91 @@ func NewEnsemble(configs []types.LLMModelConfig) (*Ensemble, error) {
 	ensemble.rand = rand.New(rand.NewSource(seed))
 
 	// Log ensemble configuration
-	log.Printf("Initialized LLM ensemble with %d models:", len(ensemble.clients))
+	ensemble.logger.Infof("Initialized LLM ensemble with %d models", len(ensemble.clients))
 	for i, cfg := range configs {
-		log.Printf("  - %s (weight: %.2f)", cfg.Name, ensemble.weights[i])
+		ensemble.logger.WithFields(logrus.Fields{
+			"model":  cfg.Name,
+			"weight": ensemble.weights[i],
+		}).Info("Ensemble model")
 	}
 
 	return ensemble, nil
 }
 
+// NewEnsembleFromConfig creates the main ensemble from config.Models and
+// registers the diff, rewrite, cheap and strong pools. Settings left empty on a model are
+// taken from the top-level LLM configuration.
+func NewEnsembleFromConfig(config types.LLMConfig) (*Ensemble, error) {
+	ensemble, err := NewEnsemble(modelsWithDefaults(config.Models, config))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensemble.AddPool(constants.EditModeDiff, modelsWithDefaults(config.DiffModels, config)); err != nil {
+		return nil, err
+	}
+	if err := ensemble.AddPool(constants.EditModeRewrite, modelsWithDefaults(config.RewriteModels, config)); err != nil {
+		return nil, err
+	}
+	if err := ensemble.AddPool(constants.ModelPoolCheap, modelsWithDefaults(config.CheapModels, config)); err != nil {
+		return nil, err
+	}
+	if err := ensemble.AddPool(constants.ModelPoolStrong, modelsWithDefaults(config.StrongModels, config)); err != nil {
+		return nil, err
+	}
+
+	return ensemble, nil
+}
+
+// NewEvaluatorEnsemble creates the ensemble of the evaluator models, which
+// judge programs in place of the models that evolve them. Settings left
+// empty on a model are taken from config. It returns nil when there are no
+// evaluator models.
+func NewEvaluatorEnsemble(config types.LLMConfig) (*Ensemble, error) {
+	if len(config.EvaluatorModels) == 0 {
+		return nil, nil
+	}
+	return NewEnsemble(modelsWithDefaults(config.EvaluatorModels, config))
+}
+
+// IslandPool names the model pool of an island with its own models
+func IslandPool(islandID int) string {
+	return fmt.Sprintf("island-%d", islandID)
+}
+
+// AddIslandPools registers a pool for every island override with models of
+// its own. Settings left empty on a model are taken from config.
+func (e *Ensemble) AddIslandPools(overrides map[int]types.IslandOverride, config types.LLMConfig) error {
+	for islandID, override := range overrides {
+		if err := e.AddPool(IslandPool(islandID), modelsWithDefaults(override.Models, config)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// modelsWithDefaults fills unset model settings from the LLM configuration
+func modelsWithDefaults(models []types.LLMModelConfig, config types.LLMConfig) []types.LLMModelConfig {
+	filled := make([]types.LLMModelConfig, len(models))
+	for i, model := range models {
+		model.APIBase = getOrDefault(model.APIBase, config.APIBase)
+		model.APIKey = getOrDefault(model.APIKey, config.APIKey)
+		model.SystemMessage = getOrDefault(model.SystemMessage, config.SystemMessage)
+		model.Temperature = getOrDefaultFloat64(model.Temperature, config.Temperature)
+		model.TopP = getOrDefaultFloat64(model.TopP, config.TopP)
+		model.MaxTokens = getOrDefaultInt(model.MaxTokens, config.MaxTokens)
+		model.Timeout = getOrDefaultInt(model.Timeout, config.Timeout)
+		model.Retries = getOrDefaultInt(model.Retries, config.Retries)
+		model.RetryDelay = getOrDefaultInt(model.RetryDelay, config.RetryDelay)
+		model.RandomSeed = getOrDefaultInt(model.RandomSeed, config.RandomSeed)
+		if model.ReasoningEffort == nil {
+			model.ReasoningEffort = config.ReasoningEffort
+		}
+		filled[i] = model
+	}
+	return filled
+}
+
 // Generate generates text using a randomly selected model based on weights
 func (e *Ensemble) Generate(ctx context.Context, prompt string) (*types.LLMResponse, error) {
 	client, err := e.selectClient()
@@ -93,12 +270,45 @@ func (e *Ensemble) Generate(ctx context.Context, prompt string) (*types.LLMRespo
 	if err != nil {
 		return nil, fmt.Errorf("generation failed: %w", err)
 	}
+	e.usage.record(response)
 
 	// Add ensemble metadata
 	response.Model = fmt.Sprintf("ensemble[%s]", response.Model)
 	return response, nil
 }
 
+// GenerateWithOptions generates text using a weighted model selection that
+// honours the given overrides. Clients without override support fall back
+// to their defaults.
+func (e *Ensemble) GenerateWithOptions(ctx context.Context, prompt string, opts GenerateOptions) (*types.LLMResponse, error) {
+	if pool := e.pool(opts.Pool); pool != nil {
+		opts.Pool = ""
+		return pool.GenerateWithOptions(ctx, prompt, opts)
+	}
+
+	index, err := e.selectMember(opts.Model, opts.ExcludeModels)
+	if err != nil {
+		return nil, err
+	}
+
+	client := e.clients[index]
+	var response *types.LLMResponse
+	if withOptions, ok := client.(optionsClient); ok {
+		response, err = withOptions.GenerateWithOptions(ctx, prompt, opts)
+	} else {
+		response, err = client.Generate(ctx, prompt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generation failed: %w", err)
+	}
+	e.usage.record(response)
+
+	// Add ensemble metadata
+	response.Model = fmt.Sprintf("ensemble[%s]", response.Model)
+	response.Member = e.names[index]
+	return response, nil
+}
+
 // GenerateWithSystemMessage generates text using a system message and conversational context
 func (e *Ensemble) GenerateWithSystemMessage(ctx context.Context, systemMessage string, messages []types.LLMMessage) (*types.LLMResponse, error) {
 	client, err := e.selectClient()
@@ -110,6 +320,7 @@ func (e *Ensemble) GenerateWithSystemMessage(ctx context.Context, systemMessage
 	if err != nil {
 		return nil, fmt.Errorf("generation with context failed: %w", err)
 	}
+	e.usage.record(response)
 
 	// Add ensemble metadata
 	response.Model = fmt.Sprintf("ensemble[%s]", response.Model)
@@ -186,6 +397,9 @@ func (e *Ensemble) GenerateAll(ctx context.Context, systemMessage string, messag
 		go func(index int, c Client) {
 			defer wg.Done()
 			response, err := c.GenerateWithSystemMessage(ctx, systemMessage, messages)
+			if err == nil {
+				e.usage.record(response)
+			}
 			responses[index] = response
 			errors[index] = err
 		}(i, client)
@@ -202,29 +416,136 @@ func (e *Ensemble) GenerateAll(ctx context.Context, systemMessage string, messag
 	return responses, nil
 }
 
-// selectClient selects a client based on weights
-func (e *Ensemble) selectClient() (Client, error) {
+// AddPool registers a named pool of models that GenerateWithOptions uses
+// when asked for that pool. An empty configuration list is ignored.
+func (e *Ensemble) AddPool(name string, configs []types.LLMModelConfig) error {
+	if len(configs) == 0 {
+		return nil
+	}
+
+	pool, err := NewEnsemble(configs)
+	if err != nil {
+		return fmt.Errorf("failed to create %s model pool: %w", name, err)
+	}
+	pool.usage = e.usage
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.pools == nil {
+		e.pools = make(map[string]*Ensemble)
+	}
+	e.pools[name] = pool
+	return nil
+}
+
+// pool returns the named pool, or nil when it does not exist
+func (e *Ensemble) pool(name string) *Ensemble {
+	if name == "" {
+		return nil
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	return e.pools[name]
+}
+
+// SetAuditLogger attaches an audit logger to every client that supports auditing
+func (e *Ensemble) SetAuditLogger(auditor *audit.Logger) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, client := range e.clients {
+		if auditable, ok := client.(interface{ SetAuditLogger(*audit.Logger) }); ok {
+			auditable.SetAuditLogger(auditor)
+		}
+	}
+	for _, pool := range e.pools {
+		pool.SetAuditLogger(auditor)
+	}
+}
+
+// selectClient selects a client based on weights
+func (e *Ensemble) selectClient() (Client, error) {
+	index, err := e.selectMember("", nil)
+	if err != nil {
+		return nil, err
+	}
+	return e.clients[index], nil
+}
+
+// selectMember returns the index of the named ensemble member, or of a
+// weighted random one when model is empty, skipping excluded models unless
+// that would leave nothing to choose from
+func (e *Ensemble) selectMember(model string, exclude []string) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if len(e.clients) == 0 {
-		return nil, fmt.Errorf("no clients available in ensemble")
+		return 0, fmt.Errorf("no clients available in ensemble")
+	}
+
+	if model != "" {
+		for i, name := range e.names {
+			if name == model {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("model %s is not part of the ensemble", model)
+	}
+
+	weights := e.weights
+	if len(exclude) > 0 {
+		weights = make([]float64, len(e.weights))
+		total := 0.0
+		for i, weight := range e.weights {
+			if !containsName(exclude, e.names[i]) {
+				weights[i] = weight
+				total += weight
+			}
+		}
+		if total > 0 {
+			for i := range weights {
+				weights[i] /= total
+			}
+		} else {
+			weights = e.weights
+		}
 	}
 
 	// Use weighted random selection
 	r := e.rand.Float64()
 	cumulative := 0.0
 
-	for i, weight := range e.weights {
+	last := len(e.clients) - 1
+	for i, weight := range weights {
+		if weight == 0 {
+			continue
+		}
 		cumulative += weight
+		last = i
 		if r <= cumulative {
-			log.Printf("Selected model with index %d and weight %.2f", i, weight)
-			return e.clients[i], nil
+			e.logger.WithFields(logrus.Fields{
+				"model":  e.names[i],
+				"weight": weight,
+			}).Debug("Selected ensemble model")
+			return i, nil
 		}
 	}
 
-	// Fallback to last client (shouldn't happen if weights sum to 1.0)
-	return e.clients[len(e.clients)-1], nil
+	// Fallback to last eligible client (shouldn't happen if weights sum to 1.0)
+	return last, nil
+}
+
+// containsName reports whether name is in names
+func containsName(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
 }
 
 // createClient creates an LLM client based on the configuration
@@ -250,6 +571,8 @@ func createClient(cfg types.LLMModelConfig) (Client, error) {
 
 // GetStats returns statistics about the ensemble
 func (e *Ensemble) GetStats() map[string]interface{} {
+	throttled := e.Throttled()
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -257,5 +580,6 @@ func (e *Ensemble) GetStats() map[string]interface{} {
 		"num_clients":   len(e.clients),
 		"total_weight":  e.totalWeight,
 		"weights":       e.weights,
+		"throttled_seconds": throttled.Seconds(),
 	}
 }
\ No newline at end of file
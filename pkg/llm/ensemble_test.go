@@ -2,8 +2,13 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -229,6 +234,34 @@ func TestEnsembleSelectClient(t *testing.T) {
 	assert.InDelta(t, 4.0, ratio, 1.0) // Allow some variance
 }
 
+func TestEnsembleSelectMemberExcludes(t *testing.T) {
+	configs := []types.LLMModelConfig{
+		{Name: "gpt-4", Weight: 0.9, APIKey: "test-key"},
+		{Name: "gpt-3.5-turbo", Weight: 0.1, APIKey: "test-key"},
+	}
+
+	ensemble, err := NewEnsemble(configs)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		index, err := ensemble.selectMember("", []string{"gpt-4"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, index)
+	}
+
+	// Excluding every member falls back to the full ensemble
+	_, err = ensemble.selectMember("", []string{"gpt-4", "gpt-3.5-turbo"})
+	assert.NoError(t, err)
+
+	// A forced model ignores weights
+	index, err := ensemble.selectMember("gpt-3.5-turbo", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	_, err = ensemble.selectMember("unknown", nil)
+	assert.Error(t, err)
+}
+
 func TestEnsembleGenerateWithSystemMessage(t *testing.T) {
 	configs := []types.LLMModelConfig{
 		{
@@ -278,4 +311,84 @@ func TestEnsembleGenerateAll(t *testing.T) {
 	// Should fail due to invalid API, but return partial results
 	assert.Error(t, err)
 	assert.Equal(t, 2, len(responses)) // One response per client
-}
\ No newline at end of file
+}
+// completionServer answers every chat completion with the requested model
+// name as content
+func completionServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		fmt.Fprintf(w, `{"model": %q, "choices": [{"message": {"role": "assistant", "content": %q}}]}`, request.Model, request.Model)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewEvaluatorEnsemble(t *testing.T) {
+	server := completionServer(t)
+	config := types.LLMConfig{
+		APIBase: server.URL,
+		APIKey:  "test-key",
+		Models:  []types.LLMModelConfig{{Name: "evolution-model", Weight: 1}},
+	}
+
+	ensemble, err := NewEvaluatorEnsemble(config)
+	require.NoError(t, err)
+	assert.Nil(t, ensemble)
+
+	// The evaluator models answer, with settings taken from the config
+	config.EvaluatorModels = []types.LLMModelConfig{{Name: "judge-model", Weight: 1}}
+	ensemble, err = NewEvaluatorEnsemble(config)
+	require.NoError(t, err)
+	response, err := ensemble.Generate(context.Background(), "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "judge-model", response.Content)
+}
+
+func TestEnsembleModelPoolsFromConfig(t *testing.T) {
+	server := completionServer(t)
+	ensemble, err := NewEnsembleFromConfig(types.LLMConfig{
+		APIBase:    server.URL,
+		APIKey:     "test-key",
+		Models:     []types.LLMModelConfig{{Name: "strong-model", Weight: 1}},
+		DiffModels: []types.LLMModelConfig{{Name: "cheap-model", Weight: 1}},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	response, err := ensemble.GenerateWithOptions(ctx, "prompt", GenerateOptions{Pool: constants.EditModeDiff})
+	require.NoError(t, err)
+	assert.Equal(t, "cheap-model", response.Content)
+	assert.Equal(t, "cheap-model", response.Member)
+
+	// Modes without a pool use the ensemble's own models
+	response, err = ensemble.GenerateWithOptions(ctx, "prompt", GenerateOptions{Pool: constants.EditModeRewrite})
+	require.NoError(t, err)
+	assert.Equal(t, "strong-model", response.Content)
+	assert.Equal(t, "strong-model", response.Member)
+}
+
+func TestEnsembleUsageCountsPools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"model": "m", "choices": [{"message": {"role": "assistant", "content": "ok"}}], "usage": {"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5}}`)
+	}))
+	defer server.Close()
+
+	ensemble, err := NewEnsembleFromConfig(types.LLMConfig{
+		APIBase:    server.URL,
+		APIKey:     "test-key",
+		Models:     []types.LLMModelConfig{{Name: "strong-model", Weight: 1}},
+		DiffModels: []types.LLMModelConfig{{Name: "cheap-model", Weight: 1}},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = ensemble.Generate(ctx, "prompt")
+	require.NoError(t, err)
+	_, err = ensemble.GenerateWithOptions(ctx, "prompt", GenerateOptions{Pool: constants.EditModeDiff})
+	require.NoError(t, err)
+
+	assert.Equal(t, types.TokenUsage{PromptTokens: 6, CompletionTokens: 4, TotalTokens: 10}, ensemble.Usage())
+}
@@ -6,11 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/audit"
 )
 
 // OpenAIClient implements an LLM client for OpenAI-compatible APIs
@@ -19,6 +25,14 @@ type OpenAIClient struct {
 	httpClient  *http.Client
 	baseURL     string
 	apiKey      string
+	auditor     *audit.Logger
+
+	// Nanoseconds spent waiting to retry rate-limited requests
+	throttled atomic.Int64
+
+	// Jitters retry delays; seeded from the model's random seed
+	rngMu sync.Mutex
+	rng   *rand.Rand
 }
 
 // NewOpenAIClient creates a new OpenAI-compatible LLM client
@@ -28,6 +42,11 @@ func NewOpenAIClient(config types.LLMModelConfig) *OpenAIClient {
 		timeout = 60 * time.Second
 	}
 
+	seed := time.Now().UnixNano()
+	if config.RandomSeed > 0 {
+		seed = int64(config.RandomSeed)
+	}
+
 	return &OpenAIClient{
 		config: config,
 		httpClient: &http.Client{
@@ -35,22 +54,48 @@ func NewOpenAIClient(config types.LLMModelConfig) *OpenAIClient {
 		},
 		baseURL: getOrDefault(config.APIBase, "https://api.openai.com/v1"),
 		apiKey:  config.APIKey,
+		rng:     rand.New(rand.NewSource(seed)),
 	}
 }
 
+// SetAuditLogger records every HTTP call made by this client in the audit log
+func (c *OpenAIClient) SetAuditLogger(auditor *audit.Logger) {
+	c.auditor = auditor
+}
+
+// Throttled returns the time spent waiting to retry rate-limited requests
+func (c *OpenAIClient) Throttled() time.Duration {
+	return time.Duration(c.throttled.Load())
+}
+
+// Close releases the client's idle connections
+func (c *OpenAIClient) Close() {
+	c.httpClient.CloseIdleConnections()
+}
+
 // Generate generates text from a prompt
 func (c *OpenAIClient) Generate(ctx context.Context, prompt string) (*types.LLMResponse, error) {
+	return c.GenerateWithOptions(ctx, prompt, GenerateOptions{})
+}
+
+// GenerateWithOptions generates text from a prompt with per-call sampling overrides
+func (c *OpenAIClient) GenerateWithOptions(ctx context.Context, prompt string, opts GenerateOptions) (*types.LLMResponse, error) {
 	messages := []types.LLMMessage{
 		{Role: "user", Content: prompt},
 	}
 
 	systemMessage := getOrDefault(c.config.SystemMessage, "You are an expert programmer helping to evolve and improve code.")
 
-	return c.GenerateWithSystemMessage(ctx, systemMessage, messages)
+	return c.generate(ctx, systemMessage, messages, opts)
 }
 
 // GenerateWithSystemMessage generates text using a system message and conversational context
 func (c *OpenAIClient) GenerateWithSystemMessage(ctx context.Context, systemMessage string, messages []types.LLMMessage) (*types.LLMResponse, error) {
+	return c.generate(ctx, systemMessage, messages, GenerateOptions{})
+}
+
+// generate sends a chat completion request, retrying transient failures
+func (c *OpenAIClient) generate(ctx context.Context, systemMessage string, messages []types.LLMMessage, opts GenerateOptions) (*types.LLMResponse, error) {
 	// Prepare messages with system message first
 	allMessages := make([]types.LLMMessage, 0, len(messages)+1)
 	allMessages = append(allMessages, types.LLMMessage{Role: "system", Content: systemMessage})
@@ -64,6 +109,13 @@ func (c *OpenAIClient) GenerateWithSystemMessage(ctx context.Context, systemMess
 		TopP:        getOrDefaultFloat64(c.config.TopP, 0.95),
 		MaxTokens:   getOrDefaultInt(c.config.MaxTokens, 4096),
 		Timeout:     time.Duration(getOrDefaultInt(c.config.Timeout, 60)) * time.Second,
+		Seed:        getOrDefaultInt(opts.Seed, c.config.RandomSeed),
+	}
+	if opts.Temperature > 0 {
+		request.Temperature = opts.Temperature
+	}
+	if opts.MaxTokensFactor > 0 && opts.MaxTokensFactor < 1 {
+		request.MaxTokens = max(1, int(float64(request.MaxTokens)*opts.MaxTokensFactor))
 	}
 
 	// Handle reasoning models (o1, o3 series)
@@ -82,12 +134,18 @@ func (c *OpenAIClient) GenerateWithSystemMessage(ctx context.Context, systemMess
 	var lastErr error
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
-			// Add jitter to retry delay
-			jitter := time.Duration(float64(retryDelay) * (0.5 + 0.5*float64(attempt%2)))
+			wait, throttled := c.retryWait(lastErr, retryDelay, attempt)
+			waitStart := time.Now()
 			select {
 			case <-ctx.Done():
+				if throttled {
+					c.throttled.Add(int64(time.Since(waitStart)))
+				}
 				return nil, ctx.Err()
-			case <-time.After(jitter):
+			case <-time.After(wait):
+			}
+			if throttled {
+				c.throttled.Add(int64(time.Since(waitStart)))
 			}
 		}
 
@@ -116,7 +174,7 @@ func (c *OpenAIClient) GenerateWithSystemMessage(ctx context.Context, systemMess
 }
 
 // makeRequest makes an HTTP request to the LLM API
-func (c *OpenAIClient) makeRequest(ctx context.Context, request types.LLMRequest) (*types.LLMResponse, error) {
+func (c *OpenAIClient) makeRequest(ctx context.Context, request types.LLMRequest) (response *types.LLMResponse, err error) {
 	// Prepare request body
 	var body bytes.Buffer
 	encoder := json.NewEncoder(&body)
@@ -143,16 +201,47 @@ func (c *OpenAIClient) makeRequest(ctx context.Context, request types.LLMRequest
 	}
 
 	// Add seed for reproducibility if specified
-	if c.config.RandomSeed > 0 {
-		requestMap["seed"] = c.config.RandomSeed
+	if request.Seed > 0 {
+		requestMap["seed"] = request.Seed
 	}
 
 	if err := encoder.Encode(requestMap); err != nil {
 		return nil, fmt.Errorf("failed to encode request: %w", err)
 	}
 
+	// Bound this attempt by the request timeout without outliving the caller's deadline
+	if request.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, request.Timeout)
+		defer cancel()
+	}
+
 	// Create HTTP request
 	url := fmt.Sprintf("%s/chat/completions", c.baseURL)
+
+	// Audit the call once it has finished, whatever the outcome
+	inputHash := audit.Hash(body.Bytes())
+	var respBody []byte
+	var statusCode int
+	callStart := time.Now()
+	defer func() {
+		entry := audit.Entry{
+			Kind:       audit.KindLLM,
+			Name:       request.Model,
+			Args:       []string{"POST", url},
+			InputHash:  inputHash,
+			Duration:   time.Since(callStart),
+			ExitStatus: statusCode,
+		}
+		if respBody != nil {
+			entry.OutputHash = audit.Hash(respBody)
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		c.auditor.Record(entry)
+	}()
+
 	req, err := http.NewRequestWithContext(ctx, "POST", url, &body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -169,9 +258,10 @@ func (c *OpenAIClient) makeRequest(ctx context.Context, request types.LLMRequest
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
 	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -181,6 +271,7 @@ func (c *OpenAIClient) makeRequest(ctx context.Context, request types.LLMRequest
 		return nil, &HTTPError{
 			StatusCode: resp.StatusCode,
 			Message:    string(respBody),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 		}
 	}
 
@@ -259,12 +350,69 @@ type OpenAIResponse struct {
 type HTTPError struct {
 	StatusCode int
 	Message    string
+	// RetryAfter is how long the server asked us to wait, if it did
+	RetryAfter time.Duration
 }
 
 func (e *HTTPError) Error() string {
 	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
 }
 
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns zero when the header is missing, malformed or past.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// retryWait returns how long to wait before retrying after err and whether
+// the wait is for a rate limit. A rate-limited request waits as long as the
+// server asked, up to the backoff cap; any other waits the jittered backoff.
+func (c *OpenAIClient) retryWait(err error, base time.Duration, attempt int) (time.Duration, bool) {
+	httpErr, throttled := err.(*HTTPError)
+	throttled = throttled && httpErr.StatusCode == http.StatusTooManyRequests
+	if throttled && httpErr.RetryAfter > 0 {
+		return min(httpErr.RetryAfter, maxRetryDelay), true
+	}
+
+	c.rngMu.Lock()
+	defer c.rngMu.Unlock()
+	return backoff(base, attempt, c.rng), throttled
+}
+
+// maxRetryDelay caps every wait between retries
+const maxRetryDelay = time.Duration(constants.DefaultMaxRetryDelay) * time.Second
+
+// backoff returns the delay before a retry: base doubled for every earlier
+// retry and capped, with equal jitter drawn from rng so concurrent callers
+// spread out
+func backoff(base time.Duration, attempt int, rng *rand.Rand) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rng.Int63n(int64(delay-half)+1))
+}
+
 // Helper functions
 func getOrDefault(value, defaultValue string) string {
 	if value == "" {
@@ -2,11 +2,17 @@ package llm
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ishanwen-byte/openevolve-go/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewOpenAIClient(t *testing.T) {
@@ -226,6 +232,93 @@ func TestOpenAIClientWithCustomAPIBase(t *testing.T) {
 	assert.False(t, client.isReasoningModel())
 }
 
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("0", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+}
+
+func TestBackoff(t *testing.T) {
+	base := time.Second
+	rng := rand.New(rand.NewSource(1))
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := base << (attempt - 1)
+		if limit := 60 * time.Second; delay > limit {
+			delay = limit
+		}
+		for i := 0; i < 20; i++ {
+			wait := backoff(base, attempt, rng)
+			assert.GreaterOrEqual(t, wait, delay/2, "attempt %d", attempt)
+			assert.LessOrEqual(t, wait, delay, "attempt %d", attempt)
+		}
+	}
+	assert.Zero(t, backoff(0, 3, rng))
+
+	// The same seed jitters the same way
+	a, b := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+	for attempt := 1; attempt <= 5; attempt++ {
+		assert.Equal(t, backoff(base, attempt, a), backoff(base, attempt, b))
+	}
+}
+
+func TestRetryWaitCapsRetryAfter(t *testing.T) {
+	client := NewOpenAIClient(types.LLMModelConfig{Name: "gpt-4", RandomSeed: 1})
+
+	wait, throttled := client.retryWait(&HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}, time.Second, 1)
+	assert.True(t, throttled)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, throttled = client.retryWait(&HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 24 * time.Hour}, time.Second, 1)
+	assert.True(t, throttled)
+	assert.Equal(t, 60*time.Second, wait, "a huge Retry-After is capped")
+
+	wait, throttled = client.retryWait(&HTTPError{StatusCode: http.StatusBadGateway}, time.Second, 1)
+	assert.False(t, throttled)
+	assert.LessOrEqual(t, wait, time.Second)
+}
+
+func TestOpenAIClientHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error": "rate limited"}`)
+			return
+		}
+		fmt.Fprint(w, `{"model": "gpt-4", "choices": [{"message": {"role": "assistant", "content": "ok"}}]}`)
+	}))
+	defer server.Close()
+
+	// The configured delay is far longer than Retry-After, which wins
+	ensemble, err := NewEnsemble([]types.LLMModelConfig{{
+		Name:       "gpt-4",
+		APIBase:    server.URL,
+		APIKey:     "test-key",
+		Retries:    2,
+		RetryDelay: 30,
+		Weight:     1,
+	}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	response, err := ensemble.Generate(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response.Content)
+	assert.Equal(t, int32(2), calls.Load())
+
+	throttled := ensemble.Throttled()
+	assert.GreaterOrEqual(t, throttled, time.Second)
+	assert.Less(t, throttled, 5*time.Second)
+	assert.InDelta(t, throttled.Seconds(), ensemble.GetStats()["throttled_seconds"], 0.001)
+}
+
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
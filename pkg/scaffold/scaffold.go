@@ -0,0 +1,155 @@
+// Package scaffold generates a ready-to-edit evaluator for a new problem: a
+// program that prints the JSON result OpenEvolve reads, answers cascade
+// stage flags and holds example scoring code for each stage.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+// Options describes the evaluator to generate
+type Options struct {
+	// Language is go (the default) or python. A Python evaluator comes
+	// with a Go launcher, since OpenEvolve runs evaluators with go run.
+	Language string
+	// Stages are the cascade stages, each scored by its own function; nil
+	// uses DefaultStages
+	Stages []types.CascadeStage
+	// Interpreter runs a Python evaluator; empty uses python3
+	Interpreter string
+}
+
+// DefaultStages checks that the program runs, then scores its output
+var DefaultStages = []types.CascadeStage{
+	{Name: "runs", Threshold: 1, Critical: true},
+	{Name: "accuracy"},
+}
+
+// Generate returns the files of an evaluator keyed by file name. The Go
+// evaluator, or the launcher of a Python one, is always evaluator.go.
+func Generate(opts Options) (map[string]string, error) {
+	if opts.Stages == nil {
+		opts.Stages = DefaultStages
+	}
+	if opts.Interpreter == "" {
+		opts.Interpreter = "python3"
+	}
+	if err := validateStages(opts.Stages); err != nil {
+		return nil, err
+	}
+
+	switch opts.Language {
+	case "", constants.ScaffoldLanguageGo:
+		evaluator, err := render("evaluator.go.tmpl", opts, true)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"evaluator.go": evaluator}, nil
+	case constants.ScaffoldLanguagePython:
+		evaluator, err := render("evaluator.py.tmpl", opts, false)
+		if err != nil {
+			return nil, err
+		}
+		launcher, err := render("launcher.go.tmpl", opts, true)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"evaluator.py": evaluator, "evaluator.go": launcher}, nil
+	default:
+		return nil, fmt.Errorf("unknown scaffold language: %s", opts.Language)
+	}
+}
+
+// Write generates an evaluator into dir, creating it if needed, and
+// returns the paths written. It refuses to overwrite existing files.
+func Write(dir string, opts Options) ([]string, error) {
+	files, err := Generate(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return nil, fmt.Errorf("%s already exists", filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(names)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create scaffold directory: %w", err)
+	}
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+		mode := os.FileMode(0644)
+		if filepath.Ext(name) == constants.PythonExt {
+			mode = 0755
+		}
+		if err := os.WriteFile(paths[i], []byte(files[name]), mode); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return paths, nil
+}
+
+// validateStages rejects stages that cannot be written into source code
+func validateStages(stages []types.CascadeStage) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("scaffold needs at least one stage")
+	}
+	seen := make(map[string]bool, len(stages))
+	for _, stage := range stages {
+		if stage.Name == "" {
+			return fmt.Errorf("stage name must not be empty")
+		}
+		if strings.IndexFunc(stage.Name, unicode.IsControl) >= 0 {
+			return fmt.Errorf("stage name %q has control characters", stage.Name)
+		}
+		if seen[stage.Name] {
+			return fmt.Errorf("duplicate stage %s", stage.Name)
+		}
+		seen[stage.Name] = true
+		if math.IsNaN(stage.Threshold) || math.IsInf(stage.Threshold, 0) {
+			return fmt.Errorf("stage %s threshold must be finite", stage.Name)
+		}
+	}
+	return nil
+}
+
+// render executes a template, formatting the result when it is Go source
+func render(name string, opts Options, goSource bool) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, opts); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	if !goSource {
+		return buf.String(), nil
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format %s: %w", name, err)
+	}
+	return string(source), nil
+}
@@ -0,0 +1,128 @@
+package scaffold
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+	"github.com/ishanwen-byte/openevolve-go/pkg/evaluator"
+)
+
+func TestGenerateGo(t *testing.T) {
+	for _, stages := range [][]types.CascadeStage{nil, {{Name: "only"}}} {
+		files, err := Generate(Options{Stages: stages})
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		source := files["evaluator.go"]
+		_, err = parser.ParseFile(token.NewFileSet(), "evaluator.go", source, 0)
+		require.NoError(t, err)
+		assert.Contains(t, source, `"--stage=stage"`)
+		assert.Contains(t, source, `json:"score"`)
+
+		if _, err := exec.LookPath("go"); err != nil {
+			continue
+		}
+		path := filepath.Join(t.TempDir(), "evaluator.go")
+		require.NoError(t, os.WriteFile(path, []byte(source), 0644))
+		output, err := exec.Command("go", "vet", path).CombinedOutput()
+		assert.NoError(t, err, string(output))
+	}
+}
+
+func TestGeneratedEvaluatorsScoreProgram(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	languages := []string{constants.ScaffoldLanguageGo}
+	if _, err := exec.LookPath("python3"); err == nil {
+		languages = append(languages, constants.ScaffoldLanguagePython)
+	}
+	for _, language := range languages {
+		// The scaffold lives apart from where evaluated programs are written
+		paths, err := Write(filepath.Join(t.TempDir(), "scaffold"), Options{Language: language})
+		require.NoError(t, err)
+
+		e, err := evaluator.New(types.EvaluatorConfig{ParallelWorkers: 1, Timeout: 120}, paths[0])
+		require.NoError(t, err)
+
+		result, err := e.Evaluate(context.Background(), "package main\n\nfunc main() { println(41) }\n")
+		require.NoError(t, err)
+		assert.True(t, result.Success, "%s: %s %s", language, result.Error, result.Artifacts["stderr"])
+		assert.InDelta(t, 0.5, result.Score, 1e-9, language)
+		assert.Equal(t, 1.0, result.Metrics["runs"], language)
+
+		result, err = e.Evaluate(context.Background(), "package main\n\nfunc main() { panic(1) }\n")
+		require.NoError(t, err)
+		assert.False(t, result.Success, language)
+		e.Close()
+	}
+}
+
+func TestGeneratePython(t *testing.T) {
+	files, err := Generate(Options{
+		Language:    constants.ScaffoldLanguagePython,
+		Stages:      []types.CascadeStage{{Name: "compiles", Threshold: 0.5}, {Name: "fast"}, {Name: "exact"}},
+		Interpreter: "python3.12",
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "evaluator.go", files["evaluator.go"], 0)
+	require.NoError(t, err)
+	assert.Contains(t, files["evaluator.go"], `"python3.12"`)
+
+	script := files["evaluator.py"]
+	assert.Contains(t, script, `("compiles", 0.5, score_stage1)`)
+	assert.Contains(t, script, `("exact", 0, score_stage3)`)
+	assert.True(t, strings.HasPrefix(script, "#!/usr/bin/env python3\n"))
+
+	if _, err := exec.LookPath("python3"); err != nil {
+		return
+	}
+	path := filepath.Join(t.TempDir(), "evaluator.py")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0644))
+	output, err := exec.Command("python3", "-m", "py_compile", path).CombinedOutput()
+	assert.NoError(t, err, string(output))
+}
+
+func TestGenerateRejectsBadOptions(t *testing.T) {
+	for name, opts := range map[string]Options{
+		"language":  {Language: "rust"},
+		"no stages": {Stages: []types.CascadeStage{}},
+		"no name":   {Stages: []types.CascadeStage{{}}},
+		"newline":   {Stages: []types.CascadeStage{{Name: "a\nb"}}},
+		"duplicate": {Stages: []types.CascadeStage{{Name: "a"}, {Name: "a"}}},
+	} {
+		_, err := Generate(opts)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestWriteRefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "evaluator.py"), []byte("keep"), 0644))
+
+	_, err := Write(dir, Options{Language: constants.ScaffoldLanguagePython})
+	assert.Error(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "evaluator.py"))
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(content))
+	_, err = os.Stat(filepath.Join(dir, "evaluator.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	paths, err := Write(filepath.Join(dir, "new"), Options{Language: constants.ScaffoldLanguagePython})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "new", "evaluator.go"), filepath.Join(dir, "new", "evaluator.py")}, paths)
+}
@@ -0,0 +1,132 @@
+// Package score maps raw evaluator metrics, such as a runtime in
+// milliseconds, onto the 0-1 score range selection assumes. Evaluator
+// programs opt in by importing it.
+package score
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/atomicfile"
+)
+
+// DefaultHistorySize is how many values per metric a History keeps
+const DefaultHistorySize = 1000
+
+// MinMax scales value linearly from [lo, hi] onto [0, 1], clamping values
+// outside the range. A degenerate range maps everything to 0.5.
+func MinMax(value, lo, hi float64) float64 {
+	if hi <= lo {
+		return 0.5
+	}
+	return clamp((value - lo) / (hi - lo))
+}
+
+// Sigmoid squashes value onto (0, 1) around midpoint, which maps to 0.5.
+// scale is the distance from the midpoint that maps to about 0.73; a
+// negative scale rewards values below the midpoint instead.
+func Sigmoid(value, midpoint, scale float64) float64 {
+	if scale == 0 {
+		return 0.5
+	}
+	return 1 / (1 + math.Exp(-(value-midpoint)/scale))
+}
+
+// RankPercentile returns the fraction of history that value beats, with
+// ties counting half. An empty history gives 0.5.
+func RankPercentile(value float64, history []float64) float64 {
+	if len(history) == 0 {
+		return 0.5
+	}
+	below, equal := 0, 0
+	for _, past := range history {
+		if past < value {
+			below++
+		} else if past == value {
+			equal++
+		}
+	}
+	return (float64(below) + float64(equal)/2) / float64(len(history))
+}
+
+// Invert turns a score where lower is better into one where higher is
+func Invert(score float64) float64 {
+	return 1 - clamp(score)
+}
+
+func clamp(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
+
+// History records the raw values of metrics across evaluations so scores
+// can be normalized against what earlier programs achieved. It is kept in a
+// JSON file because every evaluation runs in its own process. Concurrent
+// evaluations may drop each other's updates, which only makes the history
+// slightly shorter.
+type History struct {
+	path   string
+	size   int
+	Values map[string][]float64 `json:"values"`
+}
+
+// LoadHistory reads the history at path, starting empty when the file does
+// not exist yet
+func LoadHistory(path string) (*History, error) {
+	history := &History{path: path, size: DefaultHistorySize, Values: make(map[string][]float64)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read score history: %w", err)
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse score history: %w", err)
+	}
+	if history.Values == nil {
+		history.Values = make(map[string][]float64)
+	}
+	return history, nil
+}
+
+// Observe adds a raw value of metric, dropping the oldest values beyond
+// DefaultHistorySize
+func (h *History) Observe(metric string, value float64) {
+	values := append(h.Values[metric], value)
+	if len(values) > h.size {
+		values = values[len(values)-h.size:]
+	}
+	h.Values[metric] = values
+}
+
+// MinMax scales value between the smallest and largest values of metric
+// seen so far
+func (h *History) MinMax(metric string, value float64) float64 {
+	values := h.Values[metric]
+	if len(values) == 0 {
+		return 0.5
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return MinMax(value, sorted[0], sorted[len(sorted)-1])
+}
+
+// RankPercentile returns the fraction of recorded values of metric that
+// value beats
+func (h *History) RankPercentile(metric string, value float64) float64 {
+	return RankPercentile(value, h.Values[metric])
+}
+
+// Save writes the history back to its file, replacing it atomically
+func (h *History) Save() error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to marshal score history: %w", err)
+	}
+	return atomicfile.Write(h.path, data)
+}
@@ -0,0 +1,63 @@
+package score
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinMax(t *testing.T) {
+	assert.Equal(t, 0.25, MinMax(25, 0, 100))
+	assert.Equal(t, 0.0, MinMax(-5, 0, 100))
+	assert.Equal(t, 1.0, MinMax(150, 0, 100))
+	assert.Equal(t, 0.5, MinMax(3, 7, 7))
+}
+
+func TestSigmoid(t *testing.T) {
+	assert.Equal(t, 0.5, Sigmoid(200, 200, 50))
+	assert.Greater(t, Sigmoid(300, 200, 50), 0.8)
+
+	// A negative scale rewards fast runtimes
+	assert.Greater(t, Sigmoid(100, 200, -50), 0.8)
+	assert.Less(t, Sigmoid(300, 200, -50), 0.2)
+}
+
+func TestRankPercentile(t *testing.T) {
+	history := []float64{10, 20, 30, 40}
+	assert.Equal(t, 0.0, RankPercentile(5, history))
+	assert.Equal(t, 0.625, RankPercentile(30, history))
+	assert.Equal(t, 1.0, RankPercentile(50, history))
+	assert.Equal(t, 0.5, RankPercentile(50, nil))
+	assert.Equal(t, 0.75, Invert(0.25))
+}
+
+func TestHistoryPersistsAcrossLoads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "scores.json")
+
+	history, err := LoadHistory(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, history.MinMax("runtime_ms", 120))
+	history.Observe("runtime_ms", 100)
+	history.Observe("runtime_ms", 300)
+	require.NoError(t, history.Save())
+
+	reloaded, err := LoadHistory(path)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100, 300}, reloaded.Values["runtime_ms"])
+	assert.Equal(t, 0.5, reloaded.MinMax("runtime_ms", 200))
+	assert.Equal(t, 0.75, Invert(reloaded.MinMax("runtime_ms", 150)))
+	assert.Equal(t, 0.5, reloaded.RankPercentile("runtime_ms", 200))
+}
+
+func TestHistoryKeepsRecentValues(t *testing.T) {
+	history, err := LoadHistory(filepath.Join(t.TempDir(), "scores.json"))
+	require.NoError(t, err)
+	history.size = 3
+
+	for _, value := range []float64{1, 2, 3, 4, 5} {
+		history.Observe("memory", value)
+	}
+	assert.Equal(t, []float64{3, 4, 5}, history.Values["memory"])
+}
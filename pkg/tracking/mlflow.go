@@ -0,0 +1,200 @@
+package tracking
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// MLflow limits on a single log-batch request and on parameter values
+const (
+	mlflowBatchParams    = 100
+	mlflowParamMaxLength = 500
+)
+
+// mlflowArtifactScheme prefixes artifact locations the tracking server
+// proxies itself
+const mlflowArtifactScheme = "mlflow-artifacts:/"
+
+// mlflowSink logs to a run on an MLflow tracking server through its REST
+// API
+type mlflowSink struct {
+	client       *client
+	baseURL      string
+	runID        string
+	artifactPath string
+}
+
+// newMLflowSink finds or creates the experiment and starts a run in it
+func newMLflowSink(ctx context.Context, config types.TrackingConfig) (*mlflowSink, error) {
+	if config.URI == "" {
+		return nil, fmt.Errorf("mlflow tracking needs a tracking URI")
+	}
+	s := &mlflowSink{
+		client: newClient(config, func(req *http.Request) {
+			if config.APIKey != "" {
+				req.Header.Set("Authorization", "Bearer "+config.APIKey)
+			}
+		}),
+		baseURL: strings.TrimSuffix(config.URI, "/"),
+	}
+
+	experiment := config.Experiment
+	if experiment == "" {
+		experiment = constants.DefaultTrackingExperiment
+	}
+	experimentID, err := s.experimentID(ctx, experiment)
+	if err != nil {
+		return nil, err
+	}
+
+	var created struct {
+		Run struct {
+			Info struct {
+				RunID       string `json:"run_id"`
+				ArtifactURI string `json:"artifact_uri"`
+			} `json:"info"`
+		} `json:"run"`
+	}
+	request := map[string]interface{}{
+		"experiment_id": experimentID,
+		"start_time":    time.Now().UnixMilli(),
+	}
+	if config.RunName != "" {
+		request["run_name"] = config.RunName
+	}
+	if err := s.client.do(ctx, http.MethodPost, s.api("runs/create"), request, &created); err != nil {
+		return nil, fmt.Errorf("failed to create mlflow run: %w", err)
+	}
+	s.runID = created.Run.Info.RunID
+	if strings.HasPrefix(created.Run.Info.ArtifactURI, mlflowArtifactScheme) {
+		s.artifactPath = strings.TrimPrefix(created.Run.Info.ArtifactURI, mlflowArtifactScheme)
+	}
+	return s, nil
+}
+
+// api returns the URL of an MLflow REST endpoint
+func (s *mlflowSink) api(endpoint string) string {
+	return s.baseURL + "/api/2.0/mlflow/" + endpoint
+}
+
+// experimentID returns the ID of the named experiment, creating it if it
+// does not exist
+func (s *mlflowSink) experimentID(ctx context.Context, name string) (string, error) {
+	var found struct {
+		Experiment struct {
+			ExperimentID string `json:"experiment_id"`
+		} `json:"experiment"`
+	}
+	err := s.client.do(ctx, http.MethodGet, s.api("experiments/get-by-name?experiment_name="+url.QueryEscape(name)), nil, &found)
+	if err == nil {
+		return found.Experiment.ExperimentID, nil
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		return "", fmt.Errorf("failed to look up mlflow experiment: %w", err)
+	}
+
+	var created struct {
+		ExperimentID string `json:"experiment_id"`
+	}
+	if err := s.client.do(ctx, http.MethodPost, s.api("experiments/create"), map[string]string{"name": name}, &created); err != nil {
+		return "", fmt.Errorf("failed to create mlflow experiment: %w", err)
+	}
+	return created.ExperimentID, nil
+}
+
+// LogConfig logs the configuration as run parameters with dotted keys.
+// Values longer than MLflow accepts are truncated.
+func (s *mlflowSink) LogConfig(ctx context.Context, config types.Config) error {
+	values, err := configValues(config)
+	if err != nil {
+		return err
+	}
+	flat := make(map[string]string)
+	flatten("", values, flat)
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for start := 0; start < len(keys); start += mlflowBatchParams {
+		end := min(start+mlflowBatchParams, len(keys))
+		params := make([]map[string]string, 0, end-start)
+		for _, key := range keys[start:end] {
+			value := flat[key]
+			if len(value) > mlflowParamMaxLength {
+				value = value[:mlflowParamMaxLength]
+			}
+			params = append(params, map[string]string{"key": key, "value": value})
+		}
+		if err := s.logBatch(ctx, map[string]interface{}{"params": params}); err != nil {
+			return fmt.Errorf("failed to log config: %w", err)
+		}
+	}
+	return nil
+}
+
+// LogMetrics logs metrics at an iteration
+func (s *mlflowSink) LogMetrics(ctx context.Context, step int, metrics map[string]float64) error {
+	timestamp := time.Now().UnixMilli()
+	batch := make([]map[string]interface{}, 0, len(metrics))
+	for key, value := range metrics {
+		batch = append(batch, map[string]interface{}{
+			"key":       key,
+			"value":     value,
+			"timestamp": timestamp,
+			"step":      step,
+		})
+	}
+	if err := s.logBatch(ctx, map[string]interface{}{"metrics": batch}); err != nil {
+		return fmt.Errorf("failed to log metrics: %w", err)
+	}
+	return nil
+}
+
+// logBatch sends one log-batch request for the run
+func (s *mlflowSink) logBatch(ctx context.Context, batch map[string]interface{}) error {
+	batch["run_id"] = s.runID
+	return s.client.do(ctx, http.MethodPost, s.api("runs/log-batch"), batch, nil)
+}
+
+// LogArtifact uploads a file through the tracking server's artifact proxy
+func (s *mlflowSink) LogArtifact(ctx context.Context, name string, data []byte) error {
+	if s.artifactPath == "" {
+		return fmt.Errorf("mlflow run artifacts are not served by the tracking server")
+	}
+	target := s.baseURL + "/api/2.0/mlflow-artifacts/artifacts/" + s.artifactPath + "/" + url.PathEscape(name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := s.client.send(req, nil); err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close marks the run finished
+func (s *mlflowSink) Close(ctx context.Context) error {
+	request := map[string]interface{}{
+		"run_id":   s.runID,
+		"status":   "FINISHED",
+		"end_time": time.Now().UnixMilli(),
+	}
+	if err := s.client.do(ctx, http.MethodPost, s.api("runs/update"), request, nil); err != nil {
+		return fmt.Errorf("failed to finish mlflow run: %w", err)
+	}
+	return nil
+}
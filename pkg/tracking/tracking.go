@@ -0,0 +1,177 @@
+// Package tracking pushes the metrics, configuration and artifacts of an
+// evolution run to an external experiment tracker, so runs appear alongside
+// other ML experiments.
+package tracking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// Sink receives what an evolution run reports. Implementations must be safe
+// for concurrent use; islands report their iterations concurrently.
+type Sink interface {
+	// LogConfig records the run's configuration
+	LogConfig(ctx context.Context, config types.Config) error
+	// LogMetrics records metrics observed at an iteration
+	LogMetrics(ctx context.Context, step int, metrics map[string]float64) error
+	// LogArtifact stores a file produced by the run under name
+	LogArtifact(ctx context.Context, name string, data []byte) error
+	// Close marks the run finished
+	Close(ctx context.Context) error
+}
+
+// New starts a run on the tracker described by config. It returns a nil
+// Sink when tracking is disabled.
+func New(ctx context.Context, config types.TrackingConfig) (Sink, error) {
+	switch config.Backend {
+	case "":
+		return nil, nil
+	case constants.TrackingBackendMLflow:
+		return newMLflowSink(ctx, config)
+	case constants.TrackingBackendWandB:
+		return newWandBSink(ctx, config)
+	default:
+		return nil, fmt.Errorf("unknown tracking backend: %s", config.Backend)
+	}
+}
+
+// HTTPError is an unsuccessful response from a tracker
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// client sends the requests of one tracker
+type client struct {
+	http *http.Client
+	// authorize adds credentials to a request
+	authorize func(*http.Request)
+}
+
+// newClient creates a client whose requests time out after config.Timeout
+// seconds
+func newClient(config types.TrackingConfig, authorize func(*http.Request)) *client {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = constants.DefaultTrackingTimeout
+	}
+	return &client{
+		http:      &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		authorize: authorize,
+	}
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out when it is not nil
+func (c *client) do(ctx context.Context, method, url string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(req, out)
+}
+
+// send sends a prepared request and decodes a JSON response into out when
+// it is not nil
+func (c *client) send(req *http.Request, out interface{}) error {
+	if c.authorize != nil {
+		c.authorize(req)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &HTTPError{StatusCode: resp.StatusCode, Message: string(data)}
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+// configValues returns the configuration as nested JSON values with every
+// API key removed, so credentials never reach the tracker
+func configValues(config types.Config) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	dropSecrets(values)
+	return values, nil
+}
+
+// dropSecrets removes api_key fields at any depth
+func dropSecrets(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		delete(v, "api_key")
+		for _, child := range v {
+			dropSecrets(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			dropSecrets(child)
+		}
+	}
+}
+
+// flatten turns nested configuration values into dotted keys with string
+// values; lists and empty values are kept as JSON
+func flatten(prefix string, value interface{}, out map[string]string) {
+	if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+		for key, child := range m {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, child, out)
+		}
+		return
+	}
+	switch v := value.(type) {
+	case string:
+		out[prefix] = v
+	case nil:
+		out[prefix] = ""
+	default:
+		data, _ := json.Marshal(v)
+		out[prefix] = strings.TrimSpace(string(data))
+	}
+}
@@ -0,0 +1,170 @@
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// recordedRequest is a request a fake tracker received
+type recordedRequest struct {
+	method string
+	path   string
+	auth   string
+	body   string
+}
+
+// fakeTracker answers requests with the handler's response and records them
+func fakeTracker(t *testing.T, handler func(r *http.Request, body string) (int, string)) (*httptest.Server, func() []recordedRequest) {
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), string(data)})
+		mu.Unlock()
+		status, response := handler(r, string(data))
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func secretConfig() types.Config {
+	return types.Config{
+		LLM:      types.LLMConfig{APIKey: "sk-secret", Models: []types.LLMModelConfig{{Name: "gpt", APIKey: "sk-model"}}},
+		Database: types.DatabaseConfig{NumIslands: 3},
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	sink, err := New(context.Background(), types.TrackingConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, sink)
+
+	_, err = New(context.Background(), types.TrackingConfig{Backend: "tensorboard"})
+	assert.ErrorContains(t, err, "unknown tracking backend")
+}
+
+func TestMLflowSink(t *testing.T) {
+	server, requests := fakeTracker(t, func(r *http.Request, body string) (int, string) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "experiments/get-by-name"):
+			return http.StatusNotFound, `{"error_code": "RESOURCE_DOES_NOT_EXIST"}`
+		case strings.HasSuffix(r.URL.Path, "experiments/create"):
+			return http.StatusOK, `{"experiment_id": "7"}`
+		case strings.HasSuffix(r.URL.Path, "runs/create"):
+			return http.StatusOK, `{"run": {"info": {"run_id": "r1", "artifact_uri": "mlflow-artifacts:/7/r1/artifacts"}}}`
+		}
+		return http.StatusOK, `{}`
+	})
+
+	ctx := context.Background()
+	sink, err := New(ctx, types.TrackingConfig{Backend: constants.TrackingBackendMLflow, URI: server.URL, Experiment: "search", APIKey: "token"})
+	require.NoError(t, err)
+	require.NoError(t, sink.LogConfig(ctx, secretConfig()))
+	require.NoError(t, sink.LogMetrics(ctx, 3, map[string]float64{"best_score": 0.5}))
+	require.NoError(t, sink.LogArtifact(ctx, "best_program.go", []byte("package main")))
+	require.NoError(t, sink.Close(ctx))
+
+	got := requests()
+	require.Len(t, got, 8)
+	assert.Equal(t, "Bearer token", got[0].auth)
+	assert.Contains(t, got[0].path, "experiment_name=search")
+	assert.JSONEq(t, `{"name": "search"}`, got[1].body)
+	assert.Contains(t, got[2].body, `"experiment_id":"7"`)
+
+	// Parameters are flattened, sent in batches MLflow accepts and carry
+	// no API keys
+	assert.Contains(t, got[3].body, `{"key":"database.num_islands","value":"3"}`)
+	assert.Equal(t, mlflowBatchParams, strings.Count(got[3].body, `"key"`))
+	assert.Contains(t, got[4].body, `{"key":"tracking.backend","value":""}`)
+	assert.NotContains(t, got[3].body+got[4].body, "sk-")
+
+	var batch struct {
+		RunID   string `json:"run_id"`
+		Metrics []struct {
+			Key   string  `json:"key"`
+			Value float64 `json:"value"`
+			Step  int     `json:"step"`
+		} `json:"metrics"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(got[5].body), &batch))
+	assert.Equal(t, "r1", batch.RunID)
+	require.Len(t, batch.Metrics, 1)
+	assert.Equal(t, 3, batch.Metrics[0].Step)
+
+	assert.Equal(t, http.MethodPut, got[6].method)
+	assert.Equal(t, "/api/2.0/mlflow-artifacts/artifacts/7/r1/artifacts/best_program.go", got[6].path)
+	assert.Equal(t, "package main", got[6].body)
+	assert.Contains(t, got[7].body, `"status":"FINISHED"`)
+}
+
+func TestWandBSink(t *testing.T) {
+	var server *httptest.Server
+	server, requests := fakeTracker(t, func(r *http.Request, body string) (int, string) {
+		switch {
+		case strings.Contains(body, "upsertBucket"):
+			return http.StatusOK, `{"data": {"upsertBucket": {"bucket": {"name": "run", "project": {"name": "search", "entity": {"name": "team"}}}}}}`
+		case strings.Contains(body, "createRunFiles"):
+			return http.StatusOK, `{"data": {"createRunFiles": {"uploadHeaders": ["X-Upload: yes"], "files": [{"name": "best_program.go", "uploadUrl": "` + server.URL + `/upload"}]}}}`
+		}
+		return http.StatusOK, `{}`
+	})
+
+	ctx := context.Background()
+	sink, err := New(ctx, types.TrackingConfig{Backend: constants.TrackingBackendWandB, URI: server.URL, Experiment: "search", APIKey: "key"})
+	require.NoError(t, err)
+	run := sink.(*wandbSink).run
+	require.NoError(t, sink.LogConfig(ctx, secretConfig()))
+	require.NoError(t, sink.LogMetrics(ctx, 5, map[string]float64{"best_score": 0.5}))
+	require.NoError(t, sink.LogMetrics(ctx, 4, map[string]float64{"best_score": 0.6}))
+	require.NoError(t, sink.LogArtifact(ctx, "best_program.go", []byte("package main")))
+	require.NoError(t, sink.Close(ctx))
+
+	got := requests()
+	require.Len(t, got, 7)
+	assert.True(t, strings.HasPrefix(got[0].auth, "Basic "))
+	assert.Contains(t, got[1].body, `\"num_islands\":3`)
+	assert.NotContains(t, got[1].body, "sk-")
+
+	// Rows stream in order with steps of their own
+	stream := "/files/team/search/" + run + "/file_stream"
+	for i, iteration := range []int{5, 4} {
+		var body struct {
+			Files map[string]struct {
+				Offset  int      `json:"offset"`
+				Content []string `json:"content"`
+			} `json:"files"`
+		}
+		assert.Equal(t, stream, got[2+i].path)
+		require.NoError(t, json.Unmarshal([]byte(got[2+i].body), &body))
+		history := body.Files[wandbHistoryFile]
+		assert.Equal(t, i, history.Offset)
+		var row map[string]float64
+		require.NoError(t, json.Unmarshal([]byte(history.Content[0]), &row))
+		assert.Equal(t, float64(i), row["_step"])
+		assert.Equal(t, float64(iteration), row["iteration"])
+	}
+
+	assert.Equal(t, "/upload", got[5].path)
+	assert.Empty(t, got[5].auth, "pre-signed uploads carry no credentials")
+	assert.Equal(t, "package main", got[5].body)
+	assert.JSONEq(t, `{"complete": true, "exitcode": 0}`, got[6].body)
+}
@@ -0,0 +1,246 @@
+package tracking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ishanwen-byte/openevolve-go/internal/constants"
+	"github.com/ishanwen-byte/openevolve-go/internal/types"
+)
+
+// wandbHistoryFile is the run file W&B reads logged metrics from
+const wandbHistoryFile = "wandb-history.jsonl"
+
+const wandbUpsertRun = `mutation UpsertBucket($name: String, $project: String, $entity: String, $displayName: String, $config: JSONString) {
+	upsertBucket(input: {name: $name, modelName: $project, entityName: $entity, displayName: $displayName, config: $config}) {
+		bucket { name project { name entity { name } } }
+	}
+}`
+
+const wandbCreateRunFiles = `mutation CreateRunFiles($entity: String!, $project: String!, $run: String!, $files: [String!]!) {
+	createRunFiles(input: {entityName: $entity, projectName: $project, runName: $run, files: $files}) {
+		uploadHeaders
+		files { name uploadUrl }
+	}
+}`
+
+// wandbSink logs to a Weights & Biases run through the GraphQL and file
+// stream APIs the W&B client libraries use
+type wandbSink struct {
+	client  *client
+	baseURL string
+	entity  string
+	project string
+	run     string
+	started time.Time
+
+	// History rows streamed so far. Each row takes the next step, since
+	// W&B drops steps that go backwards and islands finish iterations out
+	// of order; the iteration itself is logged as a metric.
+	mu   sync.Mutex
+	rows int
+}
+
+// newWandBSink creates a run in the configured project
+func newWandBSink(ctx context.Context, config types.TrackingConfig) (*wandbSink, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("wandb tracking needs an API key")
+	}
+	baseURL := config.URI
+	if baseURL == "" {
+		baseURL = constants.DefaultWandBBaseURL
+	}
+	project := config.Experiment
+	if project == "" {
+		project = constants.DefaultTrackingExperiment
+	}
+	s := &wandbSink{
+		client: newClient(config, func(req *http.Request) {
+			req.SetBasicAuth("api", config.APIKey)
+		}),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		project: project,
+		run:     strings.ReplaceAll(uuid.New().String(), "-", "")[:8],
+		started: time.Now(),
+	}
+
+	bucket, err := s.upsertRun(ctx, map[string]interface{}{
+		"name":        s.run,
+		"project":     project,
+		"entity":      config.Entity,
+		"displayName": config.RunName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wandb run: %w", err)
+	}
+	s.entity = bucket.Project.Entity.Name
+	return s, nil
+}
+
+// wandbBucket is the run part of an upsertBucket response
+type wandbBucket struct {
+	Name    string `json:"name"`
+	Project struct {
+		Name   string `json:"name"`
+		Entity struct {
+			Name string `json:"name"`
+		} `json:"entity"`
+	} `json:"project"`
+}
+
+// upsertRun creates or updates the run
+func (s *wandbSink) upsertRun(ctx context.Context, variables map[string]interface{}) (*wandbBucket, error) {
+	var data struct {
+		UpsertBucket struct {
+			Bucket wandbBucket `json:"bucket"`
+		} `json:"upsertBucket"`
+	}
+	if err := s.graphql(ctx, wandbUpsertRun, variables, &data); err != nil {
+		return nil, err
+	}
+	return &data.UpsertBucket.Bucket, nil
+}
+
+// graphql sends a GraphQL request and decodes its data into out
+func (s *wandbSink) graphql(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	request := map[string]interface{}{"query": query, "variables": variables}
+	if err := s.client.do(ctx, http.MethodPost, s.baseURL+"/graphql", request, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		return fmt.Errorf("wandb: %s", response.Errors[0].Message)
+	}
+	if err := json.Unmarshal(response.Data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// stream posts to the run's file stream
+func (s *wandbSink) stream(ctx context.Context, body map[string]interface{}) error {
+	url := fmt.Sprintf("%s/files/%s/%s/%s/file_stream", s.baseURL, s.entity, s.project, s.run)
+	return s.client.do(ctx, http.MethodPost, url, body, nil)
+}
+
+// LogConfig sets the run's configuration
+func (s *wandbSink) LogConfig(ctx context.Context, config types.Config) error {
+	values, err := configValues(config)
+	if err != nil {
+		return err
+	}
+	wrapped := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		wrapped[key] = map[string]interface{}{"value": value}
+	}
+	data, err := json.Marshal(wrapped)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	_, err = s.upsertRun(ctx, map[string]interface{}{
+		"name":    s.run,
+		"project": s.project,
+		"entity":  s.entity,
+		"config":  string(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log config: %w", err)
+	}
+	return nil
+}
+
+// LogMetrics appends a row to the run's history
+func (s *wandbSink) LogMetrics(ctx context.Context, step int, metrics map[string]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row := make(map[string]interface{}, len(metrics)+4)
+	for key, value := range metrics {
+		row[key] = value
+	}
+	row["iteration"] = step
+	row["_step"] = s.rows
+	row["_runtime"] = time.Since(s.started).Seconds()
+	row["_timestamp"] = float64(time.Now().UnixNano()) / 1e9
+	line, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+
+	err = s.stream(ctx, map[string]interface{}{
+		"files": map[string]interface{}{
+			wandbHistoryFile: map[string]interface{}{
+				"offset":  s.rows,
+				"content": []string{string(line)},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log metrics: %w", err)
+	}
+	s.rows++
+	return nil
+}
+
+// LogArtifact uploads a file to the run's files
+func (s *wandbSink) LogArtifact(ctx context.Context, name string, data []byte) error {
+	var created struct {
+		CreateRunFiles struct {
+			UploadHeaders []string `json:"uploadHeaders"`
+			Files         []struct {
+				Name      string `json:"name"`
+				UploadURL string `json:"uploadUrl"`
+			} `json:"files"`
+		} `json:"createRunFiles"`
+	}
+	err := s.graphql(ctx, wandbCreateRunFiles, map[string]interface{}{
+		"entity":  s.entity,
+		"project": s.project,
+		"run":     s.run,
+		"files":   []string{name},
+	}, &created)
+	if err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", name, err)
+	}
+	if len(created.CreateRunFiles.Files) == 0 {
+		return fmt.Errorf("failed to upload artifact %s: no upload URL", name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, created.CreateRunFiles.Files[0].UploadURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for _, header := range created.CreateRunFiles.UploadHeaders {
+		if key, value, ok := strings.Cut(header, ":"); ok {
+			req.Header.Set(key, value)
+		}
+	}
+	// Upload URLs are pre-signed and must not carry W&B credentials
+	upload := &client{http: s.client.http}
+	if err := upload.send(req, nil); err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close marks the run finished
+func (s *wandbSink) Close(ctx context.Context) error {
+	if err := s.stream(ctx, map[string]interface{}{"complete": true, "exitcode": 0}); err != nil {
+		return fmt.Errorf("failed to finish wandb run: %w", err)
+	}
+	return nil
+}